@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"context"
+	"strconv"
+
 	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/models"
 	"wedding-invitation-backend/repositories"
@@ -20,29 +23,73 @@ func NewGuestCache(repository repositories.GuestRepository) *GuestCache {
 	}
 }
 
+// nameKey and idKey are the cache keys of a guest looked up by name or ID
+func nameKey(name string) string {
+	return "guest_name:" + name
+}
+
+func idKey(id int64) string {
+	return "guest_id:" + strconv.FormatInt(id, 10)
+}
+
 // GetByName retrieves a guest by name, using cache if available
-func (gc *GuestCache) GetByName(name string) (*models.Guest, error) {
+func (gc *GuestCache) GetByName(ctx context.Context, name string) (*models.Guest, error) {
 	// Try cache first
-	if cached, found := gc.cache.Get("guest_" + name); found {
+	if cached, found := gc.cache.Get(nameKey(name)); found {
 		if guest, ok := cached.(*models.Guest); ok {
 			return guest, nil
 		}
 	}
 	
 	// Cache miss, get from repository
-	guest, err := gc.repository.GetByName(name)
+	guest, err := gc.repository.GetByName(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Cache the result (including nil for not found)
-	gc.cache.Set("guest_"+name, guest)
+	// Cache the result (including nil for not found), unless it was read
+	// in a transaction that may yet be rolled back
+	if !repositories.InTx(ctx) {
+		gc.cache.Set(nameKey(name), guest)
+	}
 	
 	return guest, nil
 }
 
+// GetByID retrieves a guest by ID, using cache if available
+func (gc *GuestCache) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	if cached, found := gc.cache.Get(idKey(id)); found {
+		if guest, ok := cached.(*models.Guest); ok {
+			return guest, nil
+		}
+	}
+
+	guest, err := gc.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the result (including nil for not found)
+	if !repositories.InTx(ctx) {
+		gc.cache.Set(idKey(id), guest)
+	}
+
+	return guest, nil
+}
+
+// forget drops the cached lookups of a guest by ID and, when the ID lookup
+// is cached, by the name it was cached under, which differs after a rename
+func (gc *GuestCache) forget(id int64) {
+	if cached, found := gc.cache.Get(idKey(id)); found {
+		if guest, ok := cached.(*models.Guest); ok && guest != nil {
+			gc.cache.Delete(nameKey(guest.Name))
+		}
+	}
+	gc.cache.Delete(idKey(id))
+}
+
 // GetAll retrieves all guests, using cache if available
-func (gc *GuestCache) GetAll() ([]models.Guest, error) {
+func (gc *GuestCache) GetAll(ctx context.Context) ([]models.Guest, error) {
 	// Try cache first
 	if cached, found := gc.cache.Get("all_guests"); found {
 		if guests, ok := cached.([]models.Guest); ok {
@@ -51,48 +98,56 @@ func (gc *GuestCache) GetAll() ([]models.Guest, error) {
 	}
 	
 	// Cache miss, get from repository
-	guests, err := gc.repository.GetAll()
+	guests, err := gc.repository.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 	
 	// Cache the result
-	gc.cache.Set("all_guests", guests)
+	if !repositories.InTx(ctx) {
+		gc.cache.Set("all_guests", guests)
+	}
 	
 	return guests, nil
 }
 
 // Create creates a new guest and invalidates relevant caches
-func (gc *GuestCache) Create(guest *models.Guest) error {
-	err := gc.repository.Create(guest)
+func (gc *GuestCache) Create(ctx context.Context, guest *models.Guest) error {
+	err := gc.repository.Create(ctx, guest)
 	if err != nil {
 		return err
 	}
 	
 	// Invalidate caches
 	gc.cache.Delete("all_guests")
-	gc.cache.Delete("guest_" + guest.Name)
+	gc.cache.Delete(nameKey(guest.Name))
+	gc.cache.Delete(idKey(guest.ID))
 	
 	return nil
 }
 
 // Update updates a guest and invalidates relevant caches
-func (gc *GuestCache) Update(guest *models.Guest) error {
-	err := gc.repository.Update(guest)
+func (gc *GuestCache) Update(ctx context.Context, guest *models.Guest) error {
+	err := gc.repository.Update(ctx, guest)
 	if err != nil {
 		return err
 	}
 	
-	// Invalidate caches
-	gc.cache.Delete("all_guests")
-	gc.cache.Delete("guest_" + guest.Name)
-	
+	gc.Invalidate(guest)
 	return nil
 }
 
+// Invalidate drops the cached lookups of a guest, e.g. after it was
+// changed without going through the cache
+func (gc *GuestCache) Invalidate(guest *models.Guest) {
+	gc.forget(guest.ID)
+	gc.cache.Delete("all_guests")
+	gc.cache.Delete(nameKey(guest.Name))
+}
+
 // BulkCreate creates multiple guests and clears all caches
-func (gc *GuestCache) BulkCreate(guests []models.Guest) error {
-	err := gc.repository.BulkCreate(guests)
+func (gc *GuestCache) BulkCreate(ctx context.Context, guests []models.Guest) error {
+	err := gc.repository.BulkCreate(ctx, guests)
 	if err != nil {
 		return err
 	}
@@ -104,8 +159,8 @@ func (gc *GuestCache) BulkCreate(guests []models.Guest) error {
 }
 
 // BulkUpdate updates multiple guests and clears all caches
-func (gc *GuestCache) BulkUpdate(guests []models.Guest) error {
-	err := gc.repository.BulkUpdate(guests)
+func (gc *GuestCache) BulkUpdate(ctx context.Context, guests []models.Guest) error {
+	err := gc.repository.BulkUpdate(ctx, guests)
 	if err != nil {
 		return err
 	}
@@ -117,19 +172,24 @@ func (gc *GuestCache) BulkUpdate(guests []models.Guest) error {
 }
 
 // MarkInvitationOpened marks invitation as opened and invalidates caches
-func (gc *GuestCache) MarkInvitationOpened(name string) error {
-	err := gc.repository.MarkInvitationOpened(name)
+func (gc *GuestCache) MarkInvitationOpened(ctx context.Context, guestID int64) error {
+	err := gc.repository.MarkInvitationOpened(ctx, guestID)
 	if err != nil {
 		return err
 	}
 	
 	// Invalidate relevant caches
-	gc.cache.Delete("guest_" + name)
+	gc.forget(guestID)
 	gc.cache.Delete("all_guests")
 	
 	return nil
 }
 
+// Clear drops every cached guest, e.g. after the database was replaced
+func (gc *GuestCache) Clear() {
+	gc.cache.Clear()
+}
+
 // Stop stops the underlying cache
 func (gc *GuestCache) Stop() {
 	if gc.cache != nil {
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,61 +12,69 @@ import (
 // mockGuestRepo is a function-field mock for GuestRepository
 type mockGuestRepo struct {
 	GetByNameFunc            func(name string) (*models.Guest, error)
+	GetByIDFunc              func(id int64) (*models.Guest, error)
 	GetAllFunc               func() ([]models.Guest, error)
 	CreateFunc               func(guest *models.Guest) error
 	UpdateFunc               func(guest *models.Guest) error
 	BulkCreateFunc           func(guests []models.Guest) error
 	BulkUpdateFunc           func(guests []models.Guest) error
-	MarkInvitationOpenedFunc func(name string) error
+	MarkInvitationOpenedFunc func(guestID int64) error
 }
 
 // Implement GuestRepository interface
 
-func (m *mockGuestRepo) GetByName(name string) (*models.Guest, error) {
+func (m *mockGuestRepo) GetByName(ctx context.Context, name string) (*models.Guest, error) {
 	if m.GetByNameFunc != nil {
 		return m.GetByNameFunc(name)
 	}
 	return nil, nil
 }
 
-func (m *mockGuestRepo) GetAll() ([]models.Guest, error) {
+func (m *mockGuestRepo) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestRepo) GetAll(ctx context.Context) ([]models.Guest, error) {
 	if m.GetAllFunc != nil {
 		return m.GetAllFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockGuestRepo) Create(guest *models.Guest) error {
+func (m *mockGuestRepo) Create(ctx context.Context, guest *models.Guest) error {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestRepo) Update(guest *models.Guest) error {
+func (m *mockGuestRepo) Update(ctx context.Context, guest *models.Guest) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestRepo) BulkCreate(guests []models.Guest) error {
+func (m *mockGuestRepo) BulkCreate(ctx context.Context, guests []models.Guest) error {
 	if m.BulkCreateFunc != nil {
 		return m.BulkCreateFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestRepo) BulkUpdate(guests []models.Guest) error {
+func (m *mockGuestRepo) BulkUpdate(ctx context.Context, guests []models.Guest) error {
 	if m.BulkUpdateFunc != nil {
 		return m.BulkUpdateFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestRepo) MarkInvitationOpened(name string) error {
+func (m *mockGuestRepo) MarkInvitationOpened(ctx context.Context, guestID int64) error {
 	if m.MarkInvitationOpenedFunc != nil {
-		return m.MarkInvitationOpenedFunc(name)
+		return m.MarkInvitationOpenedFunc(guestID)
 	}
 	return nil
 }
@@ -92,7 +101,7 @@ func TestGuestCache_GetByName_CacheMiss(t *testing.T) {
 	t.Cleanup(func() { gc.Stop() })
 
 	// First call should hit the repository (cache miss)
-	guest, err := gc.GetByName("John Doe")
+	guest, err := gc.GetByName(context.Background(), "John Doe")
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuest, guest)
 	assert.Equal(t, 1, callCount, "repository should be called once")
@@ -116,13 +125,13 @@ func TestGuestCache_GetByName_CacheHit(t *testing.T) {
 	t.Cleanup(func() { gc.Stop() })
 
 	// First call - cache miss, hits repository
-	guest, err := gc.GetByName("Jane Smith")
+	guest, err := gc.GetByName(context.Background(), "Jane Smith")
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuest, guest)
 	assert.Equal(t, 1, callCount, "repository should be called once after first GetByName")
 
 	// Second call - cache hit, should NOT hit repository
-	guest, err = gc.GetByName("Jane Smith")
+	guest, err = gc.GetByName(context.Background(), "Jane Smith")
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuest, guest)
 	assert.Equal(t, 1, callCount, "repository should still be called only once (cache hit)")
@@ -146,7 +155,7 @@ func TestGuestCache_GetAll_CacheMiss(t *testing.T) {
 	t.Cleanup(func() { gc.Stop() })
 
 	// First call should hit the repository (cache miss)
-	guests, err := gc.GetAll()
+	guests, err := gc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuests, guests)
 	assert.Equal(t, 1, callCount, "repository should be called once")
@@ -173,16 +182,57 @@ func TestGuestCache_Create_InvalidatesCache(t *testing.T) {
 	t.Cleanup(func() { gc.Stop() })
 
 	// First GetByName - cache miss, hits repository
-	_, err := gc.GetByName("New Guest")
+	_, err := gc.GetByName(context.Background(), "New Guest")
 	assert.NoError(t, err)
 	assert.Equal(t, 1, getByNameCallCount, "repository should be called once after first GetByName")
 
 	// Create should invalidate the cache
-	err = gc.Create(&models.Guest{Name: "New Guest"})
+	err = gc.Create(context.Background(), &models.Guest{Name: "New Guest"})
 	assert.NoError(t, err)
 
 	// Second GetByName - cache should be invalidated, hits repository again
-	_, err = gc.GetByName("New Guest")
+	_, err = gc.GetByName(context.Background(), "New Guest")
 	assert.NoError(t, err)
 	assert.Equal(t, 2, getByNameCallCount, "repository should be called twice (cache invalidated by Create)")
 }
+
+func TestGuestCache_Update_Rename(t *testing.T) {
+	stored := &models.Guest{ID: 1, Name: "Jon Doe"}
+	mock := &mockGuestRepo{
+		GetByNameFunc: func(name string) (*models.Guest, error) {
+			if name == stored.Name {
+				copied := *stored
+				return &copied, nil
+			}
+			return nil, nil
+		},
+		GetByIDFunc: func(id int64) (*models.Guest, error) {
+			copied := *stored
+			return &copied, nil
+		},
+		UpdateFunc: func(guest *models.Guest) error {
+			*stored = *guest
+			return nil
+		},
+	}
+
+	gc := NewGuestCache(mock)
+	t.Cleanup(func() { gc.Stop() })
+
+	// Warm both lookups under the old name
+	_, err := gc.GetByID(context.Background(), 1)
+	assert.NoError(t, err)
+	_, err = gc.GetByName(context.Background(), "Jon Doe")
+	assert.NoError(t, err)
+
+	err = gc.Update(context.Background(), &models.Guest{ID: 1, Name: "John Doe"})
+	assert.NoError(t, err)
+
+	guest, err := gc.GetByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", guest.Name)
+
+	guest, err = gc.GetByName(context.Background(), "Jon Doe")
+	assert.NoError(t, err)
+	assert.Nil(t, guest, "the old name must not resolve from the cache")
+}
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"context"
+
 	"wedding-invitation-backend/models"
 )
 
@@ -16,13 +18,16 @@ type CacheInterface interface {
 
 // GuestCacheInterface defines the interface for guest cache operations
 type GuestCacheInterface interface {
-	GetByName(name string) (*models.Guest, error)
-	GetAll() ([]models.Guest, error)
-	Create(guest *models.Guest) error
-	Update(guest *models.Guest) error
-	BulkCreate(guests []models.Guest) error
-	BulkUpdate(guests []models.Guest) error
-	MarkInvitationOpened(name string) error
+	GetByName(ctx context.Context, name string) (*models.Guest, error)
+	GetByID(ctx context.Context, id int64) (*models.Guest, error)
+	GetAll(ctx context.Context) ([]models.Guest, error)
+	Create(ctx context.Context, guest *models.Guest) error
+	Update(ctx context.Context, guest *models.Guest) error
+	BulkCreate(ctx context.Context, guests []models.Guest) error
+	BulkUpdate(ctx context.Context, guests []models.Guest) error
+	MarkInvitationOpened(ctx context.Context, guestID int64) error
+	Invalidate(guest *models.Guest)
+	Clear()
 	Stop()
 }
 
@@ -0,0 +1,128 @@
+// Package cli implements the maintenance commands of the server binary
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+const adminUsage = `usage: wedding-invitation-backend admin <command> [flags]
+
+commands:
+  create -username NAME [-role ROLE]   create an admin (password read from stdin)
+  list                                 list admin accounts
+  set-password -username NAME          replace a password (read from stdin)
+  set-role -username NAME -role ROLE   change an admin's role
+
+roles: owner, planner, moderator, viewer`
+
+// RunAdmin runs an admin account command. Passwords are read from the first
+// line of in so they never appear in the process list or shell history.
+func RunAdmin(adminService services.AdminServiceInterface, args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(adminUsage)
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("admin "+command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	username := flags.String("username", "", "admin username")
+	role := flags.String("role", "", "admin role")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "create":
+		if *role == "" {
+			*role = models.AdminRoleOwner
+		}
+		password, err := readPassword(in, out)
+		if err != nil {
+			return err
+		}
+		admin, err := adminService.CreateAdmin(*username, password, *role)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Created %s %q (id %d)\n", admin.Role, admin.Username, admin.ID)
+		return nil
+
+	case "list":
+		admins, err := adminService.GetAllAdmins()
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tUSERNAME\tROLE\tLAST LOGIN")
+		for _, admin := range admins {
+			lastLogin := "never"
+			if admin.LastLoginAt.Valid {
+				lastLogin = admin.LastLoginAt.Time.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", admin.ID, admin.Username, admin.Role, lastLogin)
+		}
+		return tw.Flush()
+
+	case "set-password":
+		admin, err := findAdmin(adminService, *username)
+		if err != nil {
+			return err
+		}
+		password, err := readPassword(in, out)
+		if err != nil {
+			return err
+		}
+		if _, err := adminService.ChangePassword(admin.ID, password); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Password changed for %q\n", admin.Username)
+		return nil
+
+	case "set-role":
+		admin, err := findAdmin(adminService, *username)
+		if err != nil {
+			return err
+		}
+		if _, err := adminService.UpdateAdminRole(admin.ID, *role); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%q is now %s\n", admin.Username, *role)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown admin command %q\n\n%s", command, adminUsage)
+	}
+}
+
+func findAdmin(adminService services.AdminServiceInterface, username string) (*models.Admin, error) {
+	if username == "" {
+		return nil, errors.New("-username is required")
+	}
+	admin, err := adminService.GetAdminByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if admin == nil {
+		return nil, fmt.Errorf("admin %q not found", username)
+	}
+	return admin, nil
+}
+
+func readPassword(in io.Reader, out io.Writer) (string, error) {
+	fmt.Fprint(out, "Password: ")
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", errors.New("no password given on stdin")
+	}
+	fmt.Fprintln(out)
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+	"wedding-invitation-backend/services"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func setupAdminService(t *testing.T) *services.AdminService {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return services.NewAdminService(repositories.NewSQLAdminRepository(db, db))
+}
+
+func TestRunAdmin_CreateAndLogin(t *testing.T) {
+	service := setupAdminService(t)
+	var out bytes.Buffer
+
+	err := RunAdmin(service, []string{"create", "-username", "alice"}, strings.NewReader("correct horse battery\n"), &out)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `Created owner "alice"`)
+
+	admin, err := service.Authenticate("alice", "correct horse battery")
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdminRoleOwner, admin.Role)
+}
+
+func TestRunAdmin_CreateWithoutPassword(t *testing.T) {
+	service := setupAdminService(t)
+
+	err := RunAdmin(service, []string{"create", "-username", "alice"}, strings.NewReader(""), &bytes.Buffer{})
+
+	assert.Error(t, err)
+}
+
+func TestRunAdmin_SetRoleAndPassword(t *testing.T) {
+	service := setupAdminService(t)
+	_, err := service.CreateAdmin("owner", "long enough password", models.AdminRoleOwner)
+	assert.NoError(t, err)
+	_, err = service.CreateAdmin("bob", "long enough password", models.AdminRoleViewer)
+	assert.NoError(t, err)
+
+	err = RunAdmin(service, []string{"set-role", "-username", "bob", "-role", "moderator"}, nil, &bytes.Buffer{})
+	assert.NoError(t, err)
+
+	err = RunAdmin(service, []string{"set-password", "-username", "bob"}, strings.NewReader("a brand new password"), &bytes.Buffer{})
+	assert.NoError(t, err)
+
+	admin, err := service.Authenticate("bob", "a brand new password")
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdminRoleModerator, admin.Role)
+
+	var out bytes.Buffer
+	assert.NoError(t, RunAdmin(service, []string{"list"}, nil, &out))
+	assert.Contains(t, out.String(), "bob")
+	assert.Contains(t, out.String(), "moderator")
+}
+
+func TestRunAdmin_UnknownAdmin(t *testing.T) {
+	service := setupAdminService(t)
+
+	err := RunAdmin(service, []string{"set-role", "-username", "nobody", "-role", "viewer"}, nil, &bytes.Buffer{})
+
+	assert.EqualError(t, err, `admin "nobody" not found`)
+}
+
+func TestRunAdmin_UnknownCommand(t *testing.T) {
+	err := RunAdmin(setupAdminService(t), []string{"promote"}, nil, &bytes.Buffer{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "usage:")
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"wedding-invitation-backend/services"
+)
+
+const encryptionUsage = `usage: wedding-invitation-backend encryption <command>
+
+commands:
+  status      count encrypted guest fields by key
+  reencrypt   rewrite fields in plain text or under old keys with the current key,
+              and remove their values from old audit entries`
+
+// RunEncryption runs a field encryption command
+func RunEncryption(encryptionService services.EncryptionServiceInterface, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New(encryptionUsage)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "status":
+		counts, err := encryptionService.Status(ctx)
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(counts))
+		for id := range counts {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		fmt.Fprintf(out, "Current key: %s\n\n", encryptionService.CurrentKeyID())
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tVALUES")
+		for _, id := range ids {
+			label := id
+			switch {
+			case id == "":
+				label = "(plain text)"
+			case id == encryptionService.CurrentKeyID():
+				label += " (current)"
+			}
+			fmt.Fprintf(tw, "%s\t%d\n", label, counts[id])
+		}
+		return tw.Flush()
+
+	case "reencrypt":
+		rewritten, err := encryptionService.Reencrypt(ctx)
+		if err != nil {
+			return err
+		}
+		if rewritten == 0 {
+			fmt.Fprintf(out, "Everything is encrypted with %s already\n", encryptionService.CurrentKeyID())
+		} else {
+			fmt.Fprintf(out, "Re-encrypted %d guests with %s\n", rewritten, encryptionService.CurrentKeyID())
+		}
+
+		masked, err := encryptionService.MaskAuditLog(ctx)
+		if err != nil {
+			return err
+		}
+		if masked > 0 {
+			fmt.Fprintf(out, "Removed dietary restrictions from %d audit entries\n", masked)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown encryption command %q\n\n%s", args[0], encryptionUsage)
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/fieldcrypt"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+	"wedding-invitation-backend/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunEncryption(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, models.CreateAuditEntries(db, []models.AuditEntry{{
+		ActorName: "owner", Action: models.AuditActionGuestCreate, TargetType: models.AuditTargetGuest, TargetID: "1",
+		Changes: json.RawMessage(`{"DietaryRestrictions":{"before":null,"after":{"String":"Vegan","Valid":true}}}`),
+	}}))
+
+	guests := repositories.NewMemoryGuestRepository()
+	tx := repositories.NewMemoryTxManager(guests, repositories.NewMemoryCommentRepository(guests))
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "alice", DietaryRestrictions: sql.NullString{String: "Vegan", Valid: true}}))
+
+	key, err := fieldcrypt.ParseKey("2026-10", base64.StdEncoding.EncodeToString(make([]byte, fieldcrypt.KeySize)))
+	assert.NoError(t, err)
+	ring, err := fieldcrypt.NewKeyRing("2026-10", []*fieldcrypt.Key{key})
+	assert.NoError(t, err)
+	service := services.NewEncryptionService(tx, repositories.NewSQLAuditRepository(db, db), ring)
+
+	var out bytes.Buffer
+	assert.NoError(t, RunEncryption(service, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "Current key: 2026-10")
+	assert.Contains(t, out.String(), "(plain text)  1")
+
+	out.Reset()
+	assert.NoError(t, RunEncryption(service, []string{"reencrypt"}, &out))
+	assert.Equal(t, "Re-encrypted 1 guests with 2026-10\nRemoved dietary restrictions from 1 audit entries\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, RunEncryption(service, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "2026-10 (current)  1")
+	assert.NotContains(t, out.String(), "plain text")
+
+	out.Reset()
+	assert.NoError(t, RunEncryption(service, []string{"reencrypt"}, &out))
+	assert.Equal(t, "Everything is encrypted with 2026-10 already\n", out.String())
+
+	assert.Error(t, RunEncryption(service, nil, &out))
+	assert.Error(t, RunEncryption(service, []string{"rotate"}, &out))
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"wedding-invitation-backend/database"
+)
+
+const migrateUsage = `usage: wedding-invitation-backend migrate <command> [flags]
+
+commands:
+  status            list migrations and whether they are applied
+  up                apply all pending migrations
+  down [-steps N]   revert the last N applied migrations (default 1)`
+
+// RunMigrate runs a schema migration command
+func RunMigrate(migrator *database.Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state = "applied"
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04")
+			}
+			if status.Modified {
+				state = "modified"
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return tw.Flush()
+
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Fprintf(out, "Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "Schema is up to date")
+		}
+		return nil
+
+	case "down":
+		if *steps < 1 {
+			return errors.New("-steps must be at least 1")
+		}
+		reverted, err := migrator.Down(*steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "Reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "No migrations to revert")
+		}
+		return nil
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+
+	"wedding-invitation-backend/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMigrator(t *testing.T) *database.Migrator {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func TestRunMigrate_StatusUpDown(t *testing.T) {
+	migrator := setupMigrator(t)
+	var out bytes.Buffer
+
+	assert.NoError(t, RunMigrate(migrator, []string{"status"}, &out))
+	assert.Regexp(t, `0001 +initial_schema +pending`, out.String())
+	assert.Contains(t, out.String(), "pending")
+
+	out.Reset()
+	assert.NoError(t, RunMigrate(migrator, []string{"up"}, &out))
+	assert.Contains(t, out.String(), "Applied 0001_initial_schema")
+
+	out.Reset()
+	assert.NoError(t, RunMigrate(migrator, []string{"up"}, &out))
+	assert.Contains(t, out.String(), "Schema is up to date")
+
+	out.Reset()
+	assert.NoError(t, RunMigrate(migrator, []string{"down", "-steps", "1"}, &out))
+	assert.Contains(t, out.String(), "Reverted 0003_foreign_key_actions")
+
+	out.Reset()
+	assert.NoError(t, RunMigrate(migrator, []string{"status"}, &out))
+	assert.Contains(t, out.String(), "applied")
+	assert.Contains(t, out.String(), "pending")
+}
+
+func TestRunMigrate_Usage(t *testing.T) {
+	migrator := setupMigrator(t)
+	var out bytes.Buffer
+
+	err := RunMigrate(migrator, nil, &out)
+	assert.ErrorContains(t, err, "usage")
+
+	err = RunMigrate(migrator, []string{"down", "-steps", "0"}, &out)
+	assert.Error(t, err)
+}
@@ -4,20 +4,79 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Database drivers. With postgres, guests and comments are stored in
+// Postgres and every other table stays in the SQLite file at DB_PATH.
+const (
+	DBDriverSQLite   = "sqlite"
+	DBDriverPostgres = "postgres"
+)
+
+// Guest auth modes. In header mode clients keep tokens themselves and may
+// ask for cookies per login; in cookie mode tokens only travel in cookies.
+const (
+	AuthModeHeader = "header"
+	AuthModeCookie = "cookie"
+)
+
 var (
 	// Server configuration
-	ServerPort  string
-	JWTSecret   string
-	JWTExpiry   int
-	DBPath      string
-	AdminAPIKey string
+	ServerPort string
+	JWTSecret  string
+	JWTExpiry  int
+	DBPath     string
+
+	// Apply pending schema migrations at startup
+	DBAutoMigrate bool
+
+	// Where guests and comments are stored
+	DBDriver    string
+	DatabaseURL string
+
+	// How long a request may spend on database work; 0 disables the deadline
+	DBRequestTimeout time.Duration
+
+	// Read-only SQLite connections next to the single writer; 0 sends
+	// reads to the writer
+	DBReadPoolSize int
+
+	// Guest token signing keys
+	JWTKeysDir        string
+	JWTSigningKeyID   string
+	JWTRetiredKeys    string
+	JWTKeyGracePeriod time.Duration
+
+	// Keys that encrypt sensitive guest fields; without any, the fields are
+	// stored in plain text
+	FieldEncryptionKey     string
+	FieldEncryptionKeysDir string
+	FieldEncryptionKeyID   string
+
+	// Guest refresh tokens and auth cookies
+	RefreshTokenExpiry time.Duration
+	AuthCookieSecure   bool
+	AuthMode           string
+
+	// Invitation link configuration
+	InviteLinkSecret  string
+	InviteLinkBaseURL string
+	InviteLinkExpiry  time.Duration
+
+	// Guest login lockout configuration
+	LoginBackoffThreshold int
+	LoginBackoffBase      time.Duration
+	LoginBackoffMax       time.Duration
+	LoginFailureWindow    time.Duration
+	LoginMinResponseTime  time.Duration
+	LoginEventRetention   time.Duration
 
 	// Cache configuration
 	CacheGuestTTL   time.Duration
 	CacheCommentTTL time.Duration
+	CacheSessionTTL time.Duration
 
 	// Rate limit configuration
 	RateLimitAuthMax       int
@@ -29,26 +88,106 @@ var (
 
 	// Business logic configuration
 	MaxCommentsPerGuest int
+
+	// Live stream configuration
+	StreamHeartbeatInterval time.Duration
+	StreamSubscriberBuffer  int
+	StreamHistorySize       int
+
+	// Content filter configuration
+	ContentFilterEnabled         bool
+	ContentFilterWordLists       []string
+	ContentFilterWordAction      string
+	ContentFilterLinkAction      string
+	ContentFilterRepeatAction    string
+	ContentFilterRepeatThreshold int
+	ContentFilterDuplicateAction string
+
+	// Media configuration
+	MediaDir            string
+	MediaURLPrefix      string
+	MediaMaxUploadBytes int64
+	MediaMaxDimension   int
+	MediaThumbnailSize  int
+
+	// Backup configuration
+	BackupDir             string
+	BackupInterval        time.Duration
+	BackupRetention       int
+	BackupMaxRestoreBytes int64
+
+	// Admin account configuration
+	AdminJWTSecret         string
+	AdminJWTExpiry         int
+	AdminBootstrapUsername string
+	AdminBootstrapPassword string
 )
 
 func init() {
 	loadServerConfig()
+	loadJWTKeyConfig()
+	loadFieldEncryptionConfig()
+	loadRefreshTokenConfig()
+	loadInviteLinkConfig()
 	loadCacheConfig()
 	loadRateLimitConfig()
+	loadLoginGuardConfig()
 	loadBusinessConfig()
+	loadStreamConfig()
+	loadContentFilterConfig()
+	loadMediaConfig()
+	loadBackupConfig()
+	loadAdminConfig()
 }
 
 func loadServerConfig() {
 	ServerPort = getEnv("SERVER_PORT", ":8080")
-	JWTSecret = getEnv("JWT_SECRET", "test-secret")
-	JWTExpiry = getEnvInt("JWT_EXPIRY", 24*60*60)
+	// No default: a well-known secret would let anyone sign guest tokens
+	JWTSecret = getEnv("JWT_SECRET", "")
+	// Access tokens are short-lived; guests stay logged in with refresh tokens
+	JWTExpiry = getEnvInt("JWT_EXPIRY", 15*60)
 	DBPath = getEnv("DB_PATH", "data/guests.db")
-	AdminAPIKey = getEnv("ADMIN_API_KEY", "admin-api-key")
+	DBAutoMigrate = getEnvBool("DB_AUTO_MIGRATE", true)
+	DBDriver = strings.ToLower(getEnv("DB_DRIVER", DBDriverSQLite))
+	DatabaseURL = getEnv("DATABASE_URL", "")
+	DBRequestTimeout = getEnvDuration("DB_REQUEST_TIMEOUT", 5*time.Second)
+	DBReadPoolSize = getEnvInt("DB_READ_POOL_SIZE", 4)
+}
+
+func loadJWTKeyConfig() {
+	JWTKeysDir = getEnv("JWT_KEYS_DIR", "")
+	JWTSigningKeyID = getEnv("JWT_SIGNING_KEY_ID", "")
+	// kid=time pairs, parsed with the key ring
+	JWTRetiredKeys = getEnv("JWT_RETIRED_KEYS", "")
+	// By default retired keys verify until every token they signed has expired
+	JWTKeyGracePeriod = getEnvDuration("JWT_KEY_GRACE_PERIOD", time.Duration(JWTExpiry)*time.Second)
+}
+
+func loadFieldEncryptionConfig() {
+	FieldEncryptionKey = getEnv("FIELD_ENCRYPTION_KEY", "")
+	FieldEncryptionKeysDir = getEnv("FIELD_ENCRYPTION_KEYS_DIR", "")
+	FieldEncryptionKeyID = getEnv("FIELD_ENCRYPTION_KEY_ID", "")
+}
+
+func loadRefreshTokenConfig() {
+	// A session ends after this long without a refresh
+	RefreshTokenExpiry = getEnvDuration("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour)
+	AuthCookieSecure = getEnvBool("AUTH_COOKIE_SECURE", true)
+	AuthMode = strings.ToLower(getEnv("AUTH_MODE", AuthModeHeader))
+}
+
+func loadInviteLinkConfig() {
+	InviteLinkSecret = getEnv("INVITE_LINK_SECRET", "invite-test-secret")
+	// Links point here, so this is the public address of the site
+	InviteLinkBaseURL = strings.TrimSuffix(getEnv("INVITE_LINK_BASE_URL", "http://localhost:8080"), "/")
+	InviteLinkExpiry = getEnvDuration("INVITE_LINK_EXPIRY", 90*24*time.Hour)
 }
 
 func loadCacheConfig() {
 	CacheGuestTTL = getEnvDuration("CACHE_GUEST_TTL", 5*time.Minute)
 	CacheCommentTTL = getEnvDuration("CACHE_COMMENT_TTL", 2*time.Minute)
+	// Revoked sessions stay usable for at most this long on this instance
+	CacheSessionTTL = getEnvDuration("CACHE_SESSION_TTL", 30*time.Second)
 }
 
 func loadRateLimitConfig() {
@@ -60,11 +199,61 @@ func loadRateLimitConfig() {
 	RateLimitCommentWindow = getEnvDuration("RATE_LIMIT_COMMENT_WINDOW", time.Minute)
 }
 
+func loadLoginGuardConfig() {
+	// Failed logins allowed per IP and per name before lockouts start; each
+	// further failure doubles the lockout, up to the maximum
+	LoginBackoffThreshold = getEnvInt("LOGIN_BACKOFF_THRESHOLD", 5)
+	LoginBackoffBase = getEnvDuration("LOGIN_BACKOFF_BASE", 30*time.Second)
+	LoginBackoffMax = getEnvDuration("LOGIN_BACKOFF_MAX", time.Hour)
+	// Failures older than this are forgotten
+	LoginFailureWindow = getEnvDuration("LOGIN_FAILURE_WINDOW", time.Hour)
+	LoginMinResponseTime = getEnvDuration("LOGIN_MIN_RESPONSE_TIME", 300*time.Millisecond)
+	LoginEventRetention = getEnvDuration("LOGIN_EVENT_RETENTION", 30*24*time.Hour)
+}
+
 func loadBusinessConfig() {
 	MaxCommentsPerGuest = getEnvInt("MAX_COMMENTS_PER_GUEST", 2)
 }
 
+func loadStreamConfig() {
+	StreamHeartbeatInterval = getEnvDuration("STREAM_HEARTBEAT_INTERVAL", 15*time.Second)
+	StreamSubscriberBuffer = getEnvInt("STREAM_SUBSCRIBER_BUFFER", 32)
+	StreamHistorySize = getEnvInt("STREAM_HISTORY_SIZE", 100)
+}
+
+func loadContentFilterConfig() {
+	ContentFilterEnabled = getEnvBool("CONTENT_FILTER_ENABLED", true)
+	ContentFilterWordLists = getEnvList("CONTENT_FILTER_WORDLISTS", nil)
+	ContentFilterWordAction = getEnv("CONTENT_FILTER_WORD_ACTION", "mask")
+	ContentFilterLinkAction = getEnv("CONTENT_FILTER_LINK_ACTION", "moderate")
+	ContentFilterRepeatAction = getEnv("CONTENT_FILTER_REPEAT_ACTION", "moderate")
+	ContentFilterRepeatThreshold = getEnvInt("CONTENT_FILTER_REPEAT_THRESHOLD", 8)
+	ContentFilterDuplicateAction = getEnv("CONTENT_FILTER_DUPLICATE_ACTION", "reject")
+}
+
+func loadMediaConfig() {
+	MediaDir = getEnv("MEDIA_DIR", "data/media")
+	MediaURLPrefix = strings.TrimSuffix(getEnv("MEDIA_URL_PREFIX", "/media"), "/")
+	MediaMaxUploadBytes = int64(getEnvInt("MEDIA_MAX_UPLOAD_BYTES", 8<<20))
+	MediaMaxDimension = getEnvInt("MEDIA_MAX_DIMENSION", 1600)
+	MediaThumbnailSize = getEnvInt("MEDIA_THUMBNAIL_SIZE", 320)
+}
+
+func loadBackupConfig() {
+	BackupDir = getEnv("BACKUP_DIR", "data/backups")
+	BackupInterval = getEnvDuration("BACKUP_INTERVAL", 24*time.Hour)
+	BackupRetention = getEnvInt("BACKUP_RETENTION", 7)
+	BackupMaxRestoreBytes = int64(getEnvInt("BACKUP_MAX_RESTORE_BYTES", 256<<20))
+}
+
 // Helper functions
+func loadAdminConfig() {
+	AdminJWTSecret = getEnv("ADMIN_JWT_SECRET", "admin-test-secret")
+	AdminJWTExpiry = getEnvInt("ADMIN_JWT_EXPIRY", 8*60*60)
+	AdminBootstrapUsername = getEnv("ADMIN_BOOTSTRAP_USERNAME", "")
+	AdminBootstrapPassword = getEnv("ADMIN_BOOTSTRAP_PASSWORD", "")
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -81,6 +270,31 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList reads a comma-separated list, dropping empty entries
+func getEnvList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value, exists := os.LookupEnv(key); exists {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -95,12 +309,64 @@ func ValidateConfig() {
 	var warnings []string
 	var errors []string
 
-	if JWTSecret == "test-secret" {
-		warnings = append(warnings, "JWT_SECRET is using default value - this is insecure for production")
+	if JWTSecret == "" && JWTKeysDir == "" {
+		errors = append(errors, "JWT_SECRET or JWT_KEYS_DIR must be set to sign guest tokens")
+	}
+
+	if os.Getenv("ADMIN_API_KEY") != "" {
+		warnings = append(warnings, "ADMIN_API_KEY is no longer used - create scoped API tokens for scripts instead")
+	}
+
+	if AdminJWTSecret == "admin-test-secret" {
+		warnings = append(warnings, "ADMIN_JWT_SECRET is using default value - this is insecure for production")
+	}
+
+	if JWTSecret != "" && AdminJWTSecret == JWTSecret {
+		errors = append(errors, "ADMIN_JWT_SECRET must differ from JWT_SECRET so guest tokens cannot be used as admin tokens")
+	}
+
+	if FieldEncryptionKey == "" && FieldEncryptionKeysDir == "" {
+		warnings = append(warnings, "FIELD_ENCRYPTION_KEY is not set - dietary notes are stored in plain text")
+	}
+
+	if DBDriver != DBDriverSQLite && DBDriver != DBDriverPostgres {
+		errors = append(errors, "DB_DRIVER must be \"sqlite\" or \"postgres\"")
+	}
+
+	if DBDriver == DBDriverPostgres && DatabaseURL == "" {
+		errors = append(errors, "DATABASE_URL is required when DB_DRIVER is postgres")
+	}
+
+	if DBDriver == DBDriverPostgres {
+		warnings = append(warnings, "DB_DRIVER=postgres stores only guests and comments in Postgres - guest names in the session list are not supported yet")
+	}
+
+	if DBRequestTimeout < 0 {
+		errors = append(errors, "DB_REQUEST_TIMEOUT must not be negative")
+	}
+
+	if DBReadPoolSize < 0 {
+		errors = append(errors, "DB_READ_POOL_SIZE must not be negative")
+	}
+
+	if BackupInterval < 0 {
+		errors = append(errors, "BACKUP_INTERVAL must not be negative")
+	}
+
+	if BackupRetention < 0 {
+		errors = append(errors, "BACKUP_RETENTION must not be negative")
+	}
+
+	if StreamHeartbeatInterval <= 0 {
+		errors = append(errors, "STREAM_HEARTBEAT_INTERVAL must be positive")
+	}
+
+	if AuthMode != AuthModeHeader && AuthMode != AuthModeCookie {
+		errors = append(errors, "AUTH_MODE must be \"header\" or \"cookie\"")
 	}
 
-	if AdminAPIKey == "admin-api-key" {
-		warnings = append(warnings, "ADMIN_API_KEY is using default value - this is insecure for production")
+	if AuthMode == AuthModeCookie && !AuthCookieSecure {
+		warnings = append(warnings, "AUTH_COOKIE_SECURE is disabled in cookie mode - auth cookies are sent over plain HTTP")
 	}
 
 	for _, warning := range warnings {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"testing"
 	"time"
 )
@@ -9,6 +10,7 @@ func TestCacheConfigDefaults(t *testing.T) {
 	// Reset and reload
 	CacheGuestTTL = 0
 	CacheCommentTTL = 0
+	CacheSessionTTL = 0
 	loadCacheConfig()
 
 	if CacheGuestTTL != 5*time.Minute {
@@ -17,6 +19,71 @@ func TestCacheConfigDefaults(t *testing.T) {
 	if CacheCommentTTL != 2*time.Minute {
 		t.Errorf("expected default comment TTL 2m, got %v", CacheCommentTTL)
 	}
+	if CacheSessionTTL != 30*time.Second {
+		t.Errorf("expected default session TTL 30s, got %v", CacheSessionTTL)
+	}
+}
+
+func TestServerConfigDBAutoMigrate(t *testing.T) {
+	loadServerConfig()
+	if !DBAutoMigrate {
+		t.Error("expected migrations to run at startup by default")
+	}
+
+	t.Setenv("DB_AUTO_MIGRATE", "false")
+	loadServerConfig()
+	if DBAutoMigrate {
+		t.Error("expected DB_AUTO_MIGRATE=false to disable startup migrations")
+	}
+}
+
+func TestServerConfigDBDriver(t *testing.T) {
+	loadServerConfig()
+	if DBDriver != DBDriverSQLite {
+		t.Errorf("expected default driver sqlite, got %q", DBDriver)
+	}
+
+	t.Setenv("DB_DRIVER", "Postgres")
+	t.Setenv("DATABASE_URL", "postgres://wedding@localhost/wedding")
+	loadServerConfig()
+	if DBDriver != DBDriverPostgres {
+		t.Errorf("expected driver postgres, got %q", DBDriver)
+	}
+	if DatabaseURL != "postgres://wedding@localhost/wedding" {
+		t.Errorf("unexpected database URL %q", DatabaseURL)
+	}
+
+	DBDriver, DatabaseURL = DBDriverSQLite, ""
+}
+
+func TestServerConfigDBRequestTimeout(t *testing.T) {
+	loadServerConfig()
+	if DBRequestTimeout != 5*time.Second {
+		t.Errorf("expected default DB request timeout 5s, got %v", DBRequestTimeout)
+	}
+
+	t.Setenv("DB_REQUEST_TIMEOUT", "250ms")
+	loadServerConfig()
+	if DBRequestTimeout != 250*time.Millisecond {
+		t.Errorf("expected DB request timeout 250ms, got %v", DBRequestTimeout)
+	}
+
+	DBRequestTimeout = 5 * time.Second
+}
+
+func TestServerConfigDBReadPoolSize(t *testing.T) {
+	loadServerConfig()
+	if DBReadPoolSize != 4 {
+		t.Errorf("expected default read pool size 4, got %d", DBReadPoolSize)
+	}
+
+	t.Setenv("DB_READ_POOL_SIZE", "0")
+	loadServerConfig()
+	if DBReadPoolSize != 0 {
+		t.Errorf("expected read pool disabled, got %d", DBReadPoolSize)
+	}
+
+	DBReadPoolSize = 4
 }
 
 func TestRateLimitConfigDefaults(t *testing.T) {
@@ -39,3 +106,171 @@ func TestBusinessConfigDefaults(t *testing.T) {
 		t.Errorf("expected max comments 2, got %d", MaxCommentsPerGuest)
 	}
 }
+
+func TestStreamConfigDefaults(t *testing.T) {
+	loadStreamConfig()
+
+	if StreamHeartbeatInterval != 15*time.Second {
+		t.Errorf("expected default heartbeat 15s, got %v", StreamHeartbeatInterval)
+	}
+	if StreamSubscriberBuffer != 32 {
+		t.Errorf("expected default subscriber buffer 32, got %d", StreamSubscriberBuffer)
+	}
+	if StreamHistorySize != 100 {
+		t.Errorf("expected default history size 100, got %d", StreamHistorySize)
+	}
+}
+
+func TestContentFilterConfigDefaults(t *testing.T) {
+	loadContentFilterConfig()
+
+	if !ContentFilterEnabled {
+		t.Error("expected content filter enabled by default")
+	}
+	if len(ContentFilterWordLists) != 0 {
+		t.Errorf("expected no word list files by default, got %v", ContentFilterWordLists)
+	}
+	if ContentFilterWordAction != "mask" {
+		t.Errorf("expected default word action mask, got %s", ContentFilterWordAction)
+	}
+	if ContentFilterDuplicateAction != "reject" {
+		t.Errorf("expected default duplicate action reject, got %s", ContentFilterDuplicateAction)
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	t.Setenv("TEST_LIST", " a.txt, ,b.txt ")
+
+	list := getEnvList("TEST_LIST", nil)
+
+	if len(list) != 2 || list[0] != "a.txt" || list[1] != "b.txt" {
+		t.Errorf("expected [a.txt b.txt], got %v", list)
+	}
+}
+
+func TestMediaConfigTrimsURLPrefix(t *testing.T) {
+	// Cleanups run in reverse, so this reloads after the variable is restored
+	t.Cleanup(loadMediaConfig)
+	t.Setenv("MEDIA_URL_PREFIX", "https://cdn.example.com/media/")
+	loadMediaConfig()
+
+	if MediaURLPrefix != "https://cdn.example.com/media" {
+		t.Errorf("expected trailing slash trimmed, got %s", MediaURLPrefix)
+	}
+	if MediaMaxUploadBytes != 8<<20 {
+		t.Errorf("expected default max upload 8MB, got %d", MediaMaxUploadBytes)
+	}
+}
+
+func TestBackupConfig(t *testing.T) {
+	t.Cleanup(loadBackupConfig)
+	loadBackupConfig()
+
+	if BackupDir != "data/backups" || BackupInterval != 24*time.Hour || BackupRetention != 7 {
+		t.Errorf("unexpected backup defaults: %s every %v keeping %d", BackupDir, BackupInterval, BackupRetention)
+	}
+
+	t.Setenv("BACKUP_INTERVAL", "0")
+	loadBackupConfig()
+	if BackupInterval != 0 {
+		t.Errorf("expected BACKUP_INTERVAL=0 to disable scheduled backups, got %v", BackupInterval)
+	}
+}
+
+func TestAdminConfigDefaults(t *testing.T) {
+	loadAdminConfig()
+
+	if AdminJWTExpiry != 8*60*60 {
+		t.Errorf("expected default admin token expiry 8h, got %d", AdminJWTExpiry)
+	}
+	if AdminJWTSecret == JWTSecret {
+		t.Error("expected admin and guest JWT secrets to differ by default")
+	}
+}
+
+func TestJWTSecretHasNoDefault(t *testing.T) {
+	t.Cleanup(loadServerConfig)
+	t.Setenv("JWT_SECRET", "")
+	os.Unsetenv("JWT_SECRET")
+	loadServerConfig()
+
+	if JWTSecret != "" {
+		t.Errorf("expected no default JWT secret, got %q", JWTSecret)
+	}
+}
+
+func TestJWTKeyConfig(t *testing.T) {
+	t.Cleanup(loadJWTKeyConfig)
+	t.Setenv("JWT_KEYS_DIR", "/etc/wedding/jwt")
+	t.Setenv("JWT_SIGNING_KEY_ID", "2026-04")
+	t.Setenv("JWT_RETIRED_KEYS", "default=2026-04-01T12:00:00Z")
+	loadJWTKeyConfig()
+
+	if JWTKeysDir != "/etc/wedding/jwt" || JWTSigningKeyID != "2026-04" || JWTRetiredKeys != "default=2026-04-01T12:00:00Z" {
+		t.Errorf("unexpected key config: dir %q, signing key %q, retired keys %q", JWTKeysDir, JWTSigningKeyID, JWTRetiredKeys)
+	}
+	// Retired keys verify for one token lifetime unless configured otherwise
+	if JWTKeyGracePeriod != time.Duration(JWTExpiry)*time.Second {
+		t.Errorf("expected grace period to default to JWT_EXPIRY, got %v", JWTKeyGracePeriod)
+	}
+
+	t.Setenv("JWT_KEY_GRACE_PERIOD", "2h")
+	loadJWTKeyConfig()
+	if JWTKeyGracePeriod != 2*time.Hour {
+		t.Errorf("expected grace period 2h, got %v", JWTKeyGracePeriod)
+	}
+}
+
+func TestRefreshTokenConfigDefaults(t *testing.T) {
+	t.Cleanup(loadRefreshTokenConfig)
+	loadRefreshTokenConfig()
+
+	if RefreshTokenExpiry != 30*24*time.Hour {
+		t.Errorf("expected default refresh token expiry 720h, got %v", RefreshTokenExpiry)
+	}
+	if !AuthCookieSecure {
+		t.Error("expected auth cookies to be Secure by default")
+	}
+	if AuthMode != AuthModeHeader {
+		t.Errorf("expected header auth mode by default, got %q", AuthMode)
+	}
+
+	t.Setenv("REFRESH_TOKEN_EXPIRY", "168h")
+	t.Setenv("AUTH_COOKIE_SECURE", "false")
+	t.Setenv("AUTH_MODE", "Cookie")
+	loadRefreshTokenConfig()
+	if AuthMode != AuthModeCookie {
+		t.Errorf("expected cookie auth mode, got %q", AuthMode)
+	}
+	if RefreshTokenExpiry != 7*24*time.Hour || AuthCookieSecure {
+		t.Errorf("unexpected refresh config: expiry %v, secure %v", RefreshTokenExpiry, AuthCookieSecure)
+	}
+}
+
+func TestLoginGuardConfigDefaults(t *testing.T) {
+	t.Cleanup(loadLoginGuardConfig)
+	loadLoginGuardConfig()
+
+	if LoginBackoffThreshold != 5 || LoginBackoffBase != 30*time.Second || LoginBackoffMax != time.Hour {
+		t.Errorf("unexpected backoff defaults: %d, %v, %v", LoginBackoffThreshold, LoginBackoffBase, LoginBackoffMax)
+	}
+	if LoginFailureWindow != time.Hour {
+		t.Errorf("expected failure window 1h, got %v", LoginFailureWindow)
+	}
+	if LoginMinResponseTime != 300*time.Millisecond {
+		t.Errorf("expected min response time 300ms, got %v", LoginMinResponseTime)
+	}
+}
+
+func TestInviteLinkConfig(t *testing.T) {
+	t.Setenv("INVITE_LINK_BASE_URL", "https://wedding.example.com/")
+	t.Cleanup(loadInviteLinkConfig)
+	loadInviteLinkConfig()
+
+	if InviteLinkBaseURL != "https://wedding.example.com" {
+		t.Errorf("expected trailing slash trimmed, got %q", InviteLinkBaseURL)
+	}
+	if InviteLinkExpiry != 90*24*time.Hour {
+		t.Errorf("expected expiry 2160h, got %v", InviteLinkExpiry)
+	}
+}
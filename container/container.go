@@ -2,8 +2,14 @@ package container
 
 import (
 	"database/sql"
+	"log"
 	"wedding-invitation-backend/cache"
 	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/contentpolicy"
+	"wedding-invitation-backend/invitelink"
+	"wedding-invitation-backend/jwtkeys"
+	"wedding-invitation-backend/media"
+	"wedding-invitation-backend/pubsub"
 	"wedding-invitation-backend/ratelimit"
 	"wedding-invitation-backend/repositories"
 	"wedding-invitation-backend/services"
@@ -13,30 +19,97 @@ import (
 type Container struct {
 	GuestService   services.GuestServiceInterface
 	CommentService services.CommentServiceInterface
+	AdminService   services.AdminServiceInterface
+	AuditService   services.AuditServiceInterface
+	SessionService services.SessionServiceInterface
+	LoginGuard     services.LoginGuardServiceInterface
+	InviteLinks    services.InviteLinkServiceInterface
+	APITokens      services.APITokenServiceInterface
+	Backups        services.BackupServiceInterface
+	Privacy        services.PrivacyServiceInterface
 
 	// Rate limiters
 	AuthLimiter    *ratelimit.SlidingWindowLimiter
 	RSVPLimiter    *ratelimit.SlidingWindowLimiter
 	CommentLimiter *ratelimit.SlidingWindowLimiter
 
+	// Keys that sign and verify guest tokens
+	TokenKeys *jwtkeys.KeyRing
+
+	// Live event broker for the guestbook stream
+	Broker *pubsub.Broker
+
+	// Store for guestbook photos; nil when the media directory is unusable
+	MediaStore media.Store
+
 	// Cache references for shutdown
 	guestCache   cache.GuestCacheInterface
 	commentCache cache.CacheInterface
+	sessionCache cache.CacheInterface
+}
+
+// NewContainer creates and wires all dependencies, with every table in db.
+// Reads go to reader, which may be db itself.
+func NewContainer(db, reader *sql.DB, tokenKeys *jwtkeys.KeyRing) *Container {
+	return NewContainerWithRepositories(db, reader, repositories.NewSQLGuestRepository(db, reader), repositories.NewSQLCommentRepository(db, reader), repositories.NewSQLTxManager(db), tokenKeys)
 }
 
-// NewContainer creates and wires all dependencies
-func NewContainer(db *sql.DB) *Container {
+// NewContainerWithRepositories creates and wires all dependencies, storing
+// guests and comments in the given repositories, whose transactions tx
+// manages, and everything else in db
+func NewContainerWithRepositories(db, reader *sql.DB, guestRepo repositories.GuestRepository, commentRepo repositories.CommentRepository, tx repositories.TxManager, tokenKeys *jwtkeys.KeyRing) *Container {
 	// Create repositories
-	guestRepo := repositories.NewSQLGuestRepository(db)
-	commentRepo := repositories.NewSQLCommentRepository(db)
+	adminRepo := repositories.NewSQLAdminRepository(db, reader)
+	auditRepo := repositories.NewSQLAuditRepository(db, reader)
+	sessionRepo := repositories.NewSQLSessionRepository(db, reader)
+	loginGuardRepo := repositories.NewSQLLoginGuardRepository(db, reader)
+	inviteLinkRepo := repositories.NewSQLInviteLinkRepository(db, reader)
+	apiTokenRepo := repositories.NewSQLAPITokenRepository(db, reader)
+	privacyRepo := repositories.NewSQLPrivacyRepository(db, reader)
 
 	// Create caches with config TTL
 	guestCache := cache.NewGuestCache(guestRepo)
 	commentCache := cache.NewMemoryCache(config.CacheCommentTTL)
+	sessionCache := cache.NewMemoryCache(config.CacheSessionTTL)
+
+	// Create event broker for live updates
+	broker := pubsub.NewBroker(config.StreamHistorySize, config.StreamSubscriberBuffer)
+
+	// Create content policy for guestbook comments
+	commentPolicy := contentpolicy.NewPolicyFromConfig(commentRepo.HasDuplicate)
+
+	// Create photo store; without it the guestbook keeps working text-only
+	var mediaStore media.Store
+	var photos *media.Photos
+	if store, err := media.NewLocalStore(config.MediaDir); err != nil {
+		log.Printf("Photo uploads disabled: could not open media directory %s: %v", config.MediaDir, err)
+	} else {
+		mediaStore = store
+		photos = media.NewPhotos(store, config.MediaMaxDimension, config.MediaThumbnailSize)
+	}
 
 	// Create services
-	guestService := services.NewGuestService(guestRepo)
-	commentService := services.NewCommentService(commentRepo, guestService)
+	guestService := services.NewGuestService(guestRepo, auditRepo, tx)
+	commentService := services.NewCommentService(commentRepo, guestService, broker, commentPolicy, photos, tx)
+	adminService := services.NewAdminService(adminRepo)
+	auditService := services.NewAuditService(auditRepo)
+	sessionService := services.NewSessionService(sessionRepo, sessionCache)
+	loginGuard := services.NewLoginGuardService(loginGuardRepo)
+	inviteLinks := services.NewInviteLinkService(inviteLinkRepo, guestService, invitelink.NewSigner([]byte(config.InviteLinkSecret)))
+	apiTokens := services.NewAPITokenService(apiTokenRepo)
+
+	clearCaches := func() {
+		guestService.ClearCache()
+		commentService.ClearCache()
+		sessionCache.Clear()
+	}
+
+	// Create backup service; a restore replaces the data behind every cache
+	backups := services.NewBackupService(db, config.BackupDir, config.BackupRetention, clearCaches)
+
+	// Create privacy service; forgetting a guest renames them, removes their
+	// photos and ends their sessions, all of which may be cached
+	privacy := services.NewPrivacyService(guestService, commentService, privacyRepo, tx, photos, clearCaches)
 
 	// Create rate limiters with config
 	authLimiter := ratelimit.NewSlidingWindowLimiter(
@@ -55,11 +128,23 @@ func NewContainer(db *sql.DB) *Container {
 	return &Container{
 		GuestService:   guestService,
 		CommentService: commentService,
+		AdminService:   adminService,
+		AuditService:   auditService,
+		SessionService: sessionService,
+		LoginGuard:     loginGuard,
+		InviteLinks:    inviteLinks,
+		APITokens:      apiTokens,
+		Backups:        backups,
+		Privacy:        privacy,
 		AuthLimiter:    authLimiter,
 		RSVPLimiter:    rsvpLimiter,
 		CommentLimiter: commentLimiter,
+		TokenKeys:      tokenKeys,
+		Broker:         broker,
+		MediaStore:     mediaStore,
 		guestCache:     guestCache,
 		commentCache:   commentCache,
+		sessionCache:   sessionCache,
 	}
 }
 
@@ -71,4 +156,13 @@ func (c *Container) Shutdown() {
 	if c.commentCache != nil {
 		c.commentCache.Stop()
 	}
+	if c.sessionCache != nil {
+		c.sessionCache.Stop()
+	}
+	if c.Broker != nil {
+		c.Broker.Stop()
+	}
+	if c.Backups != nil {
+		c.Backups.Stop()
+	}
 }
@@ -2,12 +2,26 @@ package container
 
 import (
 	"database/sql"
+	"os"
 	"testing"
 
 	_ "modernc.org/sqlite"
+	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/services"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the photo store out of the source tree
+	dir, err := os.MkdirTemp("", "container-media-*")
+	if err != nil {
+		panic(err)
+	}
+	config.MediaDir = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 // TestNewContainer_ReturnsNonNilServices verifies that NewContainer creates
 // a container with non-nil services
 func TestNewContainer_ReturnsNonNilServices(t *testing.T) {
@@ -19,7 +33,7 @@ func TestNewContainer_ReturnsNonNilServices(t *testing.T) {
 	defer db.Close()
 
 	// Create container
-	container := NewContainer(db)
+	container := NewContainer(db, db, nil)
 
 	// Verify services are non-nil
 	if container.GuestService == nil {
@@ -47,7 +61,7 @@ func TestContainerServices_SatisfyInterfaces(t *testing.T) {
 	defer db.Close()
 
 	// Create container
-	container := NewContainer(db)
+	container := NewContainer(db, db, nil)
 
 	// Verify GuestService implements GuestServiceInterface
 	var _ services.GuestServiceInterface = container.GuestService
@@ -66,7 +80,7 @@ func TestShutdown_DoesNotPanic(t *testing.T) {
 	defer db.Close()
 
 	// Create container
-	container := NewContainer(db)
+	container := NewContainer(db, db, nil)
 
 	// Call Shutdown and verify it doesn't panic
 	func() {
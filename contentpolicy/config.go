@@ -0,0 +1,93 @@
+package contentpolicy
+
+import (
+	"embed"
+	"log"
+
+	"wedding-invitation-backend/config"
+)
+
+// Default English and Indonesian block lists, used when no files are configured
+//
+//go:embed wordlists/*.txt
+var defaultWordLists embed.FS
+
+// NewPolicyFromConfig builds the comment policy from configuration. Rules
+// with an invalid action or word lists that cannot be read are skipped with a
+// warning so a configuration mistake does not stop the guestbook.
+func NewPolicyFromConfig(lookup DuplicateLookup) *Policy {
+	if !config.ContentFilterEnabled {
+		return NewPolicy()
+	}
+
+	var rules []Rule
+
+	if action, ok := parseConfiguredAction("CONTENT_FILTER_WORD_ACTION", config.ContentFilterWordAction); ok {
+		words := loadConfiguredWordLists(config.ContentFilterWordLists)
+		rules = append(rules, NewWordListRule(words, action))
+	}
+	if action, ok := parseConfiguredAction("CONTENT_FILTER_LINK_ACTION", config.ContentFilterLinkAction); ok {
+		rules = append(rules, NewLinkRule(action))
+	}
+	if action, ok := parseConfiguredAction("CONTENT_FILTER_REPEAT_ACTION", config.ContentFilterRepeatAction); ok {
+		rules = append(rules, NewRepeatedCharRule(config.ContentFilterRepeatThreshold, action))
+	}
+	if lookup != nil {
+		if action, ok := parseConfiguredAction("CONTENT_FILTER_DUPLICATE_ACTION", config.ContentFilterDuplicateAction); ok {
+			rules = append(rules, NewDuplicateRule(lookup, action))
+		}
+	}
+
+	return NewPolicy(rules...)
+}
+
+func parseConfiguredAction(key, value string) (Action, bool) {
+	action, err := ParseAction(value)
+	if err != nil {
+		log.Printf("CONFIG WARNING: %s: %v - rule disabled", key, err)
+		return ActionAllow, false
+	}
+	return action, action != ActionAllow
+}
+
+func loadConfiguredWordLists(paths []string) []string {
+	if len(paths) == 0 {
+		return loadDefaultWordLists()
+	}
+
+	var words []string
+	for _, path := range paths {
+		list, err := LoadWordList(path)
+		if err != nil {
+			log.Printf("CONFIG WARNING: could not load word list %s: %v", path, err)
+			continue
+		}
+		words = append(words, list...)
+	}
+	return words
+}
+
+func loadDefaultWordLists() []string {
+	entries, err := defaultWordLists.ReadDir("wordlists")
+	if err != nil {
+		log.Printf("Failed to read default word lists: %v", err)
+		return nil
+	}
+
+	var words []string
+	for _, entry := range entries {
+		f, err := defaultWordLists.Open("wordlists/" + entry.Name())
+		if err != nil {
+			log.Printf("Failed to open default word list %s: %v", entry.Name(), err)
+			continue
+		}
+		list, err := parseWordList(f)
+		f.Close()
+		if err != nil {
+			log.Printf("Failed to read default word list %s: %v", entry.Name(), err)
+			continue
+		}
+		words = append(words, list...)
+	}
+	return words
+}
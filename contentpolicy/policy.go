@@ -0,0 +1,139 @@
+package contentpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Action is what a rule wants done with a comment that triggers it
+type Action int
+
+// Actions ordered by severity; the most severe triggered action wins
+const (
+	ActionAllow Action = iota
+	ActionMask
+	ActionModerate
+	ActionReject
+)
+
+// String returns the configuration name of the action
+func (a Action) String() string {
+	switch a {
+	case ActionAllow:
+		return "allow"
+	case ActionMask:
+		return "mask"
+	case ActionModerate:
+		return "moderate"
+	case ActionReject:
+		return "reject"
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// ParseAction parses an action name as used in configuration
+func ParseAction(name string) (Action, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "allow", "off", "":
+		return ActionAllow, nil
+	case "mask":
+		return ActionMask, nil
+	case "moderate":
+		return ActionModerate, nil
+	case "reject":
+		return ActionReject, nil
+	}
+	return ActionAllow, fmt.Errorf("unknown content policy action %q", name)
+}
+
+// Input is the comment being checked
+type Input struct {
+	GuestID int64
+	Content string
+}
+
+// Finding is the outcome of a single rule that matched
+type Finding struct {
+	Rule   string
+	Action Action
+	Reason string
+	// Masked is the content with offending parts masked; only used when
+	// Action is ActionMask
+	Masked string
+}
+
+// Rule checks a comment against one content policy
+type Rule interface {
+	Name() string
+	// Check returns nil when the content does not trigger the rule
+	Check(ctx context.Context, input Input) (*Finding, error)
+}
+
+// Verdict is the combined outcome of all rules
+type Verdict struct {
+	Action   Action
+	Content  string
+	Findings []Finding
+}
+
+// Reason returns a human-readable summary of the findings
+func (v *Verdict) Reason() string {
+	reasons := make([]string, 0, len(v.Findings))
+	for _, finding := range v.Findings {
+		reasons = append(reasons, finding.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// RejectedError is returned when a comment is rejected by the policy
+type RejectedError struct {
+	Rule   string
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("comment rejected by %s: %s", e.Rule, e.Reason)
+}
+
+// Policy runs a list of rules over incoming comments
+type Policy struct {
+	rules []Rule
+}
+
+// NewPolicy creates a policy from rules, which run in the given order
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Evaluate runs every rule in order. Masking rules rewrite the content seen
+// by later rules, and the first rejecting rule stops evaluation.
+func (p *Policy) Evaluate(ctx context.Context, input Input) (*Verdict, error) {
+	verdict := &Verdict{
+		Action:  ActionAllow,
+		Content: input.Content,
+	}
+
+	for _, rule := range p.rules {
+		finding, err := rule.Check(ctx, Input{GuestID: input.GuestID, Content: verdict.Content})
+		if err != nil {
+			return nil, err
+		}
+		if finding == nil || finding.Action == ActionAllow {
+			continue
+		}
+
+		verdict.Findings = append(verdict.Findings, *finding)
+		if finding.Action == ActionMask {
+			verdict.Content = finding.Masked
+		}
+		if finding.Action > verdict.Action {
+			verdict.Action = finding.Action
+		}
+		if finding.Action == ActionReject {
+			return verdict, &RejectedError{Rule: finding.Rule, Reason: finding.Reason}
+		}
+	}
+
+	return verdict, nil
+}
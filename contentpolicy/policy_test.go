@@ -0,0 +1,185 @@
+package contentpolicy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_AllowsCleanContent(t *testing.T) {
+	policy := NewPolicy(
+		NewWordListRule([]string{"bangsat"}, ActionMask),
+		NewLinkRule(ActionModerate),
+		NewRepeatedCharRule(6, ActionModerate),
+	)
+
+	verdict, err := policy.Evaluate(context.Background(), Input{GuestID: 1, Content: "Selamat menempuh hidup baru!"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ActionAllow, verdict.Action)
+	assert.Equal(t, "Selamat menempuh hidup baru!", verdict.Content)
+	assert.Empty(t, verdict.Findings)
+}
+
+func TestWordListRule_MasksWholeWordsCaseInsensitive(t *testing.T) {
+	policy := NewPolicy(NewWordListRule([]string{"Bangsat", "tai"}, ActionMask))
+
+	verdict, err := policy.Evaluate(context.Background(), Input{Content: "BANGSAT, detail is not tai"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ActionMask, verdict.Action)
+	assert.Equal(t, "*******, detail is not ***", verdict.Content)
+}
+
+func TestLinkRule_Moderates(t *testing.T) {
+	policy := NewPolicy(NewLinkRule(ActionModerate))
+
+	for _, content := range []string{
+		"visit https://spam.example/x",
+		"check www.example.org",
+		"promo at cheap-deals.shop now",
+	} {
+		verdict, err := policy.Evaluate(context.Background(), Input{Content: content})
+		assert.NoError(t, err)
+		assert.Equal(t, ActionModerate, verdict.Action, content)
+		// Moderation keeps the original text for the reviewer
+		assert.Equal(t, content, verdict.Content)
+	}
+}
+
+func TestLinkRule_Mask(t *testing.T) {
+	policy := NewPolicy(NewLinkRule(ActionMask))
+
+	verdict, err := policy.Evaluate(context.Background(), Input{Content: "see http://x.io/a now"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "see [link removed] now", verdict.Content)
+}
+
+func TestRepeatedCharRule(t *testing.T) {
+	rule := NewRepeatedCharRule(5, ActionMask)
+
+	finding, err := rule.Check(context.Background(), Input{Content: "Yaaaaaay!!!!!!!"})
+	assert.NoError(t, err)
+	assert.NotNil(t, finding)
+	assert.Equal(t, "Yaaay!!!", finding.Masked)
+
+	finding, err = rule.Check(context.Background(), Input{Content: "Sooo happy for you"})
+	assert.NoError(t, err)
+	assert.Nil(t, finding)
+}
+
+func TestRepeatedCharRule_ShortRuns(t *testing.T) {
+	rule := NewRepeatedCharRule(2, ActionMask)
+
+	tests := []struct {
+		content string
+		masked  string
+	}{
+		{"aab", "aab"},
+		{"hi!!", "hi!!"},
+		{"hi!!!!", "hi!!!"},
+		{"zz", "zz"},
+		{"Yaaaay", "Yaaay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			finding, err := rule.Check(context.Background(), Input{Content: tt.content})
+			assert.NoError(t, err)
+			if assert.NotNil(t, finding) {
+				assert.Equal(t, tt.masked, finding.Masked)
+			}
+		})
+	}
+}
+
+func TestDuplicateRule(t *testing.T) {
+	lookup := func(ctx context.Context, guestID int64, content string) (bool, error) {
+		return guestID == 1 && content == "Congrats!", nil
+	}
+	policy := NewPolicy(NewDuplicateRule(lookup, ActionReject))
+
+	_, err := policy.Evaluate(context.Background(), Input{GuestID: 1, Content: "Congrats!"})
+	var rejected *RejectedError
+	assert.True(t, errors.As(err, &rejected))
+	assert.Equal(t, "duplicate", rejected.Rule)
+
+	verdict, err := policy.Evaluate(context.Background(), Input{GuestID: 2, Content: "Congrats!"})
+	assert.NoError(t, err)
+	assert.Equal(t, ActionAllow, verdict.Action)
+}
+
+func TestDuplicateRule_LookupError(t *testing.T) {
+	lookup := func(ctx context.Context, guestID int64, content string) (bool, error) {
+		return false, errors.New("database error")
+	}
+	policy := NewPolicy(NewDuplicateRule(lookup, ActionReject))
+
+	_, err := policy.Evaluate(context.Background(), Input{GuestID: 1, Content: "Congrats!"})
+
+	assert.EqualError(t, err, "database error")
+}
+
+func TestPolicy_MostSevereActionWins(t *testing.T) {
+	policy := NewPolicy(
+		NewWordListRule([]string{"tolol"}, ActionMask),
+		NewLinkRule(ActionModerate),
+	)
+
+	verdict, err := policy.Evaluate(context.Background(), Input{Content: "tolol www.spam.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, ActionModerate, verdict.Action)
+	assert.Equal(t, "***** www.spam.com", verdict.Content)
+	assert.Len(t, verdict.Findings, 2)
+	assert.Equal(t, "contains blocked words; contains links", verdict.Reason())
+}
+
+func TestPolicy_RejectStopsEvaluation(t *testing.T) {
+	called := false
+	lookup := func(ctx context.Context, guestID int64, content string) (bool, error) {
+		called = true
+		return false, nil
+	}
+	policy := NewPolicy(
+		NewLinkRule(ActionReject),
+		NewDuplicateRule(lookup, ActionReject),
+	)
+
+	verdict, err := policy.Evaluate(context.Background(), Input{Content: "http://spam.example"})
+
+	assert.Error(t, err)
+	assert.Equal(t, ActionReject, verdict.Action)
+	assert.False(t, called)
+}
+
+func TestParseAction(t *testing.T) {
+	action, err := ParseAction(" Moderate ")
+	assert.NoError(t, err)
+	assert.Equal(t, ActionModerate, action)
+
+	_, err = ParseAction("explode")
+	assert.Error(t, err)
+}
+
+func TestLoadWordList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	err := os.WriteFile(path, []byte("# comment\nfoo\n\n  bar  \n"), 0644)
+	assert.NoError(t, err)
+
+	words, err := LoadWordList(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, words)
+}
+
+func TestLoadDefaultWordLists(t *testing.T) {
+	words := loadDefaultWordLists()
+
+	assert.Contains(t, words, "bangsat")
+	assert.Contains(t, words, "bullshit")
+}
@@ -0,0 +1,217 @@
+package contentpolicy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// WordListRule flags comments containing words from a block list
+type WordListRule struct {
+	words  map[string]struct{}
+	action Action
+}
+
+// NewWordListRule creates a word filter; matching is case-insensitive and on whole words
+func NewWordListRule(words []string, action Action) *WordListRule {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			set[word] = struct{}{}
+		}
+	}
+	return &WordListRule{words: set, action: action}
+}
+
+// LoadWordList reads one word per line, skipping blank lines and # comments
+func LoadWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words, err := parseWordList(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading word list %s: %w", path, err)
+	}
+	return words, nil
+}
+
+func parseWordList(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+func (r *WordListRule) Name() string {
+	return "word_list"
+}
+
+func (r *WordListRule) Check(ctx context.Context, input Input) (*Finding, error) {
+	runes := []rune(input.Content)
+	matched := false
+
+	// Walk the content word by word, masking matches in place
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := strings.ToLower(string(runes[start:end]))
+		if _, blocked := r.words[word]; blocked {
+			matched = true
+			for i := start; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+
+	if !matched {
+		return nil, nil
+	}
+	return &Finding{
+		Rule:   r.Name(),
+		Action: r.action,
+		Reason: "contains blocked words",
+		Masked: string(runes),
+	}, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// linkPattern matches URLs, www. hosts and bare domains with common TLDs
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://\S+|www\.\S+|[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|id|co|io|me|ly|gl|xyz|info|biz|link|site|online|shop|top|click)\b(?:/\S*)?)`)
+
+// LinkRule flags comments containing links
+type LinkRule struct {
+	action Action
+}
+
+// NewLinkRule creates a link filter
+func NewLinkRule(action Action) *LinkRule {
+	return &LinkRule{action: action}
+}
+
+func (r *LinkRule) Name() string {
+	return "links"
+}
+
+func (r *LinkRule) Check(ctx context.Context, input Input) (*Finding, error) {
+	if !linkPattern.MatchString(input.Content) {
+		return nil, nil
+	}
+	return &Finding{
+		Rule:   r.Name(),
+		Action: r.action,
+		Reason: "contains links",
+		Masked: linkPattern.ReplaceAllString(input.Content, "[link removed]"),
+	}, nil
+}
+
+// RepeatedCharRule flags comments with long runs of the same character,
+// such as "!!!!!!!!!!" or "aaaaaaaaaa"
+type RepeatedCharRule struct {
+	threshold int
+	action    Action
+}
+
+// NewRepeatedCharRule creates a rule triggered by threshold or more repeats of one character
+func NewRepeatedCharRule(threshold int, action Action) *RepeatedCharRule {
+	if threshold < 2 {
+		threshold = 2
+	}
+	return &RepeatedCharRule{threshold: threshold, action: action}
+}
+
+func (r *RepeatedCharRule) Name() string {
+	return "repeated_characters"
+}
+
+func (r *RepeatedCharRule) Check(ctx context.Context, input Input) (*Finding, error) {
+	var masked strings.Builder
+	matched := false
+
+	runes := []rune(input.Content)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && unicode.ToLower(runes[end]) == unicode.ToLower(runes[start]) {
+			end++
+		}
+		run := runes[start:end]
+		if len(run) >= r.threshold && !unicode.IsSpace(runes[start]) {
+			matched = true
+			// Collapse the run to at most three characters
+			run = run[:min(3, len(run))]
+		}
+		masked.WriteString(string(run))
+		start = end
+	}
+
+	if !matched {
+		return nil, nil
+	}
+	return &Finding{
+		Rule:   r.Name(),
+		Action: r.action,
+		Reason: "contains repeated characters",
+		Masked: masked.String(),
+	}, nil
+}
+
+// DuplicateLookup reports whether the guest already posted the given content
+type DuplicateLookup func(ctx context.Context, guestID int64, content string) (bool, error)
+
+// DuplicateRule flags a guest posting the same content twice. There is
+// nothing to mask in a duplicate, so a mask action is treated as reject.
+type DuplicateRule struct {
+	lookup DuplicateLookup
+	action Action
+}
+
+// NewDuplicateRule creates a duplicate content check backed by lookup
+func NewDuplicateRule(lookup DuplicateLookup, action Action) *DuplicateRule {
+	if action == ActionMask {
+		action = ActionReject
+	}
+	return &DuplicateRule{lookup: lookup, action: action}
+}
+
+func (r *DuplicateRule) Name() string {
+	return "duplicate"
+}
+
+func (r *DuplicateRule) Check(ctx context.Context, input Input) (*Finding, error) {
+	duplicate, err := r.lookup(ctx, input.GuestID, input.Content)
+	if err != nil {
+		return nil, err
+	}
+	if !duplicate {
+		return nil, nil
+	}
+	return &Finding{
+		Rule:   r.Name(),
+		Action: r.action,
+		Reason: "duplicates an earlier comment",
+	}, nil
+}
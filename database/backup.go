@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+)
+
+// ErrInvalidBackup is returned when a file offered for restore is not an
+// intact database with a schema this binary can run
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// Snapshot writes a consistent copy of the SQLite database db to path with
+// VACUUM INTO. The copy is compacted and self-contained, without a journal
+// or WAL file. path must not exist yet.
+func Snapshot(ctx context.Context, db *sql.DB, path string) error {
+	_, err := db.ExecContext(ctx, `VACUUM INTO ?`, path)
+	return err
+}
+
+// ValidateBackup checks the SQLite file at path before it is restored: it
+// must pass an integrity check, have been created by a migrated install and
+// have no migrations this binary does not know. Older backups are migrated
+// to the current schema in place, so path should be a scratch copy.
+func ValidateBackup(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: integrity check failed: %s", ErrInvalidBackup, result)
+	}
+
+	var tracked int
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&tracked)
+	if err != nil {
+		return err
+	}
+	if tracked == 0 {
+		return fmt.Errorf("%w: no schema_migrations table", ErrInvalidBackup)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	if _, err := migrator.Up(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	rows, err := db.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return fmt.Errorf("%w: rows reference missing parents", ErrInvalidBackup)
+	}
+	return rows.Err()
+}
+
+// Restore replaces the contents of db with the SQLite file at path using
+// SQLite's online backup API. Every page is copied in one write
+// transaction, so readers see either the old database or the new one.
+func Restore(ctx context.Context, db *sql.DB, path string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		restorer, ok := driverConn.(interface {
+			NewRestore(srcURI string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("database driver cannot restore backups")
+		}
+
+		restore, err := restorer.NewRestore(path)
+		if err != nil {
+			return err
+		}
+		if _, err := restore.Step(-1); err != nil {
+			restore.Finish()
+			return err
+		}
+		return restore.Finish()
+	})
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupFileDB opens a migrated database in a file, which VACUUM INTO and
+// the backup API need
+func setupFileDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func guestNames(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM guests ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db := setupFileDB(t, filepath.Join(dir, "guests.db"))
+	_, err := db.Exec("INSERT INTO guests (name) VALUES ('alice')")
+	assert.NoError(t, err)
+
+	snapshot := filepath.Join(dir, "snapshot.db")
+	assert.NoError(t, Snapshot(ctx, db, snapshot))
+	assert.NoError(t, ValidateBackup(ctx, snapshot))
+
+	// Changes after the snapshot are undone by restoring it
+	_, err = db.Exec("INSERT INTO guests (name) VALUES ('bob')")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, guestNames(t, db))
+
+	assert.NoError(t, Restore(ctx, db, snapshot))
+	assert.Equal(t, []string{"alice"}, guestNames(t, db))
+
+	// The snapshot path must be new
+	assert.Error(t, Snapshot(ctx, db, snapshot))
+}
+
+func TestValidateBackup_RejectsUnusableFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	assert.NoError(t, os.WriteFile(garbage, []byte("definitely not a database, just some text padding it out"), 0600))
+	assert.ErrorIs(t, ValidateBackup(ctx, garbage), ErrInvalidBackup)
+
+	// A database that was never migrated, e.g. some other application's
+	unrelated := filepath.Join(dir, "unrelated.db")
+	db, err := sql.Open("sqlite", unrelated)
+	assert.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE notes (body TEXT)")
+	assert.NoError(t, err)
+	db.Close()
+	assert.ErrorIs(t, ValidateBackup(ctx, unrelated), ErrInvalidBackup)
+
+	// A backup from a newer release
+	newer := filepath.Join(dir, "newer.db")
+	db = setupFileDB(t, newer)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (9999, 'future', 'x', CURRENT_TIMESTAMP)")
+	assert.NoError(t, err)
+	db.Close()
+	assert.ErrorIs(t, ValidateBackup(ctx, newer), ErrInvalidBackup)
+}
+
+func TestValidateBackup_MigratesOlderBackups(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "old.db")
+	db := setupFileDB(t, path)
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+	_, err = migrator.Down(1)
+	assert.NoError(t, err)
+	db.Close()
+
+	assert.NoError(t, ValidateBackup(ctx, path))
+
+	db, err = sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+	migrator, err = NewMigrator(db)
+	assert.NoError(t, err)
+	pending, err := migrator.Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
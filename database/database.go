@@ -1,8 +1,11 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,9 +15,22 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-var DB *sql.DB
+var (
+	// DB is the SQLite writer. SQLite allows one writer at a time, so it
+	// has a single connection.
+	DB *sql.DB
+	// ReadDB is the pool of read-only SQLite connections, which WAL lets
+	// read alongside the writer. It is DB itself when there is no pool.
+	ReadDB *sql.DB
+)
 
-func InitDB() error {
+// busyTimeout is how long, in milliseconds, a connection waits for a lock
+// held by another connection before failing
+const busyTimeout = 5000
+
+// OpenDB opens the database at config.DBPath, and the Postgres database
+// when DB_DRIVER is postgres, without touching the schema
+func OpenDB() error {
 	// Get database path from config
 	dbPath := config.DBPath
 
@@ -26,29 +42,171 @@ func InitDB() error {
 		}
 	}
 
+	// Sessions and invitation links refer to guests, which are not in this
+	// file when they are stored in Postgres
+	foreignKeys := config.DBDriver != config.DBDriverPostgres
+
 	var err error
-	DB, err = sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL")
+	DB, ReadDB, err = OpenSQLite(dbPath, config.DBReadPoolSize, foreignKeys)
 	if err != nil {
 		return err
 	}
 
-	// Configure connection pool for SQLite
-	// SQLite only allows one writer at a time, so limit connections
-	DB.SetMaxOpenConns(1)
-	DB.SetMaxIdleConns(1)
-	DB.SetConnMaxLifetime(time.Hour)
+	if config.DBDriver == config.DBDriverPostgres {
+		PostgresDB, err = OpenPostgres(config.DatabaseURL)
+		if err != nil {
+			return fmt.Errorf("opening Postgres: %w", err)
+		}
+	}
+	return nil
+}
+
+// OpenSQLite opens the SQLite database at path in WAL mode with a writer
+// of one connection and a pool of up to readers read-only connections. With
+// no readers, the writer is returned as the reader too. foreignKeys turns on
+// foreign key enforcement for every connection.
+func OpenSQLite(path string, readers int, foreignKeys bool) (writer, reader *sql.DB, err error) {
+	writerPragmas := []string{
+		"journal_mode(WAL)",
+		fmt.Sprintf("busy_timeout(%d)", busyTimeout),
+		"synchronous(NORMAL)",
+	}
+	readerPragmas := []string{
+		fmt.Sprintf("busy_timeout(%d)", busyTimeout),
+		"query_only(1)",
+	}
+	if foreignKeys {
+		writerPragmas = append(writerPragmas, "foreign_keys(1)")
+		readerPragmas = append(readerPragmas, "foreign_keys(1)")
+	}
+
+	writer, err = sql.Open("sqlite", sqliteDSN(path, writerPragmas...))
+	if err != nil {
+		return nil, nil, err
+	}
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+	writer.SetConnMaxLifetime(time.Hour)
+
+	// The writer switches the file to WAL before any reader opens it
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, nil, err
+	}
+	if readers <= 0 {
+		return writer, writer, nil
+	}
+
+	reader, err = sql.Open("sqlite", sqliteDSN(path, readerPragmas...))
+	if err != nil {
+		writer.Close()
+		return nil, nil, err
+	}
+	reader.SetMaxOpenConns(readers)
+	reader.SetMaxIdleConns(readers)
+	reader.SetConnMaxLifetime(time.Hour)
+
+	if err := reader.Ping(); err != nil {
+		reader.Close()
+		writer.Close()
+		return nil, nil, err
+	}
+	return writer, reader, nil
+}
+
+// sqliteDSN returns the data source name of the file at path with the
+// pragmas run on every new connection
+func sqliteDSN(path string, pragmas ...string) string {
+	query := url.Values{"_pragma": pragmas}
+	return path + "?" + query.Encode()
+}
+
+// Migrators returns a migrator for each open database, SQLite first
+func Migrators() ([]*Migrator, error) {
+	migrator, err := NewMigrator(DB)
+	if err != nil {
+		return nil, err
+	}
+	migrators := []*Migrator{migrator}
+
+	if PostgresDB != nil {
+		migrator, err := NewPostgresMigrator(PostgresDB)
+		if err != nil {
+			return nil, err
+		}
+		migrators = append(migrators, migrator)
+	}
+	return migrators, nil
+}
 
-	// Test the connection
-	if err := DB.Ping(); err != nil {
+// Close closes every open database
+func Close() {
+	if PostgresDB != nil {
+		PostgresDB.Close()
+	}
+	if ReadDB != nil && ReadDB != DB {
+		ReadDB.Close()
+	}
+	DB.Close()
+}
+
+// InitDB opens the database and makes sure its schema is current. Pending
+// migrations are applied unless DB_AUTO_MIGRATE is off, in which case they
+// must be applied with `migrate up` first.
+func InitDB() error {
+	if err := OpenDB(); err != nil {
 		return err
 	}
 
-	// Create guests table if it doesn't exist
-	// Uses shared schema from database/schema.go
-	if err := CreateSchema(DB); err != nil {
+	// Problems are reported but do not stop the server, which may still
+	// serve most requests
+	problems, err := CheckIntegrity(context.Background(), DB, config.DBDriver != config.DBDriverPostgres)
+	if err != nil {
+		return fmt.Errorf("checking database integrity: %w", err)
+	}
+	for _, problem := range problems {
+		log.Printf("Database integrity problem: %s", problem)
+	}
+
+	migrators, err := Migrators()
+	if err != nil {
 		return err
 	}
+	for _, migrator := range migrators {
+		if config.DBAutoMigrate {
+			if _, err := migrator.Up(); err != nil {
+				return fmt.Errorf("migrating %s schema: %w", migrator.Dialect(), err)
+			}
+			continue
+		}
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d %s schema migrations are pending; run `migrate up` or set DB_AUTO_MIGRATE=true", len(pending), migrator.Dialect())
+		}
+	}
 
 	log.Println("Database initialized successfully")
 	return nil
 }
+
+// InitMemoryDB opens a private in-memory database with the current schema,
+// for demo mode. Its one connection is never recycled, since the data only
+// lives as long as the connection, and it serves reads as well.
+func InitMemoryDB() error {
+	var err error
+	// Guests live in memory, so foreign keys to them stay off
+	DB, err = sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return err
+	}
+
+	DB.SetMaxOpenConns(1)
+	DB.SetMaxIdleConns(1)
+	DB.SetConnMaxLifetime(0)
+	ReadDB = DB
+
+	return CreateSchema(DB)
+}
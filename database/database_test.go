@@ -0,0 +1,41 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenSQLite_ReaderPool(t *testing.T) {
+	writer, reader, err := OpenSQLite(filepath.Join(t.TempDir(), "guests.db"), 2, true)
+	assert.NoError(t, err)
+	defer writer.Close()
+	defer reader.Close()
+
+	var journalMode string
+	assert.NoError(t, writer.QueryRow("PRAGMA journal_mode").Scan(&journalMode))
+	assert.Equal(t, "wal", journalMode)
+
+	_, err = writer.Exec("CREATE TABLE notes (body TEXT)")
+	assert.NoError(t, err)
+	_, err = writer.Exec("INSERT INTO notes (body) VALUES ('hello')")
+	assert.NoError(t, err)
+
+	// Readers see committed writes but cannot write themselves
+	var count int
+	assert.NoError(t, reader.QueryRow("SELECT COUNT(*) FROM notes").Scan(&count))
+	assert.Equal(t, 1, count)
+	_, err = reader.Exec("INSERT INTO notes (body) VALUES ('nope')")
+	assert.Error(t, err)
+	assert.Equal(t, 2, reader.Stats().MaxOpenConnections)
+}
+
+func TestOpenSQLite_NoReaders(t *testing.T) {
+	writer, reader, err := OpenSQLite(filepath.Join(t.TempDir(), "guests.db"), 0, true)
+	assert.NoError(t, err)
+	defer writer.Close()
+
+	assert.Same(t, writer, reader)
+	assert.Equal(t, 1, writer.Stats().MaxOpenConnections)
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// CheckIntegrity runs SQLite's integrity check on db and looks for orphans,
+// rows whose parent row does not exist, and returns a description of each
+// problem found. Foreign keys are only enforced from migration 3 on, so
+// older databases may have orphans. Unless guestsHere is set, rows
+// referring to guests are not checked, since the guests are stored
+// elsewhere.
+func CheckIntegrity(ctx context.Context, db *sql.DB, guestsHere bool) ([]string, error) {
+	var problems []string
+
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if result != "ok" {
+			problems = append(problems, "integrity check: "+result)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	orphans, err := countOrphans(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orphans {
+		if o.parent == "guests" && !guestsHere {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%d %s rows refer to %s rows that do not exist", o.count, o.table, o.parent))
+	}
+	return problems, nil
+}
+
+type orphanCount struct {
+	table, parent string
+	count         int
+}
+
+// countOrphans counts the rows of each table that violate a foreign key,
+// by parent table
+func countOrphans(ctx context.Context, db interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}) ([]orphanCount, error) {
+	rows, err := db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[[2]string]int)
+	for rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, err
+		}
+		counts[[2]string{table, parent}]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	orphans := make([]orphanCount, 0, len(counts))
+	for key, count := range counts {
+		orphans = append(orphans, orphanCount{table: key[0], parent: key[1], count: count})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].table != orphans[j].table {
+			return orphans[i].table < orphans[j].table
+		}
+		return orphans[i].parent < orphans[j].parent
+	})
+	return orphans, nil
+}
+
+// addedOrphans describes the tables that have more orphans in after than
+// in before
+func addedOrphans(before, after []orphanCount) []string {
+	had := make(map[[2]string]int, len(before))
+	for _, o := range before {
+		had[[2]string{o.table, o.parent}] = o.count
+	}
+	var added []string
+	for _, o := range after {
+		if o.count > had[[2]string{o.table, o.parent}] {
+			added = append(added, fmt.Sprintf("%s rows refer to missing %s", o.table, o.parent))
+		}
+	}
+	return added
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenSQLite_ForeignKeys(t *testing.T) {
+	for _, enforced := range []bool{true, false} {
+		writer, reader, err := OpenSQLite(filepath.Join(t.TempDir(), "guests.db"), 2, enforced)
+		assert.NoError(t, err)
+		defer writer.Close()
+		defer reader.Close()
+
+		for _, db := range []*sql.DB{writer, reader} {
+			var foreignKeys bool
+			assert.NoError(t, db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys))
+			assert.Equal(t, enforced, foreignKeys)
+		}
+	}
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	// Foreign keys off, as before they were enforced
+	writer, reader, err := OpenSQLite(filepath.Join(t.TempDir(), "guests.db"), 0, false)
+	assert.NoError(t, err)
+	defer reader.Close()
+	assert.NoError(t, CreateSchema(writer))
+	ctx := context.Background()
+
+	problems, err := CheckIntegrity(ctx, writer, true)
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+
+	_, err = writer.Exec(`
+		INSERT INTO comments (guest_id, content) VALUES (99, 'orphan'), (99, 'another');
+		INSERT INTO sessions (id, guest_id, user_agent, ip_address, created_at, expires_at)
+			VALUES ('s1', 99, 'test', '127.0.0.1', '2026-06-01 10:00:00', '2026-07-01 10:00:00');
+		INSERT INTO refresh_tokens (session_id, token_hash, created_at, expires_at)
+			VALUES ('gone', 'hash', '2026-06-01 10:00:00', '2026-07-01 10:00:00');
+	`)
+	assert.NoError(t, err)
+
+	problems, err = CheckIntegrity(ctx, writer, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"2 comments rows refer to guests rows that do not exist",
+		"1 refresh_tokens rows refer to sessions rows that do not exist",
+		"1 sessions rows refer to guests rows that do not exist",
+	}, problems)
+
+	// With guests stored elsewhere only the other references are checked
+	problems, err = CheckIntegrity(ctx, writer, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1 refresh_tokens rows refer to sessions rows that do not exist"}, problems)
+}
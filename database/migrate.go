@@ -0,0 +1,416 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations are SQL files named <version>_<name>.up.sql, each with a
+// matching .down.sql. They are applied in version order, each in its own
+// transaction, so the files must not contain BEGIN or COMMIT. The SQLite
+// migrations are in migrations, the Postgres ones in migrations/postgres.
+//
+//go:embed migrations/*.sql migrations/postgres/*.sql
+var migrationFiles embed.FS
+
+// SQL dialects the migrator supports
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+)
+
+var (
+	// ErrMigrationModified is returned when an applied migration's file no
+	// longer matches the checksum recorded when it was applied
+	ErrMigrationModified = errors.New("applied migration has been modified")
+	// ErrUnknownMigration is returned when the database has a migration
+	// applied that this binary does not know, e.g. after a downgrade
+	ErrUnknownMigration = errors.New("database has an unknown migration applied")
+)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus is a migration together with whether it is applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Modified is set when the file changed after the migration was applied
+	Modified bool
+}
+
+// Migrator applies migrations to a database and records them in the
+// schema_migrations table
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the SQLite migrations embedded in the
+// binary
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	return NewMigratorFS(db, DialectSQLite, migrationFiles, "migrations")
+}
+
+// NewPostgresMigrator creates a migrator for the Postgres migrations
+// embedded in the binary
+func NewPostgresMigrator(db *sql.DB) (*Migrator, error) {
+	return NewMigratorFS(db, DialectPostgres, migrationFiles, "migrations/postgres")
+}
+
+// NewMigratorFS creates a migrator for the migrations in dir of fsys
+func NewMigratorFS(db *sql.DB, dialect string, fsys fs.FS, dir string) (*Migrator, error) {
+	if dialect != DialectSQLite && dialect != DialectPostgres {
+		return nil, fmt.Errorf("unsupported migration dialect %q", dialect)
+	}
+	migrations, err := loadMigrations(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Dialect returns the SQL dialect of the migrator's database
+func (m *Migrator) Dialect() string {
+	return m.dialect
+}
+
+// loadMigrations reads and pairs the up and down files in dir
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		var direction string
+		switch {
+		case strings.HasSuffix(filename, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(filename, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(filename, "."+direction+".sql")
+		versionText, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>.%s.sql", filename, direction)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// applied returns the recorded migrations by version, creating the
+// schema_migrations table on first use
+func (m *Migrator) applied() (map[int64]appliedMigration, error) {
+	timestampType := "DATETIME"
+	if m.dialect == DialectPostgres {
+		timestampType = "TIMESTAMPTZ"
+	}
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at ` + timestampType + ` NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query(`SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var record appliedMigration
+		if err := rows.Scan(&version, &record.checksum, &record.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = record
+	}
+	return applied, rows.Err()
+}
+
+// Status lists every known migration and whether it is applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkUnknown(applied); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = record.appliedAt
+			statuses[i].Modified = record.checksum != migration.Checksum
+		}
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations Up would apply. Returns
+// ErrMigrationModified if an applied migration was edited.
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.Modified {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMigrationModified, status.Version, status.Name)
+		}
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order and returns them. It stops at
+// the first failure; the migrations before it stay applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) > 0 && m.dialect == DialectSQLite {
+		if err := m.adoptLegacySchema(); err != nil {
+			return nil, fmt.Errorf("preparing database created before migrations: %w", err)
+		}
+	}
+
+	for i, migration := range pending {
+		err := m.run(migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.rebind(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`),
+				migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	return pending, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// them
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := statuses[i]
+		if !migration.Applied {
+			continue
+		}
+		err := m.run(migration.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.rebind(`DELETE FROM schema_migrations WHERE version = ?`), migration.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		log.Printf("Reverted migration %04d_%s", migration.Version, migration.Name)
+		reverted = append(reverted, migration.Migration)
+	}
+	return reverted, nil
+}
+
+// checkUnknown fails if the database has migrations applied that are not in
+// this binary
+func (m *Migrator) checkUnknown(applied map[int64]appliedMigration) error {
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+	for version := range applied {
+		if !known[version] {
+			return fmt.Errorf("%w: version %d", ErrUnknownMigration, version)
+		}
+	}
+	return nil
+}
+
+// rebind rewrites ? placeholders for the migrator's dialect
+func (m *Migrator) rebind(query string) string {
+	if m.dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// run executes a migration's SQL and record in one transaction
+func (m *Migrator) run(statements string, record func(tx *sql.Tx) error) error {
+	if m.dialect == DialectPostgres {
+		// Postgres checks foreign keys as it goes and runs DDL in
+		// transactions
+		tx, err := m.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.Exec(statements); err != nil {
+			return err
+		}
+		if err := record(tx); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	// SQLite: foreign keys are switched off meanwhile so tables can be
+	// rebuilt, and checked before committing
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var foreignKeys bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return err
+	}
+	if foreignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Orphans from before foreign keys were enforced are reported at
+	// startup; only new ones fail the migration
+	before, err := countOrphans(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(statements); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+
+	after, err := countOrphans(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if added := addedOrphans(before, after); len(added) > 0 {
+		return fmt.Errorf("migration leaves rows that violate foreign keys: %s", strings.Join(added, ", "))
+	}
+
+	return tx.Commit()
+}
+
+// adoptLegacySchema adds the columns that CreateSchema used to add to
+// existing tables, so databases created before migrations match the
+// baseline. Tables that do not exist yet are left to the migrations.
+func (m *Migrator) adoptLegacySchema() error {
+	columns := []struct{ table, column, definition string }{
+		{"guests", "first_opened_at", "DATETIME"},
+		{"comments", "status", "TEXT NOT NULL DEFAULT 'approved'"},
+		{"comments", "moderation_reason", "TEXT NOT NULL DEFAULT ''"},
+		{"comments", "photo_key", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(m.db, c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("adding %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrator_UpOnFreshDatabase(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrator.migrations), len(applied))
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied, status.Name)
+		assert.False(t, status.Modified, status.Name)
+	}
+
+	// Running again is a no-op
+	applied, err = migrator.Up()
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// Guests who have not replied can be stored
+	_, err = db.Exec("INSERT INTO guests (name) VALUES ('alice')")
+	assert.NoError(t, err)
+
+	// Foreign keys are back on after the table rebuild
+	var foreignKeys bool
+	assert.NoError(t, db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys))
+	assert.True(t, foreignKeys)
+}
+
+func TestMigrator_AdoptsLegacyDatabase(t *testing.T) {
+	db := setupTestDB(t)
+	// The schema of an early install: attending is NOT NULL and later
+	// columns are missing
+	_, err := db.Exec(`
+		CREATE TABLE guests (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			attending INTEGER NOT NULL,
+			plus_ones INTEGER DEFAULT 0,
+			dietary_restrictions TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guest_id INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (guest_id) REFERENCES guests(id)
+		);
+		INSERT INTO guests (id, name, attending) VALUES (7, 'alice', 1);
+		INSERT INTO comments (guest_id, content) VALUES (7, 'Congratulations!');
+	`)
+	assert.NoError(t, err)
+	// Added by the old startup code before migrations existed
+	_, err = db.Exec("ALTER TABLE guests ADD COLUMN first_opened_at DATETIME")
+	assert.NoError(t, err)
+	_, err = db.Exec("UPDATE guests SET first_opened_at = '2024-06-01 10:00:00'")
+	assert.NoError(t, err)
+
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	var name string
+	var attending sql.NullBool
+	var firstOpenedAt sql.NullString
+	err = db.QueryRow("SELECT name, attending, first_opened_at FROM guests WHERE id = 7").Scan(&name, &attending, &firstOpenedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+	assert.True(t, attending.Bool)
+	assert.True(t, firstOpenedAt.Valid)
+
+	var status string
+	err = db.QueryRow("SELECT status FROM comments WHERE guest_id = 7").Scan(&status)
+	assert.NoError(t, err)
+	assert.Equal(t, "approved", status)
+
+	_, err = db.Exec("INSERT INTO guests (name) VALUES ('bob')")
+	assert.NoError(t, err)
+}
+
+func testMigrations(up string) fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/0001_widgets.up.sql":   {Data: []byte(up)},
+		"migrations/0001_widgets.down.sql": {Data: []byte("DROP TABLE widgets;")},
+		"migrations/0002_gadgets.up.sql":   {Data: []byte("CREATE TABLE gadgets (id INTEGER PRIMARY KEY);")},
+		"migrations/0002_gadgets.down.sql": {Data: []byte("DROP TABLE gadgets;")},
+	}
+}
+
+func TestMigrator_DetectsModifiedMigration(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigratorFS(db, DialectSQLite, testMigrations("CREATE TABLE widgets (id INTEGER PRIMARY KEY);"), "migrations")
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	edited, err := NewMigratorFS(db, DialectSQLite, testMigrations("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT);"), "migrations")
+	assert.NoError(t, err)
+
+	statuses, err := edited.Status()
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].Modified)
+	assert.False(t, statuses[1].Modified)
+
+	_, err = edited.Up()
+	assert.ErrorIs(t, err, ErrMigrationModified)
+}
+
+func TestMigrator_DownRevertsNewestFirst(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigratorFS(db, DialectSQLite, testMigrations("CREATE TABLE widgets (id INTEGER PRIMARY KEY);"), "migrations")
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	reverted, err := migrator.Down(1)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, 1)
+	assert.Equal(t, int64(2), reverted[0].Version)
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'gadgets'").Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestMigrator_FailedMigrationRollsBack(t *testing.T) {
+	db := setupTestDB(t)
+	migrations := testMigrations("CREATE TABLE widgets (id INTEGER PRIMARY KEY);")
+	migrations["migrations/0002_gadgets.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE gadgets (id INTEGER PRIMARY KEY); SELECT * FROM missing;")}
+	migrator, err := NewMigratorFS(db, DialectSQLite, migrations, "migrations")
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up()
+	assert.Error(t, err)
+	assert.Len(t, applied, 1)
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'gadgets'").Scan(&count))
+	assert.Equal(t, 0, count)
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestMigrator_UnknownAppliedVersion(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigratorFS(db, DialectSQLite, testMigrations("CREATE TABLE widgets (id INTEGER PRIMARY KEY);"), "migrations")
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (9, 'future', '', CURRENT_TIMESTAMP)")
+	assert.NoError(t, err)
+
+	_, err = migrator.Status()
+	assert.ErrorIs(t, err, ErrUnknownMigration)
+}
+
+func TestLoadMigrations_RequiresDownFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0001_widgets.up.sql": {Data: []byte("CREATE TABLE widgets (id INTEGER PRIMARY KEY);")},
+	}
+	_, err := NewMigratorFS(setupTestDB(t), DialectSQLite, fsys, "migrations")
+	assert.Error(t, err)
+}
+
+func TestMigrator_PostgresPlaceholders(t *testing.T) {
+	migrator, err := NewPostgresMigrator(nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrator.migrations)
+	assert.Equal(t, "DELETE FROM t WHERE a = $1 AND b = $2", migrator.rebind("DELETE FROM t WHERE a = ? AND b = ?"))
+
+	sqlite, err := NewMigrator(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE a = ?", sqlite.rebind("DELETE FROM t WHERE a = ?"))
+}
+
+func TestMigrator_ForeignKeyActions(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	// Rows written before the migration keep their IDs and stay searchable
+	_, err = migrator.Down(1)
+	assert.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO guests (id, name) VALUES (7, 'alice'), (8, 'bob');
+		INSERT INTO comments (id, guest_id, content) VALUES (3, 7, 'Congratulations!'), (4, 8, 'Cheers');
+		INSERT INTO sessions (id, guest_id, user_agent, ip_address, created_at, expires_at)
+			VALUES ('s1', 7, 'test', '127.0.0.1', '2026-06-01 10:00:00', '2026-07-01 10:00:00');
+		INSERT INTO refresh_tokens (session_id, token_hash, created_at, expires_at)
+			VALUES ('s1', 'hash', '2026-06-01 10:00:00', '2026-07-01 10:00:00');
+		INSERT INTO invite_links (id, guest_id, created_at, expires_at)
+			VALUES ('l1', 7, '2026-06-01 10:00:00', '2026-07-01 10:00:00');
+	`)
+	assert.NoError(t, err)
+	// An orphan from before enforcement does not stop the migration
+	_, err = db.Exec("PRAGMA foreign_keys = OFF; INSERT INTO comments (guest_id, content) VALUES (99, 'orphan'); PRAGMA foreign_keys = ON")
+	assert.NoError(t, err)
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+
+	var id int64
+	assert.NoError(t, db.QueryRow("SELECT rowid FROM comments_fts WHERE comments_fts MATCH 'congratulations'").Scan(&id))
+	assert.Equal(t, int64(3), id)
+
+	// Deleting a guest takes everything that refers to them along
+	_, err = db.Exec("DELETE FROM guests WHERE id = 7")
+	assert.NoError(t, err)
+	for table, want := range map[string]int{"comments": 2, "sessions": 0, "refresh_tokens": 0, "invite_links": 0, "comments_fts": 2} {
+		var count int
+		assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM "+table).Scan(&count))
+		assert.Equal(t, want, count, table)
+	}
+
+	_, err = db.Exec("INSERT INTO comments (guest_id, content) VALUES (99, 'orphan')")
+	assert.ErrorContains(t, err, "FOREIGN KEY constraint failed")
+
+	var plan string
+	assert.NoError(t, db.QueryRow("EXPLAIN QUERY PLAN SELECT * FROM comments ORDER BY created_at DESC LIMIT 20").Scan(new(int), new(int), new(int), &plan))
+	assert.Contains(t, plan, "idx_comments_created_at")
+}
+
+func TestMigrator_RejectsNewOrphans(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigratorFS(db, DialectSQLite, testMigrations(`
+		CREATE TABLE parents (id INTEGER PRIMARY KEY);
+		CREATE TABLE children (parent_id INTEGER REFERENCES parents(id));
+		INSERT INTO children (parent_id) VALUES (1);
+	`), "migrations")
+	assert.NoError(t, err)
+
+	_, err = migrator.Up()
+	assert.ErrorContains(t, err, "children rows refer to missing parents")
+
+	var tables int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'children'").Scan(&tables))
+	assert.Equal(t, 0, tables)
+}
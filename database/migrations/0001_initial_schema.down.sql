@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS comments_fts_update;
+DROP TRIGGER IF EXISTS comments_fts_delete;
+DROP TRIGGER IF EXISTS comments_fts_insert;
+DROP TABLE IF EXISTS comments_fts;
+
+DROP TABLE IF EXISTS api_tokens;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS login_lockouts;
+DROP TABLE IF EXISTS invite_links;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS guests;
@@ -0,0 +1,3 @@
+-- Nothing to undo: a NOT NULL attending column cannot hold the guests who
+-- have not replied yet, and the rebuilt table is otherwise the same.
+SELECT 1;
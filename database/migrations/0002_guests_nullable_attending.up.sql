@@ -0,0 +1,24 @@
+-- Databases from before July 2024 have a NOT NULL attending column, so
+-- guests who have not replied cannot be stored. SQLite cannot change a
+-- column's constraints, so the table is rebuilt. Unlike the hand-run
+-- script this replaces, the column list is explicit and first_opened_at
+-- is kept.
+
+CREATE TABLE guests_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    attending INTEGER,
+    plus_ones INTEGER DEFAULT 0,
+    dietary_restrictions TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    first_opened_at DATETIME
+);
+
+INSERT INTO guests_new
+    (id, name, attending, plus_ones, dietary_restrictions, created_at, updated_at, first_opened_at)
+SELECT id, name, attending, plus_ones, dietary_restrictions, created_at, updated_at, first_opened_at
+FROM guests;
+
+DROP TABLE guests;
+ALTER TABLE guests_new RENAME TO guests;
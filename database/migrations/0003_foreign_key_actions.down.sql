@@ -0,0 +1,87 @@
+-- Rebuilds the child tables without ON DELETE actions and drops the
+-- comment indexes.
+
+DROP INDEX idx_comments_created_at;
+DROP INDEX idx_comments_guest_id;
+
+CREATE TABLE comments_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guest_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'approved',
+    moderation_reason TEXT NOT NULL DEFAULT '',
+    photo_key TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guest_id) REFERENCES guests(id)
+);
+
+INSERT INTO comments_new (id, guest_id, content, status, moderation_reason, photo_key, created_at)
+SELECT id, guest_id, content, status, moderation_reason, photo_key, created_at FROM comments;
+
+DROP TABLE comments;
+ALTER TABLE comments_new RENAME TO comments;
+
+CREATE TABLE sessions_new (
+    id TEXT PRIMARY KEY,
+    guest_id INTEGER NOT NULL,
+    user_agent TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    FOREIGN KEY (guest_id) REFERENCES guests(id)
+);
+
+INSERT INTO sessions_new (id, guest_id, user_agent, ip_address, created_at, expires_at, revoked_at)
+SELECT id, guest_id, user_agent, ip_address, created_at, expires_at, revoked_at FROM sessions;
+
+DROP TABLE sessions;
+ALTER TABLE sessions_new RENAME TO sessions;
+CREATE INDEX idx_sessions_guest_id ON sessions (guest_id);
+
+CREATE TABLE refresh_tokens_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    FOREIGN KEY (session_id) REFERENCES sessions(id)
+);
+
+INSERT INTO refresh_tokens_new (id, session_id, token_hash, created_at, expires_at, used_at)
+SELECT id, session_id, token_hash, created_at, expires_at, used_at FROM refresh_tokens;
+
+DROP TABLE refresh_tokens;
+ALTER TABLE refresh_tokens_new RENAME TO refresh_tokens;
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+
+CREATE TABLE invite_links_new (
+    id TEXT PRIMARY KEY,
+    guest_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    last_used_at DATETIME,
+    FOREIGN KEY (guest_id) REFERENCES guests(id)
+);
+
+INSERT INTO invite_links_new (id, guest_id, created_at, expires_at, revoked_at, last_used_at)
+SELECT id, guest_id, created_at, expires_at, revoked_at, last_used_at FROM invite_links;
+
+DROP TABLE invite_links;
+ALTER TABLE invite_links_new RENAME TO invite_links;
+CREATE INDEX idx_invite_links_guest_id ON invite_links (guest_id);
+
+CREATE TRIGGER comments_fts_insert AFTER INSERT ON comments BEGIN
+    INSERT INTO comments_fts(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER comments_fts_delete AFTER DELETE ON comments BEGIN
+    INSERT INTO comments_fts(comments_fts, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER comments_fts_update AFTER UPDATE OF content ON comments BEGIN
+    INSERT INTO comments_fts(comments_fts, rowid, content) VALUES ('delete', old.id, old.content);
+    INSERT INTO comments_fts(rowid, content) VALUES (new.id, new.content);
+END;
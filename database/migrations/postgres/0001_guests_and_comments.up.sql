@@ -0,0 +1,31 @@
+-- With DB_DRIVER=postgres, guests and comments live in Postgres; the other
+-- tables stay in the SQLite database.
+
+CREATE TABLE guests (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    attending BOOLEAN,
+    plus_ones INTEGER NOT NULL DEFAULT 0,
+    dietary_restrictions TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    first_opened_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_guests_name ON guests (name);
+
+CREATE TABLE comments (
+    id BIGSERIAL PRIMARY KEY,
+    guest_id BIGINT NOT NULL REFERENCES guests (id),
+    content TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'approved',
+    moderation_reason TEXT NOT NULL DEFAULT '',
+    photo_key TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_comments_guest_id ON comments (guest_id, created_at DESC);
+CREATE INDEX idx_comments_status ON comments (status, created_at);
+
+-- Full-text search over comment content, matching the SQLite FTS5 table
+CREATE INDEX idx_comments_content_fts ON comments USING GIN (to_tsvector('simple', content));
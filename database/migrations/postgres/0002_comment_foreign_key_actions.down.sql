@@ -0,0 +1,5 @@
+DROP INDEX idx_comments_created_at;
+
+ALTER TABLE comments
+    DROP CONSTRAINT comments_guest_id_fkey,
+    ADD CONSTRAINT comments_guest_id_fkey FOREIGN KEY (guest_id) REFERENCES guests (id);
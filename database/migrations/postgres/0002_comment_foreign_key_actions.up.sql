@@ -0,0 +1,6 @@
+-- Deleting a guest deletes their comments, as on SQLite
+ALTER TABLE comments
+    DROP CONSTRAINT comments_guest_id_fkey,
+    ADD CONSTRAINT comments_guest_id_fkey FOREIGN KEY (guest_id) REFERENCES guests (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_comments_created_at ON comments (created_at);
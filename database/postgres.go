@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresDB holds guests and comments when DB_DRIVER is postgres, and is
+// nil otherwise
+var PostgresDB *sql.DB
+
+// OpenPostgres opens a Postgres connection pool. Unlike SQLite, Postgres
+// handles concurrent writers, so the pool is not limited to one connection.
+func OpenPostgres(url string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(20)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(time.Hour)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -5,36 +5,41 @@ import (
 	"log"
 )
 
-// CreateSchema creates all tables if they don't exist.
-// This is the single source of truth for the database schema.
+// CreateSchema brings the database schema up to date by applying every
+// pending migration. The migrations in database/migrations are the single
+// source of truth for the schema.
 func CreateSchema(db *sql.DB) error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS guests (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		attending INTEGER,
-		plus_ones INTEGER DEFAULT 0,
-		dietary_restrictions TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		first_opened_at DATETIME
-	);
-
-	CREATE TABLE IF NOT EXISTS comments (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		guest_id INTEGER NOT NULL,
-		content TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (guest_id) REFERENCES guests(id)
-	);
-	`
-
-	_, err := db.Exec(schema)
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		log.Printf("Failed to create schema: %v", err)
+		log.Printf("Failed to load migrations: %v", err)
+		return err
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		log.Printf("Failed to migrate schema: %v", err)
 		return err
 	}
 
 	log.Println("Database schema initialized successfully")
 	return nil
 }
+
+// addColumnIfMissing adds a column to an existing table if it is not there
+// yet. Missing tables are left alone.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	var tables, count int
+	row := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table)
+	if err := row.Scan(&tables); err != nil {
+		return err
+	}
+	row = db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column)
+	if err := row.Scan(&count); err != nil {
+		return err
+	}
+	if tables == 0 || count > 0 {
+		return nil
+	}
+
+	_, err := db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	return err
+}
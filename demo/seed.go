@@ -0,0 +1,77 @@
+// Package demo seeds fake guests and guestbook comments for demo mode
+package demo
+
+import (
+	"context"
+	"database/sql"
+
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+// Owner account created in demo mode. The data is thrown away on exit, so
+// a fixed password is fine.
+const (
+	AdminUsername = "demo"
+	AdminPassword = "demo-password"
+)
+
+type seedGuest struct {
+	name      string
+	attending *bool
+	plusOnes  int
+	dietary   string
+	opened    bool
+	comments  []string
+}
+
+func reply(attending bool) *bool {
+	return &attending
+}
+
+var seedGuests = []seedGuest{
+	{name: "Alice Hartono", attending: reply(true), plusOnes: 1, dietary: "vegetarian", opened: true,
+		comments: []string{"Congratulations! We can't wait to celebrate with you in Bali."}},
+	{name: "Budi Santoso", attending: reply(true), opened: true,
+		comments: []string{"Selamat menempuh hidup baru!"}},
+	{name: "Charlotte Weber", attending: reply(false), opened: true,
+		comments: []string{"So sorry we can't make it - sending all our love from Berlin."}},
+	{name: "Dewi Lestari", attending: reply(true), plusOnes: 2, dietary: "no peanuts", opened: true},
+	{name: "Edward Lim", opened: true},
+	{name: "Fatimah Rahman", attending: reply(true), dietary: "halal", opened: true,
+		comments: []string{"Wishing you a lifetime of happiness together!"}},
+	{name: "George Tan"},
+	{name: "Hana Suzuki"},
+}
+
+// Seed fills empty repositories with fake guests, replies and comments
+func Seed(ctx context.Context, guests repositories.GuestRepository, comments repositories.CommentRepository) error {
+	for _, seed := range seedGuests {
+		guest := &models.Guest{
+			Name:     seed.name,
+			PlusOnes: seed.plusOnes,
+		}
+		if seed.attending != nil {
+			guest.Attending = sql.NullBool{Bool: *seed.attending, Valid: true}
+		}
+		if seed.dietary != "" {
+			guest.DietaryRestrictions = sql.NullString{String: seed.dietary, Valid: true}
+		}
+		if err := guests.Create(ctx, guest); err != nil {
+			return err
+		}
+
+		if seed.opened {
+			if err := guests.MarkInvitationOpened(ctx, guest.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, content := range seed.comments {
+			if err := comments.Create(ctx, &models.Comment{GuestID: guest.ID, Content: content}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package demo
+
+import (
+	"context"
+	"testing"
+
+	"wedding-invitation-backend/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeed(t *testing.T) {
+	guests := repositories.NewMemoryGuestRepository()
+	comments := repositories.NewMemoryCommentRepository(guests)
+
+	assert.NoError(t, Seed(context.Background(), guests, comments))
+
+	all, err := guests.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, all, len(seedGuests))
+
+	alice, err := guests.GetByName(context.Background(), "Alice Hartono")
+	assert.NoError(t, err)
+	assert.True(t, alice.Attending.Bool)
+	assert.True(t, alice.FirstOpenedAt.Valid)
+
+	page, err := comments.GetAllWithGuests(context.Background(), 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, page.TotalCount)
+
+	results, err := comments.Search(context.Background(), "bali", 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, results.TotalCount)
+}
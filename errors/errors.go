@@ -1,6 +1,8 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 )
@@ -23,12 +25,13 @@ func (e *AppError) Unwrap() error {
 
 // Predefined errors for common cases
 var (
-	ErrUnauthorized     = &AppError{Code: http.StatusUnauthorized, Message: "Unauthorized"}
-	ErrForbidden        = &AppError{Code: http.StatusForbidden, Message: "Forbidden"}
-	ErrNotFound         = &AppError{Code: http.StatusNotFound, Message: "Resource not found"}
-	ErrBadRequest       = &AppError{Code: http.StatusBadRequest, Message: "Bad request"}
-	ErrInternal         = &AppError{Code: http.StatusInternalServerError, Message: "Internal server error"}
-	ErrTooManyRequests  = &AppError{Code: http.StatusTooManyRequests, Message: "Too many requests"}
+	ErrUnauthorized       = &AppError{Code: http.StatusUnauthorized, Message: "Unauthorized"}
+	ErrForbidden          = &AppError{Code: http.StatusForbidden, Message: "Forbidden"}
+	ErrNotFound           = &AppError{Code: http.StatusNotFound, Message: "Resource not found"}
+	ErrBadRequest         = &AppError{Code: http.StatusBadRequest, Message: "Bad request"}
+	ErrInternal           = &AppError{Code: http.StatusInternalServerError, Message: "Internal server error"}
+	ErrTooManyRequests    = &AppError{Code: http.StatusTooManyRequests, Message: "Too many requests"}
+	ErrServiceUnavailable = &AppError{Code: http.StatusServiceUnavailable, Message: "Service temporarily unavailable, please try again"}
 )
 
 // NewValidationError creates a validation error with details
@@ -49,6 +52,13 @@ func WrapError(err error, message string) *AppError {
 			Err:     appErr,
 		}
 	}
+	if IsTimeout(err) {
+		return &AppError{
+			Code:    http.StatusServiceUnavailable,
+			Message: message,
+			Err:     err,
+		}
+	}
 	return &AppError{
 		Code:    http.StatusInternalServerError,
 		Message: message,
@@ -56,6 +66,12 @@ func WrapError(err error, message string) *AppError {
 	}
 }
 
+// IsTimeout reports whether an error comes from a request whose deadline
+// passed before its database work finished
+func IsTimeout(err error) bool {
+	return stderrors.Is(err, context.DeadlineExceeded)
+}
+
 // IsAppError checks if an error is an AppError
 func IsAppError(err error) (*AppError, bool) {
 	if appErr, ok := err.(*AppError); ok {
@@ -1,6 +1,8 @@
 package errors
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"testing"
 )
@@ -39,6 +41,7 @@ func TestPredefinedErrors(t *testing.T) {
 		{"BadRequest", ErrBadRequest, http.StatusBadRequest},
 		{"Internal", ErrInternal, http.StatusInternalServerError},
 		{"TooManyRequests", ErrTooManyRequests, http.StatusTooManyRequests},
+		{"ServiceUnavailable", ErrServiceUnavailable, http.StatusServiceUnavailable},
 	}
 
 	for _, tt := range tests {
@@ -71,3 +74,17 @@ func TestWrapError(t *testing.T) {
 		t.Error("expected inner error to be preserved")
 	}
 }
+
+func TestWrapError_Timeout(t *testing.T) {
+	err := fmt.Errorf("query guests: %w", context.DeadlineExceeded)
+	wrapped := WrapError(err, "Failed to retrieve RSVPs")
+	if wrapped.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", wrapped.Code)
+	}
+	if !IsTimeout(wrapped) {
+		t.Error("expected the wrapped error to still be a timeout")
+	}
+	if IsTimeout(context.Canceled) {
+		t.Error("expected a cancelled request not to count as a timeout")
+	}
+}
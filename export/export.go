@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"wedding-invitation-backend/models"
+)
+
+// ErrUnknownFormat is returned for export formats that are not supported
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Format identifies an export file format
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+	FormatHTML Format = "html"
+	FormatPDF  Format = "pdf"
+)
+
+// ParseFormat converts a format name to a Format
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case FormatJSON, FormatCSV, FormatHTML, FormatPDF:
+		return format, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Guestbook is the content of an export. Comments are rendered in the order
+// given, so callers pass them sorted oldest first.
+type Guestbook struct {
+	Title       string
+	GeneratedAt time.Time
+	Comments    []models.CommentWithGuest
+}
+
+// Write renders the guestbook to w in the given format
+func Write(w io.Writer, format Format, book *Guestbook) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, book)
+	case FormatCSV:
+		return writeCSV(w, book)
+	case FormatHTML:
+		return writeHTML(w, book)
+	case FormatPDF:
+		return writePDF(w, book)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// displayName is shown for comments whose guest has since been removed
+func displayName(comment models.CommentWithGuest) string {
+	if comment.GuestName == "" {
+		return "A guest"
+	}
+	return comment.GuestName
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 January 2006, 15:04")
+}
+
+func writeJSON(w io.Writer, book *Guestbook) error {
+	comments := book.Comments
+	if comments == nil {
+		comments = []models.CommentWithGuest{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Title       string                    `json:"title"`
+		GeneratedAt time.Time                 `json:"generated_at"`
+		Count       int                       `json:"count"`
+		Comments    []models.CommentWithGuest `json:"comments"`
+	}{
+		Title:       book.Title,
+		GeneratedAt: book.GeneratedAt,
+		Count:       len(comments),
+		Comments:    comments,
+	})
+}
+
+func writeCSV(w io.Writer, book *Guestbook) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "guest_name", "content", "created_at", "photo_url"}); err != nil {
+		return err
+	}
+	for _, comment := range book.Comments {
+		record := []string{
+			strconv.FormatInt(comment.ID, 10),
+			csvSafe(comment.GuestName),
+			csvSafe(comment.Content),
+			comment.CreatedAt.Format(time.RFC3339),
+			comment.PhotoURL,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvSafe stops spreadsheet applications from evaluating guest-written
+// text that starts like a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
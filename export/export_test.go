@@ -0,0 +1,112 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+)
+
+func testGuestbook(contents ...string) *Guestbook {
+	book := &Guestbook{
+		Title:       "Rina & Budi",
+		GeneratedAt: time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC),
+	}
+	for i, content := range contents {
+		book.Comments = append(book.Comments, models.CommentWithGuest{
+			Comment: models.Comment{
+				ID:        int64(i + 1),
+				Content:   content,
+				Status:    models.CommentStatusApproved,
+				CreatedAt: time.Date(2026, 6, 20, 18, i, 0, 0, time.UTC),
+			},
+			GuestName: "Guest " + string(rune('A'+i)),
+		})
+	}
+	return book
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat(" PDF ")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatPDF, format)
+
+	_, err = ParseFormat("docx")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, FormatJSON, testGuestbook("First", "Second"))
+	assert.NoError(t, err)
+
+	var decoded struct {
+		Title    string `json:"title"`
+		Count    int    `json:"count"`
+		Comments []struct {
+			Content   string
+			GuestName string
+		} `json:"comments"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "Rina & Budi", decoded.Title)
+	assert.Equal(t, 2, decoded.Count)
+	assert.Equal(t, "First", decoded.Comments[0].Content)
+	assert.Equal(t, "Guest B", decoded.Comments[1].GuestName)
+}
+
+func TestWriteJSON_EmptyGuestbook(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, FormatJSON, testGuestbook())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"comments": []`)
+}
+
+func TestWriteCSV_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, FormatCSV, testGuestbook("=HYPERLINK(\"http://evil\")", "Plain, with comma"))
+	assert.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, []string{"id", "guest_name", "content", "created_at", "photo_url"}, records[0])
+	assert.Equal(t, `'=HYPERLINK("http://evil")`, records[1][2])
+	assert.Equal(t, "Plain, with comma", records[2][2])
+	assert.Equal(t, "2026-06-20T18:01:00Z", records[2][3])
+}
+
+func TestWriteHTML_EscapesContent(t *testing.T) {
+	book := testGuestbook("<script>alert(1)</script>\n\nSecond line")
+	book.Comments[0].PhotoURL = "/media/abc.jpg"
+	book.Comments[0].GuestName = ""
+
+	var buf bytes.Buffer
+	err := Write(&buf, FormatHTML, book)
+	assert.NoError(t, err)
+
+	html := buf.String()
+	assert.NotContains(t, html, "<script>")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.Contains(t, html, "<p>Second line</p>")
+	assert.Contains(t, html, `<img src="/media/abc.jpg"`)
+	assert.Contains(t, html, "A guest")
+	assert.Contains(t, html, "20 June 2026, 18:00")
+	assert.Contains(t, html, "<title>Rina &amp; Budi</title>")
+}
+
+func TestWriteHTML_KeepsOrder(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, FormatHTML, testGuestbook("Earliest", "Middle", "Latest"))
+	assert.NoError(t, err)
+
+	html := buf.String()
+	assert.Less(t, strings.Index(html, "Earliest"), strings.Index(html, "Middle"))
+	assert.Less(t, strings.Index(html, "Middle"), strings.Index(html, "Latest"))
+}
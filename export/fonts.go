@@ -0,0 +1,97 @@
+package export
+
+// Advance widths of the standard Helvetica faces for ASCII 32-126, in
+// 1/1000 em, from the Adobe font metrics. The oblique face shares the
+// regular widths.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space - /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	278, 278, 584, 584, 584, 556, 1015, // : - @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	278, 278, 278, 469, 556, 333, // [ - `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a-m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n-z
+	334, 260, 334, 584, // { - ~
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278, // space - /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	333, 333, 584, 584, 584, 611, 975, // : - @
+	722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	333, 278, 333, 584, 556, 333, // [ - `
+	556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, // a-m
+	611, 611, 611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, // n-z
+	389, 280, 389, 584, // { - ~
+}
+
+// Widths of the WinAnsi punctuation above 0x7F that guests commonly type;
+// other Latin-1 characters are close enough to the average letter width
+var winAnsiWidths = map[byte]int{
+	0x85: 1000, // ellipsis
+	0x91: 222,  // left single quote
+	0x92: 222,  // right single quote
+	0x93: 333,  // left double quote
+	0x94: 333,  // right double quote
+	0x95: 350,  // bullet
+	0x96: 556,  // en dash
+	0x97: 1000, // em dash
+	0xA0: 278,  // no-break space
+}
+
+const defaultGlyphWidth = 556
+
+// winAnsiSpecials maps the characters WinAnsiEncoding places in 0x80-0x9F
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// toWinAnsi converts text to the single-byte encoding of the standard PDF
+// fonts. Emoji and their joiners are dropped since the fonts cannot draw
+// them; other unsupported characters become '?'.
+func toWinAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7F:
+			out = append(out, byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case winAnsiSpecials[r] != 0:
+			out = append(out, winAnsiSpecials[r])
+		case r < 0x20, r == 0x7F, r > 0xFFFF, r == 0x200D, r >= 0xFE00 && r <= 0xFE0F, r >= 0x2600 && r <= 0x27BF:
+			// control characters and emoji
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// textWidth returns the width of WinAnsi text in points
+func textWidth(text []byte, bold bool, size float64) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, c := range text {
+		switch {
+		case c >= 32 && c <= 126:
+			total += widths[c-32]
+		case winAnsiWidths[c] != 0:
+			total += winAnsiWidths[c]
+		default:
+			total += defaultGlyphWidth
+		}
+	}
+	return float64(total) * size / 1000
+}
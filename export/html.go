@@ -0,0 +1,32 @@
+package export
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"strings"
+)
+
+//go:embed templates/guestbook.html
+var templates embed.FS
+
+var guestbookTemplate = template.Must(template.New("guestbook.html").Funcs(template.FuncMap{
+	"displayName": displayName,
+	"formatDate":  formatDate,
+	"paragraphs":  paragraphs,
+}).ParseFS(templates, "templates/guestbook.html"))
+
+func writeHTML(w io.Writer, book *Guestbook) error {
+	return guestbookTemplate.Execute(w, book)
+}
+
+// paragraphs splits comment text on line breaks, dropping empty lines
+func paragraphs(content string) []string {
+	var result []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
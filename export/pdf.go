@@ -0,0 +1,295 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Page geometry in points (A4)
+const (
+	pageWidth     = 595.28
+	pageHeight    = 841.89
+	pageMargin    = 56.0
+	contentTop    = pageHeight - pageMargin
+	contentBottom = pageMargin + 12
+	footerY       = 34.0
+)
+
+// Type sizes and spacing in points
+const (
+	titleSize    = 26.0
+	summarySize  = 11.0
+	nameSize     = 12.0
+	dateSize     = 9.0
+	bodySize     = 11.0
+	bodyLeading  = 15.5
+	entrySpacing = 22.0
+	footerSize   = 9.0
+)
+
+type pdfFont struct {
+	resource string
+	baseFont string
+	bold     bool
+}
+
+// The three standard fonts used by the book. Standard fonts need no
+// embedding, which keeps the generator small and the output portable.
+var (
+	fontRegular = pdfFont{resource: "F1", baseFont: "Helvetica"}
+	fontBold    = pdfFont{resource: "F2", baseFont: "Helvetica-Bold", bold: true}
+	fontItalic  = pdfFont{resource: "F3", baseFont: "Helvetica-Oblique"}
+	pdfFonts    = []pdfFont{fontRegular, fontBold, fontItalic}
+)
+
+// pdfPage collects the content stream operators of one page
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (p *pdfPage) text(font pdfFont, size, x, y, gray float64, text []byte) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s g %s %s Td (", font.resource, pdfNum(size), pdfNum(gray), pdfNum(x), pdfNum(y))
+	writePDFString(&p.content, text)
+	p.content.WriteString(") Tj ET\n")
+}
+
+// centeredText draws text horizontally centered on the page
+func (p *pdfPage) centeredText(font pdfFont, size, y, gray float64, text []byte) {
+	x := (pageWidth - textWidth(text, font.bold, size)) / 2
+	p.text(font, size, x, y, gray, text)
+}
+
+func (p *pdfPage) rule(y, gray float64) {
+	fmt.Fprintf(&p.content, "%s G 0.5 w %s %s m %s %s l S\n",
+		pdfNum(gray), pdfNum(pageMargin), pdfNum(y), pdfNum(pageWidth-pageMargin), pdfNum(y))
+}
+
+// pdfLayout flows guestbook entries down the page, starting a new page
+// whenever the next block does not fit
+type pdfLayout struct {
+	pages []*pdfPage
+	y     float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &pdfPage{})
+	l.y = contentTop
+}
+
+func (l *pdfLayout) page() *pdfPage {
+	return l.pages[len(l.pages)-1]
+}
+
+// ensure starts a new page unless height points still fit on the current one
+func (l *pdfLayout) ensure(height float64) {
+	if l.y-height < contentBottom {
+		l.newPage()
+	}
+}
+
+func writePDF(w io.Writer, book *Guestbook) error {
+	pages := layoutGuestbook(book)
+	return writePDFDocument(w, book, pages)
+}
+
+// layoutGuestbook renders the title and entries onto as many pages as needed,
+// then numbers the pages
+func layoutGuestbook(book *Guestbook) []*pdfPage {
+	l := &pdfLayout{}
+	l.newPage()
+
+	l.y -= titleSize
+	l.page().centeredText(fontBold, titleSize, l.y, 0, toWinAnsi(book.Title))
+	l.y -= summarySize + 12
+	summary := fmt.Sprintf("%d messages from our guests", len(book.Comments))
+	if len(book.Comments) == 1 {
+		summary = "1 message from our guests"
+	}
+	l.page().centeredText(fontItalic, summarySize, l.y, 0.45, toWinAnsi(summary))
+	l.y -= entrySpacing
+
+	lineWidth := pageWidth - 2*pageMargin
+	for _, comment := range book.Comments {
+		// Keep the name, date and first line of the message together
+		l.ensure(entrySpacing/2 + nameSize + dateSize + 10 + bodyLeading)
+		if l.y < contentTop {
+			l.page().rule(l.y, 0.85)
+			l.y -= entrySpacing / 2
+		}
+
+		l.y -= nameSize
+		l.page().text(fontBold, nameSize, pageMargin, l.y, 0, toWinAnsi(displayName(comment)))
+		l.y -= dateSize + 4
+		l.page().text(fontItalic, dateSize, pageMargin, l.y, 0.5, toWinAnsi(formatDate(comment.CreatedAt)))
+		l.y -= 6
+
+		for _, line := range wrapText(comment.Content, bodySize, lineWidth) {
+			l.ensure(bodyLeading)
+			l.y -= bodyLeading
+			l.page().text(fontRegular, bodySize, pageMargin, l.y, 0.1, line)
+		}
+		l.y -= entrySpacing / 2
+	}
+
+	for i, page := range l.pages {
+		number := toWinAnsi(fmt.Sprintf("%d / %d", i+1, len(l.pages)))
+		page.centeredText(fontRegular, footerSize, footerY, 0.5, number)
+	}
+	return l.pages
+}
+
+// wrapText breaks content into lines no wider than width, keeping the
+// guest's paragraphs and splitting words that are too long for one line
+func wrapText(content string, size, width float64) [][]byte {
+	var lines [][]byte
+	space := textWidth([]byte(" "), false, size)
+
+	for _, paragraph := range paragraphs(content) {
+		var line []byte
+		lineWidth := 0.0
+		for _, field := range strings.Fields(paragraph) {
+			word := toWinAnsi(field)
+			if len(word) == 0 {
+				continue
+			}
+			wordWidth := textWidth(word, false, size)
+
+			if len(line) > 0 && lineWidth+space+wordWidth <= width {
+				line = append(line, ' ')
+				line = append(line, word...)
+				lineWidth += space + wordWidth
+				continue
+			}
+			if len(line) > 0 {
+				lines = append(lines, line)
+				line, lineWidth = nil, 0
+			}
+			for wordWidth > width {
+				n := fitBytes(word, size, width)
+				lines = append(lines, word[:n])
+				word = word[n:]
+				wordWidth = textWidth(word, false, size)
+			}
+			line = append([]byte(nil), word...)
+			lineWidth = wordWidth
+		}
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// fitBytes returns how many leading bytes of text fit in width (at least one)
+func fitBytes(text []byte, size, width float64) int {
+	n := 1
+	for n < len(text) && textWidth(text[:n+1], false, size) <= width {
+		n++
+	}
+	return n
+}
+
+// writePDFDocument serializes the pages as a PDF 1.4 file
+func writePDFDocument(w io.Writer, book *Guestbook, pages []*pdfPage) error {
+	var buf bytes.Buffer
+	var offsets []int
+	beginObject := func() int {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		return len(offsets)
+	}
+	endObject := func() {
+		buf.WriteString("endobj\n")
+	}
+
+	// Object numbers: catalog, page tree, fonts, info, then a page and its
+	// content stream for every page
+	const catalogObj, pagesObj, firstFontObj = 1, 2, 3
+	infoObj := firstFontObj + len(pdfFonts)
+	firstPageObj := infoObj + 1
+
+	// The binary comment marks the file as binary for transfer tools
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	beginObject()
+	fmt.Fprintf(&buf, "<< /Type /Catalog /Pages %d 0 R >>\n", pagesObj)
+	endObject()
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+2*i)
+	}
+	beginObject()
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(pages))
+	endObject()
+
+	var fontResources strings.Builder
+	for i, font := range pdfFonts {
+		beginObject()
+		fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", font.baseFont)
+		endObject()
+		fmt.Fprintf(&fontResources, "/%s %d 0 R ", font.resource, firstFontObj+i)
+	}
+
+	beginObject()
+	buf.WriteString("<< /Title (")
+	writePDFString(&buf, toWinAnsi(book.Title))
+	fmt.Fprintf(&buf, ") /Producer (Wedding Invitation Guestbook) /CreationDate (D:%s) >>\n",
+		book.GeneratedAt.UTC().Format("20060102150405Z"))
+	endObject()
+
+	for _, page := range pages {
+		pageObj := beginObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>\n",
+			pagesObj, pdfNum(pageWidth), pdfNum(pageHeight), fontResources.String(), pageObj+1)
+		endObject()
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		beginObject()
+		fmt.Fprintf(&buf, "<< /Length %d /Filter /FlateDecode >>\nstream\n", stream.Len())
+		buf.Write(stream.Bytes())
+		buf.WriteString("\nendstream\n")
+		endObject()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalogObj, infoObj, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writePDFString writes text as the body of a PDF literal string
+func writePDFString(buf *bytes.Buffer, text []byte) {
+	for _, c := range text {
+		switch c {
+		case '\\', '(', ')':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+}
+
+func pdfNum(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkPDFStructure verifies the header, trailer and that every xref entry
+// points at the start of its object
+func checkPDFStructure(t *testing.T, data []byte) {
+	t.Helper()
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	assert.NotNil(t, startxref)
+	xrefOffset, _ := strconv.Atoi(string(startxref[1]))
+	assert.True(t, bytes.HasPrefix(data[xrefOffset:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(data[xrefOffset:], -1)
+	assert.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+// pageContents inflates every content stream in document order
+func pageContents(t *testing.T, data []byte) []string {
+	t.Helper()
+	var contents []string
+	for _, match := range regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindAllSubmatchIndex(data, -1) {
+		length, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		zr, err := zlib.NewReader(bytes.NewReader(data[match[1] : match[1]+length]))
+		assert.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		assert.NoError(t, err)
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestWritePDF_SinglePage(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, FormatPDF, testGuestbook("Congratulations (and cheers) \\o/"))
+	assert.NoError(t, err)
+
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+	assert.Contains(t, string(data), "/Count 1")
+
+	contents := pageContents(t, data)
+	assert.Len(t, contents, 1)
+	assert.Contains(t, contents[0], "(Rina & Budi) Tj")
+	assert.Contains(t, contents[0], `(Congratulations \(and cheers\) \\o/) Tj`)
+	assert.Contains(t, contents[0], "(1 / 1) Tj")
+}
+
+func TestWritePDF_Paginates(t *testing.T) {
+	var messages []string
+	for i := 0; i < 60; i++ {
+		messages = append(messages, fmt.Sprintf("Message %02d. %s", i, strings.Repeat("Semoga bahagia selalu. ", 10)))
+	}
+
+	var buf bytes.Buffer
+	err := Write(&buf, FormatPDF, testGuestbook(messages...))
+	assert.NoError(t, err)
+
+	data := buf.Bytes()
+	checkPDFStructure(t, data)
+	contents := pageContents(t, data)
+	assert.Greater(t, len(contents), 1)
+	assert.Contains(t, string(data), fmt.Sprintf("/Count %d", len(contents)))
+
+	// Entries stay in order across pages and every page is numbered
+	all := strings.Join(contents, "")
+	last := -1
+	for i := range messages {
+		index := strings.Index(all, fmt.Sprintf("(Message %02d.", i))
+		assert.Greater(t, index, last, "message %d", i)
+		last = index
+	}
+	for i, content := range contents {
+		assert.Contains(t, content, fmt.Sprintf("(%d / %d) Tj", i+1, len(contents)))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("one two three four five six seven\n\nnext paragraph", bodySize, 80)
+
+	assert.Greater(t, len(lines), 2)
+	for _, line := range lines {
+		assert.LessOrEqual(t, textWidth(line, false, bodySize), 80.0, string(line))
+	}
+	assert.Equal(t, "next paragraph", string(lines[len(lines)-1]))
+
+	// Words wider than a line are split rather than overflowing
+	lines = wrapText(strings.Repeat("w", 40), bodySize, 80)
+	assert.Greater(t, len(lines), 1)
+}
+
+func TestToWinAnsi(t *testing.T) {
+	assert.Equal(t, []byte("caf\xe9 \x97 \x93hi\x94 "), toWinAnsi("café — “hi” 🎉"))
+	assert.Equal(t, []byte("?"), toWinAnsi("漢"))
+}
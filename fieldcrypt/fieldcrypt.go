@@ -0,0 +1,228 @@
+// Package fieldcrypt encrypts single database fields with AES-256-GCM.
+// Every encrypted value names the key that encrypted it, so keys can be
+// rotated while values written with older keys stay readable.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"wedding-invitation-backend/config"
+)
+
+// KeySize is the length of a key in bytes, for AES-256
+const KeySize = 32
+
+// DefaultKeyID names the key given in FIELD_ENCRYPTION_KEY
+const DefaultKeyID = "default"
+
+// prefix starts every encrypted value; the rest is <key ID>:<base64 of
+// nonce and ciphertext>. Values without it are plain text written before
+// encryption was turned on.
+const prefix = "enc:v1:"
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted with a key the
+	// ring does not hold
+	ErrUnknownKey = errors.New("value encrypted with an unknown key")
+	// ErrInvalidKeyID is returned for key IDs that are empty or contain unexpected characters
+	ErrInvalidKeyID = errors.New("key IDs may only contain letters, digits, '.', '_' and '-'")
+	// ErrMalformed is returned for encrypted values that cannot be decrypted
+	ErrMalformed = errors.New("malformed encrypted value")
+)
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Key is a named AES-256 key
+type Key struct {
+	ID   string
+	aead cipher.AEAD
+}
+
+// NewKey creates a key from KeySize bytes of secret
+func NewKey(id string, secret []byte) (*Key, error) {
+	if !keyIDPattern.MatchString(id) {
+		return nil, ErrInvalidKeyID
+	}
+	if len(secret) != KeySize {
+		return nil, fmt.Errorf("key %s: keys must be %d bytes, got %d", id, KeySize, len(secret))
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: id, aead: aead}, nil
+}
+
+// ParseKey creates a key from base64-encoded secret, as written by
+// `openssl rand -base64 32`
+func ParseKey(id, encoded string) (*Key, error) {
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key %s: not base64: %w", id, err)
+	}
+	return NewKey(id, secret)
+}
+
+// LoadDir reads every <key ID>.key file in dir
+func LoadDir(dir string) ([]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".key" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseKey(strings.TrimSuffix(entry.Name(), ".key"), string(data))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// KeyRing encrypts new values with its current key and decrypts values
+// encrypted with any of its keys
+type KeyRing struct {
+	current *Key
+	keys    map[string]*Key
+}
+
+// NewKeyRing creates a key ring that encrypts with the key named currentID
+func NewKeyRing(currentID string, keys []*Key) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, exists := ring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key ID %s", key.ID)
+		}
+		ring.keys[key.ID] = key
+	}
+
+	ring.current = ring.keys[currentID]
+	if ring.current == nil {
+		return nil, fmt.Errorf("current key %s not found", currentID)
+	}
+	return ring, nil
+}
+
+// NewKeyRingFromConfig builds the key ring from FIELD_ENCRYPTION_KEY, which
+// becomes the "default" key, and the key files in FIELD_ENCRYPTION_KEYS_DIR.
+// Returns nil when neither is set.
+func NewKeyRingFromConfig() (*KeyRing, error) {
+	var keys []*Key
+	if config.FieldEncryptionKey != "" {
+		key, err := ParseKey(DefaultKeyID, config.FieldEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if config.FieldEncryptionKeysDir != "" {
+		fileKeys, err := LoadDir(config.FieldEncryptionKeysDir)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	currentID := config.FieldEncryptionKeyID
+	if currentID == "" {
+		currentID = DefaultKeyID
+	}
+	return NewKeyRing(currentID, keys)
+}
+
+// CurrentKeyID returns the ID of the key used for new values
+func (r *KeyRing) CurrentKeyID() string {
+	return r.current.ID
+}
+
+// Encrypt encrypts plaintext with the current key. field names the column
+// the value is stored in and is authenticated with it, so a value copied to
+// another column does not decrypt.
+func (r *KeyRing) Encrypt(field, plaintext string) (string, error) {
+	nonce := make([]byte, r.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := r.current.aead.Seal(nonce, nonce, []byte(plaintext), []byte(field))
+	return prefix + r.current.ID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plain text of a value Encrypt returned for field.
+// Values that are not encrypted are returned as they are.
+func (r *KeyRing) Decrypt(field, value string) (string, error) {
+	keyID, encoded, encrypted := split(value)
+	if !encrypted {
+		return value, nil
+	}
+
+	key := r.keys[keyID]
+	if key == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():]
+	plaintext, err := key.aead.Open(nil, nonce, ciphertext, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencryption reports whether value is plain text or encrypted with
+// a key other than the current one
+func (r *KeyRing) NeedsReencryption(value string) bool {
+	keyID, _, encrypted := split(value)
+	return !encrypted || keyID != r.current.ID
+}
+
+// KeyID returns the ID of the key that encrypted value, or "" if value is
+// plain text
+func KeyID(value string) string {
+	keyID, _, _ := split(value)
+	return keyID
+}
+
+// split takes an encrypted value apart into its key ID and payload
+func split(value string) (keyID, payload string, encrypted bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return "", "", false
+	}
+	keyID, payload, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", "", false
+	}
+	return keyID, payload, true
+}
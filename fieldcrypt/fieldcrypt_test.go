@@ -0,0 +1,125 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKey(t *testing.T, id string) *Key {
+	t.Helper()
+	secret := make([]byte, KeySize)
+	_, err := rand.Read(secret)
+	assert.NoError(t, err)
+	key, err := NewKey(id, secret)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestNewKey_Validation(t *testing.T) {
+	_, err := NewKey("", make([]byte, KeySize))
+	assert.ErrorIs(t, err, ErrInvalidKeyID)
+
+	_, err = NewKey("bad:id", make([]byte, KeySize))
+	assert.ErrorIs(t, err, ErrInvalidKeyID)
+
+	_, err = NewKey("short", make([]byte, 16))
+	assert.Error(t, err)
+
+	_, err = ParseKey("notbase64", "***")
+	assert.Error(t, err)
+}
+
+func TestKeyRing_EncryptDecrypt(t *testing.T) {
+	ring, err := NewKeyRing("k1", []*Key{newTestKey(t, "k1")})
+	assert.NoError(t, err)
+
+	value, err := ring.Encrypt("guests.dietary_restrictions", "No nuts")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(value, "enc:v1:k1:"))
+	assert.NotContains(t, value, "No nuts")
+	assert.Equal(t, "k1", KeyID(value))
+	assert.False(t, ring.NeedsReencryption(value))
+
+	again, err := ring.Encrypt("guests.dietary_restrictions", "No nuts")
+	assert.NoError(t, err)
+	assert.NotEqual(t, value, again, "each value gets a fresh nonce")
+
+	plaintext, err := ring.Decrypt("guests.dietary_restrictions", value)
+	assert.NoError(t, err)
+	assert.Equal(t, "No nuts", plaintext)
+
+	_, err = ring.Decrypt("guests.name", value)
+	assert.ErrorIs(t, err, ErrMalformed, "values are bound to their field")
+
+	_, err = ring.Decrypt("guests.dietary_restrictions", "enc:v1:k1:bm90IGEgdmFsdWU=")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestKeyRing_PlainTextPassesThrough(t *testing.T) {
+	ring, err := NewKeyRing("k1", []*Key{newTestKey(t, "k1")})
+	assert.NoError(t, err)
+
+	plaintext, err := ring.Decrypt("guests.dietary_restrictions", "Vegetarian")
+	assert.NoError(t, err)
+	assert.Equal(t, "Vegetarian", plaintext)
+	assert.Equal(t, "", KeyID("Vegetarian"))
+	assert.True(t, ring.NeedsReencryption("Vegetarian"))
+}
+
+func TestKeyRing_Rotation(t *testing.T) {
+	oldKey, newKey := newTestKey(t, "2026-01"), newTestKey(t, "2026-06")
+
+	oldRing, err := NewKeyRing("2026-01", []*Key{oldKey})
+	assert.NoError(t, err)
+	value, err := oldRing.Encrypt("guests.dietary_restrictions", "Vegan")
+	assert.NoError(t, err)
+
+	ring, err := NewKeyRing("2026-06", []*Key{oldKey, newKey})
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-06", ring.CurrentKeyID())
+	assert.True(t, ring.NeedsReencryption(value))
+
+	plaintext, err := ring.Decrypt("guests.dietary_restrictions", value)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vegan", plaintext)
+
+	newOnly, err := NewKeyRing("2026-06", []*Key{newKey})
+	assert.NoError(t, err)
+	_, err = newOnly.Decrypt("guests.dietary_restrictions", value)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestNewKeyRing_Errors(t *testing.T) {
+	_, err := NewKeyRing("missing", []*Key{newTestKey(t, "k1")})
+	assert.Error(t, err)
+
+	_, err = NewKeyRing("k1", []*Key{newTestKey(t, "k1"), newTestKey(t, "k1")})
+	assert.Error(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	secret := bytes.Repeat([]byte{7}, KeySize)
+	encoded := base64.StdEncoding.EncodeToString(secret) + "\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.key"), []byte(encoded), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.key"), []byte(encoded), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0600))
+
+	keys, err := LoadDir(dir)
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 2) {
+		assert.Equal(t, "a", keys[0].ID)
+		assert.Equal(t, "b", keys[1].ID)
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c.key"), []byte("dG9vIHNob3J0"), 0600))
+	_, err = LoadDir(dir)
+	assert.Error(t, err)
+}
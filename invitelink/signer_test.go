@@ -0,0 +1,54 @@
+package invitelink
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_RoundTrip(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	claims := Claims{LinkID: "abc123", GuestID: 42, ExpiresAt: now.Add(time.Hour)}
+
+	token := signer.Sign(claims)
+
+	assert.True(t, strings.HasPrefix(token, "42."))
+	assert.Equal(t, token, signer.Sign(claims))
+	got, err := signer.Verify(token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, claims, got)
+}
+
+func TestSigner_Expired(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	token := signer.Sign(Claims{LinkID: "abc123", GuestID: 42, ExpiresAt: now})
+
+	_, err := signer.Verify(token, now)
+
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestSigner_RejectsTampering(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	token := signer.Sign(Claims{LinkID: "abc123", GuestID: 42, ExpiresAt: now.Add(time.Hour)})
+
+	tests := map[string]string{
+		"other guest":   "43" + strings.TrimPrefix(token, "42"),
+		"other secret":  NewSigner([]byte("other")).Sign(Claims{LinkID: "abc123", GuestID: 42, ExpiresAt: now.Add(time.Hour)}),
+		"missing mac":   token[:strings.LastIndexByte(token, '.')],
+		"empty":         "",
+		"garbage":       "not-a-token",
+		"truncated mac": token[:len(token)-2],
+	}
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := signer.Verify(tampered, now)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
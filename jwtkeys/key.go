@@ -0,0 +1,168 @@
+// Package jwtkeys manages the keys that sign and verify guest tokens, so
+// keys can be rotated without invalidating every token at once.
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Minimum sizes accepted for key material
+const (
+	MinHMACSecretLength = 32
+	MinRSAKeyBits       = 2048
+)
+
+var (
+	// ErrUnsupportedKey is returned for key types other than Ed25519, RSA and HMAC secrets
+	ErrUnsupportedKey = errors.New("unsupported key type")
+
+	// ErrInvalidKeyID is returned for key IDs that are empty or contain unexpected characters
+	ErrInvalidKeyID = errors.New("key IDs may only contain letters, digits, '.', '_' and '-'")
+)
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Key is a named signing key. Keys built from a private key or secret can
+// sign and verify; keys built from a public key can only verify.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign reports whether the key holds private material
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey creates an HS256 key from a shared secret
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if !keyIDPattern.MatchString(id) {
+		return nil, ErrInvalidKeyID
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("key %s: empty secret", id)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// NewKey creates a key from an Ed25519 or RSA private or public key. Ed25519
+// keys use EdDSA and RSA keys use RS256.
+func NewKey(id string, key interface{}) (*Key, error) {
+	if !keyIDPattern.MatchString(id) {
+		return nil, ErrInvalidKeyID
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: k}, nil
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < MinRSAKeyBits {
+			return nil, fmt.Errorf("key %s: RSA keys must be at least %d bits", id, MinRSAKeyBits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < MinRSAKeyBits {
+			return nil, fmt.Errorf("key %s: RSA keys must be at least %d bits", id, MinRSAKeyBits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	default:
+		return nil, fmt.Errorf("key %s: %w %T", id, ErrUnsupportedKey, key)
+	}
+}
+
+// LoadKeyFile reads a key from disk. Files ending in .pem hold an Ed25519 or
+// RSA key in PKCS#8, PKCS#1 or PKIX form; files ending in .secret hold an
+// HMAC secret of at least MinHMACSecretLength bytes.
+func LoadKeyFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".secret":
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) < MinHMACSecretLength {
+			return nil, fmt.Errorf("key %s: HMAC secrets must be at least %d bytes", id, MinHMACSecretLength)
+		}
+		return NewHMACKey(id, secret)
+	case ".pem":
+		parsed, err := parsePEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+		return NewKey(id, parsed)
+	default:
+		return nil, fmt.Errorf("key %s: %w: expected a .pem or .secret file", id, ErrUnsupportedKey)
+	}
+}
+
+// LoadDir loads every .pem and .secret file in dir, using the file name
+// without its extension as the key ID. Other files are ignored.
+func LoadDir(dir string) ([]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*Key
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".pem" && ext != ".secret") {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), ext)
+		if other, ok := seen[id]; ok {
+			return nil, fmt.Errorf("key %s is defined by both %s and %s", id, other, entry.Name())
+		}
+		seen[id] = entry.Name()
+
+		key, err := LoadKeyFile(id, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func parsePEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: PEM block %q", ErrUnsupportedKey, block.Type)
+	}
+}
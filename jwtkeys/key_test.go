@@ -0,0 +1,111 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "2026-04.pem"), "PRIVATE KEY", der)
+
+	der, err = x509.MarshalPKIXPublicKey(public)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "partner.pem"), "PUBLIC KEY", der)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, MinRSAKeyBits)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "2026-01.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "2025-12.secret"), []byte("0123456789abcdef0123456789abcdef\n"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0600))
+
+	keys, err := LoadDir(dir)
+
+	assert.NoError(t, err)
+	assert.Len(t, keys, 4)
+	byID := make(map[string]*Key)
+	for _, key := range keys {
+		byID[key.ID] = key
+	}
+	assert.Equal(t, jwt.SigningMethodHS256, byID["2025-12"].Method)
+	assert.Equal(t, jwt.SigningMethodRS256, byID["2026-01"].Method)
+	assert.Equal(t, jwt.SigningMethodEdDSA, byID["2026-04"].Method)
+	assert.True(t, byID["2026-04"].CanSign())
+	assert.Equal(t, jwt.SigningMethodEdDSA, byID["partner"].Method)
+	assert.False(t, byID["partner"].CanSign())
+}
+
+func TestLoadKeyFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	short := filepath.Join(dir, "short.secret")
+	assert.NoError(t, os.WriteFile(short, []byte("too-short"), 0600))
+	_, err := LoadKeyFile("short", short)
+	assert.Error(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	assert.NoError(t, err)
+	ec := filepath.Join(dir, "ec.pem")
+	writePEM(t, ec, "PRIVATE KEY", der)
+	_, err = LoadKeyFile("ec", ec)
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	weakPath := filepath.Join(dir, "weak.pem")
+	writePEM(t, weakPath, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(weak))
+	_, err = LoadKeyFile("weak", weakPath)
+	assert.Error(t, err)
+
+	garbage := filepath.Join(dir, "garbage.pem")
+	assert.NoError(t, os.WriteFile(garbage, []byte("not a key"), 0600))
+	_, err = LoadKeyFile("garbage", garbage)
+	assert.Error(t, err)
+}
+
+func TestLoadDir_DuplicateKeyID(t *testing.T) {
+	dir := t.TempDir()
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "k1.secret"), secret, 0600))
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "k1.pem"), "PRIVATE KEY", der)
+
+	_, err = LoadDir(dir)
+	assert.Error(t, err)
+}
+
+func TestNewHMACKey_InvalidID(t *testing.T) {
+	_, err := NewHMACKey("../etc", []byte("secret"))
+	assert.ErrorIs(t, err, ErrInvalidKeyID)
+}
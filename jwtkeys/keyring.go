@@ -0,0 +1,151 @@
+package jwtkeys
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeyID names the key built from JWT_SECRET. Tokens issued before
+// key IDs existed carry no kid and are verified with this key.
+const DefaultKeyID = "default"
+
+var (
+	// ErrUnknownKey is returned when a token names a key the ring does not hold
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+
+	// ErrRetiredKey is returned for tokens signed by a key retired for longer than the grace period
+	ErrRetiredKey = errors.New("token signed with a retired key")
+)
+
+// KeyRing signs new tokens with one key and verifies tokens signed by any of
+// its keys. A key is retired by giving it a retirement time: it then only
+// verifies tokens until the grace period after that time has passed, which
+// lets sessions signed before a rotation run out naturally. Other keys
+// verify without a time limit.
+type KeyRing struct {
+	signing     *Key
+	keys        map[string]*Key
+	methods     []string
+	retiredAt   map[string]time.Time
+	gracePeriod time.Duration
+}
+
+// NewKeyRing creates a key ring that signs with the key named signingID.
+// retiredAt maps the IDs of retired keys to the time they were retired.
+func NewKeyRing(signingID string, keys []*Key, retiredAt map[string]time.Time, gracePeriod time.Duration) (*KeyRing, error) {
+	ring := &KeyRing{
+		keys:        make(map[string]*Key, len(keys)),
+		retiredAt:   make(map[string]time.Time, len(retiredAt)),
+		gracePeriod: gracePeriod,
+	}
+
+	methods := make(map[string]bool)
+	for _, key := range keys {
+		if _, exists := ring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key ID %s", key.ID)
+		}
+		ring.keys[key.ID] = key
+		if !methods[key.Method.Alg()] {
+			methods[key.Method.Alg()] = true
+			ring.methods = append(ring.methods, key.Method.Alg())
+		}
+	}
+
+	ring.signing = ring.keys[signingID]
+	if ring.signing == nil {
+		return nil, fmt.Errorf("signing key %s not found", signingID)
+	}
+	if !ring.signing.CanSign() {
+		return nil, fmt.Errorf("signing key %s is a public key and cannot sign", signingID)
+	}
+
+	for id, at := range retiredAt {
+		if ring.keys[id] == nil {
+			return nil, fmt.Errorf("retired key %s not found", id)
+		}
+		if id == signingID {
+			return nil, fmt.Errorf("signing key %s cannot be retired", id)
+		}
+		ring.retiredAt[id] = at
+	}
+
+	return ring, nil
+}
+
+// ParseRetiredKeys parses a comma-separated list of kid=time pairs, with
+// times in RFC 3339 form, as used by JWT_RETIRED_KEYS
+func ParseRetiredKeys(value string) (map[string]time.Time, error) {
+	retiredAt := make(map[string]time.Time)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		id, at, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("retired key %q: expected kid=time", item)
+		}
+		id = strings.TrimSpace(id)
+		if _, exists := retiredAt[id]; exists {
+			return nil, fmt.Errorf("key %s is retired twice", id)
+		}
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(at))
+		if err != nil {
+			return nil, fmt.Errorf("retired key %s: %w", id, err)
+		}
+		retiredAt[id] = t
+	}
+	return retiredAt, nil
+}
+
+// SigningKeyID returns the ID of the key used for new tokens
+func (r *KeyRing) SigningKeyID() string {
+	return r.signing.ID
+}
+
+// Sign creates a token for claims signed with the signing key
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.signing.Method, claims)
+	token.Header["kid"] = r.signing.ID
+	return token.SignedString(r.signing.signKey)
+}
+
+// Parse verifies tokenString and decodes it into claims. The token's kid
+// selects the key, and the token's algorithm must match that key.
+func (r *KeyRing) Parse(tokenString string, claims jwt.Claims, options ...jwt.ParserOption) (*jwt.Token, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = DefaultKeyID
+		}
+
+		key := r.keys[kid]
+		if key == nil {
+			return nil, ErrUnknownKey
+		}
+		// Checked before the signature, so the token's own claims cannot
+		// extend the life of a retired key
+		if r.expired(key.ID, time.Now()) {
+			return nil, ErrRetiredKey
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("token algorithm %s does not match key %s", token.Method.Alg(), key.ID)
+		}
+		return key.verifyKey, nil
+	}
+
+	options = append(options, jwt.WithValidMethods(r.methods))
+	return jwt.ParseWithClaims(tokenString, claims, keyFunc, options...)
+}
+
+// expired reports whether the key was retired more than the grace period
+// before now
+func (r *KeyRing) expired(id string, now time.Time) bool {
+	retiredAt, retired := r.retiredAt[id]
+	return retired && !now.Before(retiredAt.Add(r.gracePeriod))
+}
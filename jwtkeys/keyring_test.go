@@ -0,0 +1,239 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEd25519Key(t *testing.T, id string) *Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	key, err := NewKey(id, private)
+	assert.NoError(t, err)
+	return key
+}
+
+func newRSAKey(t *testing.T, id string) *Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, MinRSAKeyBits)
+	assert.NoError(t, err)
+	key, err := NewKey(id, private)
+	assert.NoError(t, err)
+	return key
+}
+
+func newSecretKey(t *testing.T, id string) *Key {
+	t.Helper()
+	key, err := NewHMACKey(id, []byte("a-secret-that-is-long-enough-for-hs256"))
+	assert.NoError(t, err)
+	return key
+}
+
+func claimsIssuedAt(issuedAt time.Time) *jwt.RegisteredClaims {
+	return &jwt.RegisteredClaims{
+		Subject:   "alice",
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		ExpiresAt: jwt.NewNumericDate(issuedAt.Add(24 * time.Hour)),
+	}
+}
+
+func TestKeyRing_SignAndParse(t *testing.T) {
+	for _, key := range []*Key{newSecretKey(t, "hs"), newEd25519Key(t, "ed"), newRSAKey(t, "rsa")} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			ring, err := NewKeyRing(key.ID, []*Key{key}, nil, time.Hour)
+			assert.NoError(t, err)
+
+			tokenString, err := ring.Sign(claimsIssuedAt(time.Now()))
+			assert.NoError(t, err)
+
+			claims := &jwt.RegisteredClaims{}
+			token, err := ring.Parse(tokenString, claims)
+			assert.NoError(t, err)
+			assert.True(t, token.Valid)
+			assert.Equal(t, key.ID, token.Header["kid"])
+			assert.Equal(t, "alice", claims.Subject)
+		})
+	}
+}
+
+func TestKeyRing_Rotation(t *testing.T) {
+	oldKey := newSecretKey(t, DefaultKeyID)
+	newKey := newEd25519Key(t, "2026-04")
+
+	before, err := NewKeyRing(DefaultKeyID, []*Key{oldKey}, nil, time.Hour)
+	assert.NoError(t, err)
+	old, err := before.Sign(claimsIssuedAt(time.Now().Add(-2 * time.Hour)))
+	assert.NoError(t, err)
+	// Someone holding the old key can claim any issue time
+	minted, err := before.Sign(claimsIssuedAt(time.Now()))
+	assert.NoError(t, err)
+
+	// Not yet retired: the old key verifies whatever the token's age
+	added, err := NewKeyRing("2026-04", []*Key{oldKey, newKey}, nil, time.Hour)
+	assert.NoError(t, err)
+	_, err = added.Parse(old, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+
+	// Retired half an hour ago: still within the grace period
+	recent, err := NewKeyRing("2026-04", []*Key{oldKey, newKey}, map[string]time.Time{DefaultKeyID: time.Now().Add(-30 * time.Minute)}, time.Hour)
+	assert.NoError(t, err)
+	_, err = recent.Parse(old, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+
+	// Past the grace period every token of the key is refused, however
+	// recent its iat claims to be
+	after, err := NewKeyRing("2026-04", []*Key{oldKey, newKey}, map[string]time.Time{DefaultKeyID: time.Now().Add(-2 * time.Hour)}, time.Hour)
+	assert.NoError(t, err)
+	for _, token := range []string{old, minted} {
+		_, err = after.Parse(token, &jwt.RegisteredClaims{})
+		assert.ErrorIs(t, err, ErrRetiredKey)
+	}
+
+	// The signing key is not limited by the grace period
+	fresh, err := after.Sign(claimsIssuedAt(time.Now().Add(-2 * time.Hour)))
+	assert.NoError(t, err)
+	_, err = after.Parse(fresh, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+}
+
+func TestKeyRing_TokensWithoutKeyID(t *testing.T) {
+	secret := []byte("a-secret-that-is-long-enough-for-hs256")
+	legacy, err := NewHMACKey(DefaultKeyID, secret)
+	assert.NoError(t, err)
+
+	// Tokens issued before key IDs: no kid and no iat
+	claims := jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(20 * time.Hour))}
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	assert.NoError(t, err)
+
+	ring, err := NewKeyRing(DefaultKeyID, []*Key{legacy}, nil, time.Hour)
+	assert.NoError(t, err)
+	_, err = ring.Parse(tokenString, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+
+	// They are checked against the default key, so they follow its retirement
+	rotated, err := NewKeyRing("ed", []*Key{legacy, newEd25519Key(t, "ed")}, map[string]time.Time{DefaultKeyID: time.Now().Add(-2 * time.Hour)}, time.Hour)
+	assert.NoError(t, err)
+	_, err = rotated.Parse(tokenString, &jwt.RegisteredClaims{})
+	assert.ErrorIs(t, err, ErrRetiredKey)
+}
+
+func TestParseRetiredKeys(t *testing.T) {
+	retiredAt, err := ParseRetiredKeys(" default=2026-04-01T12:00:00Z, 2026-04=2026-10-01T00:00:00+02:00 ,")
+	assert.NoError(t, err)
+	assert.Len(t, retiredAt, 2)
+	assert.True(t, retiredAt[DefaultKeyID].Equal(time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)))
+	assert.True(t, retiredAt["2026-04"].Equal(time.Date(2026, 9, 30, 22, 0, 0, 0, time.UTC)))
+
+	retiredAt, err = ParseRetiredKeys("")
+	assert.NoError(t, err)
+	assert.Empty(t, retiredAt)
+
+	for _, value := range []string{"default", "default=yesterday", "a=2026-04-01T12:00:00Z,a=2026-05-01T12:00:00Z"} {
+		_, err = ParseRetiredKeys(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestKeyRing_RejectsUnknownKeys(t *testing.T) {
+	ring, err := NewKeyRing("a", []*Key{newEd25519Key(t, "a")}, nil, time.Hour)
+	assert.NoError(t, err)
+	other, err := NewKeyRing("b", []*Key{newEd25519Key(t, "b")}, nil, time.Hour)
+	assert.NoError(t, err)
+
+	tokenString, err := other.Sign(claimsIssuedAt(time.Now()))
+	assert.NoError(t, err)
+
+	_, err = ring.Parse(tokenString, &jwt.RegisteredClaims{})
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// A token claiming ring's kid but signed by another key fails verification
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claimsIssuedAt(time.Now()))
+	token.Header["kid"] = "a"
+	forged, err := token.SignedString(other.signing.signKey)
+	assert.NoError(t, err)
+	_, err = ring.Parse(forged, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+}
+
+func TestKeyRing_RejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa")
+	secret := newSecretKey(t, "hs")
+	ring, err := NewKeyRing("rsa", []*Key{rsaKey, secret}, nil, time.Hour)
+	assert.NoError(t, err)
+
+	// HS256 is allowed in the ring, but not for the RSA key's kid
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claimsIssuedAt(time.Now()))
+	token.Header["kid"] = "rsa"
+	tokenString, err := token.SignedString(secret.signKey)
+	assert.NoError(t, err)
+
+	_, err = ring.Parse(tokenString, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+}
+
+func TestNewKeyRing_Errors(t *testing.T) {
+	ed := newEd25519Key(t, "ed")
+	public, err := NewKey("public", ed.verifyKey)
+	assert.NoError(t, err)
+	assert.False(t, public.CanSign())
+
+	_, err = NewKeyRing("missing", []*Key{ed}, nil, time.Hour)
+	assert.Error(t, err)
+
+	_, err = NewKeyRing("public", []*Key{ed, public}, nil, time.Hour)
+	assert.Error(t, err)
+
+	_, err = NewKeyRing("ed", []*Key{ed, newEd25519Key(t, "ed")}, nil, time.Hour)
+	assert.Error(t, err)
+
+	// Retirement times must name a key of the ring other than the signing key
+	_, err = NewKeyRing("ed", []*Key{ed}, map[string]time.Time{"missing": time.Now()}, time.Hour)
+	assert.Error(t, err)
+
+	_, err = NewKeyRing("ed", []*Key{ed}, map[string]time.Time{"ed": time.Now()}, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	ed := newEd25519Key(t, "ed")
+	rsaKey := newRSAKey(t, "rsa")
+	ring, err := NewKeyRing("ed", []*Key{ed, rsaKey, newSecretKey(t, DefaultKeyID)}, nil, time.Hour)
+	assert.NoError(t, err)
+
+	set := ring.JWKS()
+
+	assert.Len(t, set.Keys, 2, "HMAC secrets must not be published")
+	assert.Equal(t, "ed", set.Keys[0].KeyID)
+	assert.Equal(t, "OKP", set.Keys[0].KeyType)
+	assert.Equal(t, "Ed25519", set.Keys[0].Curve)
+	assert.Equal(t, "EdDSA", set.Keys[0].Algorithm)
+	assert.NotEmpty(t, set.Keys[0].X)
+	assert.Equal(t, "rsa", set.Keys[1].KeyID)
+	assert.Equal(t, "RSA", set.Keys[1].KeyType)
+	assert.Equal(t, "RS256", set.Keys[1].Algorithm)
+	assert.Equal(t, "AQAB", set.Keys[1].Exponent)
+	assert.NotEmpty(t, set.Keys[1].Modulus)
+}
+
+func TestKeyRing_JWKSLeavesOutExpiredKeys(t *testing.T) {
+	ring, err := NewKeyRing("ed", []*Key{newEd25519Key(t, "ed"), newEd25519Key(t, "old"), newEd25519Key(t, "recent")}, map[string]time.Time{
+		"old":    time.Now().Add(-2 * time.Hour),
+		"recent": time.Now().Add(-30 * time.Minute),
+	}, time.Hour)
+	assert.NoError(t, err)
+
+	set := ring.JWKS()
+
+	if assert.Len(t, set.Keys, 2) {
+		assert.Equal(t, "ed", set.Keys[0].KeyID)
+		assert.Equal(t, "recent", set.Keys[1].KeyID)
+	}
+}
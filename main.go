@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"wedding-invitation-backend/cli"
 	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/container"
 	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/demo"
+	"wedding-invitation-backend/fieldcrypt"
+	"wedding-invitation-backend/middleware/auth"
+	"wedding-invitation-backend/middleware/requestlog"
+	"wedding-invitation-backend/repositories"
 	"wedding-invitation-backend/routes"
+	"wedding-invitation-backend/services"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -22,18 +33,164 @@ func main() {
 	// Validate configuration
 	config.ValidateConfig()
 
+	// Schema commands, e.g. `migrate status`, run before anything touches
+	// the schema
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := database.OpenDB(); err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		migrators, err := database.Migrators()
+		for _, migrator := range migrators {
+			if err != nil {
+				break
+			}
+			if len(migrators) > 1 {
+				fmt.Printf("%s:\n", migrator.Dialect())
+			}
+			err = cli.RunMigrate(migrator, os.Args[2:], os.Stdout)
+		}
+		database.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Demo mode, `--demo`, serves seeded fake guests from memory and writes
+	// nothing to disk
+	demoMode := len(os.Args) > 1 && os.Args[1] == "--demo"
+
 	// Initialize database
-	if err := database.InitDB(); err != nil {
+	if demoMode {
+		if err := database.InitMemoryDB(); err != nil {
+			log.Fatalf("Failed to initialize demo database: %v", err)
+		}
+	} else if err := database.InitDB(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer database.DB.Close()
+	defer database.Close()
+
+	// Maintenance commands, e.g. `admin create -username alice`
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		adminService := services.NewAdminService(repositories.NewSQLAdminRepository(database.DB, database.ReadDB))
+		if err := cli.RunAdmin(adminService, os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			database.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Load guest token signing keys
+	tokenKeys, err := auth.LoadKeyRing()
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	log.Printf("Signing guest tokens with key %q", tokenKeys.SigningKeyID())
+
+	// Initialize dependency injection container; guests and comments may
+	// live in Postgres
+	guestRepo := repositories.NewSQLGuestRepository(database.DB, database.ReadDB)
+	commentRepo := repositories.NewSQLCommentRepository(database.DB, database.ReadDB)
+	txManager := repositories.NewSQLTxManager(database.DB)
+	switch {
+	case demoMode:
+		guestRepo = repositories.NewMemoryGuestRepository()
+		commentRepo = repositories.NewMemoryCommentRepository(guestRepo)
+		txManager = repositories.NewMemoryTxManager(guestRepo, commentRepo)
+		if err := demo.Seed(context.Background(), guestRepo, commentRepo); err != nil {
+			log.Fatalf("Failed to seed demo data: %v", err)
+		}
+		if dir, err := os.MkdirTemp("", "wedding-demo-media-"); err != nil {
+			log.Printf("Warning: demo photo uploads go to %s: %v", config.MediaDir, err)
+		} else {
+			config.MediaDir = dir
+		}
+		config.BackupInterval = 0
+		if dir, err := os.MkdirTemp("", "wedding-demo-backups-"); err != nil {
+			log.Printf("Warning: demo backups go to %s: %v", config.BackupDir, err)
+		} else {
+			config.BackupDir = dir
+		}
+		log.Println("DEMO MODE: guests and comments are seeded fake data kept in memory only")
+		if config.AdminBootstrapUsername == "" {
+			config.AdminBootstrapUsername = demo.AdminUsername
+			config.AdminBootstrapPassword = demo.AdminPassword
+			log.Printf("DEMO MODE: log in to the admin area as %q with password %q", demo.AdminUsername, demo.AdminPassword)
+		}
+	case database.PostgresDB != nil:
+		guestRepo = repositories.NewPostgresGuestRepository(database.PostgresDB)
+		commentRepo = repositories.NewPostgresCommentRepository(database.PostgresDB)
+		txManager = repositories.NewPostgresTxManager(database.PostgresDB)
+		log.Println("Storing guests and comments in Postgres")
+	}
+
+	// Encrypt sensitive guest fields at rest
+	fieldKeys, err := fieldcrypt.NewKeyRingFromConfig()
+	if err != nil {
+		log.Fatalf("Failed to load field encryption keys: %v", err)
+	}
+
+	// Key rotation commands, e.g. `encryption reencrypt`
+	if len(os.Args) > 1 && os.Args[1] == "encryption" {
+		err := errors.New("FIELD_ENCRYPTION_KEY or FIELD_ENCRYPTION_KEYS_DIR must be set")
+		if fieldKeys != nil {
+			err = cli.RunEncryption(services.NewEncryptionService(txManager, repositories.NewSQLAuditRepository(database.DB, database.ReadDB), fieldKeys), os.Args[2:], os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			database.Close()
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Initialize dependency injection container
-	appContainer := container.NewContainer(database.DB)
+	if fieldKeys != nil {
+		guestRepo = repositories.NewEncryptedGuestRepository(guestRepo, fieldKeys)
+		txManager = repositories.NewEncryptedTxManager(txManager, fieldKeys)
+		log.Printf("Encrypting guest fields with key %q", fieldKeys.CurrentKeyID())
+	} else if err := services.CheckUnencryptedStorage(context.Background(), guestRepo); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+	appContainer := container.NewContainerWithRepositories(database.DB, database.ReadDB, guestRepo, commentRepo, txManager, tokenKeys)
 	log.Println("Dependency injection container initialized with caching enabled")
 
+	// Create the first owner from the environment on a fresh install
+	if config.AdminBootstrapUsername != "" {
+		admin, created, err := appContainer.AdminService.BootstrapOwner(config.AdminBootstrapUsername, config.AdminBootstrapPassword)
+		if err != nil {
+			log.Fatalf("Failed to create bootstrap admin: %v", err)
+		}
+		if created {
+			log.Printf("Created owner account %q from ADMIN_BOOTSTRAP_USERNAME", admin.Username)
+		}
+	}
+
+	// Drop sessions whose tokens have expired
+	if pruned, err := appContainer.SessionService.PruneExpiredSessions(); err != nil {
+		log.Printf("Warning: Failed to prune expired sessions: %v", err)
+	} else if pruned > 0 {
+		log.Printf("Pruned %d expired guest sessions", pruned)
+	}
+
+	// Drop login events past their retention period
+	if pruned, err := appContainer.LoginGuard.PruneLoginEvents(); err != nil {
+		log.Printf("Warning: Failed to prune login events: %v", err)
+	} else if pruned > 0 {
+		log.Printf("Pruned %d old login events", pruned)
+	}
+
+	// Write scheduled backups of the SQLite database
+	if config.BackupInterval > 0 {
+		appContainer.Backups.Start(config.BackupInterval)
+		log.Printf("Backing up the database to %s every %v, keeping %d", config.BackupDir, config.BackupInterval, config.BackupRetention)
+	}
+
 	// Initialize Gin router
-	r := gin.Default()
+	// Guest tokens sent in the query string stay out of the request log
+	r := gin.New()
+	r.Use(requestlog.Logger("token"), gin.Recovery())
 
 	// Configure trusted proxies for security
 	if err := r.SetTrustedProxies(nil); err != nil {
@@ -44,7 +201,7 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost", "http://127.0.0.1"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-CSRF-Token"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
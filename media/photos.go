@@ -0,0 +1,242 @@
+package media
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	_ "image/png" // register PNG decoder
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedImage is returned for uploads that are not JPEG, PNG or GIF images
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	// ErrImageTooLarge is returned for images with more pixels than we are willing to decode
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+)
+
+// maxPixels guards against decompression bombs; 24 megapixels covers phone cameras
+const maxPixels = 24_000_000
+
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+var fileNamePattern = regexp.MustCompile(`^[0-9a-f]{32}(_thumb)?\.jpg$`)
+
+// PhotoFileName returns the store name of the full-size photo for a key
+func PhotoFileName(key string) string {
+	return key + ".jpg"
+}
+
+// ThumbnailFileName returns the store name of the thumbnail for a key
+func ThumbnailFileName(key string) string {
+	return key + "_thumb.jpg"
+}
+
+// PhotoKey returns the key of the photo a store file name belongs to, for
+// both the full-size photo and its thumbnail
+func PhotoKey(name string) string {
+	name = strings.TrimSuffix(name, ".jpg")
+	return strings.TrimSuffix(name, "_thumb")
+}
+
+// IsPhotoFileName reports whether name is a file name produced by Photos
+func IsPhotoFileName(name string) bool {
+	return fileNamePattern.MatchString(name)
+}
+
+// Photos turns uploaded images into re-encoded, size-limited JPEGs with
+// thumbnails. Re-encoding drops all metadata, including EXIF location data.
+type Photos struct {
+	store         Store
+	maxDimension  int
+	thumbnailSize int
+}
+
+// NewPhotos creates a photo processor that stores results in store
+func NewPhotos(store Store, maxDimension, thumbnailSize int) *Photos {
+	return &Photos{
+		store:         store,
+		maxDimension:  maxDimension,
+		thumbnailSize: thumbnailSize,
+	}
+}
+
+// Save validates, processes and stores an uploaded photo and returns its key.
+// Every upload gets a fresh random key, so stored files never change and a
+// photo can be deleted without affecting other comments.
+func (p *Photos) Save(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return "", ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return "", ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrUnsupportedImage
+	}
+
+	// Re-encoding drops EXIF, so apply the orientation it described first
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	flat := flatten(img)
+	full, err := encodeJPEG(applyOrientation(resizeToFit(flat, p.maxDimension), orientation), 85)
+	if err != nil {
+		return "", err
+	}
+	thumb, err := encodeJPEG(applyOrientation(resizeToFit(flat, p.thumbnailSize), orientation), 80)
+	if err != nil {
+		return "", err
+	}
+
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(id[:])
+
+	if err := p.store.Put(PhotoFileName(key), full); err != nil {
+		return "", err
+	}
+	if err := p.store.Put(ThumbnailFileName(key), thumb); err != nil {
+		p.store.Delete(PhotoFileName(key))
+		return "", err
+	}
+	return key, nil
+}
+
+// Delete removes a stored photo and its thumbnail
+func (p *Photos) Delete(key string) error {
+	if err := p.store.Delete(PhotoFileName(key)); err != nil {
+		return err
+	}
+	return p.store.Delete(ThumbnailFileName(key))
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten converts any image to RGBA at the origin, compositing transparent
+// areas onto white since JPEG has no alpha channel
+func flatten(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
+
+// resizeToFit scales the image down so neither side exceeds maxSize, using
+// an area-averaging box filter. Smaller images are returned unchanged.
+func resizeToFit(src *image.RGBA, maxSize int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if maxSize <= 0 || (sw <= maxSize && sh <= maxSize) {
+		return src
+	}
+
+	dw, dh := maxSize, maxSize
+	if sw >= sh {
+		dh = max(1, sh*maxSize/sw)
+	} else {
+		dw = max(1, sw*maxSize/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, (y+1)*sh/dh
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, (x+1)*sw/dw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(src.Pix[offset])
+					g += uint64(src.Pix[offset+1])
+					b += uint64(src.Pix[offset+2])
+					a += uint64(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// applyOrientation transforms the image according to an EXIF orientation
+// value so that it displays upright without metadata
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90 counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,155 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testJPEG encodes a solid w x h JPEG, optionally with an EXIF orientation tag
+func testJPEG(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+	data := buf.Bytes()
+	if orientation == 0 {
+		return data
+	}
+
+	// Little-endian TIFF with a single IFD entry for the orientation tag
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3) // SHORT
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], uint16(orientation))
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0) // no next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func readStored(t *testing.T, store Store, name string) []byte {
+	t.Helper()
+	f, _, err := store.Open(name)
+	assert.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	assert.Equal(t, 6, jpegOrientation(testJPEG(t, 4, 2, 6)))
+	assert.Equal(t, 1, jpegOrientation(testJPEG(t, 4, 2, 0)))
+	assert.Equal(t, 1, jpegOrientation([]byte("not a jpeg")))
+}
+
+func TestPhotos_SaveResizesAndStripsEXIF(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	photos := NewPhotos(store, 400, 100)
+
+	key, err := photos.Save(testJPEG(t, 800, 600, 6))
+	assert.NoError(t, err)
+	assert.True(t, IsPhotoFileName(PhotoFileName(key)))
+	assert.True(t, IsPhotoFileName(ThumbnailFileName(key)))
+	assert.Equal(t, key, PhotoKey(PhotoFileName(key)))
+	assert.Equal(t, key, PhotoKey(ThumbnailFileName(key)))
+
+	full := readStored(t, store, PhotoFileName(key))
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(full))
+	assert.NoError(t, err)
+	// Rotated 90 degrees and scaled to fit 400px
+	assert.Equal(t, 300, cfg.Width)
+	assert.Equal(t, 400, cfg.Height)
+	assert.False(t, bytes.Contains(full, []byte("Exif")))
+
+	thumb := readStored(t, store, ThumbnailFileName(key))
+	cfg, err = jpeg.DecodeConfig(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, 75, cfg.Width)
+	assert.Equal(t, 100, cfg.Height)
+}
+
+func TestPhotos_SaveFlattensTransparentPNG(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	photos := NewPhotos(store, 400, 100)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+
+	key, err := photos.Save(buf.Bytes())
+	assert.NoError(t, err)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(readStored(t, store, PhotoFileName(key))))
+	assert.NoError(t, err)
+	r, g, b, _ := decoded.At(5, 5).RGBA()
+	white := color.White
+	wr, wg, wb, _ := white.RGBA()
+	assert.InDelta(t, wr, r, 0x0400)
+	assert.InDelta(t, wg, g, 0x0400)
+	assert.InDelta(t, wb, b, 0x0400)
+}
+
+func TestPhotos_SaveRejectsNonImages(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	photos := NewPhotos(store, 400, 100)
+
+	_, err = photos.Save([]byte("<html>not an image</html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+
+	// Looks like a JPEG but is truncated
+	_, err = photos.Save([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0})
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+func TestPhotos_Delete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	photos := NewPhotos(store, 400, 100)
+
+	key, err := photos.Save(testJPEG(t, 20, 20, 0))
+	assert.NoError(t, err)
+
+	assert.NoError(t, photos.Delete(key))
+	_, _, err = store.Open(PhotoFileName(key))
+	assert.Error(t, err)
+	_, _, err = store.Open(ThumbnailFileName(key))
+	assert.Error(t, err)
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 2x1 image: red then blue
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	src.Set(1, 0, color.RGBA{0, 0, 255, 255})
+
+	rotated := applyOrientation(src, 6)
+
+	assert.Equal(t, image.Rect(0, 0, 1, 2), rotated.Bounds())
+	// Rotating clockwise puts the left pixel on top
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, rotated.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, rotated.RGBAAt(0, 1))
+}
@@ -0,0 +1,99 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrInvalidName is returned for object names that could escape the store
+var ErrInvalidName = errors.New("invalid media name")
+
+// Store defines the interface for storing media objects by name
+type Store interface {
+	Put(name string, data []byte) error
+	// Open returns the object and its modification time; a missing object
+	// returns an error satisfying errors.Is(err, os.ErrNotExist)
+	Open(name string) (io.ReadSeekCloser, time.Time, error)
+	Delete(name string) error
+}
+
+// LocalStore implements Store on the local filesystem
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore creates a store rooted at dir, creating it if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put writes an object atomically so readers never see a partial file
+func (s *LocalStore) Put(name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open opens an object for reading
+func (s *LocalStore) Open(name string) (io.ReadSeekCloser, time.Time, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, err
+	}
+	return f, info.ModTime(), nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (s *LocalStore) Delete(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) path(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(s.dir, name), nil
+}
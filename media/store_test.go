@@ -0,0 +1,43 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore_PutOpenDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Put("photo.jpg", []byte("data")))
+
+	f, modTime, err := store.Open("photo.jpg")
+	assert.NoError(t, err)
+	data, err := io.ReadAll(f)
+	f.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+	assert.False(t, modTime.IsZero())
+
+	assert.NoError(t, store.Delete("photo.jpg"))
+	_, _, err = store.Open("photo.jpg")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// Deleting again is not an error
+	assert.NoError(t, store.Delete("photo.jpg"))
+}
+
+func TestLocalStore_RejectsPathTraversal(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, name := range []string{"", "../secret", "a/b.jpg", `a\b.jpg`, ".hidden"} {
+		assert.ErrorIs(t, store.Put(name, []byte("x")), ErrInvalidName, name)
+		_, _, err := store.Open(name)
+		assert.ErrorIs(t, err, ErrInvalidName, name)
+	}
+}
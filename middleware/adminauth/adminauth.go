@@ -0,0 +1,199 @@
+package adminauth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// adminAudience keeps admin tokens apart from guest tokens
+const adminAudience = "admin"
+
+// Context keys holding the authenticated *models.Admin and, for scripts,
+// the *models.APIToken
+const (
+	contextKey      = "admin"
+	tokenContextKey = "api_token"
+)
+
+// APITokenHeader carries an admin API token
+const APITokenHeader = "X-API-Key"
+
+// Claims represents JWT token claims for admin authentication
+type Claims struct {
+	AdminID int64  `json:"admin_id"`
+	Role    string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// GenerateToken creates an admin JWT for the given admin
+func GenerateToken(admin *models.Admin) (string, time.Time, error) {
+	expiresAt := time.Now().Add(time.Duration(config.AdminJWTExpiry) * time.Second)
+	claims := &Claims{
+		AdminID: admin.ID,
+		Role:    admin.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   admin.Username,
+			Audience:  jwt.ClaimStrings{adminAudience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(config.AdminJWTSecret))
+	return signed, expiresAt, err
+}
+
+// Middleware authenticates admin requests with an admin JWT, or with an API
+// token in the X-API-Key header. The account or token is loaded on every
+// request so role changes, deletions and revocations apply immediately.
+func Middleware(adminService services.AdminServiceInterface, tokenService services.APITokenServiceInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret := c.GetHeader(APITokenHeader); secret != "" {
+			token, err := tokenService.Authenticate(secret)
+			if err != nil {
+				if errors.Is(err, services.ErrInvalidAPIToken) {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+						"error": "This API token is invalid, expired or revoked.",
+					})
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "We're having trouble verifying your access. Please try again.",
+				})
+				return
+			}
+			SetCurrentAPIToken(c, token)
+			c.Next()
+			return
+		}
+
+		tokenString := c.GetHeader("Authorization")
+		if !strings.HasPrefix(tokenString, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin login required"})
+			return
+		}
+		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(config.AdminJWTSecret), nil
+		}, jwt.WithAudience(adminAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+		if err != nil || !token.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Your admin session is invalid or has expired. Please log in again.",
+			})
+			return
+		}
+
+		admin, err := adminService.GetAdminByID(claims.AdminID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "We're having trouble verifying your access. Please try again.",
+			})
+			return
+		}
+		if admin == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "This admin account no longer exists.",
+			})
+			return
+		}
+
+		SetCurrentAdmin(c, admin)
+		c.Next()
+	}
+}
+
+// RequireRole allows the request only for admins with one of the given
+// roles. Owners are always allowed. API tokens are refused; routes open to
+// scripts use RequireScope.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return RequireScope("", roles...)
+}
+
+// RequireScope allows API tokens granted scope, and admins with one of the
+// given roles
+func RequireScope(scope string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := CurrentAPIToken(c); token != nil {
+			if scope != "" && token.HasScope(scope) {
+				c.Next()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "This API token's scopes do not allow this action.",
+			})
+			return
+		}
+
+		admin := CurrentAdmin(c)
+		if admin == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin login required"})
+			return
+		}
+
+		if admin.Role == models.AdminRoleOwner {
+			c.Next()
+			return
+		}
+		for _, role := range roles {
+			if admin.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Your admin role does not allow this action.",
+		})
+	}
+}
+
+// AnyRole allows every authenticated admin, for read-only routes
+func AnyRole() gin.HandlerFunc {
+	return RequireRole(models.AdminRoles...)
+}
+
+// CurrentAdmin returns the authenticated admin, or nil outside admin routes
+func CurrentAdmin(c *gin.Context) *models.Admin {
+	value, exists := c.Get(contextKey)
+	if !exists {
+		return nil
+	}
+	admin, _ := value.(*models.Admin)
+	return admin
+}
+
+// SetCurrentAdmin stores the authenticated admin on the request context
+func SetCurrentAdmin(c *gin.Context, admin *models.Admin) {
+	c.Set(contextKey, admin)
+}
+
+// CurrentAPIToken returns the API token a script authenticated with, or nil
+// for admins
+func CurrentAPIToken(c *gin.Context) *models.APIToken {
+	value, exists := c.Get(tokenContextKey)
+	if !exists {
+		return nil
+	}
+	token, _ := value.(*models.APIToken)
+	return token
+}
+
+// SetCurrentAPIToken stores the authenticated API token on the request
+// context. The token also stands in as the current admin, without a role, so
+// audit entries name it.
+func SetCurrentAPIToken(c *gin.Context, token *models.APIToken) {
+	c.Set(tokenContextKey, token)
+	SetCurrentAdmin(c, &models.Admin{Username: "api-token:" + token.Name})
+}
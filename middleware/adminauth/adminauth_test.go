@@ -0,0 +1,256 @@
+package adminauth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	config.AdminJWTSecret = "admin-test-secret"
+	config.AdminJWTExpiry = 3600
+	m.Run()
+}
+
+// mockAdminService implements services.AdminServiceInterface; only lookups by ID are used here
+type mockAdminService struct {
+	services.AdminServiceInterface
+	GetAdminByIDFunc func(id int64) (*models.Admin, error)
+}
+
+func (m *mockAdminService) GetAdminByID(id int64) (*models.Admin, error) {
+	if m.GetAdminByIDFunc != nil {
+		return m.GetAdminByIDFunc(id)
+	}
+	return nil, nil
+}
+
+// mockAPITokenService implements services.APITokenServiceInterface; only authentication is used here
+type mockAPITokenService struct {
+	services.APITokenServiceInterface
+	AuthenticateFunc func(secret string) (*models.APIToken, error)
+}
+
+func (m *mockAPITokenService) Authenticate(secret string) (*models.APIToken, error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(secret)
+	}
+	return nil, services.ErrInvalidAPIToken
+}
+
+func setupRouter(adminService services.AdminServiceInterface, handlers ...gin.HandlerFunc) *gin.Engine {
+	return setupTokenRouter(adminService, &mockAPITokenService{}, handlers...)
+}
+
+func setupTokenRouter(adminService services.AdminServiceInterface, tokenService services.APITokenServiceInterface, handlers ...gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	handlers = append([]gin.HandlerFunc{Middleware(adminService, tokenService)}, handlers...)
+	handlers = append(handlers, func(c *gin.Context) {
+		c.JSON(200, gin.H{"username": CurrentAdmin(c).Username})
+	})
+	router.GET("/test", handlers...)
+	return router
+}
+
+func serve(router *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func storedAdmin(role string) *mockAdminService {
+	return &mockAdminService{
+		GetAdminByIDFunc: func(id int64) (*models.Admin, error) {
+			if id == 7 {
+				return &models.Admin{ID: 7, Username: "alice", Role: role}, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+func TestMiddleware_ValidToken(t *testing.T) {
+	token, expiresAt, err := GenerateToken(&models.Admin{ID: 7, Username: "alice", Role: models.AdminRolePlanner})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	w := serve(setupRouter(storedAdmin(models.AdminRolePlanner)), "Authorization", "Bearer "+token)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "alice")
+}
+
+func TestMiddleware_MissingToken(t *testing.T) {
+	w := serve(setupRouter(storedAdmin(models.AdminRoleOwner)), "", "")
+
+	assert.Equal(t, 401, w.Code)
+	assert.Contains(t, w.Body.String(), "Admin login required")
+}
+
+func TestMiddleware_GuestTokenRejected(t *testing.T) {
+	// A token signed with the right secret but without the admin audience
+	claims := jwt.MapClaims{
+		"admin_id": 7,
+		"role":     models.AdminRoleOwner,
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AdminJWTSecret))
+	assert.NoError(t, err)
+
+	w := serve(setupRouter(storedAdmin(models.AdminRoleOwner)), "Authorization", "Bearer "+token)
+
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestMiddleware_DeletedAdmin(t *testing.T) {
+	token, _, err := GenerateToken(&models.Admin{ID: 8, Username: "gone", Role: models.AdminRoleOwner})
+	assert.NoError(t, err)
+
+	w := serve(setupRouter(storedAdmin(models.AdminRoleOwner)), "Authorization", "Bearer "+token)
+
+	assert.Equal(t, 401, w.Code)
+	assert.Contains(t, w.Body.String(), "no longer exists")
+}
+
+func TestMiddleware_ServiceError(t *testing.T) {
+	token, _, err := GenerateToken(&models.Admin{ID: 7, Username: "alice", Role: models.AdminRoleOwner})
+	assert.NoError(t, err)
+	service := &mockAdminService{
+		GetAdminByIDFunc: func(id int64) (*models.Admin, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	w := serve(setupRouter(service), "Authorization", "Bearer "+token)
+
+	assert.Equal(t, 500, w.Code)
+}
+
+func TestMiddleware_RoleReloadedFromDatabase(t *testing.T) {
+	// The token still says owner, but the account has since been demoted
+	token, _, err := GenerateToken(&models.Admin{ID: 7, Username: "alice", Role: models.AdminRoleOwner})
+	assert.NoError(t, err)
+
+	router := setupRouter(storedAdmin(models.AdminRoleViewer), RequireRole(models.AdminRoleModerator))
+	w := serve(router, "Authorization", "Bearer "+token)
+
+	assert.Equal(t, 403, w.Code)
+}
+
+// syncTokens authenticates "wit_sync", a token that may read guests
+var syncTokens = &mockAPITokenService{
+	AuthenticateFunc: func(secret string) (*models.APIToken, error) {
+		if secret == "wit_sync" {
+			return &models.APIToken{ID: 3, Name: "sync", Scopes: []string{models.ScopeGuestsRead}}, nil
+		}
+		return nil, services.ErrInvalidAPIToken
+	},
+}
+
+func TestMiddleware_APIToken(t *testing.T) {
+	router := setupTokenRouter(storedAdmin(models.AdminRoleOwner), syncTokens, RequireScope(models.ScopeGuestsRead, models.AdminRoles...))
+
+	w := serve(router, APITokenHeader, "wit_sync")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "api-token:sync")
+
+	w = serve(router, APITokenHeader, "wit_wrong")
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestMiddleware_APITokenServiceError(t *testing.T) {
+	tokens := &mockAPITokenService{
+		AuthenticateFunc: func(secret string) (*models.APIToken, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	w := serve(setupTokenRouter(storedAdmin(models.AdminRoleOwner), tokens), APITokenHeader, "wit_sync")
+
+	assert.Equal(t, 500, w.Code)
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		expected int
+	}{
+		{"granted scope", RequireScope(models.ScopeGuestsRead, models.AdminRoles...), 200},
+		{"missing scope", RequireScope(models.ScopeGuestsWrite, models.AdminRolePlanner), 403},
+		{"role-only route", RequireRole(models.AdminRoleOwner), 403},
+		{"any role route", AnyRole(), 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTokenRouter(storedAdmin(models.AdminRoleOwner), syncTokens, tt.handler)
+			w := serve(router, APITokenHeader, "wit_sync")
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestRequireScope_Admins(t *testing.T) {
+	router := gin.New()
+	router.GET("/test", func(c *gin.Context) {
+		SetCurrentAdmin(c, &models.Admin{ID: 1, Role: models.AdminRoleViewer})
+	}, RequireScope(models.ScopeGuestsWrite, models.AdminRolePlanner), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	w := serve(router, "", "")
+
+	assert.Equal(t, 403, w.Code)
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		role     string
+		expected int
+	}{
+		{models.AdminRoleOwner, 200},
+		{models.AdminRoleModerator, 200},
+		{models.AdminRolePlanner, 403},
+		{models.AdminRoleViewer, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/test", func(c *gin.Context) {
+				SetCurrentAdmin(c, &models.Admin{ID: 1, Role: tt.role})
+			}, RequireRole(models.AdminRoleModerator), func(c *gin.Context) {
+				c.Status(200)
+			})
+
+			w := serve(router, "", "")
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestRequireRole_NoAdmin(t *testing.T) {
+	router := gin.New()
+	router.GET("/test", AnyRole(), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	w := serve(router, "", "")
+
+	assert.Equal(t, 401, w.Code)
+}
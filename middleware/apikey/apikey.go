@@ -1,28 +0,0 @@
-package apikey
-
-import (
-	"net/http"
-
-	"wedding-invitation-backend/config"
-
-	"github.com/gin-gonic/gin"
-)
-
-func APIKeyMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		apiKey := c.GetHeader("X-API-Key")
-		if apiKey == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
-			c.Abort()
-			return
-		}
-
-		if apiKey != config.AdminAPIKey {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
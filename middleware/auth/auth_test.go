@@ -1,11 +1,14 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/jwtkeys"
 	"wedding-invitation-backend/models"
 	"wedding-invitation-backend/services"
 
@@ -17,71 +20,114 @@ import (
 // mockGuestService implements services.GuestServiceInterface for testing
 type mockGuestService struct {
 	GetGuestByNameFunc       func(name string) (*models.Guest, error)
+	GetGuestByIDFunc         func(id int64) (*models.Guest, error)
 	GetAllGuestsFunc         func() ([]models.Guest, error)
 	CreateGuestFunc          func(guest *models.Guest) error
 	UpdateGuestFunc          func(guest *models.Guest) error
+	SubmitRSVPFunc           func(name string, attending bool) (*models.Guest, error)
 	BulkCreateGuestsFunc     func(guests []models.Guest) error
 	BulkUpdateGuestsFunc     func(guests []models.Guest) error
-	MarkInvitationOpenedFunc func(name string) error
-	ValidateGuestAccessFunc  func(name string) (*models.Guest, error)
+	MarkInvitationOpenedFunc func(guestID int64) error
+	ValidateGuestAccessFunc  func(guestID int64) (*models.Guest, error)
 }
 
-func (m *mockGuestService) GetGuestByName(name string) (*models.Guest, error) {
+func (m *mockGuestService) GetGuestByName(ctx context.Context, name string) (*models.Guest, error) {
 	if m.GetGuestByNameFunc != nil {
 		return m.GetGuestByNameFunc(name)
 	}
 	return nil, nil
 }
 
-func (m *mockGuestService) GetAllGuests() ([]models.Guest, error) {
+func (m *mockGuestService) GetGuestByID(ctx context.Context, id int64) (*models.Guest, error) {
+	if m.GetGuestByIDFunc != nil {
+		return m.GetGuestByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestService) GetAllGuests(ctx context.Context) ([]models.Guest, error) {
 	if m.GetAllGuestsFunc != nil {
 		return m.GetAllGuestsFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockGuestService) CreateGuest(guest *models.Guest) error {
+func (m *mockGuestService) CreateGuest(ctx context.Context, guest *models.Guest) error {
 	if m.CreateGuestFunc != nil {
 		return m.CreateGuestFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestService) UpdateGuest(guest *models.Guest) error {
+func (m *mockGuestService) UpdateGuest(ctx context.Context, guest *models.Guest) error {
 	if m.UpdateGuestFunc != nil {
 		return m.UpdateGuestFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestService) BulkCreateGuests(guests []models.Guest) error {
+func (m *mockGuestService) SubmitRSVP(ctx context.Context, name string, attending bool) (*models.Guest, error) {
+	if m.SubmitRSVPFunc != nil {
+		return m.SubmitRSVPFunc(name, attending)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestService) BulkCreateGuests(ctx context.Context, guests []models.Guest) error {
 	if m.BulkCreateGuestsFunc != nil {
 		return m.BulkCreateGuestsFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestService) BulkUpdateGuests(guests []models.Guest) error {
+func (m *mockGuestService) BulkUpdateGuests(ctx context.Context, guests []models.Guest) error {
 	if m.BulkUpdateGuestsFunc != nil {
 		return m.BulkUpdateGuestsFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestService) MarkInvitationOpened(name string) error {
+func (m *mockGuestService) MarkInvitationOpened(ctx context.Context, guestID int64) error {
 	if m.MarkInvitationOpenedFunc != nil {
-		return m.MarkInvitationOpenedFunc(name)
+		return m.MarkInvitationOpenedFunc(guestID)
 	}
 	return nil
 }
 
-func (m *mockGuestService) ValidateGuestAccess(name string) (*models.Guest, error) {
+func (m *mockGuestService) ValidateGuestAccess(ctx context.Context, guestID int64) (*models.Guest, error) {
 	if m.ValidateGuestAccessFunc != nil {
-		return m.ValidateGuestAccessFunc(name)
+		return m.ValidateGuestAccessFunc(guestID)
 	}
 	return nil, nil
 }
 
+// mockSessionService implements services.SessionServiceInterface for testing;
+// sessions are active unless IsSessionActiveFunc says otherwise
+type mockSessionService struct {
+	services.SessionServiceInterface
+	IsSessionActiveFunc func(id string) (bool, error)
+}
+
+func (m *mockSessionService) IsSessionActive(id string) (bool, error) {
+	if m.IsSessionActiveFunc != nil {
+		return m.IsSessionActiveFunc(id)
+	}
+	return true, nil
+}
+
+// testSession returns a fresh session of guest 1, "testuser", lasting
+// config.JWTExpiry
+func testSession() *models.Session {
+	now := time.Now()
+	return &models.Session{
+		ID:        "test-session",
+		GuestID:   1,
+		GuestName: "testuser",
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(config.JWTExpiry) * time.Second),
+	}
+}
+
 func init() {
 	gin.SetMode(gin.TestMode)
 }
@@ -91,27 +137,40 @@ func TestMain(m *testing.M) {
 	m.Run()
 }
 
+// testSecret is a JWT_SECRET long enough for the key ring
+const testSecret = "test-secret-that-is-long-enough-for-hs256"
+
+// testKeyRing builds the key ring from the test's config.JWTSecret
+func testKeyRing(t *testing.T) *jwtkeys.KeyRing {
+	t.Helper()
+	keys, err := LoadKeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
 func TestJWTMiddlewareWithService_ValidToken(t *testing.T) {
 	// Setup config
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 	config.JWTExpiry = 3600
 
 	// Create mock service
 	mockSvc := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
-			assert.Equal(t, "testuser", name)
-			return &models.Guest{Name: name}, nil
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			assert.Equal(t, int64(1), guestID)
+			return &models.Guest{ID: guestID, Name: "testuser"}, nil
 		},
 	}
 
 	// Generate valid token
-	token, err := GenerateToken("testuser")
+	token, err := GenerateToken(testKeyRing(t), testSession())
 	assert.NoError(t, err)
 
 	// Setup router
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(200)
 	})
@@ -125,13 +184,13 @@ func TestJWTMiddlewareWithService_ValidToken(t *testing.T) {
 }
 
 func TestJWTMiddlewareWithService_MissingToken(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 
 	mockSvc := &mockGuestService{}
 
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(200)
 	})
@@ -144,13 +203,13 @@ func TestJWTMiddlewareWithService_MissingToken(t *testing.T) {
 }
 
 func TestJWTMiddlewareWithService_MalformedToken(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 
 	mockSvc := &mockGuestService{}
 
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(200)
 	})
@@ -164,7 +223,7 @@ func TestJWTMiddlewareWithService_MalformedToken(t *testing.T) {
 }
 
 func TestJWTMiddlewareWithService_ExpiredToken(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 
 	mockSvc := &mockGuestService{}
 
@@ -181,7 +240,7 @@ func TestJWTMiddlewareWithService_ExpiredToken(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(200)
 	})
@@ -195,22 +254,22 @@ func TestJWTMiddlewareWithService_ExpiredToken(t *testing.T) {
 }
 
 func TestJWTMiddlewareWithService_GuestNotFound(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 	config.JWTExpiry = 3600
 
 	// Mock returns nil guest (access revoked)
 	mockSvc := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return nil, nil
 		},
 	}
 
-	token, err := GenerateToken("testuser")
+	token, err := GenerateToken(testKeyRing(t), testSession())
 	assert.NoError(t, err)
 
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(200)
 	})
@@ -224,22 +283,22 @@ func TestJWTMiddlewareWithService_GuestNotFound(t *testing.T) {
 }
 
 func TestJWTMiddlewareWithService_ValidateGuestAccessError(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 	config.JWTExpiry = 3600
 
 	// Mock returns error
 	mockSvc := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return nil, assert.AnError
 		},
 	}
 
-	token, err := GenerateToken("testuser")
+	token, err := GenerateToken(testKeyRing(t), testSession())
 	assert.NoError(t, err)
 
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(200)
 	})
@@ -253,10 +312,10 @@ func TestJWTMiddlewareWithService_ValidateGuestAccessError(t *testing.T) {
 }
 
 func TestGenerateToken(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 	config.JWTExpiry = 3600
 
-	token, err := GenerateToken("alice")
+	token, err := GenerateToken(testKeyRing(t), testSession())
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -267,7 +326,115 @@ func TestGenerateToken(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.True(t, parsedToken.Valid)
-	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, int64(1), claims.GuestID)
+	assert.Equal(t, "testuser", claims.Username)
+	assert.Equal(t, "test-session", claims.ID)
+	assert.NotNil(t, claims.IssuedAt)
+}
+
+func TestJWTMiddlewareWithService_RevokedSession(t *testing.T) {
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 3600
+
+	mockSvc := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return &models.Guest{ID: guestID, Name: "testuser"}, nil
+		},
+	}
+	mockSessions := &mockSessionService{
+		IsSessionActiveFunc: func(id string) (bool, error) {
+			assert.Equal(t, "test-session", id)
+			return false, nil
+		},
+	}
+
+	token, err := GenerateToken(testKeyRing(t), testSession())
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), mockSessions))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 401, w.Code)
+	assert.Contains(t, w.Body.String(), "signed out")
+}
+
+func TestJWTMiddlewareWithService_TokenWithoutSession(t *testing.T) {
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 3600
+
+	mockSvc := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			t.Errorf("unexpected lookup of guest %q", name)
+			return &models.Guest{ID: 1, Name: name}, nil
+		},
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			t.Errorf("unexpected lookup of guest %d", guestID)
+			return nil, nil
+		},
+	}
+
+	// A validly signed token without a jti cannot be signed out, so it is
+	// refused
+	claims := &Claims{
+		GuestID:  1,
+		Username: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := testKeyRing(t).Sign(claims)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestJWTMiddlewareWithService_RenamedGuest(t *testing.T) {
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 3600
+
+	// The guest logged in as "testuser" and was renamed since
+	mockSvc := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return &models.Guest{ID: guestID, Name: "Test User"}, nil
+		},
+	}
+
+	token, err := GenerateToken(testKeyRing(t), testSession())
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
+	router.GET("/test", func(c *gin.Context) {
+		assert.Equal(t, int64(1), c.MustGet("guest_id"))
+		c.String(200, c.GetString("username"))
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "Test User", w.Body.String())
 }
 
 // Compile-time check to ensure mock implements interface
@@ -275,25 +442,26 @@ var _ services.GuestServiceInterface = (*mockGuestService)(nil)
 
 // Test with sql.NullBool for completeness
 func TestJWTMiddlewareWithService_ValidTokenWithFullGuest(t *testing.T) {
-	config.JWTSecret = "test-secret"
+	config.JWTSecret = testSecret
 	config.JWTExpiry = 3600
 
 	mockSvc := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return &models.Guest{
-				Name:      name,
+				ID:        guestID,
+				Name:      "testuser",
 				Attending: sql.NullBool{Bool: true, Valid: true},
 				PlusOnes:  2,
 			}, nil
 		},
 	}
 
-	token, err := GenerateToken("testuser")
+	token, err := GenerateToken(testKeyRing(t), testSession())
 	assert.NoError(t, err)
 
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
-	router.Use(JWTMiddlewareWithService(mockSvc))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
 	router.GET("/test", func(c *gin.Context) {
 		username := c.MustGet("username").(string)
 		assert.Equal(t, "testuser", username)
@@ -306,3 +474,80 @@ func TestJWTMiddlewareWithService_ValidTokenWithFullGuest(t *testing.T) {
 
 	assert.Equal(t, 200, w.Code)
 }
+
+func TestQueryTokenMiddleware_UsesQueryParameter(t *testing.T) {
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 3600
+
+	mockSvc := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return &models.Guest{ID: guestID, Name: "testuser"}, nil
+		},
+	}
+
+	token, err := GenerateToken(testKeyRing(t), testSession())
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(QueryTokenMiddleware("token"))
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	req := httptest.NewRequest("GET", "/test?token="+token, nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestJWTMiddlewareWithService_AccessTokenCookie(t *testing.T) {
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 3600
+
+	mockSvc := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return &models.Guest{ID: guestID, Name: "testuser"}, nil
+		},
+	}
+
+	token, err := GenerateToken(testKeyRing(t), testSession())
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(JWTMiddlewareWithService(mockSvc, testKeyRing(t), &mockSessionService{}))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(200, c.GetString("username"))
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: token})
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "testuser", w.Body.String())
+}
+
+func TestGenerateToken_ShortLivedWithinSession(t *testing.T) {
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 900
+
+	session := testSession()
+	session.ExpiresAt = time.Now().Add(30 * 24 * time.Hour)
+	token, err := GenerateToken(testKeyRing(t), session)
+	assert.NoError(t, err)
+	claims := &Claims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+	// A session about to end caps the access token
+	session.ExpiresAt = time.Now().Add(time.Minute)
+	token, err = GenerateToken(testKeyRing(t), session)
+	assert.NoError(t, err)
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, session.ExpiresAt, claims.ExpiresAt.Time, time.Second)
+}
@@ -1,20 +1,30 @@
 package auth
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/jwtkeys"
+	"wedding-invitation-backend/models"
 	"wedding-invitation-backend/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTMiddlewareWithService creates JWT middleware that uses the guest service for validation
-func JWTMiddlewareWithService(guestService services.GuestServiceInterface) gin.HandlerFunc {
+// JWTMiddlewareWithService creates JWT middleware that verifies tokens with the
+// key ring, rejects tokens whose session was revoked and uses the guest
+// service for validation. Without an Authorization header the access token
+// cookie is used.
+func JWTMiddlewareWithService(guestService services.GuestServiceInterface, keys *jwtkeys.KeyRing, sessions services.SessionServiceInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" {
+			tokenString, _ = c.Cookie(AccessTokenCookie)
+		}
 		if tokenString == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
@@ -26,9 +36,7 @@ func JWTMiddlewareWithService(guestService services.GuestServiceInterface) gin.H
 		}
 
 		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(config.JWTSecret), nil
-		})
+		token, err := keys.Parse(tokenString, claims)
 
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -44,10 +52,38 @@ func JWTMiddlewareWithService(guestService services.GuestServiceInterface) gin.H
 			return
 		}
 
-		c.Set("username", claims.Username)
+		// Every token is issued for a session. One without a jti could not
+		// be signed out, so it is refused.
+		if claims.ID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "We're having trouble verifying your login. Please try logging in again.",
+			})
+			return
+		}
 
-		// Check if user is on guest list using cached service
-		guest, err := guestService.ValidateGuestAccess(claims.Username)
+		active, err := sessions.IsSessionActive(claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "We're having trouble verifying your login. Please try again.",
+			})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "This device has been signed out. Please log in again.",
+			})
+			return
+		}
+
+		// Check if user is on guest list using cached service. Tokens issued
+		// before guest IDs were added carry only the name and are looked up
+		// by it until they expire.
+		var guest *models.Guest
+		if claims.GuestID != 0 {
+			guest, err = guestService.ValidateGuestAccess(c.Request.Context(), claims.GuestID)
+		} else {
+			guest, err = guestService.GetGuestByName(c.Request.Context(), claims.Username)
+		}
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 				"error": "We're having trouble verifying your access. Please try again.",
@@ -62,27 +98,83 @@ func JWTMiddlewareWithService(guestService services.GuestServiceInterface) gin.H
 			return
 		}
 
+		// The name is the guest's current one, for display
+		c.Set("guest_id", guest.ID)
+		c.Set("username", guest.Name)
+		c.Set("session_id", claims.ID)
+
 		c.Next()
 	}
 }
 
-// Claims represents JWT token claims for guest authentication
+// Claims represents JWT token claims for guest authentication. GuestID
+// identifies the guest; Username is the name at login, for display only.
 type Claims struct {
+	GuestID  int64  `json:"guest_id,omitempty"`
 	Username string `json:"username"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a JWT token for the given username
-func GenerateToken(username string) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(config.JWTExpiry) * time.Second)
-	expiresAt := jwt.NewNumericDate(expirationTime)
+// GenerateToken creates a short-lived access token for the session's guest,
+// signed with the key ring's current signing key. The session ID becomes the
+// jti claim. The token never outlives its session.
+func GenerateToken(keys *jwtkeys.KeyRing, session *models.Session) (string, error) {
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(config.JWTExpiry) * time.Second)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
 	claims := &Claims{
-		Username: username,
+		GuestID:  session.GuestID,
+		Username: session.GuestName,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: expiresAt,
+			ID:        session.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.JWTSecret))
-}
\ No newline at end of file
+	return keys.Sign(claims)
+}
+
+// LoadKeyRing builds the guest token key ring from JWT_SECRET, which becomes
+// the "default" key when set, and the key files in JWT_KEYS_DIR. Keys listed
+// in JWT_RETIRED_KEYS verify for JWT_KEY_GRACE_PERIOD after their retirement.
+func LoadKeyRing() (*jwtkeys.KeyRing, error) {
+	var keys []*jwtkeys.Key
+	if config.JWTSecret != "" {
+		if len(config.JWTSecret) < jwtkeys.MinHMACSecretLength {
+			return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes", jwtkeys.MinHMACSecretLength)
+		}
+		key, err := jwtkeys.NewHMACKey(jwtkeys.DefaultKeyID, []byte(config.JWTSecret))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if config.JWTKeysDir != "" {
+		fileKeys, err := jwtkeys.LoadDir(config.JWTKeysDir)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no guest token keys: set JWT_SECRET or JWT_KEYS_DIR")
+	}
+
+	signingID := config.JWTSigningKeyID
+	if signingID == "" {
+		signingID = jwtkeys.DefaultKeyID
+	}
+
+	retiredAt, err := jwtkeys.ParseRetiredKeys(config.JWTRetiredKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwtkeys.NewKeyRing(signingID, keys, retiredAt, config.JWTKeyGracePeriod)
+}
\ No newline at end of file
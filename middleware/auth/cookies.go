@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cookies used in cookie mode instead of keeping tokens in the browser's
+// storage
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFTokenCookie    = "csrf_token"
+
+	// RefreshTokenPath limits the refresh cookie to the refresh endpoint
+	RefreshTokenPath = "/auth/refresh"
+)
+
+// SetTokenCookies stores the access and refresh tokens in HttpOnly cookies
+// that expire with the access token and the session respectively. The CSRF
+// token goes into a cookie scripts can read, to be echoed back in the
+// X-CSRF-Token header.
+func SetTokenCookies(c *gin.Context, accessToken, refreshToken, csrfToken string, session *models.Session) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     AccessTokenCookie,
+		Value:    accessToken,
+		Path:     "/",
+		MaxAge:   config.JWTExpiry,
+		HttpOnly: true,
+		Secure:   config.AuthCookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     RefreshTokenCookie,
+		Value:    refreshToken,
+		Path:     RefreshTokenPath,
+		Expires:  session.ExpiresAt,
+		MaxAge:   int(time.Until(session.ExpiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   config.AuthCookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     CSRFTokenCookie,
+		Value:    csrfToken,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		MaxAge:   int(time.Until(session.ExpiresAt).Seconds()),
+		Secure:   config.AuthCookieSecure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ClearTokenCookies removes the token and CSRF cookies, e.g. after a failed
+// refresh
+func ClearTokenCookies(c *gin.Context) {
+	for name, path := range map[string]string{AccessTokenCookie: "/", RefreshTokenCookie: RefreshTokenPath, CSRFTokenCookie: "/"} {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     name,
+			Path:     path,
+			MaxAge:   -1,
+			HttpOnly: name != CSRFTokenCookie,
+			Secure:   config.AuthCookieSecure,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
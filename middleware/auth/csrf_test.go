@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		accessCookie  bool
+		csrfCookie    string
+		csrfHeader    string
+		expected      int
+	}{
+		{"matching token", "POST", "", true, "csrf-1", "csrf-1", http.StatusOK},
+		{"missing header", "POST", "", true, "csrf-1", "", http.StatusForbidden},
+		{"wrong header", "POST", "", true, "csrf-1", "csrf-2", http.StatusForbidden},
+		{"missing cookie", "POST", "", true, "", "csrf-1", http.StatusForbidden},
+		{"safe method", "GET", "", true, "csrf-1", "", http.StatusOK},
+		{"authorization header", "POST", "Bearer token", true, "", "", http.StatusOK},
+		{"no access cookie", "POST", "", false, "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CSRFMiddleware())
+			router.Handle(tt.method, "/rsvp", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/rsvp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.accessCookie {
+				req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "token"})
+			}
+			if tt.csrfCookie != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFTokenCookie, Value: tt.csrfCookie})
+			}
+			if tt.csrfHeader != "" {
+				req.Header.Set(CSRFTokenHeader, tt.csrfHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestNewCSRFToken(t *testing.T) {
+	first, err := NewCSRFToken()
+	assert.NoError(t, err)
+	second, err := NewCSRFToken()
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreKeyConfig resets the key settings changed by a test
+func restoreKeyConfig(t *testing.T) {
+	secret, dir, signingID, retired, grace := config.JWTSecret, config.JWTKeysDir, config.JWTSigningKeyID, config.JWTRetiredKeys, config.JWTKeyGracePeriod
+	t.Cleanup(func() {
+		config.JWTSecret, config.JWTKeysDir, config.JWTSigningKeyID, config.JWTRetiredKeys, config.JWTKeyGracePeriod = secret, dir, signingID, retired, grace
+	})
+}
+
+// writeEd25519KeyFile stores a new Ed25519 private key as dir/<id>.pem
+func writeEd25519KeyFile(t *testing.T, dir, id string) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), keyPEM, 0600))
+}
+
+func TestLoadKeyRing_Rotation(t *testing.T) {
+	restoreKeyConfig(t)
+	config.JWTSecret = testSecret
+	config.JWTExpiry = 3600
+	config.JWTKeyGracePeriod = time.Hour
+
+	// A session signed with the shared secret before the rotation
+	oldKeys := testKeyRing(t)
+	oldToken, err := GenerateToken(oldKeys, testSession())
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	writeEd25519KeyFile(t, dir, "2026-04")
+
+	config.JWTKeysDir = dir
+	config.JWTSigningKeyID = "2026-04"
+	config.JWTRetiredKeys = "default=" + time.Now().Add(-30*time.Minute).Format(time.RFC3339)
+	keys, err := LoadKeyRing()
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-04", keys.SigningKeyID())
+
+	newToken, err := GenerateToken(keys, testSession())
+	assert.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+	assert.Equal(t, "2026-04", parsed.Header["kid"])
+
+	mockSvc := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return &models.Guest{ID: guestID, Name: "testuser"}, nil
+		},
+	}
+	router := gin.New()
+	router.Use(JWTMiddlewareWithService(mockSvc, keys, &mockSessionService{}))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(200, c.GetString("username"))
+	})
+
+	// Within the grace period both the old and the new session are accepted
+	for _, token := range []string{oldToken, newToken} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// Once it has passed, tokens of the old key are refused even when
+	// freshly signed
+	config.JWTRetiredKeys = "default=" + time.Now().Add(-2*time.Hour).Format(time.RFC3339)
+	keys, err = LoadKeyRing()
+	assert.NoError(t, err)
+	mintedToken, err := GenerateToken(oldKeys, testSession())
+	assert.NoError(t, err)
+
+	router = gin.New()
+	router.Use(JWTMiddlewareWithService(mockSvc, keys, &mockSessionService{}))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(200, c.GetString("username"))
+	})
+	for _, token := range []string{oldToken, mintedToken} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 401, w.Code)
+	}
+}
+
+func TestLoadKeyRing_InvalidRetiredKeys(t *testing.T) {
+	restoreKeyConfig(t)
+	config.JWTSecret = testSecret
+	config.JWTKeysDir = ""
+	config.JWTSigningKeyID = ""
+
+	for _, retired := range []string{"default", "default=yesterday", "missing=2026-04-01T00:00:00Z"} {
+		config.JWTRetiredKeys = retired
+		_, err := LoadKeyRing()
+		assert.Error(t, err, retired)
+	}
+}
+
+func TestLoadKeyRing_UnknownSigningKey(t *testing.T) {
+	restoreKeyConfig(t)
+	config.JWTSecret = testSecret
+	config.JWTKeysDir = ""
+	config.JWTSigningKeyID = "missing"
+
+	_, err := LoadKeyRing()
+
+	assert.Error(t, err)
+}
+
+func TestLoadKeyRing_SharedSecret(t *testing.T) {
+	restoreKeyConfig(t)
+	config.JWTKeysDir = ""
+	config.JWTSigningKeyID = ""
+	config.JWTRetiredKeys = ""
+
+	// Neither a secret nor key files
+	config.JWTSecret = ""
+	_, err := LoadKeyRing()
+	assert.Error(t, err)
+
+	config.JWTSecret = "test-secret"
+	_, err = LoadKeyRing()
+	assert.Error(t, err, "short secrets are refused")
+
+	// Without JWT_SECRET the ring holds no default key, so a token signed
+	// with a guessed secret and no kid is refused
+	config.JWTSecret = ""
+	config.JWTKeysDir = t.TempDir()
+	config.JWTSigningKeyID = "2026-04"
+	writeEd25519KeyFile(t, config.JWTKeysDir, "2026-04")
+	keys, err := LoadKeyRing()
+	assert.NoError(t, err)
+
+	claims := &Claims{
+		GuestID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	assert.NoError(t, err)
+	_, err = keys.Parse(forged, &Claims{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTokenMiddleware copies a token from the given query parameter into the
+// Authorization header when the header is missing. It exists for clients such
+// as EventSource that cannot set request headers, and must run before
+// JWTMiddlewareWithService.
+func QueryTokenMiddleware(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(param); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
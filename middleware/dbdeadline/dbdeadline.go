@@ -0,0 +1,86 @@
+// Package dbdeadline bounds how long a request may wait on the database
+package dbdeadline
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+	"wedding-invitation-backend/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware gives every request a deadline for its database work. The
+// request context carries the deadline down to the queries, which are
+// cancelled when it passes. Handlers answer failed queries with a 500; a 500
+// written after the deadline is turned into a 503 so clients know to retry.
+// Routes in exempt, given as registered (e.g. "/comments/stream"), are
+// long-lived and get no deadline. A timeout of 0 disables the middleware.
+func Middleware(timeout time.Duration, exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if timeout <= 0 || skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &deadlineWriter{ResponseWriter: c.Writer, ctx: ctx}
+
+		c.Next()
+
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Printf("%s %s ran past its %v database deadline", c.Request.Method, c.Request.URL.Path, timeout)
+		}
+	}
+}
+
+// deadlineWriter replaces internal server errors written after the request
+// deadline with errors.ErrServiceUnavailable
+type deadlineWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+	replaced bool
+}
+
+func (w *deadlineWriter) WriteHeader(code int) {
+	if code == http.StatusInternalServerError && w.ctx.Err() == context.DeadlineExceeded {
+		w.timedOut = true
+		code = http.StatusServiceUnavailable
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *deadlineWriter) Write(data []byte) (int, error) {
+	if !w.timedOut {
+		return w.ResponseWriter.Write(data)
+	}
+	if !w.replaced {
+		w.replaced = true
+		body, err := json.Marshal(gin.H{
+			"code":    errors.ErrServiceUnavailable.Code,
+			"message": errors.ErrServiceUnavailable.Message,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if _, err := w.ResponseWriter.Write(body); err != nil {
+			return 0, err
+		}
+	}
+	// The handler's own error body is dropped
+	return len(data), nil
+}
+
+func (w *deadlineWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
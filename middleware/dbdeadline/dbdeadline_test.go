@@ -0,0 +1,71 @@
+package dbdeadline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// slowQuery stands in for a query that runs until the request is cancelled
+func slowQuery(c *gin.Context) {
+	<-c.Request.Context().Done()
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve guests"})
+}
+
+func serve(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestMiddleware_DeadlineBecomesServiceUnavailable(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware(20 * time.Millisecond))
+	r.GET("/guests", slowQuery)
+
+	w := serve(r, "/guests")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"code":503,"message":"Service temporarily unavailable, please try again"}`, w.Body.String())
+}
+
+func TestMiddleware_KeepsErrorsBeforeDeadline(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware(time.Minute))
+	r.GET("/guests", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		assert.True(t, hasDeadline)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve guests"})
+	})
+
+	w := serve(r, "/guests")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to retrieve guests")
+}
+
+func TestMiddleware_ExemptRoutesAndZeroTimeout(t *testing.T) {
+	noDeadline := func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		assert.False(t, hasDeadline)
+		c.Status(http.StatusOK)
+	}
+
+	r := gin.New()
+	r.Use(Middleware(time.Minute, "/comments/stream"))
+	r.GET("/comments/stream", noDeadline)
+	assert.Equal(t, http.StatusOK, serve(r, "/comments/stream").Code)
+
+	r = gin.New()
+	r.Use(Middleware(0))
+	r.GET("/guests", noDeadline)
+	assert.Equal(t, http.StatusOK, serve(r, "/guests").Code)
+}
@@ -40,11 +40,12 @@ func MiddlewareWithKeyFunc(limiter *ratelimit.SlidingWindowLimiter, keyFunc KeyF
 	}
 }
 
-// UserKeyFunc creates a key function based on authenticated user
+// UserKeyFunc creates a key function based on the authenticated guest's ID,
+// so renaming a guest does not reset their limit
 func UserKeyFunc() KeyFunc {
 	return func(c *gin.Context) string {
-		if username, exists := c.Get("username"); exists {
-			return fmt.Sprintf("user:%s", username)
+		if guestID, exists := c.Get("guest_id"); exists {
+			return fmt.Sprintf("guest:%d", guestID)
 		}
 		return fmt.Sprintf("ip:%s", c.ClientIP())
 	}
@@ -87,3 +87,22 @@ func TestRateLimitMiddleware_CustomKeyFunc(t *testing.T) {
 		t.Errorf("second request should be blocked, got %d", w2.Code)
 	}
 }
+
+func TestUserKeyFunc(t *testing.T) {
+	keyFunc := UserKeyFunc()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+	c.Request.RemoteAddr = "203.0.113.7:1234"
+	if key := keyFunc(c); key != "ip:203.0.113.7" {
+		t.Errorf("expected IP key for anonymous request, got %q", key)
+	}
+
+	// The guest ID is used, not the name, which can change
+	c.Set("guest_id", int64(12))
+	c.Set("username", "John Doe")
+	if key := keyFunc(c); key != "guest:12" {
+		t.Errorf("expected guest ID key, got %q", key)
+	}
+}
@@ -0,0 +1,65 @@
+// Package requestlog logs requests like gin's default logger, without the
+// credentials some clients have to send in the query string
+package requestlog
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Redacted replaces the values of redacted query parameters in the log
+const Redacted = "[redacted]"
+
+// Logger logs each request in gin's default format, with the values of the
+// given query parameters replaced by Redacted. EventSource clients, for
+// example, send their access token as ?token=.
+func Logger(params ...string) gin.HandlerFunc {
+	redact := make(map[string]bool, len(params))
+	for _, param := range params {
+		redact[param] = true
+	}
+
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			RedactQuery(param.Path, redact),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// RedactQuery replaces the values of the parameters in redact in the query
+// string of path, keeping everything else as sent
+func RedactQuery(path string, redact map[string]bool) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found || len(redact) == 0 {
+		return path
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && redact[name] {
+			pairs[i] = key + "=" + Redacted
+		}
+	}
+	return base + "?" + strings.Join(pairs, "&")
+}
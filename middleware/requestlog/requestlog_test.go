@@ -0,0 +1,55 @@
+package requestlog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactQuery(t *testing.T) {
+	redact := map[string]bool{"token": true}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/comments/stream", "/comments/stream"},
+		{"/comments/stream?token=eyJhbGc.secret", "/comments/stream?token=[redacted]"},
+		{"/comments/stream?last=3&token=secret&x=1", "/comments/stream?last=3&token=[redacted]&x=1"},
+		{"/comments/stream?to%6Ben=secret", "/comments/stream?to%6Ben=[redacted]"},
+		{"/comments/stream?token", "/comments/stream?token=[redacted]"},
+		{"/comments?tokens=1", "/comments?tokens=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, RedactQuery(tt.path, redact))
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &out
+	defer func() { gin.DefaultWriter = defaultWriter }()
+
+	r := gin.New()
+	r.Use(Logger("token"))
+	r.GET("/comments/stream", func(c *gin.Context) {
+		// Handlers still see the real value
+		assert.Equal(t, "secret-access-token", c.Query("token"))
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/comments/stream?token=secret-access-token", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, out.String(), `"/comments/stream?token=[redacted]"`)
+	assert.NotContains(t, out.String(), "secret-access-token")
+}
@@ -0,0 +1,247 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrAdminExists is returned when creating an admin with a username that is already taken
+var ErrAdminExists = errors.New("admin username already exists")
+
+// Admin roles. Owners can do everything, including managing other admins.
+const (
+	AdminRoleOwner     = "owner"
+	AdminRolePlanner   = "planner"
+	AdminRoleModerator = "moderator"
+	AdminRoleViewer    = "viewer"
+)
+
+// AdminRoles lists every valid admin role
+var AdminRoles = []string{AdminRoleOwner, AdminRolePlanner, AdminRoleModerator, AdminRoleViewer}
+
+// IsValidAdminRole reports whether role is one of AdminRoles
+func IsValidAdminRole(role string) bool {
+	for _, r := range AdminRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Admin is a named account for the wedding committee
+type Admin struct {
+	ID           int64
+	Username     string
+	PasswordHash string `json:"-"`
+	Role         string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	LastLoginAt  sql.NullTime
+}
+
+func (a *Admin) Create(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// Usernames are case-insensitive
+	var count int
+	row := tx.QueryRow("SELECT COUNT(*) FROM admins WHERE username = ? COLLATE NOCASE", a.Username)
+	if err := row.Scan(&count); err != nil {
+		log.Printf("Failed to check admin username: %v", err)
+		return err
+	}
+	if count > 0 {
+		return ErrAdminExists
+	}
+
+	stmt := `INSERT INTO admins
+		(username, password_hash, role)
+		VALUES (?, ?, ?)`
+
+	result, err := tx.Exec(stmt,
+		a.Username,
+		a.PasswordHash,
+		a.Role)
+	if err != nil {
+		log.Printf("Failed to create admin: %v", err)
+		return err
+	}
+
+	a.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Printf("Failed to get last insert ID: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully created admin %s with role %s", a.Username, a.Role)
+	return nil
+}
+
+const adminColumns = `id, username, password_hash, role, created_at, updated_at, last_login_at`
+
+func scanAdmin(scanner interface{ Scan(...interface{}) error }) (*Admin, error) {
+	admin := &Admin{}
+	err := scanner.Scan(
+		&admin.ID,
+		&admin.Username,
+		&admin.PasswordHash,
+		&admin.Role,
+		&admin.CreatedAt,
+		&admin.UpdatedAt,
+		&admin.LastLoginAt,
+	)
+	return admin, err
+}
+
+// GetAdminByUsername retrieves an admin by username, ignoring case
+func GetAdminByUsername(db *sql.DB, username string) (*Admin, error) {
+	stmt := `SELECT ` + adminColumns + ` FROM admins WHERE username = ? COLLATE NOCASE`
+
+	admin, err := scanAdmin(db.QueryRow(stmt, username))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// GetAdminByID retrieves an admin by ID
+func GetAdminByID(db *sql.DB, id int64) (*Admin, error) {
+	stmt := `SELECT ` + adminColumns + ` FROM admins WHERE id = ?`
+
+	admin, err := scanAdmin(db.QueryRow(stmt, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// GetAllAdmins retrieves all admins ordered by username
+func GetAllAdmins(db *sql.DB) ([]Admin, error) {
+	stmt := `SELECT ` + adminColumns + ` FROM admins ORDER BY username COLLATE NOCASE`
+
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var admins []Admin
+	for rows.Next() {
+		admin, err := scanAdmin(rows)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, *admin)
+	}
+
+	// Check for iteration errors
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return admins, nil
+}
+
+// CountAdminsByRole returns the number of admins with the given role
+func CountAdminsByRole(db *sql.DB, role string) (int, error) {
+	var count int
+	row := db.QueryRow("SELECT COUNT(*) FROM admins WHERE role = ?", role)
+	err := row.Scan(&count)
+	return count, err
+}
+
+// CountAdmins returns the total number of admins
+func CountAdmins(db *sql.DB) (int, error) {
+	var count int
+	row := db.QueryRow("SELECT COUNT(*) FROM admins")
+	err := row.Scan(&count)
+	return count, err
+}
+
+// UpdateAdminRole changes an admin's role
+func UpdateAdminRole(db *sql.DB, id int64, role string) error {
+	return updateAdmin(db, id, "role", role)
+}
+
+// UpdateAdminPassword replaces an admin's password hash
+func UpdateAdminPassword(db *sql.DB, id int64, passwordHash string) error {
+	return updateAdmin(db, id, "password_hash", passwordHash)
+}
+
+// updateAdmin sets a single column; column is never user input
+func updateAdmin(db *sql.DB, id int64, column string, value interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `UPDATE admins SET ` + column + ` = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+
+	res, err := tx.Exec(stmt, value, id)
+	if err != nil {
+		log.Printf("Failed to update admin %s: %v", column, err)
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Failed to get rows affected: %v", err)
+		return err
+	}
+	if rows == 0 {
+		log.Printf("No rows affected - admin not found")
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Updated %s of admin %d", column, id)
+	return nil
+}
+
+// RecordAdminLogin stores the time of an admin's latest successful login
+func RecordAdminLogin(db *sql.DB, id int64) error {
+	_, err := db.Exec("UPDATE admins SET last_login_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	return err
+}
+
+// DeleteAdmin removes an admin account
+func DeleteAdmin(db *sql.DB, id int64) error {
+	res, err := db.Exec("DELETE FROM admins WHERE id = ?", id)
+	if err != nil {
+		log.Printf("Failed to delete admin: %v", err)
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	log.Printf("Deleted admin %d", id)
+	return nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminCreate(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	admin := &Admin{Username: "Alice", PasswordHash: "hash", Role: AdminRoleOwner}
+	err := admin.Create(db)
+	assert.NoError(t, err)
+	assert.NotZero(t, admin.ID)
+
+	// Usernames are unique regardless of case
+	duplicate := &Admin{Username: "alice", PasswordHash: "hash", Role: AdminRoleViewer}
+	assert.ErrorIs(t, duplicate.Create(db), ErrAdminExists)
+}
+
+func TestGetAdminByUsername(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	admin := &Admin{Username: "Alice", PasswordHash: "hash", Role: AdminRolePlanner}
+	assert.NoError(t, admin.Create(db))
+
+	found, err := GetAdminByUsername(db, "ALICE")
+	assert.NoError(t, err)
+	assert.Equal(t, admin.ID, found.ID)
+	assert.Equal(t, AdminRolePlanner, found.Role)
+	assert.False(t, found.LastLoginAt.Valid)
+
+	missing, err := GetAdminByUsername(db, "bob")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestUpdateAdminRoleAndPassword(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	admin := &Admin{Username: "alice", PasswordHash: "old", Role: AdminRoleViewer}
+	assert.NoError(t, admin.Create(db))
+
+	assert.NoError(t, UpdateAdminRole(db, admin.ID, AdminRoleModerator))
+	assert.NoError(t, UpdateAdminPassword(db, admin.ID, "new"))
+	assert.NoError(t, RecordAdminLogin(db, admin.ID))
+
+	found, err := GetAdminByID(db, admin.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, AdminRoleModerator, found.Role)
+	assert.Equal(t, "new", found.PasswordHash)
+	assert.True(t, found.LastLoginAt.Valid)
+
+	assert.ErrorIs(t, UpdateAdminRole(db, 999, AdminRoleOwner), sql.ErrNoRows)
+}
+
+func TestCountAndDeleteAdmins(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	for _, admin := range []*Admin{
+		{Username: "owner1", PasswordHash: "h", Role: AdminRoleOwner},
+		{Username: "owner2", PasswordHash: "h", Role: AdminRoleOwner},
+		{Username: "viewer", PasswordHash: "h", Role: AdminRoleViewer},
+	} {
+		assert.NoError(t, admin.Create(db))
+	}
+
+	count, err := CountAdmins(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	owners, err := CountAdminsByRole(db, AdminRoleOwner)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, owners)
+
+	admins, err := GetAllAdmins(db)
+	assert.NoError(t, err)
+	assert.Equal(t, "owner1", admins[0].Username)
+
+	assert.NoError(t, DeleteAdmin(db, admins[0].ID))
+	assert.ErrorIs(t, DeleteAdmin(db, admins[0].ID), sql.ErrNoRows)
+
+	count, err = CountAdmins(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestIsValidAdminRole(t *testing.T) {
+	assert.True(t, IsValidAdminRole(AdminRoleModerator))
+	assert.False(t, IsValidAdminRole("superuser"))
+	assert.False(t, IsValidAdminRole(""))
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+)
+
+// API token scopes. Each scope opens a group of /admin routes to scripts.
+const (
+	ScopeGuestsRead       = "guests:read"
+	ScopeGuestsWrite      = "guests:write"
+	ScopeCommentsModerate = "comments:moderate"
+	ScopeStatsRead        = "stats:read"
+)
+
+// APITokenScopes lists every valid API token scope
+var APITokenScopes = []string{ScopeGuestsRead, ScopeGuestsWrite, ScopeCommentsModerate, ScopeStatsRead}
+
+// IsValidAPITokenScope reports whether scope is one of APITokenScopes
+func IsValidAPITokenScope(scope string) bool {
+	for _, s := range APITokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken lets a script call /admin routes within its scopes. Only the
+// token's hash is stored; the token itself is shown once when created.
+type APIToken struct {
+	ID         int64
+	Name       string
+	TokenHash  string `json:"-"`
+	Scopes     []string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
+// HasScope reports whether the token was granted scope
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive reports whether the token is neither revoked nor expired
+func (t *APIToken) IsActive(now time.Time) bool {
+	return !t.RevokedAt.Valid && (!t.ExpiresAt.Valid || now.Before(t.ExpiresAt.Time))
+}
+
+func (t *APIToken) Create(db *sql.DB) error {
+	stmt := `INSERT INTO api_tokens
+		(name, token_hash, scopes, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	var expiresAt interface{}
+	if t.ExpiresAt.Valid {
+		expiresAt = t.ExpiresAt.Time.UTC()
+	}
+	result, err := db.Exec(stmt,
+		t.Name,
+		t.TokenHash,
+		strings.Join(t.Scopes, ","),
+		t.CreatedBy,
+		t.CreatedAt.UTC(),
+		expiresAt)
+	if err != nil {
+		log.Printf("Failed to create API token: %v", err)
+		return err
+	}
+
+	t.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Printf("Failed to get last insert ID: %v", err)
+		return err
+	}
+	return nil
+}
+
+const apiTokenColumns = `id, name, token_hash, scopes, created_by, created_at,
+	expires_at, last_used_at, revoked_at FROM api_tokens`
+
+func scanAPIToken(scanner interface{ Scan(...interface{}) error }) (*APIToken, error) {
+	token := &APIToken{}
+	var scopes string
+	err := scanner.Scan(
+		&token.ID,
+		&token.Name,
+		&token.TokenHash,
+		&scopes,
+		&token.CreatedBy,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.RevokedAt,
+	)
+	token.Scopes = []string{}
+	if scopes != "" {
+		token.Scopes = strings.Split(scopes, ",")
+	}
+	return token, err
+}
+
+func getAPIToken(db *sql.DB, where string, arg interface{}) (*APIToken, error) {
+	token, err := scanAPIToken(db.QueryRow(`SELECT `+apiTokenColumns+` WHERE `+where, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// GetAPITokenByID retrieves an API token by ID
+func GetAPITokenByID(db *sql.DB, id int64) (*APIToken, error) {
+	return getAPIToken(db, "id = ?", id)
+}
+
+// GetAPITokenByHash retrieves an API token by the hash of the token
+func GetAPITokenByHash(db *sql.DB, tokenHash string) (*APIToken, error) {
+	return getAPIToken(db, "token_hash = ?", tokenHash)
+}
+
+// GetAllAPITokens retrieves all API tokens, including revoked and expired
+// ones, newest first
+func GetAllAPITokens(db *sql.DB) ([]APIToken, error) {
+	rows, err := db.Query(`SELECT ` + apiTokenColumns + ` ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken marks a token as revoked. Revoking an already revoked token
+// keeps the original time. Returns sql.ErrNoRows if it does not exist.
+func RevokeAPIToken(db *sql.DB, id int64) error {
+	result, err := db.Exec(`UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`,
+		time.Now().UTC(), id)
+	if err != nil {
+		log.Printf("Failed to revoke API token %d: %v", id, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAPITokenUsed records when a token was last used
+func MarkAPITokenUsed(db *sql.DB, id int64, at time.Time) error {
+	if _, err := db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, at.UTC(), id); err != nil {
+		log.Printf("Failed to mark API token %d as used: %v", id, err)
+		return err
+	}
+	return nil
+}
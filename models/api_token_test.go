@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokenCreateAndRevoke(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	token := &APIToken{
+		Name:      "spreadsheet sync",
+		TokenHash: "hash-1",
+		Scopes:    []string{ScopeGuestsRead, ScopeGuestsWrite},
+		CreatedBy: "alice",
+		CreatedAt: now,
+	}
+	assert.NoError(t, token.Create(db))
+	assert.NotZero(t, token.ID)
+
+	expiring := &APIToken{
+		Name:      "check-in app",
+		TokenHash: "hash-2",
+		Scopes:    []string{ScopeStatsRead},
+		CreatedBy: "alice",
+		CreatedAt: now.Add(time.Minute),
+		ExpiresAt: sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+	}
+	assert.NoError(t, expiring.Create(db))
+
+	found, err := GetAPITokenByHash(db, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.Equal(t, []string{ScopeGuestsRead, ScopeGuestsWrite}, found.Scopes)
+	assert.True(t, found.HasScope(ScopeGuestsWrite))
+	assert.False(t, found.HasScope(ScopeStatsRead))
+	assert.True(t, found.IsActive(time.Now()))
+
+	found, err = GetAPITokenByID(db, expiring.ID)
+	assert.NoError(t, err)
+	assert.True(t, found.IsActive(now))
+	assert.False(t, found.IsActive(now.Add(2*time.Hour)))
+
+	missing, err := GetAPITokenByHash(db, "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.NoError(t, MarkAPITokenUsed(db, token.ID, now))
+	assert.NoError(t, RevokeAPIToken(db, token.ID))
+	found, err = GetAPITokenByID(db, token.ID)
+	assert.NoError(t, err)
+	assert.True(t, found.LastUsedAt.Valid)
+	assert.True(t, found.RevokedAt.Valid)
+	assert.False(t, found.IsActive(time.Now()))
+
+	all, err := GetAllAPITokens(db)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, expiring.ID, all[0].ID, "newest first")
+
+	assert.ErrorIs(t, RevokeAPIToken(db, 999), sql.ErrNoRows)
+}
+
+func TestAPITokenHashIsUnique(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	first := &APIToken{Name: "a", TokenHash: "same", Scopes: []string{ScopeStatsRead}, CreatedBy: "alice", CreatedAt: time.Now()}
+	second := &APIToken{Name: "b", TokenHash: "same", Scopes: []string{ScopeStatsRead}, CreatedBy: "alice", CreatedAt: time.Now()}
+	assert.NoError(t, first.Create(db))
+	assert.Error(t, second.Create(db))
+}
@@ -0,0 +1,352 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Audit actions recorded for admin mutations, and for the RSVPs guests
+// submit themselves
+const (
+	AuditActionGuestCreate         = "guest.create"
+	AuditActionGuestUpdate         = "guest.update"
+	AuditActionGuestRSVP           = "guest.rsvp"
+	AuditActionCommentApprove      = "comment.approve"
+	AuditActionCommentReject       = "comment.reject"
+	AuditActionAdminCreate         = "admin.create"
+	AuditActionAdminRoleChange     = "admin.role_change"
+	AuditActionAdminPasswordChange = "admin.password_change"
+	AuditActionAdminDelete         = "admin.delete"
+	AuditActionSessionRevoke       = "session.revoke"
+	AuditActionGuestSessionsRevoke = "guest.sessions_revoke"
+	AuditActionGuestForget         = "guest.forget"
+	AuditActionLoginUnlock         = "login.unlock"
+	AuditActionInviteLinkCreate    = "invite_link.create"
+	AuditActionInviteLinkRevoke    = "invite_link.revoke"
+	AuditActionAPITokenCreate      = "api_token.create"
+	AuditActionAPITokenRevoke      = "api_token.revoke"
+	AuditActionBackupDownload      = "backup.download"
+	AuditActionBackupRestore       = "backup.restore"
+)
+
+// Audit target types
+const (
+	AuditTargetGuest        = "guest"
+	AuditTargetComment      = "comment"
+	AuditTargetAdmin        = "admin"
+	AuditTargetSession      = "session"
+	AuditTargetLoginLockout = "login_lockout"
+	AuditTargetInviteLink   = "invite_link"
+	AuditTargetAPIToken     = "api_token"
+	AuditTargetBackup       = "backup"
+)
+
+// AuditActorGuest is the actor name of entries a guest caused, such as a
+// submitted RSVP; their actor ID is 0
+const AuditActorGuest = "guest"
+
+// AuditRedacted stands in for the value of a sensitive field in audit
+// changes, so the log shows that the field changed but not what it holds
+const AuditRedacted = "[redacted]"
+
+// SensitiveAuditFields lists by target type the fields whose values are
+// never stored in the audit log
+var SensitiveAuditFields = map[string][]string{
+	AuditTargetGuest: {"DietaryRestrictions"},
+}
+
+// AuditEntry records one admin change. Changes holds the fields that
+// changed as {"Field": {"before": ..., "after": ...}}. Actor details are
+// copied so entries survive the admin account being deleted.
+type AuditEntry struct {
+	ID         int64
+	ActorID    int64
+	ActorName  string
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    json.RawMessage
+	IPAddress  string
+	CreatedAt  time.Time
+}
+
+// AuditFilter narrows an audit log query; zero fields are ignored
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      time.Time
+	Until      time.Time
+}
+
+// PaginatedAuditEntries represents a page of audit entries, newest first
+type PaginatedAuditEntries struct {
+	Entries    []AuditEntry `json:"entries"`
+	TotalCount int          `json:"total_count"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// CreateAuditEntries stores entries in a single transaction, so a bulk
+// change is either fully recorded or not at all
+func CreateAuditEntries(db *sql.DB, entries []AuditEntry) error {
+	return CreateAuditEntriesContext(context.Background(), db, entries)
+}
+
+// CreateAuditEntriesContext is CreateAuditEntries on db, which may be a
+// transaction the entries then join
+func CreateAuditEntriesContext(ctx context.Context, db DBTX, entries []AuditEntry) error {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO audit_log
+		(actor_id, actor_name, action, target_type, target_id, changes, ip_address, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	now := time.Now().UTC()
+	for i := range entries {
+		if entries[i].CreatedAt.IsZero() {
+			entries[i].CreatedAt = now
+		}
+		changes := entries[i].Changes
+		if len(changes) == 0 {
+			changes = json.RawMessage("{}")
+		}
+
+		result, err := tx.ExecContext(ctx, stmt,
+			entries[i].ActorID,
+			entries[i].ActorName,
+			entries[i].Action,
+			entries[i].TargetType,
+			entries[i].TargetID,
+			string(changes),
+			entries[i].IPAddress,
+			entries[i].CreatedAt)
+		if err != nil {
+			log.Printf("Failed to create audit entry %s: %v", entries[i].Action, err)
+			return err
+		}
+
+		entries[i].ID, err = result.LastInsertId()
+		if err != nil {
+			log.Printf("Failed to get last insert ID: %v", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// auditWhere builds the WHERE clause shared by the audit count and page queries
+func auditWhere(filter AuditFilter) (string, []interface{}) {
+	where := " WHERE 1 = 1"
+	var args []interface{}
+	if filter.Actor != "" {
+		where += " AND actor_name = ? COLLATE NOCASE"
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		where += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		where += " AND target_type = ?"
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		where += " AND target_id = ?"
+		args = append(args, filter.TargetID)
+	}
+	if !filter.Since.IsZero() {
+		where += " AND created_at >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where += " AND created_at < ?"
+		args = append(args, filter.Until.UTC())
+	}
+	return where, args
+}
+
+// GetAuditEntries returns a page of audit entries matching filter, newest
+// first. The cursor is the ID of the last entry on the previous page.
+func GetAuditEntries(db *sql.DB, filter AuditFilter, limit int, cursor string) (*PaginatedAuditEntries, error) {
+	where, args := auditWhere(filter)
+
+	var totalCount int
+	row := db.QueryRow("SELECT COUNT(*) FROM audit_log"+where, args...)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, err
+	}
+
+	if cursor != "" {
+		cursorID, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		where += " AND id < ?"
+		args = append(args, cursorID)
+	}
+
+	query := `SELECT id, actor_id, actor_name, action, target_type, target_id, changes, ip_address, created_at
+		FROM audit_log` + where + ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit+1) // +1 to check for next page
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		var changes string
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.ActorName,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&changes,
+			&entry.IPAddress,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.Changes = json.RawMessage(changes)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(entries) == limit+1 {
+		entries = entries[:limit]
+		nextCursor = strconv.FormatInt(entries[limit-1].ID, 10)
+	}
+
+	return &PaginatedAuditEntries{
+		Entries:    entries,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// MaskAuditFields replaces the values of fields in the changes of every
+// audit entry about targetType with AuditRedacted, in one transaction, and
+// returns how many entries changed. It cleans up entries recorded before
+// the fields were masked.
+func MaskAuditFields(ctx context.Context, db DBTX, targetType string, fields []string) (int64, error) {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	redacted, err := json.Marshal(AuditRedacted)
+	if err != nil {
+		return 0, err
+	}
+	masked, err := rewriteAuditChanges(ctx, tx, `target_type = ?`, []interface{}{targetType}, func(changes map[string]json.RawMessage) (bool, error) {
+		found := false
+		for _, field := range fields {
+			raw, ok := changes[field]
+			if !ok {
+				continue
+			}
+			var change map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &change); err != nil {
+				return false, err
+			}
+			for side, value := range change {
+				if string(value) != "null" && string(value) != string(redacted) {
+					change[side] = redacted
+					found = true
+				}
+			}
+			if changes[field], err = json.Marshal(change); err != nil {
+				return false, err
+			}
+		}
+		return found, nil
+	})
+	if err != nil {
+		log.Printf("Failed to mask audit entries: %v", err)
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return 0, err
+	}
+	return masked, nil
+}
+
+// rewriteAuditChanges passes the changes of every audit entry matching
+// where to rewrite, stores those it reports as changed and returns how many
+// it stored
+func rewriteAuditChanges(ctx context.Context, tx *txn, where string, args []interface{}, rewrite func(changes map[string]json.RawMessage) (bool, error)) (int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, changes FROM audit_log WHERE `+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		var changes map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &changes); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		changed, err := rewrite(changes)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !changed {
+			continue
+		}
+		data, err := json.Marshal(changes)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		rewritten[id] = string(data)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, changes := range rewritten {
+		if _, err := tx.ExecContext(ctx, `UPDATE audit_log SET changes = ? WHERE id = ?`, changes, id); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(rewritten)), nil
+}
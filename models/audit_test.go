@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAndGetAuditEntries(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	base := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	entries := []AuditEntry{
+		{ActorID: 1, ActorName: "alice", Action: AuditActionGuestCreate, TargetType: AuditTargetGuest, TargetID: "1", CreatedAt: base},
+		{ActorID: 1, ActorName: "alice", Action: AuditActionGuestUpdate, TargetType: AuditTargetGuest, TargetID: "1",
+			Changes: json.RawMessage(`{"PlusOnes":{"before":0,"after":2}}`), IPAddress: "10.0.0.1", CreatedAt: base.Add(time.Hour)},
+		{ActorID: 2, ActorName: "bob", Action: AuditActionCommentReject, TargetType: AuditTargetComment, TargetID: "7", CreatedAt: base.Add(2 * time.Hour)},
+	}
+	assert.NoError(t, CreateAuditEntries(db, entries))
+	assert.NotZero(t, entries[2].ID)
+
+	page, err := GetAuditEntries(db, AuditFilter{}, 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, page.TotalCount)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, "bob", page.Entries[0].ActorName, "newest first")
+	assert.JSONEq(t, `{}`, string(page.Entries[2].Changes))
+	assert.JSONEq(t, `{"PlusOnes":{"before":0,"after":2}}`, string(page.Entries[1].Changes))
+	assert.Equal(t, "10.0.0.1", page.Entries[1].IPAddress)
+
+	page, err = GetAuditEntries(db, AuditFilter{Actor: "ALICE", TargetType: AuditTargetGuest, TargetID: "1"}, 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.TotalCount)
+
+	page, err = GetAuditEntries(db, AuditFilter{Action: AuditActionCommentReject}, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Equal(t, "7", page.Entries[0].TargetID)
+
+	page, err = GetAuditEntries(db, AuditFilter{Since: base.Add(30 * time.Minute), Until: base.Add(90 * time.Minute)}, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Equal(t, AuditActionGuestUpdate, page.Entries[0].Action)
+}
+
+func TestGetAuditEntries_Pagination(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	var entries []AuditEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, AuditEntry{ActorName: "alice", Action: AuditActionGuestCreate, TargetType: AuditTargetGuest, TargetID: "x"})
+	}
+	assert.NoError(t, CreateAuditEntries(db, entries))
+
+	page, err := GetAuditEntries(db, AuditFilter{}, 2, "")
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, 5, page.TotalCount)
+	assert.NotEmpty(t, page.NextCursor)
+
+	var seen []int64
+	cursor := ""
+	for {
+		page, err := GetAuditEntries(db, AuditFilter{}, 2, cursor)
+		assert.NoError(t, err)
+		for _, entry := range page.Entries {
+			seen = append(seen, entry.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assert.Equal(t, []int64{entries[4].ID, entries[3].ID, entries[2].ID, entries[1].ID, entries[0].ID}, seen)
+
+	_, err = GetAuditEntries(db, AuditFilter{}, 2, "not-a-number")
+	assert.Error(t, err)
+}
@@ -1,34 +1,62 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log"
 	"time"
 	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/media"
 )
 
 // ErrCommentLimitReached is a sentinel error for when a guest exceeds the comment limit
 var ErrCommentLimitReached = errors.New("maximum comment limit reached")
 
+// Comment moderation statuses. Only approved comments are shown publicly.
+const (
+	CommentStatusApproved = "approved"
+	CommentStatusPending  = "pending"
+	CommentStatusRejected = "rejected"
+)
+
 type Comment struct {
-	ID        int64
-	GuestID   int64
-	Content   string
-	CreatedAt time.Time
+	ID               int64
+	GuestID          int64
+	Content          string
+	Status           string
+	ModerationReason string
+	PhotoKey         string `json:"-"`
+	PhotoURL         string `json:",omitempty"`
+	ThumbnailURL     string `json:",omitempty"`
+	CreatedAt        time.Time
+}
+
+// SetPhotoURLs derives the public photo links from the stored photo key
+func (c *Comment) SetPhotoURLs() {
+	if c.PhotoKey == "" {
+		c.PhotoURL, c.ThumbnailURL = "", ""
+		return
+	}
+	c.PhotoURL = config.MediaURLPrefix + "/" + media.PhotoFileName(c.PhotoKey)
+	c.ThumbnailURL = config.MediaURLPrefix + "/" + media.ThumbnailFileName(c.PhotoKey)
 }
 
-func (c *Comment) Create(db *sql.DB) error {
-	tx, err := db.Begin()
+func (c *Comment) Create(ctx context.Context, db DBTX) error {
+	tx, err := beginTx(ctx, db)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
 		return err
 	}
 	defer tx.Rollback()
 
-	// Check if guest already has 2 comments
+	if c.Status == "" {
+		c.Status = CommentStatusApproved
+	}
+
+	// Check if guest already has 2 comments (rejected ones do not count)
 	var count int
-	row := tx.QueryRow("SELECT COUNT(*) FROM comments WHERE guest_id = ?", c.GuestID)
+	row := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments WHERE guest_id = ? AND status != ?", c.GuestID, CommentStatusRejected)
 	if err := row.Scan(&count); err != nil {
 		log.Printf("Failed to count comments: %v", err)
 		return err
@@ -39,39 +67,33 @@ func (c *Comment) Create(db *sql.DB) error {
 	}
 
 	stmt := `INSERT INTO comments
-		(guest_id, content)
-		VALUES (?, ?)`
+		(guest_id, content, status, moderation_reason, photo_key)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id, created_at`
 
-	result, err := tx.Exec(stmt,
-		c.GuestID,
-		c.Content)
+	err = tx.QueryRowContext(ctx, stmt, c.GuestID, c.Content, c.Status, c.ModerationReason, c.PhotoKey).Scan(&c.ID, &c.CreatedAt)
 	if err != nil {
 		log.Printf("Failed to create comment: %v", err)
 		return err
 	}
 
-	c.ID, err = result.LastInsertId()
-	if err != nil {
-		log.Printf("Failed to get last insert ID: %v", err)
-		return err
-	}
-
 	if err := tx.Commit(); err != nil {
 		log.Printf("Failed to commit transaction: %v", err)
 		return err
 	}
 
+	c.SetPhotoURLs()
 	log.Printf("Successfully created comment with ID %d", c.ID)
 	return nil
 }
 
-func GetCommentsByGuestID(db *sql.DB, guestID int64) ([]Comment, error) {
+func GetCommentsByGuestID(ctx context.Context, db DBTX, guestID int64) ([]Comment, error) {
 	stmt := `SELECT 
-		id, guest_id, content, created_at
+		id, guest_id, content, status, moderation_reason, photo_key, created_at
 		FROM comments WHERE guest_id = ?
 		ORDER BY created_at DESC`
 
-	rows, err := db.Query(stmt, guestID)
+	rows, err := db.QueryContext(ctx, stmt, guestID)
 	if err != nil {
 		return nil, err
 	}
@@ -84,11 +106,15 @@ func GetCommentsByGuestID(db *sql.DB, guestID int64) ([]Comment, error) {
 			&comment.ID,
 			&comment.GuestID,
 			&comment.Content,
+			&comment.Status,
+			&comment.ModerationReason,
+			&comment.PhotoKey,
 			&comment.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		comment.SetPhotoURLs()
 		comments = append(comments, comment)
 	}
 
@@ -112,40 +138,42 @@ type PaginatedComments struct {
 	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
-func GetCommentCountByGuestID(db *sql.DB, guestID int64) (int, error) {
+func GetCommentCountByGuestID(ctx context.Context, db DBTX, guestID int64) (int, error) {
 	var count int
-	row := db.QueryRow("SELECT COUNT(*) FROM comments WHERE guest_id = ?", guestID)
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments WHERE guest_id = ?", guestID)
 	err := row.Scan(&count)
 	return count, err
 }
 
-func GetAllCommentsWithGuests(db *sql.DB, limit int, cursor string) (*PaginatedComments, error) {
-	// First, get the total count of comments
-	totalCount, err := GetCommentCount(db)
+// GetAllCommentsWithGuests returns a page of approved comments, newest first
+func GetAllCommentsWithGuests(ctx context.Context, db DBTX, limit int, cursor string) (*PaginatedComments, error) {
+	// First, get the total count of approved comments
+	totalCount, err := GetCommentCountByStatus(ctx, db, CommentStatusApproved)
 	if err != nil {
 		return nil, err
 	}
 
 	query := `
 		SELECT
-			c.id, c.guest_id, c.content, c.created_at,
+			c.id, c.guest_id, c.content, c.status, c.moderation_reason, c.photo_key, c.created_at,
 			g.name as guest_name
 		FROM comments c
 		JOIN guests g ON c.guest_id = g.id
+		WHERE c.status = ?
 	`
-	var args []interface{}
+	args := []interface{}{CommentStatusApproved}
 	if cursor != "" {
 		cursorTime, err := time.Parse(time.RFC3339, cursor)
 		if err != nil {
 			return nil, err
 		}
-		query += " WHERE c.created_at < ?"
+		query += " AND c.created_at < ?"
 		args = append(args, cursorTime)
 	}
-	query += " ORDER BY c.created_at DESC LIMIT ?"
+	query += " ORDER BY c.created_at DESC, c.id DESC LIMIT ?"
 	args = append(args, limit+1) // +1 to check for next page
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -158,12 +186,16 @@ func GetAllCommentsWithGuests(db *sql.DB, limit int, cursor string) (*PaginatedC
 			&comment.ID,
 			&comment.GuestID,
 			&comment.Content,
+			&comment.Status,
+			&comment.ModerationReason,
+			&comment.PhotoKey,
 			&comment.CreatedAt,
 			&comment.GuestName,
 		)
 		if err != nil {
 			return nil, err
 		}
+		comment.SetPhotoURLs()
 		comments = append(comments, comment)
 	}
 
@@ -189,21 +221,29 @@ func GetAllCommentsWithGuests(db *sql.DB, limit int, cursor string) (*PaginatedC
 }
 
 // GetCommentCount returns the total number of comments.
-func GetCommentCount(db *sql.DB) (int, error) {
+func GetCommentCount(ctx context.Context, db DBTX) (int, error) {
 	var count int
-	row := db.QueryRow("SELECT COUNT(*) FROM comments")
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments")
+	err := row.Scan(&count)
+	return count, err
+}
+
+// GetCommentCountByStatus returns the number of comments with the given status.
+func GetCommentCountByStatus(ctx context.Context, db DBTX, status string) (int, error) {
+	var count int
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments WHERE status = ?", status)
 	err := row.Scan(&count)
 	return count, err
 }
 
 // GetAllComments retrieves all comments
-func GetAllComments(db *sql.DB) ([]Comment, error) {
+func GetAllComments(ctx context.Context, db DBTX) ([]Comment, error) {
 	stmt := `SELECT 
-		id, guest_id, content, created_at
+		id, guest_id, content, status, moderation_reason, photo_key, created_at
 		FROM comments
 		ORDER BY created_at DESC`
 
-	rows, err := db.Query(stmt)
+	rows, err := db.QueryContext(ctx, stmt)
 	if err != nil {
 		return nil, err
 	}
@@ -216,11 +256,15 @@ func GetAllComments(db *sql.DB) ([]Comment, error) {
 			&comment.ID,
 			&comment.GuestID,
 			&comment.Content,
+			&comment.Status,
+			&comment.ModerationReason,
+			&comment.PhotoKey,
 			&comment.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		comment.SetPhotoURLs()
 		comments = append(comments, comment)
 	}
 
@@ -231,3 +275,177 @@ func GetAllComments(db *sql.DB) ([]Comment, error) {
 
 	return comments, nil
 }
+
+// GetCommentWithGuestByID retrieves a single comment with its guest name
+func GetCommentWithGuestByID(ctx context.Context, db DBTX, id int64) (*CommentWithGuest, error) {
+	stmt := `SELECT
+		c.id, c.guest_id, c.content, c.status, c.moderation_reason, c.photo_key, c.created_at,
+		g.name as guest_name
+		FROM comments c
+		JOIN guests g ON c.guest_id = g.id
+		WHERE c.id = ?`
+
+	comment := &CommentWithGuest{}
+	err := db.QueryRowContext(ctx, stmt, id).Scan(
+		&comment.ID,
+		&comment.GuestID,
+		&comment.Content,
+		&comment.Status,
+		&comment.ModerationReason,
+		&comment.PhotoKey,
+		&comment.CreatedAt,
+		&comment.GuestName,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	comment.SetPhotoURLs()
+
+	return comment, nil
+}
+
+// GetCommentsWithGuestsByStatus retrieves all comments with the given status, oldest first
+func GetCommentsWithGuestsByStatus(ctx context.Context, db DBTX, status string) ([]CommentWithGuest, error) {
+	stmt := `SELECT
+		c.id, c.guest_id, c.content, c.status, c.moderation_reason, c.photo_key, c.created_at,
+		g.name as guest_name
+		FROM comments c
+		JOIN guests g ON c.guest_id = g.id
+		WHERE c.status = ?
+		ORDER BY c.created_at ASC`
+
+	rows, err := db.QueryContext(ctx, stmt, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []CommentWithGuest
+	for rows.Next() {
+		var comment CommentWithGuest
+		err := rows.Scan(
+			&comment.ID,
+			&comment.GuestID,
+			&comment.Content,
+			&comment.Status,
+			&comment.ModerationReason,
+			&comment.PhotoKey,
+			&comment.CreatedAt,
+			&comment.GuestName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comment.SetPhotoURLs()
+		comments = append(comments, comment)
+	}
+
+	// Check for iteration errors
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// UpdateCommentStatus changes the moderation status of a comment
+func UpdateCommentStatus(ctx context.Context, db DBTX, id int64, status, reason string) error {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `UPDATE comments SET status = ?, moderation_reason = ? WHERE id = ?`
+
+	res, err := tx.ExecContext(ctx, stmt, status, reason, id)
+	if err != nil {
+		log.Printf("Failed to update comment status: %v", err)
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Failed to get rows affected: %v", err)
+		return err
+	}
+	if rows == 0 {
+		log.Printf("No rows affected - comment not found")
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Set status of comment %d to %s", id, status)
+	return nil
+}
+
+// HasDuplicateComment reports whether the guest already posted the same
+// content, ignoring case and surrounding whitespace
+func HasDuplicateComment(ctx context.Context, db DBTX, guestID int64, content string) (bool, error) {
+	var count int
+	row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments
+		WHERE guest_id = ? AND status != ? AND lower(trim(content)) = lower(trim(?))`,
+		guestID, CommentStatusRejected, content)
+	if err := row.Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetCommentPhotoStatus returns the status of the comment a photo belongs
+// to, or "" if no comment has it
+func GetCommentPhotoStatus(ctx context.Context, db DBTX, key string) (string, error) {
+	var status string
+	err := db.QueryRowContext(ctx, `SELECT status FROM comments WHERE photo_key = ? LIMIT 1`, key).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
+
+// ClearCommentPhotos detaches the photos from every comment of a guest and
+// returns their keys, so the files can be deleted
+func ClearCommentPhotos(ctx context.Context, db DBTX, guestID int64) ([]string, error) {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT photo_key FROM comments WHERE guest_id = ? AND photo_key != ''`, guestID)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE comments SET photo_key = '' WHERE guest_id = ?`, guestID); err != nil {
+		log.Printf("Failed to clear comment photos: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+	return keys, nil
+}
@@ -0,0 +1,369 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"wedding-invitation-backend/config"
+)
+
+// Postgres versions of the comment queries, used when DB_DRIVER=postgres.
+// Search uses a GIN-indexed tsvector instead of the SQLite FTS5 table.
+
+const postgresCommentColumns = `id, guest_id, content, status, moderation_reason, photo_key, created_at
+	FROM comments`
+
+const postgresCommentWithGuestColumns = `c.id, c.guest_id, c.content, c.status, c.moderation_reason, c.photo_key, c.created_at,
+	g.name as guest_name
+	FROM comments c
+	JOIN guests g ON c.guest_id = g.id`
+
+func scanComment(scanner interface{ Scan(...interface{}) error }) (*Comment, error) {
+	comment := &Comment{}
+	err := scanner.Scan(
+		&comment.ID,
+		&comment.GuestID,
+		&comment.Content,
+		&comment.Status,
+		&comment.ModerationReason,
+		&comment.PhotoKey,
+		&comment.CreatedAt,
+	)
+	comment.SetPhotoURLs()
+	return comment, err
+}
+
+func scanCommentWithGuest(scanner interface{ Scan(...interface{}) error }, extra ...interface{}) (*CommentWithGuest, error) {
+	comment := &CommentWithGuest{}
+	dest := []interface{}{
+		&comment.ID,
+		&comment.GuestID,
+		&comment.Content,
+		&comment.Status,
+		&comment.ModerationReason,
+		&comment.PhotoKey,
+		&comment.CreatedAt,
+		&comment.GuestName,
+	}
+	err := scanner.Scan(append(dest, extra...)...)
+	comment.SetPhotoURLs()
+	return comment, err
+}
+
+// PostgresCreateComment inserts a comment, enforcing the per-guest limit.
+// The guest row is locked so concurrent posts cannot both pass the check.
+func PostgresCreateComment(ctx context.Context, db DBTX, c *Comment) error {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if c.Status == "" {
+		c.Status = CommentStatusApproved
+	}
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM guests WHERE id = $1 FOR UPDATE`, c.GuestID); err != nil {
+		log.Printf("Failed to lock guest %d: %v", c.GuestID, err)
+		return err
+	}
+
+	// Rejected comments do not count towards the limit
+	var count int
+	row := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments WHERE guest_id = $1 AND status != $2", c.GuestID, CommentStatusRejected)
+	if err := row.Scan(&count); err != nil {
+		log.Printf("Failed to count comments: %v", err)
+		return err
+	}
+
+	if count >= config.MaxCommentsPerGuest {
+		return ErrCommentLimitReached
+	}
+
+	stmt := `INSERT INTO comments
+		(guest_id, content, status, moderation_reason, photo_key)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, stmt, c.GuestID, c.Content, c.Status, c.ModerationReason, c.PhotoKey).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to create comment: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	c.SetPhotoURLs()
+	log.Printf("Successfully created comment with ID %d", c.ID)
+	return nil
+}
+
+func postgresQueryComments(ctx context.Context, db DBTX, stmt string, args ...interface{}) ([]Comment, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	return comments, rows.Err()
+}
+
+func postgresQueryCommentsWithGuests(ctx context.Context, db DBTX, stmt string, args ...interface{}) ([]CommentWithGuest, error) {
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []CommentWithGuest
+	for rows.Next() {
+		comment, err := scanCommentWithGuest(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	return comments, rows.Err()
+}
+
+// PostgresGetCommentsByGuestID retrieves a guest's comments, newest first
+func PostgresGetCommentsByGuestID(ctx context.Context, db DBTX, guestID int64) ([]Comment, error) {
+	return postgresQueryComments(ctx, db, `SELECT `+postgresCommentColumns+` WHERE guest_id = $1 ORDER BY created_at DESC`, guestID)
+}
+
+// PostgresGetAllComments retrieves all comments, newest first
+func PostgresGetAllComments(ctx context.Context, db DBTX) ([]Comment, error) {
+	return postgresQueryComments(ctx, db, `SELECT `+postgresCommentColumns+` ORDER BY created_at DESC`)
+}
+
+// PostgresGetAllCommentsWithGuests returns a page of approved comments,
+// newest first
+func PostgresGetAllCommentsWithGuests(ctx context.Context, db DBTX, limit int, cursor string) (*PaginatedComments, error) {
+	var totalCount int
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments WHERE status = $1", CommentStatusApproved)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + postgresCommentWithGuestColumns + ` WHERE c.status = $1`
+	args := []interface{}{CommentStatusApproved}
+	if cursor != "" {
+		cursorTime, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursorTime)
+		query += " AND c.created_at <= $2"
+	}
+	args = append(args, limit+1) // +1 to check for next page
+	query += " ORDER BY c.created_at DESC, c.id DESC LIMIT " + placeholder(len(args))
+
+	comments, err := postgresQueryCommentsWithGuests(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(comments) == limit+1 {
+		// The cursor is the first comment of the next page, like on SQLite
+		// where created_at is compared as text. Postgres keeps sub-second
+		// times, so the cursor must too.
+		nextCursor = comments[limit].CreatedAt.Format(time.RFC3339Nano)
+		comments = comments[:limit]
+	}
+
+	return &PaginatedComments{
+		Comments:   comments,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// PostgresSearchComments runs a full-text search over comment content,
+// newest first, with the same matching rules as SearchComments
+func PostgresSearchComments(ctx context.Context, db DBTX, query string, limit int, cursor string) (*PaginatedSearchResults, error) {
+	tsQuery := buildTSQuery(query)
+	if tsQuery == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	var totalCount int
+	row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments
+		WHERE to_tsvector('simple', content) @@ to_tsquery('simple', $1)`, tsQuery)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, err
+	}
+
+	stmt := `SELECT ` + postgresCommentWithGuestColumns + `,
+		ts_headline('simple', c.content, to_tsquery('simple', $1),
+			'StartSel=' || $2::text || ', StopSel=' || $3::text || ', MaxWords=16, MinWords=8, MaxFragments=1') as snippet
+		WHERE to_tsvector('simple', c.content) @@ to_tsquery('simple', $1)`
+	args := []interface{}{tsQuery, snippetMarkStart, snippetMarkEnd}
+	if cursor != "" {
+		cursorTime, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursorTime)
+		stmt += " AND c.created_at <= $4"
+	}
+	args = append(args, limit+1) // +1 to check for next page
+	stmt += " ORDER BY c.created_at DESC LIMIT " + placeholder(len(args))
+
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []CommentSearchResult
+	for rows.Next() {
+		var result CommentSearchResult
+		comment, err := scanCommentWithGuest(rows, &result.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		result.CommentWithGuest = *comment
+		result.Snippet = highlightSnippet(result.Snippet)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(results) == limit+1 {
+		nextCursor = results[limit].CreatedAt.Format(time.RFC3339Nano)
+		results = results[:limit]
+	}
+
+	return &PaginatedSearchResults{
+		Results:    results,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// buildTSQuery turns free text into a tsquery of prefix terms that must
+// all match. Only letters and digits are kept, so user input can never be
+// interpreted as tsquery syntax.
+func buildTSQuery(query string) string {
+	words := SearchTerms(query)
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// PostgresGetCommentWithGuestByID retrieves a single comment with its guest
+// name. Returns nil if there is no such comment.
+func PostgresGetCommentWithGuestByID(ctx context.Context, db DBTX, id int64) (*CommentWithGuest, error) {
+	comment, err := scanCommentWithGuest(db.QueryRowContext(ctx, `SELECT `+postgresCommentWithGuestColumns+` WHERE c.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// PostgresGetCommentsWithGuestsByStatus retrieves all comments with the
+// given status, oldest first
+func PostgresGetCommentsWithGuestsByStatus(ctx context.Context, db DBTX, status string) ([]CommentWithGuest, error) {
+	return postgresQueryCommentsWithGuests(ctx, db, `SELECT `+postgresCommentWithGuestColumns+`
+		WHERE c.status = $1
+		ORDER BY c.created_at ASC`, status)
+}
+
+// PostgresUpdateCommentStatus changes the moderation status of a comment.
+// Returns sql.ErrNoRows if the comment does not exist.
+func PostgresUpdateCommentStatus(ctx context.Context, db DBTX, id int64, status, reason string) error {
+	res, err := db.ExecContext(ctx, `UPDATE comments SET status = $1, moderation_reason = $2 WHERE id = $3`, status, reason, id)
+	if err != nil {
+		log.Printf("Failed to update comment status: %v", err)
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		log.Printf("No rows affected - comment not found")
+		return sql.ErrNoRows
+	}
+
+	log.Printf("Set status of comment %d to %s", id, status)
+	return nil
+}
+
+// PostgresHasDuplicateComment reports whether the guest already posted the
+// same content, ignoring case and surrounding whitespace
+func PostgresHasDuplicateComment(ctx context.Context, db DBTX, guestID int64, content string) (bool, error) {
+	var exists bool
+	row := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM comments
+		WHERE guest_id = $1 AND status != $2 AND lower(trim(content)) = lower(trim($3::text)))`,
+		guestID, CommentStatusRejected, content)
+	if err := row.Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// PostgresGetCommentPhotoStatus returns the status of the comment a photo
+// belongs to, or "" if no comment has it
+func PostgresGetCommentPhotoStatus(ctx context.Context, db DBTX, key string) (string, error) {
+	var status string
+	err := db.QueryRowContext(ctx, `SELECT status FROM comments WHERE photo_key = $1 LIMIT 1`, key).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
+
+// PostgresClearCommentPhotos detaches the photos from every comment of a
+// guest and returns their keys, so the files can be deleted
+func PostgresClearCommentPhotos(ctx context.Context, db DBTX, guestID int64) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `UPDATE comments c SET photo_key = ''
+		FROM (SELECT id, photo_key FROM comments WHERE guest_id = $1 AND photo_key <> '' FOR UPDATE) old
+		WHERE c.id = old.id
+		RETURNING old.photo_key`, guestID)
+	if err != nil {
+		log.Printf("Failed to clear comment photos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// placeholder returns the Postgres placeholder for the nth argument
+func placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTSQuery(t *testing.T) {
+	assert.Equal(t, "see:* & bali:*", buildTSQuery("see bali"))
+	// Operators and quotes never reach the tsquery
+	assert.Equal(t, "a:* & b:*", buildTSQuery("a & !b:*'"))
+	assert.Equal(t, "", buildTSQuery("!! --"))
+}
@@ -0,0 +1,241 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrEmptySearchQuery is returned when a search query has no searchable terms
+var ErrEmptySearchQuery = errors.New("search query is empty")
+
+// Snippet highlight markers. Control characters are used in SQL so the
+// comment text can be HTML-escaped before the real <mark> tags are inserted.
+const (
+	snippetMarkStart = "\x02"
+	snippetMarkEnd   = "\x03"
+)
+
+// CommentSearchResult is a comment matching a search query
+type CommentSearchResult struct {
+	CommentWithGuest
+	Snippet string
+}
+
+// PaginatedSearchResults represents a paginated list of comment search results.
+type PaginatedSearchResults struct {
+	Results    []CommentSearchResult `json:"results"`
+	TotalCount int                   `json:"total_count"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// SearchComments runs a full-text search over comment content, newest first.
+// Every word in the query must match; the last letters of each word may be
+// omitted ("bal" matches "Bali").
+func SearchComments(ctx context.Context, db DBTX, query string, limit int, cursor string) (*PaginatedSearchResults, error) {
+	match := buildMatchQuery(query)
+	if match == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	var totalCount int
+	row := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM comments_fts WHERE comments_fts MATCH ?", match)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, err
+	}
+
+	stmt := `
+		SELECT
+			c.id, c.guest_id, c.content, c.status, c.moderation_reason, c.photo_key, c.created_at,
+			g.name as guest_name,
+			snippet(comments_fts, 0, ?, ?, '…', 16) as snippet
+		FROM comments_fts
+		JOIN comments c ON c.id = comments_fts.rowid
+		JOIN guests g ON c.guest_id = g.id
+		WHERE comments_fts MATCH ?
+	`
+	args := []interface{}{snippetMarkStart, snippetMarkEnd, match}
+	if cursor != "" {
+		cursorTime, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return nil, err
+		}
+		stmt += " AND c.created_at < ?"
+		args = append(args, cursorTime)
+	}
+	stmt += " ORDER BY c.created_at DESC LIMIT ?"
+	args = append(args, limit+1) // +1 to check for next page
+
+	rows, err := db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []CommentSearchResult
+	for rows.Next() {
+		var result CommentSearchResult
+		err := rows.Scan(
+			&result.ID,
+			&result.GuestID,
+			&result.Content,
+			&result.Status,
+			&result.ModerationReason,
+			&result.PhotoKey,
+			&result.CreatedAt,
+			&result.GuestName,
+			&result.Snippet,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.SetPhotoURLs()
+		result.Snippet = highlightSnippet(result.Snippet)
+		results = append(results, result)
+	}
+
+	// Check for iteration errors
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	nextCursor := ""
+	if len(results) == limit+1 {
+		// Has next page: set nextCursor and remove extra item
+		last := results[len(results)-1]
+		nextCursor = last.CreatedAt.Format(time.RFC3339)
+		results = results[:len(results)-1]
+	}
+
+	return &PaginatedSearchResults{
+		Results:    results,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// SearchTerms splits free text into the words a search matches on. Only
+// letters and digits are kept.
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(query, isNotWordRune)
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// buildMatchQuery turns free text into an FTS5 query of quoted prefix terms,
+// so user input can never be interpreted as FTS5 syntax
+func buildMatchQuery(query string) string {
+	words := SearchTerms(query)
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// MatchesSearch reports whether every term is the start of a word in
+// content, ignoring case, as the database searches do
+func MatchesSearch(content string, terms []string) bool {
+	words := SearchTerms(content)
+	for _, term := range terms {
+		found := false
+		for _, word := range words {
+			if matchesAnyTerm(word, []string{term}) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// HighlightMatches builds a search snippet without the database: up to 16
+// words of content starting near the first match, with matching words
+// marked like the snippets of SearchComments
+func HighlightMatches(content string, terms []string) string {
+	const snippetWords = 16
+
+	fields := strings.Fields(content)
+	first := -1
+	for i, field := range fields {
+		marked := markField(field, terms)
+		if marked != field && first < 0 {
+			first = i
+		}
+		fields[i] = marked
+	}
+
+	start := 0
+	if first > snippetWords/4 {
+		start = first - snippetWords/4
+	}
+	end := start + snippetWords
+	if end > len(fields) {
+		end = len(fields)
+	}
+
+	snippet := strings.Join(fields[start:end], " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(fields) {
+		snippet += "…"
+	}
+	return highlightSnippet(snippet)
+}
+
+func matchesAnyTerm(word string, terms []string) bool {
+	word = strings.ToLower(word)
+	for _, term := range terms {
+		if strings.HasPrefix(word, strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
+
+// markField wraps the words of a whitespace-separated field that start with
+// a search term in the highlight markers
+func markField(field string, terms []string) string {
+	var b strings.Builder
+	rest := field
+	for rest != "" {
+		start := strings.IndexFunc(rest, func(r rune) bool { return !isNotWordRune(r) })
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		b.WriteString(rest[:start])
+		rest = rest[start:]
+
+		end := strings.IndexFunc(rest, isNotWordRune)
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		if matchesAnyTerm(word, terms) {
+			b.WriteString(snippetMarkStart + word + snippetMarkEnd)
+		} else {
+			b.WriteString(word)
+		}
+		rest = rest[end:]
+	}
+	return b.String()
+}
+
+// highlightSnippet escapes the snippet for HTML and swaps the highlight
+// markers for <mark> tags
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetMarkStart, "<mark>")
+	return strings.ReplaceAll(escaped, snippetMarkEnd, "</mark>")
+}
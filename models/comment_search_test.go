@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchComments(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	g := &Guest{Name: "Traveller"}
+	assert.NoError(t, g.Create(context.Background(), db))
+
+	c1 := &Comment{GuestID: g.ID, Content: "See you in Bali for the honeymoon!"}
+	assert.NoError(t, c1.Create(context.Background(), db))
+	c2 := &Comment{GuestID: g.ID, Content: "Congratulations to both of you"}
+	assert.NoError(t, c2.Create(context.Background(), db))
+
+	results, err := SearchComments(context.Background(), db, "bali", 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, results.TotalCount)
+	assert.Len(t, results.Results, 1)
+	assert.Equal(t, c1.ID, results.Results[0].ID)
+	assert.Equal(t, "Traveller", results.Results[0].GuestName)
+	assert.Contains(t, results.Results[0].Snippet, "<mark>Bali</mark>")
+}
+
+func TestSearchComments_PrefixAndDiacritics(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	g := &Guest{Name: "Guest"}
+	assert.NoError(t, g.Create(context.Background(), db))
+	c := &Comment{GuestID: g.ID, Content: "Selamat menempuh hidup baru, café date soon"}
+	assert.NoError(t, c.Create(context.Background(), db))
+
+	results, err := SearchComments(context.Background(), db, "selam cafe", 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, results.Results, 1)
+}
+
+func TestSearchComments_EscapesSnippet(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	g := &Guest{Name: "Guest"}
+	assert.NoError(t, g.Create(context.Background(), db))
+	c := &Comment{GuestID: g.ID, Content: "<script>alert(1)</script> Bali"}
+	assert.NoError(t, c.Create(context.Background(), db))
+
+	results, err := SearchComments(context.Background(), db, "bali", 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, results.Results, 1)
+	assert.NotContains(t, results.Results[0].Snippet, "<script>")
+	assert.Contains(t, results.Results[0].Snippet, "&lt;script&gt;")
+}
+
+func TestSearchComments_QuerySyntaxIsIgnored(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	_, err := SearchComments(context.Background(), db, `bali" OR NEAR(`, 10, "")
+	assert.NoError(t, err)
+
+	_, err = SearchComments(context.Background(), db, `"*()`, 10, "")
+	assert.ErrorIs(t, err, ErrEmptySearchQuery)
+}
+
+func TestSearchComments_IndexFollowsDeletes(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	g := &Guest{Name: "Guest"}
+	assert.NoError(t, g.Create(context.Background(), db))
+	c := &Comment{GuestID: g.ID, Content: "Bali is lovely"}
+	assert.NoError(t, c.Create(context.Background(), db))
+
+	_, err := db.Exec("DELETE FROM comments WHERE id = ?", c.ID)
+	assert.NoError(t, err)
+
+	results, err := SearchComments(context.Background(), db, "bali", 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, results.TotalCount)
+	assert.Empty(t, results.Results)
+}
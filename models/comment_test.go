@@ -1,6 +1,8 @@
 package models
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -14,7 +16,7 @@ func TestCommentCreate(t *testing.T) {
 
 	// Create guest
 	g := &Guest{Name: "Commenter"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Create comment
@@ -22,7 +24,7 @@ func TestCommentCreate(t *testing.T) {
 		GuestID: g.ID,
 		Content: "Great event!",
 	}
-	err = comment.Create(db)
+	err = comment.Create(context.Background(), db)
 	assert.NoError(t, err)
 	assert.NotZero(t, comment.ID)
 }
@@ -33,18 +35,18 @@ func TestGetCommentsByGuestID(t *testing.T) {
 
 	// Create guest and comments
 	g := &Guest{Name: "Guest"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	c1 := &Comment{GuestID: g.ID, Content: "First"}
-	err = c1.Create(db)
+	err = c1.Create(context.Background(), db)
 	assert.NoError(t, err)
 	c2 := &Comment{GuestID: g.ID, Content: "Second"}
-	err = c2.Create(db)
+	err = c2.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Test retrieval
-	comments, err := GetCommentsByGuestID(db, g.ID)
+	comments, err := GetCommentsByGuestID(context.Background(), db, g.ID)
 	assert.NoError(t, err)
 	assert.Len(t, comments, 2)
 }
@@ -54,19 +56,19 @@ func TestGetCommentCountByGuestID(t *testing.T) {
 	defer db.Close()
 
 	g := &Guest{Name: "Counter"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
-	count, err := GetCommentCountByGuestID(db, g.ID)
+	count, err := GetCommentCountByGuestID(context.Background(), db, g.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
 
 	// Add comment
 	comment := &Comment{GuestID: g.ID, Content: "Test"}
-	err = comment.Create(db)
+	err = comment.Create(context.Background(), db)
 	assert.NoError(t, err)
 
-	count, err = GetCommentCountByGuestID(db, g.ID)
+	count, err = GetCommentCountByGuestID(context.Background(), db, g.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, count)
 }
@@ -77,45 +79,46 @@ func TestGetAllCommentsWithGuests(t *testing.T) {
 
 	// Create guests and comments
 	g1 := &Guest{Name: "Author1"}
-	err := g1.Create(db)
+	err := g1.Create(context.Background(), db)
 	assert.NoError(t, err)
 	g2 := &Guest{Name: "Author2"}
-	err = g2.Create(db)
+	err = g2.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	c1 := &Comment{GuestID: g1.ID, Content: "C1"}
-	err = c1.Create(db)
+	err = c1.Create(context.Background(), db)
 	assert.NoError(t, err)
 	time.Sleep(10 * time.Millisecond) // Ensure different timestamps
 	c2 := &Comment{GuestID: g2.ID, Content: "C2"}
-	err = c2.Create(db)
+	err = c2.Create(context.Background(), db)
 	assert.NoError(t, err)
 
-	// Test retrieval
-	paginated, err := GetAllCommentsWithGuests(db, 2, "")
+	// Test retrieval, newest first; comments in the same second are
+	// ordered by ID
+	paginated, err := GetAllCommentsWithGuests(context.Background(), db, 2, "")
 	assert.NoError(t, err)
 	assert.Len(t, paginated.Comments, 2)
-	assert.Equal(t, "Author1", paginated.Comments[0].GuestName)
-	assert.Equal(t, "Author2", paginated.Comments[1].GuestName)
+	assert.Equal(t, "Author2", paginated.Comments[0].GuestName)
+	assert.Equal(t, "Author1", paginated.Comments[1].GuestName)
 }
 
 func TestGetCommentCount(t *testing.T) {
 	db := setupDB(t)
 	defer db.Close()
 
-	count, err := GetCommentCount(db)
+	count, err := GetCommentCount(context.Background(), db)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
 
 	// Add comment
 	g := &Guest{Name: "CommentGuest"}
-	err = g.Create(db)
+	err = g.Create(context.Background(), db)
 	assert.NoError(t, err)
 	c := &Comment{GuestID: g.ID, Content: "Testing"}
-	err = c.Create(db)
+	err = c.Create(context.Background(), db)
 	assert.NoError(t, err)
 
-	count, err = GetCommentCount(db)
+	count, err = GetCommentCount(context.Background(), db)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, count)
 }
@@ -126,27 +129,27 @@ func TestCommentMaximumLimit(t *testing.T) {
 
 	// Create guest
 	g := &Guest{Name: "LimitTester"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Create first comment - should succeed
 	c1 := &Comment{GuestID: g.ID, Content: "First comment"}
-	err = c1.Create(db)
+	err = c1.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Create second comment - should succeed
 	c2 := &Comment{GuestID: g.ID, Content: "Second comment"}
-	err = c2.Create(db)
+	err = c2.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Create third comment - should fail with maximum limit error
 	c3 := &Comment{GuestID: g.ID, Content: "Third comment"}
-	err = c3.Create(db)
+	err = c3.Create(context.Background(), db)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "maximum comment limit reached")
 
 	// Verify only 2 comments exist for this guest
-	count, err := GetCommentCountByGuestID(db, g.ID)
+	count, err := GetCommentCountByGuestID(context.Background(), db, g.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, count)
 }
@@ -160,7 +163,7 @@ func TestCommentCreate_InvalidGuestID(t *testing.T) {
 		GuestID: 99999,
 		Content: "Orphan comment",
 	}
-	err := comment.Create(db)
+	err := comment.Create(context.Background(), db)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "FOREIGN KEY constraint")
 }
@@ -171,15 +174,15 @@ func TestGetAllCommentsWithGuests_EmptyCursor(t *testing.T) {
 
 	// Create guest and comment
 	g := &Guest{Name: "CursorTester"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	c := &Comment{GuestID: g.ID, Content: "Test comment"}
-	err = c.Create(db)
+	err = c.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Test with empty cursor (first page)
-	paginated, err := GetAllCommentsWithGuests(db, 10, "")
+	paginated, err := GetAllCommentsWithGuests(context.Background(), db, 10, "")
 	assert.NoError(t, err)
 	assert.Len(t, paginated.Comments, 1)
 	assert.Equal(t, 1, paginated.TotalCount)
@@ -192,16 +195,16 @@ func TestGetAllCommentsWithGuests_LargeCursor(t *testing.T) {
 
 	// Create guest and comment
 	g := &Guest{Name: "LargeCursorTester"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	c := &Comment{GuestID: g.ID, Content: "Test comment"}
-	err = c.Create(db)
+	err = c.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Use a far future cursor (all comments are before this)
 	farFuture := "2999-12-31T23:59:59Z"
-	paginated, err := GetAllCommentsWithGuests(db, 10, farFuture)
+	paginated, err := GetAllCommentsWithGuests(context.Background(), db, 10, farFuture)
 	assert.NoError(t, err)
 	assert.Len(t, paginated.Comments, 1)
 	assert.Equal(t, 1, paginated.TotalCount)
@@ -212,7 +215,7 @@ func TestGetAllComments_EmptyTable(t *testing.T) {
 	t.Cleanup(func() { db.Close() })
 
 	// Test on empty comments table
-	comments, err := GetAllComments(db)
+	comments, err := GetAllComments(context.Background(), db)
 	assert.NoError(t, err)
 	assert.Len(t, comments, 0)
 }
@@ -222,7 +225,106 @@ func TestGetCommentCountByGuestID_NonExistent(t *testing.T) {
 	t.Cleanup(func() { db.Close() })
 
 	// Test with non-existent guest ID
-	count, err := GetCommentCountByGuestID(db, 99999)
+	count, err := GetCommentCountByGuestID(context.Background(), db, 99999)
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
 }
+
+func TestCommentModerationStatus(t *testing.T) {
+	db := setupDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	g := &Guest{Name: "Moderated"}
+	assert.NoError(t, g.Create(context.Background(), db))
+
+	approved := &Comment{GuestID: g.ID, Content: "Visible"}
+	assert.NoError(t, approved.Create(context.Background(), db))
+	assert.Equal(t, CommentStatusApproved, approved.Status)
+
+	pending := &Comment{GuestID: g.ID, Content: "www.example.com", Status: CommentStatusPending, ModerationReason: "contains links"}
+	assert.NoError(t, pending.Create(context.Background(), db))
+
+	// Only approved comments are listed publicly
+	paginated, err := GetAllCommentsWithGuests(context.Background(), db, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, paginated.Comments, 1)
+	assert.Equal(t, 1, paginated.TotalCount)
+
+	queue, err := GetCommentsWithGuestsByStatus(context.Background(), db, CommentStatusPending)
+	assert.NoError(t, err)
+	assert.Len(t, queue, 1)
+	assert.Equal(t, "contains links", queue[0].ModerationReason)
+
+	assert.NoError(t, UpdateCommentStatus(context.Background(), db, pending.ID, CommentStatusApproved, ""))
+	comment, err := GetCommentWithGuestByID(context.Background(), db, pending.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, CommentStatusApproved, comment.Status)
+	assert.Equal(t, "Moderated", comment.GuestName)
+
+	assert.ErrorIs(t, UpdateCommentStatus(context.Background(), db, 99999, CommentStatusApproved, ""), sql.ErrNoRows)
+}
+
+func TestCommentLimit_IgnoresRejected(t *testing.T) {
+	db := setupDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	g := &Guest{Name: "Retry"}
+	assert.NoError(t, g.Create(context.Background(), db))
+
+	c1 := &Comment{GuestID: g.ID, Content: "First"}
+	assert.NoError(t, c1.Create(context.Background(), db))
+	c2 := &Comment{GuestID: g.ID, Content: "Second"}
+	assert.NoError(t, c2.Create(context.Background(), db))
+	assert.NoError(t, UpdateCommentStatus(context.Background(), db, c2.ID, CommentStatusRejected, "spam"))
+
+	c3 := &Comment{GuestID: g.ID, Content: "Third"}
+	assert.NoError(t, c3.Create(context.Background(), db))
+}
+
+func TestHasDuplicateComment(t *testing.T) {
+	db := setupDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	g := &Guest{Name: "Repeater"}
+	assert.NoError(t, g.Create(context.Background(), db))
+	c := &Comment{GuestID: g.ID, Content: "Congrats!"}
+	assert.NoError(t, c.Create(context.Background(), db))
+
+	duplicate, err := HasDuplicateComment(context.Background(), db, g.ID, "  CONGRATS!  ")
+	assert.NoError(t, err)
+	assert.True(t, duplicate)
+
+	duplicate, err = HasDuplicateComment(context.Background(), db, g.ID+1, "Congrats!")
+	assert.NoError(t, err)
+	assert.False(t, duplicate)
+}
+
+func TestCommentPhotoURLs(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	g := &Guest{Name: "Photographer"}
+	err := g.Create(context.Background(), db)
+	assert.NoError(t, err)
+
+	key := "0123456789abcdef0123456789abcdef"
+	withPhoto := &Comment{GuestID: g.ID, Content: "Selfie", PhotoKey: key}
+	err = withPhoto.Create(context.Background(), db)
+	assert.NoError(t, err)
+	assert.Equal(t, "/media/"+key+".jpg", withPhoto.PhotoURL)
+
+	textOnly := &Comment{GuestID: g.ID, Content: "Just words"}
+	err = textOnly.Create(context.Background(), db)
+	assert.NoError(t, err)
+
+	fetched, err := GetCommentWithGuestByID(context.Background(), db, withPhoto.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, key, fetched.PhotoKey)
+	assert.Equal(t, "/media/"+key+".jpg", fetched.PhotoURL)
+	assert.Equal(t, "/media/"+key+"_thumb.jpg", fetched.ThumbnailURL)
+
+	fetched, err = GetCommentWithGuestByID(context.Background(), db, textOnly.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, fetched.PhotoURL)
+	assert.Empty(t, fetched.ThumbnailURL)
+}
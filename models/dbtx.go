@@ -0,0 +1,53 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is what the guest and comment functions run their queries on: a
+// *sql.DB, or a *sql.Tx to make them part of a caller's transaction
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txn is a transaction begun by a model function, or the caller's
+// transaction it joined. A joined transaction is committed or rolled back
+// by the caller, so Commit and Rollback leave it alone.
+type txn struct {
+	*sql.Tx
+	joined bool
+}
+
+func (t *txn) Commit() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t *txn) Rollback() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// beginTx begins a transaction on db, or joins db if it already is one
+func beginTx(ctx context.Context, db DBTX) (*txn, error) {
+	switch db := db.(type) {
+	case *sql.Tx:
+		return &txn{Tx: db, joined: true}, nil
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{Tx: tx}, nil
+	default:
+		return nil, fmt.Errorf("cannot begin a transaction on %T", db)
+	}
+}
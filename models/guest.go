@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"time"
@@ -17,8 +18,8 @@ type Guest struct {
 	FirstOpenedAt       sql.NullTime
 }
 
-func (g *Guest) Create(db *sql.DB) error {
-	tx, err := db.Begin()
+func (g *Guest) Create(ctx context.Context, db DBTX) error {
+	tx, err := beginTx(ctx, db)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
 		return err
@@ -29,7 +30,7 @@ func (g *Guest) Create(db *sql.DB) error {
 		(name, attending, plus_ones, dietary_restrictions)
 		VALUES (?, ?, ?, ?)`
 
-	result, err := tx.Exec(stmt,
+	result, err := tx.ExecContext(ctx, stmt,
 		g.Name,
 		g.Attending,
 		g.PlusOnes,
@@ -55,14 +56,14 @@ func (g *Guest) Create(db *sql.DB) error {
 	return nil
 }
 
-func GetGuestByName(db *sql.DB, name string) (*Guest, error) {
+func GetGuestByName(ctx context.Context, db DBTX, name string) (*Guest, error) {
 	stmt := `SELECT
 		id, name, attending, plus_ones,
 		dietary_restrictions, created_at, updated_at, first_opened_at
 		FROM guests WHERE name = ?`
 
 	log.Printf("Querying guest with name: %s", name)
-	row := db.QueryRow(stmt, name)
+	row := db.QueryRowContext(ctx, stmt, name)
 	guest := &Guest{}
 	err := row.Scan(
 		&guest.ID,
@@ -87,8 +88,36 @@ func GetGuestByName(db *sql.DB, name string) (*Guest, error) {
 	return guest, nil
 }
 
-func (g *Guest) Update(db *sql.DB) error {
-	tx, err := db.Begin()
+// GetGuestByID retrieves a guest by ID
+func GetGuestByID(ctx context.Context, db DBTX, id int64) (*Guest, error) {
+	stmt := `SELECT
+		id, name, attending, plus_ones,
+		dietary_restrictions, created_at, updated_at, first_opened_at
+		FROM guests WHERE id = ?`
+
+	guest := &Guest{}
+	err := db.QueryRowContext(ctx, stmt, id).Scan(
+		&guest.ID,
+		&guest.Name,
+		&guest.Attending,
+		&guest.PlusOnes,
+		&guest.DietaryRestrictions,
+		&guest.CreatedAt,
+		&guest.UpdatedAt,
+		&guest.FirstOpenedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("Error querying guest %d: %v", id, err)
+		return nil, err
+	}
+	return guest, nil
+}
+
+func (g *Guest) Update(ctx context.Context, db DBTX) error {
+	tx, err := beginTx(ctx, db)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
 		return err
@@ -103,7 +132,7 @@ func (g *Guest) Update(db *sql.DB) error {
 		updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?`
 
-	res, err := tx.Exec(stmt,
+	res, err := tx.ExecContext(ctx, stmt,
 		g.Name,
 		g.Attending,
 		g.PlusOnes,
@@ -134,8 +163,8 @@ func (g *Guest) Update(db *sql.DB) error {
 }
 
 // BulkCreate creates multiple guests in a single transaction
-func BulkCreate(db *sql.DB, guests []Guest) error {
-	tx, err := db.Begin()
+func BulkCreate(ctx context.Context, db DBTX, guests []Guest) error {
+	tx, err := beginTx(ctx, db)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
 		return err
@@ -147,7 +176,7 @@ func BulkCreate(db *sql.DB, guests []Guest) error {
 		VALUES (?, ?, ?, ?)`
 
 	for i := range guests {
-		result, err := tx.Exec(stmt,
+		result, err := tx.ExecContext(ctx, stmt,
 			guests[i].Name,
 			guests[i].Attending,
 			guests[i].PlusOnes,
@@ -175,8 +204,8 @@ func BulkCreate(db *sql.DB, guests []Guest) error {
 }
 
 // BulkUpdate updates multiple guests in a single transaction
-func BulkUpdate(db *sql.DB, guests []Guest) error {
-	tx, err := db.Begin()
+func BulkUpdate(ctx context.Context, db DBTX, guests []Guest) error {
+	tx, err := beginTx(ctx, db)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
 		return err
@@ -192,7 +221,7 @@ func BulkUpdate(db *sql.DB, guests []Guest) error {
 		WHERE id = ?`
 
 	for i := range guests {
-		res, err := tx.Exec(stmt,
+		res, err := tx.ExecContext(ctx, stmt,
 			guests[i].Name,
 			guests[i].Attending,
 			guests[i].PlusOnes,
@@ -223,13 +252,13 @@ func BulkUpdate(db *sql.DB, guests []Guest) error {
 	return nil
 }
 
-func GetAllGuests(db *sql.DB) ([]Guest, error) {
+func GetAllGuests(ctx context.Context, db DBTX) ([]Guest, error) {
 	stmt := `SELECT
 		id, name, attending, plus_ones,
 		dietary_restrictions, created_at, updated_at, first_opened_at
 		FROM guests`
 
-	rows, err := db.Query(stmt)
+	rows, err := db.QueryContext(ctx, stmt)
 	if err != nil {
 		return nil, err
 	}
@@ -262,17 +291,17 @@ func GetAllGuests(db *sql.DB) ([]Guest, error) {
 	return guests, nil
 }
 
-func MarkInvitationOpened(db *sql.DB, name string) error {
-	tx, err := db.Begin()
+func MarkInvitationOpened(ctx context.Context, db DBTX, guestID int64) error {
+	tx, err := beginTx(ctx, db)
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt := `UPDATE guests SET first_opened_at = CURRENT_TIMESTAMP WHERE name = ? AND first_opened_at IS NULL`
+	stmt := `UPDATE guests SET first_opened_at = CURRENT_TIMESTAMP WHERE id = ? AND first_opened_at IS NULL`
 
-	result, err := tx.Exec(stmt, name)
+	result, err := tx.ExecContext(ctx, stmt, guestID)
 	if err != nil {
 		log.Printf("Failed to mark invitation opened: %v", err)
 		return err
@@ -290,7 +319,7 @@ func MarkInvitationOpened(db *sql.DB, name string) error {
 	}
 
 	if rows > 0 {
-		log.Printf("Marked invitation opened for %s", name)
+		log.Printf("Marked invitation opened for guest %d", guestID)
 	}
 	return nil
 }
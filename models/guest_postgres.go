@@ -0,0 +1,206 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+// Postgres versions of the guest queries, used when DB_DRIVER=postgres.
+// They differ from the SQLite ones in placeholders and in reading new IDs
+// with RETURNING, since the Postgres driver has no LastInsertId.
+
+const postgresGuestColumns = `id, name, attending, plus_ones,
+	dietary_restrictions, created_at, updated_at, first_opened_at
+	FROM guests`
+
+func scanGuest(scanner interface{ Scan(...interface{}) error }) (*Guest, error) {
+	guest := &Guest{}
+	err := scanner.Scan(
+		&guest.ID,
+		&guest.Name,
+		&guest.Attending,
+		&guest.PlusOnes,
+		&guest.DietaryRestrictions,
+		&guest.CreatedAt,
+		&guest.UpdatedAt,
+		&guest.FirstOpenedAt,
+	)
+	return guest, err
+}
+
+// PostgresCreateGuest inserts a guest and sets its ID
+func PostgresCreateGuest(ctx context.Context, db DBTX, g *Guest) error {
+	stmt := `INSERT INTO guests
+		(name, attending, plus_ones, dietary_restrictions)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	err := db.QueryRowContext(ctx, stmt, g.Name, g.Attending, g.PlusOnes, g.DietaryRestrictions).Scan(&g.ID)
+	if err != nil {
+		log.Printf("Failed to create guest: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully created guest with ID %d", g.ID)
+	return nil
+}
+
+// PostgresGetGuestByName retrieves a guest by name. Returns nil if there is
+// no such guest.
+func PostgresGetGuestByName(ctx context.Context, db DBTX, name string) (*Guest, error) {
+	guest, err := scanGuest(db.QueryRowContext(ctx, `SELECT `+postgresGuestColumns+` WHERE name = $1`, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("Error querying guest: %v", err)
+		return nil, err
+	}
+	return guest, nil
+}
+
+// PostgresGetGuestByID retrieves a guest by ID. Returns nil if there is no
+// such guest.
+func PostgresGetGuestByID(ctx context.Context, db DBTX, id int64) (*Guest, error) {
+	guest, err := scanGuest(db.QueryRowContext(ctx, `SELECT `+postgresGuestColumns+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		log.Printf("Error querying guest %d: %v", id, err)
+		return nil, err
+	}
+	return guest, nil
+}
+
+// PostgresGetAllGuests retrieves every guest
+func PostgresGetAllGuests(ctx context.Context, db DBTX) ([]Guest, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+postgresGuestColumns+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guests []Guest
+	for rows.Next() {
+		guest, err := scanGuest(rows)
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, *guest)
+	}
+	return guests, rows.Err()
+}
+
+// PostgresUpdateGuest saves a guest's details. Returns sql.ErrNoRows if the
+// guest does not exist.
+func PostgresUpdateGuest(ctx context.Context, db DBTX, g *Guest) error {
+	if err := postgresUpdateGuest(ctx, db, g); err != nil {
+		return err
+	}
+	log.Printf("Successfully updated guest with ID %d", g.ID)
+	return nil
+}
+
+func postgresUpdateGuest(ctx context.Context, exec interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+}, g *Guest) error {
+	stmt := `UPDATE guests SET
+		name = $1,
+		attending = $2,
+		plus_ones = $3,
+		dietary_restrictions = $4,
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5`
+
+	res, err := exec.ExecContext(ctx, stmt, g.Name, g.Attending, g.PlusOnes, g.DietaryRestrictions, g.ID)
+	if err != nil {
+		log.Printf("Failed to update guest %d: %v", g.ID, err)
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		log.Printf("No rows affected - guest %d not found", g.ID)
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PostgresBulkCreate creates multiple guests in a single transaction
+func PostgresBulkCreate(ctx context.Context, db DBTX, guests []Guest) error {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO guests
+		(name, attending, plus_ones, dietary_restrictions)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	for i := range guests {
+		err := tx.QueryRowContext(ctx, stmt,
+			guests[i].Name,
+			guests[i].Attending,
+			guests[i].PlusOnes,
+			guests[i].DietaryRestrictions).Scan(&guests[i].ID)
+		if err != nil {
+			log.Printf("Failed to create guest %s: %v", guests[i].Name, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully created %d guests", len(guests))
+	return nil
+}
+
+// PostgresBulkUpdate updates multiple guests in a single transaction
+func PostgresBulkUpdate(ctx context.Context, db DBTX, guests []Guest) error {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range guests {
+		if err := postgresUpdateGuest(ctx, tx, &guests[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully updated %d guests", len(guests))
+	return nil
+}
+
+// PostgresMarkInvitationOpened records the first time a guest opened the
+// invitation; later calls keep the original time
+func PostgresMarkInvitationOpened(ctx context.Context, db DBTX, guestID int64) error {
+	stmt := `UPDATE guests SET first_opened_at = CURRENT_TIMESTAMP WHERE id = $1 AND first_opened_at IS NULL`
+
+	result, err := db.ExecContext(ctx, stmt, guestID)
+	if err != nil {
+		log.Printf("Failed to mark invitation opened: %v", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+		log.Printf("Marked invitation opened for guest %d", guestID)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -38,7 +39,7 @@ func TestGuestCreate(t *testing.T) {
 		PlusOnes: 2,
 	}
 
-	err := guest.Create(db)
+	err := guest.Create(context.Background(), db)
 	assert.NoError(t, err)
 	assert.NotEqual(t, 0, guest.ID)
 }
@@ -49,11 +50,11 @@ func TestGetGuestByName(t *testing.T) {
 
 	// Create test guest
 	g := &Guest{Name: "Jane Smith"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Test retrieval
-	guest, err := GetGuestByName(db, "Jane Smith")
+	guest, err := GetGuestByName(context.Background(), db, "Jane Smith")
 	assert.NoError(t, err)
 	assert.Equal(t, "Jane Smith", guest.Name)
 }
@@ -63,14 +64,14 @@ func TestGuestUpdate(t *testing.T) {
 	defer db.Close()
 
 	guest := &Guest{Name: "Original"}
-	err := guest.Create(db)
+	err := guest.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	guest.Name = "Updated"
-	err = guest.Update(db)
+	err = guest.Update(context.Background(), db)
 	assert.NoError(t, err)
 
-	updatedGuest, err := GetGuestByName(db, "Updated")
+	updatedGuest, err := GetGuestByName(context.Background(), db, "Updated")
 	assert.NoError(t, err)
 	assert.Equal(t, "Updated", updatedGuest.Name)
 }
@@ -84,14 +85,14 @@ func TestBulkOperations(t *testing.T) {
 		{Name: "Bulk1"},
 		{Name: "Bulk2"},
 	}
-	err := BulkCreate(db, guests)
+	err := BulkCreate(context.Background(), db, guests)
 	assert.NoError(t, err)
 	assert.NotZero(t, guests[0].ID)
 	assert.NotZero(t, guests[1].ID)
 
 	// BulkUpdate
 	guests[0].Name = "UpdatedBulk1"
-	err = BulkUpdate(db, guests)
+	err = BulkUpdate(context.Background(), db, guests)
 	assert.NoError(t, err)
 }
 
@@ -99,16 +100,16 @@ func TestGetAllGuests(t *testing.T) {
 	db := setupDB(t)
 	defer db.Close()
 
-	guests, err := GetAllGuests(db)
+	guests, err := GetAllGuests(context.Background(), db)
 	assert.NoError(t, err)
 	assert.Len(t, guests, 0)
 
 	// Add guest and test
 	g := &Guest{Name: "Test"}
-	err = g.Create(db)
+	err = g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
-	guests, err = GetAllGuests(db)
+	guests, err = GetAllGuests(context.Background(), db)
 	assert.NoError(t, err)
 	assert.Len(t, guests, 1)
 }
@@ -119,19 +120,19 @@ func TestMarkInvitationOpened(t *testing.T) {
 
 	// Create unopened guest
 	g := &Guest{Name: "Unopened"}
-	err := g.Create(db)
+	err := g.Create(context.Background(), db)
 	assert.NoError(t, err)
 
 	// Mark opened
-	err = MarkInvitationOpened(db, "Unopened")
+	err = MarkInvitationOpened(context.Background(), db, g.ID)
 	assert.NoError(t, err)
 
 	// Should fail if already opened
-	err = MarkInvitationOpened(db, "Unopened")
+	err = MarkInvitationOpened(context.Background(), db, g.ID)
 	assert.NoError(t, err)
 
 	// Verify record
-	guest, err := GetGuestByName(db, "Unopened")
+	guest, err := GetGuestByName(context.Background(), db, "Unopened")
 	assert.NoError(t, err)
 	assert.True(t, guest.FirstOpenedAt.Valid)
 }
@@ -141,7 +142,27 @@ func TestGetGuestByName_NotFound(t *testing.T) {
 	t.Cleanup(func() { db.Close() })
 
 	// Test retrieval of non-existent guest
-	guest, err := GetGuestByName(db, "NonExistent")
+	guest, err := GetGuestByName(context.Background(), db, "NonExistent")
+	assert.NoError(t, err)
+	assert.Nil(t, guest)
+}
+
+func TestGetGuestByID(t *testing.T) {
+	db := setupDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	g := &Guest{Name: "Jon Doe"}
+	assert.NoError(t, g.Create(context.Background(), db))
+
+	// A renamed guest is still found by ID
+	g.Name = "John Doe"
+	assert.NoError(t, g.Update(context.Background(), db))
+
+	guest, err := GetGuestByID(context.Background(), db, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", guest.Name)
+
+	guest, err = GetGuestByID(context.Background(), db, g.ID+1)
 	assert.NoError(t, err)
 	assert.Nil(t, guest)
 }
@@ -154,7 +175,7 @@ func TestGuestUpdate_NonExistent(t *testing.T) {
 
 	// Try to update a guest with non-existent ID
 	guest := &Guest{ID: 99999, Name: "Ghost"}
-	err := guest.Update(db)
+	err := guest.Update(context.Background(), db)
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrNoRows, err)
 }
@@ -164,7 +185,22 @@ func TestGetAllGuests_Empty(t *testing.T) {
 	t.Cleanup(func() { db.Close() })
 
 	// Test on empty database
-	guests, err := GetAllGuests(db)
+	guests, err := GetAllGuests(context.Background(), db)
 	assert.NoError(t, err)
 	assert.Len(t, guests, 0)
 }
+
+func TestGetGuestByID_CancelledContext(t *testing.T) {
+	db := setupDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	g := &Guest{Name: "Jane Smith"}
+	assert.NoError(t, g.Create(context.Background(), db))
+
+	// A request that has gone away should not keep the query running
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	guest, err := GetGuestByID(ctx, db, g.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, guest)
+}
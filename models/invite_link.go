@@ -0,0 +1,153 @@
+package models
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// InviteLink is an invitation link that logs one guest in directly. The
+// link's token is signed, not stored; this record lets a single link be
+// revoked before it expires. GuestName is not stored, since guests may live
+// in another database; the service fills it in.
+type InviteLink struct {
+	ID         string
+	GuestID    int64
+	GuestName  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	LastUsedAt sql.NullTime
+}
+
+// IsActive reports whether the link is neither revoked nor expired
+func (l *InviteLink) IsActive(now time.Time) bool {
+	return !l.RevokedAt.Valid && now.Before(l.ExpiresAt)
+}
+
+// Create stores the link
+func (l *InviteLink) Create(db *sql.DB) error {
+	return CreateInviteLinks(db, []InviteLink{*l})
+}
+
+// CreateInviteLinks stores several links in one transaction. Their guests
+// are not checked here, since guests may be stored in Postgres.
+func CreateInviteLinks(db *sql.DB, links []InviteLink) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO invite_links (id, guest_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		log.Printf("Failed to prepare statement: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	for _, link := range links {
+		if _, err := stmt.Exec(link.ID, link.GuestID, link.CreatedAt, link.ExpiresAt); err != nil {
+			log.Printf("Failed to create invite link for guest %d: %v", link.GuestID, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// inviteLinkColumns selects invite links
+const inviteLinkColumns = `l.id, l.guest_id, l.created_at, l.expires_at, l.revoked_at, l.last_used_at
+	FROM invite_links l`
+
+func scanInviteLink(scanner interface{ Scan(...interface{}) error }) (*InviteLink, error) {
+	link := &InviteLink{}
+	err := scanner.Scan(
+		&link.ID,
+		&link.GuestID,
+		&link.CreatedAt,
+		&link.ExpiresAt,
+		&link.RevokedAt,
+		&link.LastUsedAt,
+	)
+	return link, err
+}
+
+func queryInviteLinks(db *sql.DB, stmt string, args ...interface{}) ([]InviteLink, error) {
+	rows, err := db.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []InviteLink{}
+	for rows.Next() {
+		link, err := scanInviteLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+	return links, rows.Err()
+}
+
+// GetInviteLinkByID retrieves an invite link by ID
+func GetInviteLinkByID(db *sql.DB, id string) (*InviteLink, error) {
+	stmt := `SELECT ` + inviteLinkColumns + ` WHERE l.id = ?`
+
+	link, err := scanInviteLink(db.QueryRow(stmt, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// GetInviteLinksByGuestID retrieves a guest's invite links, newest first
+func GetInviteLinksByGuestID(db *sql.DB, guestID int64) ([]InviteLink, error) {
+	return queryInviteLinks(db, `SELECT `+inviteLinkColumns+` WHERE l.guest_id = ? ORDER BY l.created_at DESC`, guestID)
+}
+
+// GetActiveInviteLinks retrieves the links of all guests that are not
+// revoked and still valid at now, newest first
+func GetActiveInviteLinks(db *sql.DB, now time.Time) ([]InviteLink, error) {
+	return queryInviteLinks(db, `SELECT `+inviteLinkColumns+`
+		WHERE l.revoked_at IS NULL AND l.expires_at > ?
+		ORDER BY l.created_at DESC`, now.UTC())
+}
+
+// RevokeInviteLink marks a link as revoked. Revoking an already revoked link
+// keeps the original time. Returns sql.ErrNoRows if it does not exist.
+func RevokeInviteLink(db *sql.DB, id string) error {
+	result, err := db.Exec(`UPDATE invite_links SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`,
+		time.Now().UTC(), id)
+	if err != nil {
+		log.Printf("Failed to revoke invite link %s: %v", id, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkInviteLinkUsed records when a link was last used to log in
+func MarkInviteLinkUsed(db *sql.DB, id string, at time.Time) error {
+	if _, err := db.Exec(`UPDATE invite_links SET last_used_at = ? WHERE id = ?`, at.UTC(), id); err != nil {
+		log.Printf("Failed to mark invite link %s as used: %v", id, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInviteLinkCreateAndRevoke(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+	alice := &Guest{Name: "alice"}
+	bob := &Guest{Name: "bob"}
+	assert.NoError(t, alice.Create(context.Background(), db))
+	assert.NoError(t, bob.Create(context.Background(), db))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	links := []InviteLink{
+		{ID: "a1", GuestID: alice.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "b1", GuestID: bob.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	}
+	assert.NoError(t, CreateInviteLinks(db, links))
+	second := &InviteLink{ID: "a2", GuestID: alice.ID, CreatedAt: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, second.Create(db))
+
+	link, err := GetInviteLinkByID(db, "a1")
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, link.GuestID)
+	assert.Empty(t, link.GuestName, "names come from the guest service")
+	assert.True(t, link.IsActive(time.Now()))
+
+	guestLinks, err := GetInviteLinksByGuestID(db, alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, guestLinks, 2)
+	assert.Equal(t, "a2", guestLinks[0].ID, "newest first")
+
+	assert.NoError(t, MarkInviteLinkUsed(db, "a1", now))
+	assert.NoError(t, RevokeInviteLink(db, "a1"))
+	link, err = GetInviteLinkByID(db, "a1")
+	assert.NoError(t, err)
+	assert.True(t, link.RevokedAt.Valid)
+	assert.True(t, link.LastUsedAt.Valid)
+	assert.False(t, link.IsActive(time.Now()))
+
+	active, err := GetActiveInviteLinks(db, now)
+	assert.NoError(t, err)
+	assert.Len(t, active, 2)
+	assert.Equal(t, "a2", active[0].ID)
+	assert.Equal(t, "b1", active[1].ID)
+
+	assert.Equal(t, sql.ErrNoRows, RevokeInviteLink(db, "missing"))
+
+	link, err = GetInviteLinkByID(db, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, link)
+}
+
+func TestCreateInviteLinks_UnknownGuest(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+	guest := &Guest{Name: "alice"}
+	assert.NoError(t, guest.Create(context.Background(), db))
+
+	now := time.Now().UTC()
+	err := CreateInviteLinks(db, []InviteLink{
+		{ID: "a1", GuestID: guest.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "x1", GuestID: guest.ID + 100, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	})
+	assert.Error(t, err, "foreign keys reject unknown guests in SQLite")
+
+	// Nothing is stored when one guest is missing
+	link, err := GetInviteLinkByID(db, "a1")
+	assert.NoError(t, err)
+	assert.Nil(t, link)
+}
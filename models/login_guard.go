@@ -0,0 +1,221 @@
+package models
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// sqliteTimeLayout is the text form the SQLite driver stores time.Time in
+const sqliteTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// Outcomes recorded for guest login attempts
+const (
+	LoginOutcomeSuccess     = "success"
+	LoginOutcomeUnknownName = "unknown_name"
+	LoginOutcomeLocked      = "locked"
+)
+
+// LoginLockout counts recent failed logins for one key, an IP address
+// ("ip:203.0.113.7") or an attempted name ("name:john doe"). Lockouts are
+// stored so they survive a restart.
+type LoginLockout struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   sql.NullTime
+}
+
+// LoginEvent records one guest login attempt for the admin security view
+type LoginEvent struct {
+	ID        int64
+	IPAddress string
+	Name      string
+	Outcome   string
+	CreatedAt time.Time
+}
+
+// LoginFailureCount summarises failed logins from one IP address or for one
+// name. Distinct counts the names tried from the IP, or the IPs that tried
+// the name.
+type LoginFailureCount struct {
+	Key           string
+	Failures      int
+	Distinct      int
+	LastAttemptAt time.Time
+}
+
+// SuspiciousLoginReport lists current lockouts and the IPs and names with
+// many failed logins
+type SuspiciousLoginReport struct {
+	Lockouts []LoginLockout      `json:"lockouts"`
+	IPs      []LoginFailureCount `json:"ips"`
+	Names    []LoginFailureCount `json:"names"`
+}
+
+// GetLoginLockouts retrieves the lockout records that exist for keys
+func GetLoginLockouts(db *sql.DB, keys ...string) ([]LoginLockout, error) {
+	lockouts := []LoginLockout{}
+	for _, key := range keys {
+		var lockout LoginLockout
+		err := db.QueryRow(`SELECT key, failures, last_failure_at, locked_until
+			FROM login_lockouts WHERE key = ?`, key).Scan(
+			&lockout.Key,
+			&lockout.Failures,
+			&lockout.LastFailureAt,
+			&lockout.LockedUntil,
+		)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, lockout)
+	}
+	return lockouts, nil
+}
+
+// IncrementLoginFailures counts a failed login for key and returns the new
+// number of failures. Counting starts over when the previous failure was
+// before resetBefore.
+func IncrementLoginFailures(db *sql.DB, key string, now, resetBefore time.Time) (int, error) {
+	stmt := `INSERT INTO login_lockouts (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END,
+			last_failure_at = excluded.last_failure_at
+		RETURNING failures`
+
+	var failures int
+	if err := db.QueryRow(stmt, key, now.UTC(), resetBefore.UTC()).Scan(&failures); err != nil {
+		log.Printf("Failed to count login failure for %s: %v", key, err)
+		return 0, err
+	}
+	return failures, nil
+}
+
+// SetLoginLockedUntil locks key until the given time
+func SetLoginLockedUntil(db *sql.DB, key string, until time.Time) error {
+	_, err := db.Exec(`UPDATE login_lockouts SET locked_until = ? WHERE key = ?`, until.UTC(), key)
+	if err != nil {
+		log.Printf("Failed to lock %s: %v", key, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteLoginLockout forgets the failures of key and reports whether there
+// were any
+func DeleteLoginLockout(db *sql.DB, key string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM login_lockouts WHERE key = ?`, key)
+	if err != nil {
+		log.Printf("Failed to clear lockout %s: %v", key, err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// CreateLoginEvent records a login attempt
+func CreateLoginEvent(db *sql.DB, event *LoginEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	result, err := db.Exec(`INSERT INTO login_events (ip_address, name, outcome, created_at) VALUES (?, ?, ?, ?)`,
+		event.IPAddress, event.Name, event.Outcome, event.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to record login event: %v", err)
+		return err
+	}
+
+	event.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Printf("Failed to get last insert ID: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetSuspiciousLogins reports lockouts still in force at now, and the IPs
+// and names with at least minFailures failed logins since the given time,
+// most failures first
+func GetSuspiciousLogins(db *sql.DB, since, now time.Time, minFailures int) (*SuspiciousLoginReport, error) {
+	report := &SuspiciousLoginReport{}
+
+	rows, err := db.Query(`SELECT key, failures, last_failure_at, locked_until
+		FROM login_lockouts WHERE locked_until > ? ORDER BY locked_until DESC`, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report.Lockouts = []LoginLockout{}
+	for rows.Next() {
+		var lockout LoginLockout
+		if err := rows.Scan(&lockout.Key, &lockout.Failures, &lockout.LastFailureAt, &lockout.LockedUntil); err != nil {
+			return nil, err
+		}
+		report.Lockouts = append(report.Lockouts, lockout)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.IPs, err = getLoginFailureCounts(db, "ip_address", "name", since, minFailures)
+	if err != nil {
+		return nil, err
+	}
+	report.Names, err = getLoginFailureCounts(db, "name", "ip_address", since, minFailures)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// getLoginFailureCounts groups failed logins by column, counting the
+// distinct values of other. Both columns are fixed by the caller.
+func getLoginFailureCounts(db *sql.DB, column, other string, since time.Time, minFailures int) ([]LoginFailureCount, error) {
+	query := `SELECT ` + column + `, COUNT(*), COUNT(DISTINCT ` + other + `), MAX(created_at)
+		FROM login_events
+		WHERE outcome != ? AND created_at >= ?
+		GROUP BY ` + column + `
+		HAVING COUNT(*) >= ?
+		ORDER BY COUNT(*) DESC
+		LIMIT 100`
+
+	rows, err := db.Query(query, LoginOutcomeSuccess, since.UTC(), minFailures)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []LoginFailureCount{}
+	for rows.Next() {
+		var count LoginFailureCount
+		var lastAttempt string
+		if err := rows.Scan(&count.Key, &count.Failures, &count.Distinct, &lastAttempt); err != nil {
+			return nil, err
+		}
+		// Aggregates lose the column type, so the stored text comes back
+		count.LastAttemptAt, err = time.Parse(sqliteTimeLayout, lastAttempt)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// DeleteLoginEventsBefore removes login events recorded before the given time
+func DeleteLoginEventsBefore(db *sql.DB, before time.Time) (int64, error) {
+	result, err := db.Exec(`DELETE FROM login_events WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		log.Printf("Failed to delete old login events: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
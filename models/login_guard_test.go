@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLockouts(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC()
+	window := now.Add(-time.Hour)
+	for i := 1; i <= 3; i++ {
+		failures, err := IncrementLoginFailures(db, "ip:10.0.0.1", now, window)
+		assert.NoError(t, err)
+		assert.Equal(t, i, failures)
+	}
+
+	until := now.Add(time.Minute)
+	assert.NoError(t, SetLoginLockedUntil(db, "ip:10.0.0.1", until))
+
+	lockouts, err := GetLoginLockouts(db, "ip:10.0.0.1", "name:nobody")
+	assert.NoError(t, err)
+	assert.Len(t, lockouts, 1)
+	assert.Equal(t, 3, lockouts[0].Failures)
+	assert.True(t, lockouts[0].LockedUntil.Valid)
+	assert.WithinDuration(t, until, lockouts[0].LockedUntil.Time, time.Second)
+
+	// A failure after the window starts the count over
+	later := now.Add(2 * time.Hour)
+	failures, err := IncrementLoginFailures(db, "ip:10.0.0.1", later, later.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	found, err := DeleteLoginLockout(db, "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	found, err = DeleteLoginLockout(db, "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestGetSuspiciousLogins(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC()
+	events := []LoginEvent{
+		{IPAddress: "10.0.0.1", Name: "aaron", Outcome: LoginOutcomeUnknownName},
+		{IPAddress: "10.0.0.1", Name: "abby", Outcome: LoginOutcomeUnknownName},
+		{IPAddress: "10.0.0.1", Name: "adam", Outcome: LoginOutcomeLocked},
+		{IPAddress: "10.0.0.2", Name: "abby", Outcome: LoginOutcomeUnknownName},
+		{IPAddress: "10.0.0.3", Name: "alice", Outcome: LoginOutcomeSuccess},
+		{IPAddress: "10.0.0.9", Name: "zoe", Outcome: LoginOutcomeUnknownName, CreatedAt: now.Add(-48 * time.Hour)},
+	}
+	for i := range events {
+		assert.NoError(t, CreateLoginEvent(db, &events[i]))
+	}
+
+	_, err := IncrementLoginFailures(db, "ip:10.0.0.1", now, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, SetLoginLockedUntil(db, "ip:10.0.0.1", now.Add(time.Minute)))
+	_, err = IncrementLoginFailures(db, "name:abby", now, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, SetLoginLockedUntil(db, "name:abby", now.Add(-time.Minute)))
+
+	report, err := GetSuspiciousLogins(db, now.Add(-24*time.Hour), now, 2)
+	assert.NoError(t, err)
+
+	assert.Len(t, report.Lockouts, 1, "expired lockouts are not listed")
+	assert.Equal(t, "ip:10.0.0.1", report.Lockouts[0].Key)
+
+	assert.Len(t, report.IPs, 1)
+	assert.Equal(t, "10.0.0.1", report.IPs[0].Key)
+	assert.Equal(t, 3, report.IPs[0].Failures)
+	assert.Equal(t, 3, report.IPs[0].Distinct)
+	assert.WithinDuration(t, events[2].CreatedAt, report.IPs[0].LastAttemptAt, time.Second)
+
+	assert.Len(t, report.Names, 1)
+	assert.Equal(t, "abby", report.Names[0].Key)
+	assert.Equal(t, 2, report.Names[0].Distinct)
+
+	deleted, err := DeleteLoginEventsBefore(db, now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
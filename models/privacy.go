@@ -0,0 +1,184 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strconv"
+)
+
+// GuestRecords are the rows about a guest kept outside the guests and
+// comments tables
+type GuestRecords struct {
+	Sessions     []Session
+	InviteLinks  []InviteLink
+	AuditEntries []AuditEntry
+}
+
+// GetGuestRecords retrieves a guest's sessions and invitation links, and
+// the audit entries about the guest, newest first
+func GetGuestRecords(db *sql.DB, guestID int64) (*GuestRecords, error) {
+	sessions, err := GetSessionsByGuestID(db, guestID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := GetInviteLinksByGuestID(db, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := &GuestRecords{Sessions: sessions, InviteLinks: links, AuditEntries: []AuditEntry{}}
+	filter := AuditFilter{TargetType: AuditTargetGuest, TargetID: strconv.FormatInt(guestID, 10)}
+	cursor := ""
+	for {
+		page, err := GetAuditEntries(db, filter, 100, cursor)
+		if err != nil {
+			return nil, err
+		}
+		records.AuditEntries = append(records.AuditEntries, page.Entries...)
+		if page.NextCursor == "" {
+			return records, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// GuestErasure counts what EraseGuestRecords removed
+type GuestErasure struct {
+	Sessions     int64 `json:"sessions_deleted"`
+	InviteLinks  int64 `json:"invite_links_deleted"`
+	LoginEvents  int64 `json:"login_events_deleted"`
+	AuditEntries int64 `json:"audit_entries_redacted"`
+}
+
+// EraseGuestRecords deletes a guest's sessions with their refresh tokens,
+// invitation links, and the login events and lockout of the guest's name,
+// which match ignoring case and surrounding spaces. It removes the given
+// fields from the changes of audit entries about the guest, and the name
+// from entries about the guest's links, sessions and comments and about the
+// lockout, all in one transaction. The guest row itself is left alone.
+func EraseGuestRecords(ctx context.Context, db DBTX, guestID int64, name, lockoutKey string, auditFields []string) (*GuestErasure, error) {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Refresh tokens are deleted explicitly since foreign keys are off when
+	// guests are stored in Postgres
+	if _, err := tx.ExecContext(ctx, `DELETE FROM refresh_tokens
+		WHERE session_id IN (SELECT id FROM sessions WHERE guest_id = ?)`, guestID); err != nil {
+		log.Printf("Failed to delete refresh tokens: %v", err)
+		return nil, err
+	}
+
+	erasure := &GuestErasure{}
+	deletes := []struct {
+		count *int64
+		stmt  string
+		arg   interface{}
+	}{
+		{&erasure.Sessions, `DELETE FROM sessions WHERE guest_id = ?`, guestID},
+		{&erasure.InviteLinks, `DELETE FROM invite_links WHERE guest_id = ?`, guestID},
+		{&erasure.LoginEvents, `DELETE FROM login_events WHERE lower(trim(name)) = lower(trim(?))`, name},
+		{new(int64), `DELETE FROM login_lockouts WHERE key = ?`, lockoutKey},
+	}
+	for _, d := range deletes {
+		res, err := tx.ExecContext(ctx, d.stmt, d.arg)
+		if err != nil {
+			log.Printf("Failed to erase guest records: %v", err)
+			return nil, err
+		}
+		if *d.count, err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	erasure.AuditEntries, err = redactAuditChanges(ctx, tx, AuditTargetGuest, strconv.FormatInt(guestID, 10), auditFields)
+	if err != nil {
+		log.Printf("Failed to redact audit entries: %v", err)
+		return nil, err
+	}
+	named, err := redactAuditGuestName(ctx, tx, guestID)
+	if err != nil {
+		log.Printf("Failed to redact audit entries: %v", err)
+		return nil, err
+	}
+	unlocks, err := redactAuditLockout(ctx, tx, lockoutKey)
+	if err != nil {
+		log.Printf("Failed to redact audit entries: %v", err)
+		return nil, err
+	}
+	erasure.AuditEntries += named + unlocks
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Erased records of guest %d", guestID)
+	return erasure, nil
+}
+
+// redactAuditChanges removes fields from the changes of every audit entry
+// about a target and returns how many entries changed
+func redactAuditChanges(ctx context.Context, tx *txn, targetType, targetID string, fields []string) (int64, error) {
+	return rewriteAuditChanges(ctx, tx, `target_type = ? AND target_id = ?`, []interface{}{targetType, targetID}, func(changes map[string]json.RawMessage) (bool, error) {
+		found := false
+		for _, field := range fields {
+			if _, ok := changes[field]; ok {
+				delete(changes, field)
+				found = true
+			}
+		}
+		return found, nil
+	})
+}
+
+// redactAuditGuestName removes GuestName from the changes of audit entries
+// whose GuestID is the guest's, such as those about the guest's invitation
+// links, sessions and comments, and returns how many entries changed
+func redactAuditGuestName(ctx context.Context, tx *txn, guestID int64) (int64, error) {
+	return rewriteAuditChanges(ctx, tx, `changes LIKE '%"GuestName"%'`, nil, func(changes map[string]json.RawMessage) (bool, error) {
+		if _, ok := changes["GuestName"]; !ok {
+			return false, nil
+		}
+		var owner struct {
+			Before *int64 `json:"before"`
+			After  *int64 `json:"after"`
+		}
+		if raw, ok := changes["GuestID"]; ok {
+			if err := json.Unmarshal(raw, &owner); err != nil {
+				return false, err
+			}
+		}
+		if (owner.Before == nil || *owner.Before != guestID) && (owner.After == nil || *owner.After != guestID) {
+			return false, nil
+		}
+		delete(changes, "GuestName")
+		return true, nil
+	})
+}
+
+// redactAuditLockout removes the name from audit entries about a name's
+// lockout, which hold it in their target ID and changes, and returns how
+// many entries changed
+func redactAuditLockout(ctx context.Context, tx *txn, lockoutKey string) (int64, error) {
+	where, args := `target_type = ? AND target_id = ?`, []interface{}{AuditTargetLoginLockout, lockoutKey}
+	if _, err := rewriteAuditChanges(ctx, tx, where, args, func(changes map[string]json.RawMessage) (bool, error) {
+		_, ok := changes["Key"]
+		delete(changes, "Key")
+		return ok, nil
+	}); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE audit_log SET target_id = ? WHERE `+where,
+		append([]interface{}{"name:" + AuditRedacted}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuestRecordsAndErasure(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	guest := &Guest{Name: "alice"}
+	other := &Guest{Name: "bob"}
+	for _, g := range []*Guest{guest, other} {
+		if err := g.Create(ctx, db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, s := range []Session{
+		{ID: "alice-phone", GuestID: guest.ID, UserAgent: "Phone", IPAddress: "10.0.0.1"},
+		{ID: "bob-laptop", GuestID: other.ID, UserAgent: "Laptop", IPAddress: "10.0.0.2"},
+	} {
+		s.CreatedAt, s.ExpiresAt = now, now.Add(time.Hour)
+		assert.NoError(t, s.Create(db, &RefreshToken{SessionID: s.ID, TokenHash: s.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	}
+	assert.NoError(t, CreateInviteLinks(db, []InviteLink{{ID: "link", GuestID: guest.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}}))
+	for _, name := range []string{"alice", " Alice", "bob"} {
+		assert.NoError(t, CreateLoginEvent(db, &LoginEvent{IPAddress: "10.0.0.9", Name: name, Outcome: LoginOutcomeUnknownName, CreatedAt: now}))
+	}
+	_, err := IncrementLoginFailures(db, "name:alice", now, now.Add(-time.Hour))
+	assert.NoError(t, err)
+
+	changes := `{"Name":{"before":null,"after":"alice"},"PlusOnes":{"before":null,"after":1}}`
+	otherChanges := `{"Name":{"before":null,"after":"bob"}}`
+	linkChanges := func(g *Guest) json.RawMessage {
+		return json.RawMessage(`{"GuestID":{"before":null,"after":` + strconv.FormatInt(g.ID, 10) + `},"GuestName":{"before":null,"after":"` + g.Name + `"}}`)
+	}
+	assert.NoError(t, CreateAuditEntries(db, []AuditEntry{
+		{ActorName: "owner", Action: AuditActionGuestCreate, TargetType: AuditTargetGuest, TargetID: strconv.FormatInt(guest.ID, 10), Changes: json.RawMessage(changes)},
+		{ActorName: "owner", Action: AuditActionGuestCreate, TargetType: AuditTargetGuest, TargetID: strconv.FormatInt(other.ID, 10), Changes: json.RawMessage(otherChanges)},
+		{ActorName: "owner", Action: AuditActionInviteLinkCreate, TargetType: AuditTargetInviteLink, TargetID: "link", Changes: linkChanges(guest)},
+		{ActorName: "owner", Action: AuditActionInviteLinkCreate, TargetType: AuditTargetInviteLink, TargetID: "bob-link", Changes: linkChanges(other)},
+		{ActorName: "owner", Action: AuditActionSessionRevoke, TargetType: AuditTargetSession, TargetID: "session-1", Changes: linkChanges(guest)},
+		{ActorName: "owner", Action: AuditActionLoginUnlock, TargetType: AuditTargetLoginLockout, TargetID: "name:alice",
+			Changes: json.RawMessage(`{"Key":{"before":"name:alice","after":null},"Failures":{"before":5,"after":null}}`)},
+		{ActorName: "owner", Action: AuditActionLoginUnlock, TargetType: AuditTargetLoginLockout, TargetID: "name:bob",
+			Changes: json.RawMessage(`{"Key":{"before":"name:bob","after":null}}`)},
+	}))
+
+	records, err := GetGuestRecords(db, guest.ID)
+	assert.NoError(t, err)
+	assert.Len(t, records.Sessions, 1)
+	assert.Len(t, records.InviteLinks, 1)
+	if assert.Len(t, records.AuditEntries, 1) {
+		assert.Equal(t, AuditActionGuestCreate, records.AuditEntries[0].Action)
+	}
+
+	erasure, err := EraseGuestRecords(ctx, db, guest.ID, "alice", "name:alice", []string{"Name", "DietaryRestrictions"})
+	assert.NoError(t, err)
+	assert.Equal(t, &GuestErasure{Sessions: 1, InviteLinks: 1, LoginEvents: 2, AuditEntries: 4}, erasure)
+
+	records, err = GetGuestRecords(db, guest.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, records.Sessions)
+	assert.Empty(t, records.InviteLinks)
+	if assert.Len(t, records.AuditEntries, 1, "audit entries are kept") {
+		assert.JSONEq(t, `{"PlusOnes":{"before":null,"after":1}}`, string(records.AuditEntries[0].Changes))
+	}
+
+	// The name is gone from entries about the guest's link, session and
+	// lockout, which are kept
+	rows, err := db.Query(`SELECT target_id, changes FROM audit_log`)
+	assert.NoError(t, err)
+	var kept int
+	for rows.Next() {
+		var targetID, stored string
+		assert.NoError(t, rows.Scan(&targetID, &stored))
+		assert.NotContains(t, targetID+stored, "alice")
+		kept++
+	}
+	rows.Close()
+	assert.Equal(t, 7, kept)
+	page, err := GetAuditEntries(db, AuditFilter{TargetType: AuditTargetLoginLockout, TargetID: "name:" + AuditRedacted}, 10, "")
+	assert.NoError(t, err)
+	if assert.Len(t, page.Entries, 1) {
+		assert.JSONEq(t, `{"Failures":{"before":5,"after":null}}`, string(page.Entries[0].Changes))
+	}
+
+	token, err := GetRefreshTokenByHash(db, "alice-phone")
+	assert.NoError(t, err)
+	assert.Nil(t, token)
+	lockouts, err := GetLoginLockouts(db, "name:alice")
+	assert.NoError(t, err)
+	assert.Empty(t, lockouts)
+
+	// Other guests keep everything
+	records, err = GetGuestRecords(db, other.ID)
+	assert.NoError(t, err)
+	assert.Len(t, records.Sessions, 1)
+	assert.JSONEq(t, otherChanges, string(records.AuditEntries[0].Changes))
+	page, err = GetAuditEntries(db, AuditFilter{TargetID: "bob-link"}, 10, "")
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(linkChanges(other)), string(page.Entries[0].Changes))
+	page, err = GetAuditEntries(db, AuditFilter{TargetID: "name:bob"}, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	var events int
+	assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM login_events`).Scan(&events))
+	assert.Equal(t, 1, events)
+
+	// Erasing again finds nothing left
+	erasure, err = EraseGuestRecords(ctx, db, guest.ID, "alice", "name:alice", []string{"Name", "DietaryRestrictions"})
+	assert.NoError(t, err)
+	assert.Equal(t, &GuestErasure{}, erasure)
+}
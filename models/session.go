@@ -0,0 +1,314 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrRefreshTokenUsed is returned when rotating a refresh token that has
+// already been exchanged
+var ErrRefreshTokenUsed = errors.New("refresh token already used")
+
+// Session is one guest login on one device. Its ID is the jti claim of every
+// access token issued for it, so revoking the session invalidates them. Each
+// refresh extends ExpiresAt.
+type Session struct {
+	ID        string
+	GuestID   int64
+	GuestName string
+	UserAgent string
+	IPAddress string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+}
+
+// IsActive reports whether the session is neither revoked nor expired
+func (s *Session) IsActive(now time.Time) bool {
+	return !s.RevokedAt.Valid && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is a single-use token that renews a session. Only the
+// SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        int64
+	SessionID string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+// Create stores the session together with its first refresh token
+func (s *Session) Create(db *sql.DB, refreshToken *RefreshToken) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO sessions
+		(id, guest_id, user_agent, ip_address, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err = tx.Exec(stmt,
+		s.ID,
+		s.GuestID,
+		s.UserAgent,
+		s.IPAddress,
+		s.CreatedAt,
+		s.ExpiresAt)
+	if err != nil {
+		log.Printf("Failed to create session for guest %d: %v", s.GuestID, err)
+		return err
+	}
+
+	if refreshToken != nil {
+		if err := createRefreshToken(tx, refreshToken); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+func createRefreshToken(tx *sql.Tx, token *RefreshToken) error {
+	stmt := `INSERT INTO refresh_tokens
+		(session_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?)`
+
+	result, err := tx.Exec(stmt,
+		token.SessionID,
+		token.TokenHash,
+		token.CreatedAt,
+		token.ExpiresAt)
+	if err != nil {
+		log.Printf("Failed to create refresh token for session %s: %v", token.SessionID, err)
+		return err
+	}
+
+	token.ID, err = result.LastInsertId()
+	if err != nil {
+		log.Printf("Failed to get last insert ID: %v", err)
+		return err
+	}
+	return nil
+}
+
+// sessionColumns selects sessions joined with their guest's name
+const sessionColumns = `s.id, s.guest_id, COALESCE(g.name, ''), s.user_agent, s.ip_address,
+	s.created_at, s.expires_at, s.revoked_at
+	FROM sessions s LEFT JOIN guests g ON g.id = s.guest_id`
+
+func scanSession(scanner interface{ Scan(...interface{}) error }) (*Session, error) {
+	session := &Session{}
+	err := scanner.Scan(
+		&session.ID,
+		&session.GuestID,
+		&session.GuestName,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	return session, err
+}
+
+// GetSessionByID retrieves a session by ID
+func GetSessionByID(db *sql.DB, id string) (*Session, error) {
+	stmt := `SELECT ` + sessionColumns + ` WHERE s.id = ?`
+
+	session, err := scanSession(db.QueryRow(stmt, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetSessionsByGuestID retrieves a guest's sessions, newest first
+func GetSessionsByGuestID(db *sql.DB, guestID int64) ([]Session, error) {
+	stmt := `SELECT ` + sessionColumns + ` WHERE s.guest_id = ? ORDER BY s.created_at DESC`
+
+	rows, err := db.Query(stmt, guestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession marks a session as revoked. Revoking an already revoked
+// session keeps the original time. Returns sql.ErrNoRows if it does not exist.
+func RevokeSession(db *sql.DB, id string) error {
+	result, err := db.Exec(`UPDATE sessions SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`,
+		time.Now().UTC(), id)
+	if err != nil {
+		log.Printf("Failed to revoke session %s: %v", id, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeSessionsByGuestID revokes every active session of a guest and
+// returns the IDs of the sessions it revoked
+func RevokeSessionsByGuestID(db *sql.DB, guestID int64) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id FROM sessions WHERE guest_id = ? AND revoked_at IS NULL`, guestID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`UPDATE sessions SET revoked_at = ? WHERE guest_id = ? AND revoked_at IS NULL`,
+		time.Now().UTC(), guestID); err != nil {
+		log.Printf("Failed to revoke sessions of guest %d: %v", guestID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its value
+func GetRefreshTokenByHash(db *sql.DB, tokenHash string) (*RefreshToken, error) {
+	stmt := `SELECT id, session_id, token_hash, created_at, expires_at, used_at
+		FROM refresh_tokens WHERE token_hash = ?`
+
+	token := &RefreshToken{}
+	err := db.QueryRow(stmt, tokenHash).Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.UsedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RotateRefreshToken marks used as exchanged, stores next in its place and
+// extends the session to next's expiry, all in one transaction. It returns
+// ErrRefreshTokenUsed if used was exchanged concurrently.
+func RotateRefreshToken(db *sql.DB, used *RefreshToken, next *RefreshToken) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`,
+		next.CreatedAt, used.ID)
+	if err != nil {
+		log.Printf("Failed to mark refresh token %d as used: %v", used.ID, err)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRefreshTokenUsed
+	}
+
+	if err := createRefreshToken(tx, next); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE sessions SET expires_at = ? WHERE id = ?`, next.ExpiresAt, next.SessionID); err != nil {
+		log.Printf("Failed to extend session %s: %v", next.SessionID, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteExpiredSessions removes sessions that expired before the given time,
+// together with their refresh tokens
+func DeleteExpiredSessions(db *sql.DB, before time.Time) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM refresh_tokens WHERE session_id IN
+		(SELECT id FROM sessions WHERE expires_at < ?)`, before.UTC()); err != nil {
+		log.Printf("Failed to delete expired refresh tokens: %v", err)
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM sessions WHERE expires_at < ?`, before.UTC())
+	if err != nil {
+		log.Printf("Failed to delete expired sessions: %v", err)
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		return 0, err
+	}
+	return deleted, nil
+}
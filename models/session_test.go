@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createSessionTestGuest(t *testing.T, db *sql.DB) *Guest {
+	t.Helper()
+	guest := &Guest{Name: "alice"}
+	if err := guest.Create(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return guest
+}
+
+func TestSessionCreateAndRevoke(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+	guest := createSessionTestGuest(t, db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, id := range []string{"first", "second"} {
+		session := &Session{
+			ID:        id,
+			GuestID:   guest.ID,
+			UserAgent: "Mozilla/5.0",
+			IPAddress: "10.0.0.1",
+			CreatedAt: now,
+			ExpiresAt: now.Add(time.Hour),
+		}
+		assert.NoError(t, session.Create(db, nil))
+		now = now.Add(time.Minute)
+	}
+
+	session, err := GetSessionByID(db, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, guest.ID, session.GuestID)
+	assert.Equal(t, "alice", session.GuestName)
+	assert.Equal(t, "Mozilla/5.0", session.UserAgent)
+	assert.True(t, session.IsActive(time.Now()))
+
+	sessions, err := GetSessionsByGuestID(db, guest.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, "second", sessions[0].ID, "newest first")
+
+	assert.NoError(t, RevokeSession(db, "first"))
+	session, err = GetSessionByID(db, "first")
+	assert.NoError(t, err)
+	assert.True(t, session.RevokedAt.Valid)
+	assert.False(t, session.IsActive(time.Now()))
+
+	assert.Equal(t, sql.ErrNoRows, RevokeSession(db, "missing"))
+
+	// Only the still active session is revoked again
+	ids, err := RevokeSessionsByGuestID(db, guest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second"}, ids)
+
+	missing, err := GetSessionByID(db, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+	guest := createSessionTestGuest(t, db)
+
+	now := time.Now().UTC()
+	expired := &Session{ID: "expired", GuestID: guest.ID, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	current := &Session{ID: "current", GuestID: guest.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, expired.Create(db, nil))
+	assert.NoError(t, current.Create(db, nil))
+	assert.False(t, expired.IsActive(now))
+
+	deleted, err := DeleteExpiredSessions(db, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	sessions, err := GetSessionsByGuestID(db, guest.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "current", sessions[0].ID)
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+	guest := createSessionTestGuest(t, db)
+
+	now := time.Now().UTC()
+	session := &Session{ID: "s1", GuestID: guest.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	first := &RefreshToken{SessionID: "s1", TokenHash: "hash-1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, session.Create(db, first))
+	assert.NotZero(t, first.ID)
+
+	stored, err := GetRefreshTokenByHash(db, "hash-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", stored.SessionID)
+	assert.False(t, stored.UsedAt.Valid)
+
+	later := now.Add(30 * time.Minute)
+	second := &RefreshToken{SessionID: "s1", TokenHash: "hash-2", CreatedAt: later, ExpiresAt: later.Add(time.Hour)}
+	assert.NoError(t, RotateRefreshToken(db, stored, second))
+
+	stored, err = GetRefreshTokenByHash(db, "hash-1")
+	assert.NoError(t, err)
+	assert.True(t, stored.UsedAt.Valid)
+
+	// The session slides forward with the new token
+	extended, err := GetSessionByID(db, "s1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, second.ExpiresAt, extended.ExpiresAt, time.Second)
+
+	// A token can only be exchanged once
+	third := &RefreshToken{SessionID: "s1", TokenHash: "hash-3", CreatedAt: later, ExpiresAt: later.Add(time.Hour)}
+	assert.Equal(t, ErrRefreshTokenUsed, RotateRefreshToken(db, stored, third))
+	missing, err := GetRefreshTokenByHash(db, "hash-3")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	// Pruning the expired session takes its refresh tokens with it
+	deleted, err := DeleteExpiredSessions(db, later.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	missing, err = GetRefreshTokenByHash(db, "hash-2")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
@@ -0,0 +1,162 @@
+package pubsub
+
+import (
+	"sync"
+)
+
+// Event represents a single message delivered to subscribers
+type Event struct {
+	ID   uint64
+	Type string
+	Data interface{}
+}
+
+// Publisher is implemented by anything that can broadcast events
+type Publisher interface {
+	Publish(eventType string, data interface{}) Event
+}
+
+// Subscriber receives events from a Broker through a bounded buffer
+type Subscriber struct {
+	events chan Event
+	once   sync.Once
+}
+
+// Events returns the channel events are delivered on. The channel is closed
+// when the subscriber is removed, either by Unsubscribe, by the broker
+// stopping, or because the subscriber fell too far behind.
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscriber) close() {
+	s.once.Do(func() {
+		close(s.events)
+	})
+}
+
+// Broker is an in-process pub/sub hub that fans events out to subscribers.
+// Publishing never blocks: a subscriber whose buffer is full is dropped so
+// it can reconnect and resume from its last event ID.
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	history     []Event
+	historySize int
+	bufferSize  int
+	nextID      uint64
+	stopped     bool
+}
+
+// NewBroker creates a broker that keeps historySize events for resuming
+// subscribers and gives each subscriber a buffer of bufferSize events
+func NewBroker(historySize, bufferSize int) *Broker {
+	if historySize < 0 {
+		historySize = 0
+	}
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Broker{
+		subscribers: make(map[*Subscriber]struct{}),
+		history:     make([]Event, 0, historySize),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish assigns the next event ID and delivers the event to all subscribers
+func (b *Broker) Publish(eventType string, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := Event{
+		ID:   b.nextID,
+		Type: eventType,
+		Data: data,
+	}
+
+	if b.stopped {
+		return event
+	}
+
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			copy(b.history, b.history[1:])
+			b.history = b.history[:len(b.history)-1]
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// Slow consumer, drop it rather than block the publisher
+			delete(b.subscribers, sub)
+			sub.close()
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a new subscriber. When lastEventID is non-zero, any
+// retained events newer than it are returned so the caller can replay them
+// before reading from the subscriber.
+func (b *Broker) Subscribe(lastEventID uint64) (*Subscriber, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscriber{
+		events: make(chan Event, b.bufferSize),
+	}
+
+	if b.stopped {
+		sub.close()
+		return sub, nil
+	}
+
+	var replay []Event
+	// An ID ahead of ours means the client saw a previous process; start fresh
+	if lastEventID > 0 && lastEventID <= b.nextID {
+		for _, event := range b.history {
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub, replay
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, sub)
+	sub.close()
+}
+
+// SubscriberCount returns the number of active subscribers
+func (b *Broker) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers)
+}
+
+// Stop disconnects all subscribers; later publishes are not delivered
+func (b *Broker) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		sub.close()
+	}
+}
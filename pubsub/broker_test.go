@@ -0,0 +1,109 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker_PublishDeliversToSubscribers(t *testing.T) {
+	broker := NewBroker(10, 4)
+	t.Cleanup(broker.Stop)
+
+	sub1, _ := broker.Subscribe(0)
+	sub2, _ := broker.Subscribe(0)
+
+	event := broker.Publish("comment.created", "hello")
+
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, event, <-sub1.Events())
+	assert.Equal(t, event, <-sub2.Events())
+}
+
+func TestBroker_SubscribeReplaysFromLastEventID(t *testing.T) {
+	broker := NewBroker(10, 4)
+	t.Cleanup(broker.Stop)
+
+	broker.Publish("comment.created", "first")
+	broker.Publish("comment.created", "second")
+	broker.Publish("comment.created", "third")
+
+	_, replay := broker.Subscribe(1)
+
+	assert.Len(t, replay, 2)
+	assert.Equal(t, "second", replay[0].Data)
+	assert.Equal(t, "third", replay[1].Data)
+}
+
+func TestBroker_SubscribeIgnoresUnknownLastEventID(t *testing.T) {
+	broker := NewBroker(10, 4)
+	t.Cleanup(broker.Stop)
+
+	broker.Publish("comment.created", "first")
+
+	// ID from a previous process run
+	_, replay := broker.Subscribe(42)
+
+	assert.Empty(t, replay)
+}
+
+func TestBroker_HistoryIsBounded(t *testing.T) {
+	broker := NewBroker(2, 4)
+	t.Cleanup(broker.Stop)
+
+	for i := 0; i < 5; i++ {
+		broker.Publish("comment.created", i)
+	}
+
+	_, replay := broker.Subscribe(1)
+
+	assert.Len(t, replay, 2)
+	assert.Equal(t, 3, replay[0].Data)
+	assert.Equal(t, 4, replay[1].Data)
+}
+
+func TestBroker_SlowSubscriberIsDropped(t *testing.T) {
+	broker := NewBroker(10, 1)
+	t.Cleanup(broker.Stop)
+
+	slow, _ := broker.Subscribe(0)
+
+	// Second publish overflows the buffer and must not block
+	broker.Publish("comment.created", "first")
+	broker.Publish("comment.created", "second")
+
+	assert.Equal(t, 0, broker.SubscriberCount())
+	event, ok := <-slow.Events()
+	assert.True(t, ok)
+	assert.Equal(t, "first", event.Data)
+	_, ok = <-slow.Events()
+	assert.False(t, ok)
+}
+
+func TestBroker_UnsubscribeClosesChannel(t *testing.T) {
+	broker := NewBroker(10, 4)
+	t.Cleanup(broker.Stop)
+
+	sub, _ := broker.Subscribe(0)
+	broker.Unsubscribe(sub)
+	// Unsubscribing twice must not panic
+	broker.Unsubscribe(sub)
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.Equal(t, 0, broker.SubscriberCount())
+}
+
+func TestBroker_StopClosesSubscribers(t *testing.T) {
+	broker := NewBroker(10, 4)
+	sub, _ := broker.Subscribe(0)
+
+	broker.Stop()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+
+	late, _ := broker.Subscribe(0)
+	_, ok = <-late.Events()
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"database/sql"
+	"wedding-invitation-backend/models"
+)
+
+// AdminRepository defines the interface for admin account data access
+type AdminRepository interface {
+	Create(admin *models.Admin) error
+	GetByUsername(username string) (*models.Admin, error)
+	GetByID(id int64) (*models.Admin, error)
+	GetAll() ([]models.Admin, error)
+	Count() (int, error)
+	CountByRole(role string) (int, error)
+	UpdateRole(id int64, role string) error
+	UpdatePassword(id int64, passwordHash string) error
+	RecordLogin(id int64) error
+	Delete(id int64) error
+}
+
+// SQLAdminRepository implements AdminRepository using SQL database
+type SQLAdminRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLAdminRepository creates a new SQL-based admin repository;
+// reads go to reader
+func NewSQLAdminRepository(db, reader *sql.DB) AdminRepository {
+	return &SQLAdminRepository{db: db, reader: reader}
+}
+
+func (r *SQLAdminRepository) Create(admin *models.Admin) error {
+	return admin.Create(r.db)
+}
+
+func (r *SQLAdminRepository) GetByUsername(username string) (*models.Admin, error) {
+	return models.GetAdminByUsername(r.reader, username)
+}
+
+func (r *SQLAdminRepository) GetByID(id int64) (*models.Admin, error) {
+	return models.GetAdminByID(r.reader, id)
+}
+
+func (r *SQLAdminRepository) GetAll() ([]models.Admin, error) {
+	return models.GetAllAdmins(r.reader)
+}
+
+func (r *SQLAdminRepository) Count() (int, error) {
+	return models.CountAdmins(r.reader)
+}
+
+func (r *SQLAdminRepository) CountByRole(role string) (int, error) {
+	return models.CountAdminsByRole(r.reader, role)
+}
+
+func (r *SQLAdminRepository) UpdateRole(id int64, role string) error {
+	return models.UpdateAdminRole(r.db, id, role)
+}
+
+func (r *SQLAdminRepository) UpdatePassword(id int64, passwordHash string) error {
+	return models.UpdateAdminPassword(r.db, id, passwordHash)
+}
+
+func (r *SQLAdminRepository) RecordLogin(id int64) error {
+	return models.RecordAdminLogin(r.db, id)
+}
+
+func (r *SQLAdminRepository) Delete(id int64) error {
+	return models.DeleteAdmin(r.db, id)
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"wedding-invitation-backend/models"
+)
+
+// APITokenRepository defines the interface for admin API token data access
+type APITokenRepository interface {
+	Create(token *models.APIToken) error
+	FindByID(id int64) (*models.APIToken, error)
+	FindByHash(tokenHash string) (*models.APIToken, error)
+	FindAll() ([]models.APIToken, error)
+	Revoke(id int64) error
+	MarkUsed(id int64, at time.Time) error
+}
+
+// SQLAPITokenRepository implements APITokenRepository using SQL database
+type SQLAPITokenRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLAPITokenRepository creates a new SQL-based API token repository;
+// reads go to reader
+func NewSQLAPITokenRepository(db, reader *sql.DB) APITokenRepository {
+	return &SQLAPITokenRepository{db: db, reader: reader}
+}
+
+func (r *SQLAPITokenRepository) Create(token *models.APIToken) error {
+	return token.Create(r.db)
+}
+
+func (r *SQLAPITokenRepository) FindByID(id int64) (*models.APIToken, error) {
+	return models.GetAPITokenByID(r.reader, id)
+}
+
+func (r *SQLAPITokenRepository) FindByHash(tokenHash string) (*models.APIToken, error) {
+	return models.GetAPITokenByHash(r.reader, tokenHash)
+}
+
+func (r *SQLAPITokenRepository) FindAll() ([]models.APIToken, error) {
+	return models.GetAllAPITokens(r.reader)
+}
+
+func (r *SQLAPITokenRepository) Revoke(id int64) error {
+	return models.RevokeAPIToken(r.db, id)
+}
+
+func (r *SQLAPITokenRepository) MarkUsed(id int64, at time.Time) error {
+	return models.MarkAPITokenUsed(r.db, id, at)
+}
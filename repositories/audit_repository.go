@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"wedding-invitation-backend/models"
+)
+
+// AuditRepository defines the interface for audit log data access
+type AuditRepository interface {
+	Create(entries []models.AuditEntry) error
+	CreateContext(ctx context.Context, entries []models.AuditEntry) error
+	Find(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error)
+	MaskFields(ctx context.Context, targetType string, fields []string) (int64, error)
+}
+
+// SQLAuditRepository implements AuditRepository using SQL database
+type SQLAuditRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLAuditRepository creates a new SQL-based audit repository;
+// reads go to reader
+func NewSQLAuditRepository(db, reader *sql.DB) AuditRepository {
+	return &SQLAuditRepository{db: db, reader: reader}
+}
+
+func (r *SQLAuditRepository) Create(entries []models.AuditEntry) error {
+	return models.CreateAuditEntries(r.db, entries)
+}
+
+// CreateContext stores entries like Create; inside a unit of work on the
+// same database they are part of its transaction
+func (r *SQLAuditRepository) CreateContext(ctx context.Context, entries []models.AuditEntry) error {
+	return models.CreateAuditEntriesContext(ctx, txConn(ctx, r.db, r.db), entries)
+}
+
+func (r *SQLAuditRepository) Find(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error) {
+	return models.GetAuditEntries(r.reader, filter, limit, cursor)
+}
+
+func (r *SQLAuditRepository) MaskFields(ctx context.Context, targetType string, fields []string) (int64, error) {
+	return models.MaskAuditFields(ctx, r.db, targetType, fields)
+}
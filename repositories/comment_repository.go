@@ -1,40 +1,78 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"wedding-invitation-backend/models"
 )
 
 // CommentRepository defines the interface for comment data access
 type CommentRepository interface {
-	Create(comment *models.Comment) error
-	GetByGuestID(guestID int64) ([]models.Comment, error)
-	GetAll() ([]models.Comment, error)
-	GetAllWithGuests(limit int, cursor string) (*models.PaginatedComments, error)
+	Create(ctx context.Context, comment *models.Comment) error
+	GetByGuestID(ctx context.Context, guestID int64) ([]models.Comment, error)
+	GetAll(ctx context.Context) ([]models.Comment, error)
+	GetAllWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error)
+	Search(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error)
+	GetByID(ctx context.Context, id int64) (*models.CommentWithGuest, error)
+	GetByStatus(ctx context.Context, status string) ([]models.CommentWithGuest, error)
+	UpdateStatus(ctx context.Context, id int64, status, reason string) error
+	HasDuplicate(ctx context.Context, guestID int64, content string) (bool, error)
+	GetPhotoStatus(ctx context.Context, key string) (string, error)
+	ClearPhotos(ctx context.Context, guestID int64) ([]string, error)
 }
 
 // SQLCommentRepository implements CommentRepository using SQL database
 type SQLCommentRepository struct {
-	db *sql.DB
+	db     models.DBTX
+	reader models.DBTX
 }
 
-// NewSQLCommentRepository creates a new SQL-based comment repository
-func NewSQLCommentRepository(db *sql.DB) CommentRepository {
-	return &SQLCommentRepository{db: db}
+// NewSQLCommentRepository creates a new SQL-based comment repository;
+// reads go to reader
+func NewSQLCommentRepository(db, reader *sql.DB) CommentRepository {
+	return &SQLCommentRepository{db: db, reader: reader}
 }
 
-func (r *SQLCommentRepository) Create(comment *models.Comment) error {
-	return comment.Create(r.db)
+func (r *SQLCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return comment.Create(ctx, txConn(ctx, r.db, r.db))
 }
 
-func (r *SQLCommentRepository) GetByGuestID(guestID int64) ([]models.Comment, error) {
-	return models.GetCommentsByGuestID(r.db, guestID)
+func (r *SQLCommentRepository) GetByGuestID(ctx context.Context, guestID int64) ([]models.Comment, error) {
+	return models.GetCommentsByGuestID(ctx, txConn(ctx, r.db, r.reader), guestID)
 }
 
-func (r *SQLCommentRepository) GetAll() ([]models.Comment, error) {
-	return models.GetAllComments(r.db)
+func (r *SQLCommentRepository) GetAll(ctx context.Context) ([]models.Comment, error) {
+	return models.GetAllComments(ctx, txConn(ctx, r.db, r.reader))
 }
 
-func (r *SQLCommentRepository) GetAllWithGuests(limit int, cursor string) (*models.PaginatedComments, error) {
-	return models.GetAllCommentsWithGuests(r.db, limit, cursor)
-}
\ No newline at end of file
+func (r *SQLCommentRepository) GetAllWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error) {
+	return models.GetAllCommentsWithGuests(ctx, txConn(ctx, r.db, r.reader), limit, cursor)
+}
+
+func (r *SQLCommentRepository) Search(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+	return models.SearchComments(ctx, txConn(ctx, r.db, r.reader), query, limit, cursor)
+}
+
+func (r *SQLCommentRepository) GetByID(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	return models.GetCommentWithGuestByID(ctx, txConn(ctx, r.db, r.reader), id)
+}
+
+func (r *SQLCommentRepository) GetByStatus(ctx context.Context, status string) ([]models.CommentWithGuest, error) {
+	return models.GetCommentsWithGuestsByStatus(ctx, txConn(ctx, r.db, r.reader), status)
+}
+
+func (r *SQLCommentRepository) UpdateStatus(ctx context.Context, id int64, status, reason string) error {
+	return models.UpdateCommentStatus(ctx, txConn(ctx, r.db, r.db), id, status, reason)
+}
+
+func (r *SQLCommentRepository) HasDuplicate(ctx context.Context, guestID int64, content string) (bool, error) {
+	return models.HasDuplicateComment(ctx, txConn(ctx, r.db, r.reader), guestID, content)
+}
+
+func (r *SQLCommentRepository) GetPhotoStatus(ctx context.Context, key string) (string, error) {
+	return models.GetCommentPhotoStatus(ctx, txConn(ctx, r.db, r.reader), key)
+}
+
+func (r *SQLCommentRepository) ClearPhotos(ctx context.Context, guestID int64) ([]string, error) {
+	return models.ClearCommentPhotos(ctx, txConn(ctx, r.db, r.db), guestID)
+}
@@ -0,0 +1,414 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+// The contract tests run the same cases against every GuestRepository and
+// CommentRepository implementation. Postgres is tested when
+// TEST_POSTGRES_URL points at a database the tests may wipe.
+
+type backend struct {
+	name string
+	open func(t *testing.T) (GuestRepository, CommentRepository)
+}
+
+func backends() []backend {
+	return []backend{
+		{"sqlite", openSQLiteBackend},
+		{"postgres", openPostgresBackend},
+		{"memory", openMemoryBackend},
+	}
+}
+
+// openSQLiteBackend uses a file with a read-only reader pool, as the server
+// does, so a write routed to the readers by mistake fails
+func openSQLiteBackend(t *testing.T) (GuestRepository, CommentRepository) {
+	db, reader := openSQLiteFile(t, 2)
+	return NewSQLGuestRepository(db, reader), NewSQLCommentRepository(db, reader)
+}
+
+// openSQLiteFile opens a database file with the current schema and a pool
+// of readers
+func openSQLiteFile(t testing.TB, readers int) (*sql.DB, *sql.DB) {
+	db, reader, err := database.OpenSQLite(filepath.Join(t.TempDir(), "guests.db"), readers, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		reader.Close()
+		db.Close()
+	})
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	return db, reader
+}
+
+func openMemoryBackend(t *testing.T) (GuestRepository, CommentRepository) {
+	guests := NewMemoryGuestRepository()
+	return guests, NewMemoryCommentRepository(guests)
+}
+
+func openPostgresBackend(t *testing.T) (GuestRepository, CommentRepository) {
+	db := openPostgresDB(t)
+	return NewPostgresGuestRepository(db), NewPostgresCommentRepository(db)
+}
+
+// openPostgresDB opens the TEST_POSTGRES_URL database migrated and emptied,
+// or skips the test when it is not set
+func openPostgresDB(t *testing.T) *sql.DB {
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL not set")
+	}
+	db, err := database.OpenPostgres(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewPostgresMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("TRUNCATE comments, guests RESTART IDENTITY"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// runContract runs a contract case against a fresh store of every backend
+func runContract(t *testing.T, test func(t *testing.T, guests GuestRepository, comments CommentRepository)) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			guests, comments := b.open(t)
+			test(t, guests, comments)
+		})
+	}
+}
+
+func createGuest(t *testing.T, guests GuestRepository, name string) *models.Guest {
+	t.Helper()
+	guest := &models.Guest{Name: name}
+	if err := guests.Create(context.Background(), guest); err != nil {
+		t.Fatal(err)
+	}
+	return guest
+}
+
+func createComment(t *testing.T, comments CommentRepository, guestID int64, content string) *models.Comment {
+	t.Helper()
+	comment := &models.Comment{GuestID: guestID, Content: content}
+	if err := comments.Create(context.Background(), comment); err != nil {
+		t.Fatal(err)
+	}
+	return comment
+}
+
+func TestGuestRepositoryContract_CreateAndGet(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, _ CommentRepository) {
+		guest := &models.Guest{
+			Name:                "Alice",
+			PlusOnes:            1,
+			DietaryRestrictions: sql.NullString{String: "vegetarian", Valid: true},
+		}
+		assert.NoError(t, guests.Create(context.Background(), guest))
+		assert.NotZero(t, guest.ID)
+
+		byID, err := guests.GetByID(context.Background(), guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Alice", byID.Name)
+		assert.Equal(t, 1, byID.PlusOnes)
+		assert.Equal(t, "vegetarian", byID.DietaryRestrictions.String)
+		// Guests start without a reply
+		assert.False(t, byID.Attending.Valid)
+		assert.False(t, byID.FirstOpenedAt.Valid)
+
+		byName, err := guests.GetByName(context.Background(), "Alice")
+		assert.NoError(t, err)
+		assert.Equal(t, guest.ID, byName.ID)
+
+		missing, err := guests.GetByID(context.Background(), guest.ID+100)
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+		missing, err = guests.GetByName(context.Background(), "Nobody")
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+}
+
+func TestGuestRepositoryContract_Update(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, _ CommentRepository) {
+		guest := createGuest(t, guests, "Bob")
+
+		guest.Attending = sql.NullBool{Bool: true, Valid: true}
+		guest.PlusOnes = 2
+		assert.NoError(t, guests.Update(context.Background(), guest))
+
+		stored, err := guests.GetByID(context.Background(), guest.ID)
+		assert.NoError(t, err)
+		assert.True(t, stored.Attending.Valid)
+		assert.True(t, stored.Attending.Bool)
+		assert.Equal(t, 2, stored.PlusOnes)
+
+		err = guests.Update(context.Background(), &models.Guest{ID: guest.ID + 100, Name: "Ghost"})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestGuestRepositoryContract_Bulk(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, _ CommentRepository) {
+		batch := []models.Guest{{Name: "Carol"}, {Name: "Dave"}}
+		assert.NoError(t, guests.BulkCreate(context.Background(), batch))
+		assert.NotZero(t, batch[0].ID)
+		assert.NotEqual(t, batch[0].ID, batch[1].ID)
+
+		all, err := guests.GetAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		// One unknown guest fails the whole batch
+		batch[0].PlusOnes = 3
+		unknown := models.Guest{ID: batch[1].ID + 100, Name: "Ghost"}
+		err = guests.BulkUpdate(context.Background(), []models.Guest{batch[0], unknown})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		stored, err := guests.GetByID(context.Background(), batch[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stored.PlusOnes)
+
+		assert.NoError(t, guests.BulkUpdate(context.Background(), batch))
+		stored, err = guests.GetByID(context.Background(), batch[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, stored.PlusOnes)
+	})
+}
+
+func TestGuestRepositoryContract_MarkInvitationOpenedOnce(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, _ CommentRepository) {
+		guest := createGuest(t, guests, "Erin")
+
+		assert.NoError(t, guests.MarkInvitationOpened(context.Background(), guest.ID))
+		first, err := guests.GetByID(context.Background(), guest.ID)
+		assert.NoError(t, err)
+		assert.True(t, first.FirstOpenedAt.Valid)
+
+		time.Sleep(1100 * time.Millisecond)
+		assert.NoError(t, guests.MarkInvitationOpened(context.Background(), guest.ID))
+		second, err := guests.GetByID(context.Background(), guest.ID)
+		assert.NoError(t, err)
+		assert.True(t, first.FirstOpenedAt.Time.Equal(second.FirstOpenedAt.Time))
+
+		// Unknown guests are ignored
+		assert.NoError(t, guests.MarkInvitationOpened(context.Background(), guest.ID+100))
+	})
+}
+
+func TestCommentRepositoryContract_CreateSetsCreatedAt(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		guest := createGuest(t, guests, "Zoe")
+		comment := createComment(t, comments, guest.ID, "Congratulations!")
+		assert.False(t, comment.CreatedAt.IsZero())
+
+		stored, err := comments.GetByID(context.Background(), comment.ID)
+		assert.NoError(t, err)
+		assert.True(t, comment.CreatedAt.Equal(stored.CreatedAt), "created %v, stored %v", comment.CreatedAt, stored.CreatedAt)
+	})
+}
+
+func TestCommentRepositoryContract_CreateEnforcesLimit(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		guest := createGuest(t, guests, "Frank")
+
+		for i := 0; i < config.MaxCommentsPerGuest; i++ {
+			comment := createComment(t, comments, guest.ID, "Message")
+			assert.NotZero(t, comment.ID)
+			assert.Equal(t, models.CommentStatusApproved, comment.Status)
+		}
+
+		err := comments.Create(context.Background(), &models.Comment{GuestID: guest.ID, Content: "One too many"})
+		assert.ErrorIs(t, err, models.ErrCommentLimitReached)
+
+		// Rejected comments free up a slot
+		own, err := comments.GetByGuestID(context.Background(), guest.ID)
+		assert.NoError(t, err)
+		assert.Len(t, own, config.MaxCommentsPerGuest)
+		assert.NoError(t, comments.UpdateStatus(context.Background(), own[0].ID, models.CommentStatusRejected, "spam"))
+		assert.NoError(t, comments.Create(context.Background(), &models.Comment{GuestID: guest.ID, Content: "Second try"}))
+	})
+}
+
+func TestCommentRepositoryContract_Moderation(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		guest := createGuest(t, guests, "Grace")
+		comment := &models.Comment{GuestID: guest.ID, Content: "Visit http://spam", Status: models.CommentStatusPending, ModerationReason: "link"}
+		assert.NoError(t, comments.Create(context.Background(), comment))
+
+		pending, err := comments.GetByStatus(context.Background(), models.CommentStatusPending)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, "Grace", pending[0].GuestName)
+		assert.Equal(t, "link", pending[0].ModerationReason)
+
+		assert.NoError(t, comments.UpdateStatus(context.Background(), comment.ID, models.CommentStatusApproved, ""))
+		stored, err := comments.GetByID(context.Background(), comment.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.CommentStatusApproved, stored.Status)
+		assert.Equal(t, "Grace", stored.GuestName)
+
+		missing, err := comments.GetByID(context.Background(), comment.ID+100)
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+		assert.ErrorIs(t, comments.UpdateStatus(context.Background(), comment.ID+100, models.CommentStatusApproved, ""), sql.ErrNoRows)
+
+		all, err := comments.GetAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+}
+
+func TestCommentRepositoryContract_HasDuplicate(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		guest := createGuest(t, guests, "Heidi")
+		other := createGuest(t, guests, "Ivan")
+		comment := createComment(t, comments, guest.ID, "Congratulations!")
+
+		duplicate, err := comments.HasDuplicate(context.Background(), guest.ID, "  congratulations! ")
+		assert.NoError(t, err)
+		assert.True(t, duplicate)
+
+		duplicate, err = comments.HasDuplicate(context.Background(), other.ID, "Congratulations!")
+		assert.NoError(t, err)
+		assert.False(t, duplicate)
+
+		// Rejected comments may be posted again
+		assert.NoError(t, comments.UpdateStatus(context.Background(), comment.ID, models.CommentStatusRejected, ""))
+		duplicate, err = comments.HasDuplicate(context.Background(), guest.ID, "Congratulations!")
+		assert.NoError(t, err)
+		assert.False(t, duplicate)
+	})
+}
+
+func TestCommentRepositoryContract_ClearPhotos(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		ctx := context.Background()
+		guest := createGuest(t, guests, "Leo")
+		other := createGuest(t, guests, "Mia")
+		assert.NoError(t, comments.Create(ctx, &models.Comment{GuestID: guest.ID, Content: "Photo!", PhotoKey: "leo.jpg"}))
+		createComment(t, comments, guest.ID, "No photo")
+		assert.NoError(t, comments.Create(ctx, &models.Comment{GuestID: other.ID, Content: "Mine", PhotoKey: "mia.jpg"}))
+
+		keys, err := comments.ClearPhotos(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"leo.jpg"}, keys)
+
+		kept, err := comments.GetByGuestID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Len(t, kept, 2, "the comments stay")
+		for _, comment := range kept {
+			assert.Empty(t, comment.PhotoKey)
+		}
+
+		keys, err = comments.ClearPhotos(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		others, err := comments.GetByGuestID(ctx, other.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "mia.jpg", others[0].PhotoKey)
+	})
+}
+
+func TestCommentRepositoryContract_GetPhotoStatus(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		ctx := context.Background()
+		guest := createGuest(t, guests, "Nina")
+		comment := &models.Comment{GuestID: guest.ID, Content: "Photo!", Status: models.CommentStatusPending, PhotoKey: "nina"}
+		assert.NoError(t, comments.Create(ctx, comment))
+
+		status, err := comments.GetPhotoStatus(ctx, "nina")
+		assert.NoError(t, err)
+		assert.Equal(t, models.CommentStatusPending, status)
+
+		assert.NoError(t, comments.UpdateStatus(ctx, comment.ID, models.CommentStatusApproved, ""))
+		status, err = comments.GetPhotoStatus(ctx, "nina")
+		assert.NoError(t, err)
+		assert.Equal(t, models.CommentStatusApproved, status)
+
+		status, err = comments.GetPhotoStatus(ctx, "unknown")
+		assert.NoError(t, err)
+		assert.Empty(t, status)
+	})
+}
+
+func TestCommentRepositoryContract_Pagination(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		guest := createGuest(t, guests, "Judy")
+		other := createGuest(t, guests, "Karl")
+		first := createComment(t, comments, guest.ID, "First")
+		// SQLite stores whole seconds, and the cursor is a time
+		time.Sleep(1100 * time.Millisecond)
+		second := createComment(t, comments, other.ID, "Second")
+		hidden := &models.Comment{GuestID: other.ID, Content: "Hidden", Status: models.CommentStatusPending}
+		assert.NoError(t, comments.Create(context.Background(), hidden))
+
+		page, err := comments.GetAllWithGuests(context.Background(), 1, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, page.TotalCount)
+		if assert.Len(t, page.Comments, 1) {
+			assert.Equal(t, second.ID, page.Comments[0].ID)
+			assert.Equal(t, "Karl", page.Comments[0].GuestName)
+		}
+		assert.NotEmpty(t, page.NextCursor)
+
+		page, err = comments.GetAllWithGuests(context.Background(), 1, page.NextCursor)
+		assert.NoError(t, err)
+		if assert.Len(t, page.Comments, 1) {
+			assert.Equal(t, first.ID, page.Comments[0].ID)
+		}
+		assert.Empty(t, page.NextCursor)
+	})
+}
+
+func TestCommentRepositoryContract_Search(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		guest := createGuest(t, guests, "Liam")
+		other := createGuest(t, guests, "Mia")
+		createComment(t, comments, guest.ID, "See you in Bali <3")
+		createComment(t, comments, other.ID, "Congratulations to you both")
+
+		results, err := comments.Search(context.Background(), "bal", 10, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, results.TotalCount)
+		if assert.Len(t, results.Results, 1) {
+			assert.Equal(t, "Liam", results.Results[0].GuestName)
+			assert.Contains(t, results.Results[0].Snippet, "<mark>Bali</mark>")
+			// Comment text is escaped in snippets
+			assert.False(t, strings.Contains(results.Results[0].Snippet, "<3"))
+		}
+
+		results, err = comments.Search(context.Background(), "you", 10, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, results.TotalCount)
+
+		_, err = comments.Search(context.Background(), "!!", 10, "")
+		assert.ErrorIs(t, err, models.ErrEmptySearchQuery)
+	})
+}
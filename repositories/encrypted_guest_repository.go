@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"wedding-invitation-backend/fieldcrypt"
+	"wedding-invitation-backend/models"
+)
+
+// DietaryRestrictionsField names the encrypted dietary restrictions column;
+// encrypted values are bound to it
+const DietaryRestrictionsField = "guests.dietary_restrictions"
+
+// encryptedGuestRepository encrypts the sensitive guest fields before they
+// reach the wrapped repository and decrypts them on the way back
+type encryptedGuestRepository struct {
+	inner GuestRepository
+	keys  *fieldcrypt.KeyRing
+}
+
+// NewEncryptedGuestRepository wraps inner so dietary restrictions are
+// stored encrypted with the current key of keys. Values stored in plain
+// text or with an older key are still read; the encryption reencrypt
+// command rewrites them.
+func NewEncryptedGuestRepository(inner GuestRepository, keys *fieldcrypt.KeyRing) GuestRepository {
+	return &encryptedGuestRepository{inner: inner, keys: keys}
+}
+
+func (r *encryptedGuestRepository) GetByName(ctx context.Context, name string) (*models.Guest, error) {
+	guest, err := r.inner.GetByName(ctx, name)
+	if err != nil || guest == nil {
+		return nil, err
+	}
+	return guest, r.decrypt(guest)
+}
+
+func (r *encryptedGuestRepository) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	guest, err := r.inner.GetByID(ctx, id)
+	if err != nil || guest == nil {
+		return nil, err
+	}
+	return guest, r.decrypt(guest)
+}
+
+func (r *encryptedGuestRepository) GetAll(ctx context.Context) ([]models.Guest, error) {
+	guests, err := r.inner.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range guests {
+		if err := r.decrypt(&guests[i]); err != nil {
+			return nil, err
+		}
+	}
+	return guests, nil
+}
+
+// Create and the other writes encrypt in place so the inner repository can
+// fill in IDs and timestamps as usual, then put the plain text back
+func (r *encryptedGuestRepository) Create(ctx context.Context, guest *models.Guest) error {
+	restore, err := r.encrypt(guest)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return r.inner.Create(ctx, guest)
+}
+
+func (r *encryptedGuestRepository) Update(ctx context.Context, guest *models.Guest) error {
+	restore, err := r.encrypt(guest)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return r.inner.Update(ctx, guest)
+}
+
+func (r *encryptedGuestRepository) BulkCreate(ctx context.Context, guests []models.Guest) error {
+	restore, err := r.encryptAll(guests)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return r.inner.BulkCreate(ctx, guests)
+}
+
+func (r *encryptedGuestRepository) BulkUpdate(ctx context.Context, guests []models.Guest) error {
+	restore, err := r.encryptAll(guests)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	return r.inner.BulkUpdate(ctx, guests)
+}
+
+func (r *encryptedGuestRepository) MarkInvitationOpened(ctx context.Context, guestID int64) error {
+	return r.inner.MarkInvitationOpened(ctx, guestID)
+}
+
+// encrypt replaces the guest's sensitive fields with their encrypted values
+// and returns a func that puts the plain text back
+func (r *encryptedGuestRepository) encrypt(guest *models.Guest) (func(), error) {
+	plaintext := guest.DietaryRestrictions
+	if plaintext.Valid {
+		value, err := r.keys.Encrypt(DietaryRestrictionsField, plaintext.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt dietary restrictions: %w", err)
+		}
+		guest.DietaryRestrictions.String = value
+	}
+	return func() { guest.DietaryRestrictions = plaintext }, nil
+}
+
+func (r *encryptedGuestRepository) encryptAll(guests []models.Guest) (func(), error) {
+	restores := make([]func(), 0, len(guests))
+	restoreAll := func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+	for i := range guests {
+		restore, err := r.encrypt(&guests[i])
+		if err != nil {
+			restoreAll()
+			return nil, err
+		}
+		restores = append(restores, restore)
+	}
+	return restoreAll, nil
+}
+
+func (r *encryptedGuestRepository) decrypt(guest *models.Guest) error {
+	if !guest.DietaryRestrictions.Valid {
+		return nil
+	}
+	plaintext, err := r.keys.Decrypt(DietaryRestrictionsField, guest.DietaryRestrictions.String)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt dietary restrictions of guest %d: %w", guest.ID, err)
+	}
+	guest.DietaryRestrictions.String = plaintext
+	return nil
+}
+
+// encryptedTxManager hands units of work encrypting guest repositories
+type encryptedTxManager struct {
+	inner TxManager
+	keys  *fieldcrypt.KeyRing
+}
+
+// NewEncryptedTxManager wraps inner so the guest repository of each unit of
+// work encrypts like NewEncryptedGuestRepository
+func NewEncryptedTxManager(inner TxManager, keys *fieldcrypt.KeyRing) TxManager {
+	return &encryptedTxManager{inner: inner, keys: keys}
+}
+
+func (m *encryptedTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	return m.inner.WithinTx(ctx, func(ctx context.Context, repos Repositories) error {
+		repos.Guests = NewEncryptedGuestRepository(repos.Guests, m.keys)
+		return fn(ctx, repos)
+	})
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"wedding-invitation-backend/fieldcrypt"
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKey(t *testing.T, id string) *fieldcrypt.Key {
+	t.Helper()
+	secret := make([]byte, fieldcrypt.KeySize)
+	_, err := rand.Read(secret)
+	assert.NoError(t, err)
+	key, err := fieldcrypt.NewKey(id, secret)
+	assert.NoError(t, err)
+	return key
+}
+
+func newTestKeyRing(t *testing.T, currentID string, keys ...*fieldcrypt.Key) *fieldcrypt.KeyRing {
+	t.Helper()
+	if len(keys) == 0 {
+		keys = []*fieldcrypt.Key{newTestKey(t, currentID)}
+	}
+	ring, err := fieldcrypt.NewKeyRing(currentID, keys)
+	assert.NoError(t, err)
+	return ring
+}
+
+func TestEncryptedGuestRepository(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		ctx := context.Background()
+		encrypted := NewEncryptedGuestRepository(guests, newTestKeyRing(t, "k1"))
+
+		guest := &models.Guest{Name: "alice", DietaryRestrictions: sql.NullString{String: "No nuts", Valid: true}}
+		assert.NoError(t, encrypted.Create(ctx, guest))
+		assert.NotZero(t, guest.ID)
+		assert.Equal(t, "No nuts", guest.DietaryRestrictions.String, "the caller keeps the plain text")
+
+		stored, err := guests.GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(stored.DietaryRestrictions.String, "enc:v1:k1:"))
+
+		read, err := encrypted.GetByName(ctx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, "No nuts", read.DietaryRestrictions.String)
+
+		batch := []models.Guest{
+			{Name: "bob", DietaryRestrictions: sql.NullString{String: "Vegan", Valid: true}},
+			{Name: "carol"},
+		}
+		assert.NoError(t, encrypted.BulkCreate(ctx, batch))
+		assert.Equal(t, "Vegan", batch[0].DietaryRestrictions.String)
+
+		all, err := encrypted.GetAll(ctx)
+		assert.NoError(t, err)
+		byName := make(map[string]models.Guest)
+		for _, g := range all {
+			byName[g.Name] = g
+		}
+		assert.Equal(t, "Vegan", byName["bob"].DietaryRestrictions.String)
+		assert.False(t, byName["carol"].DietaryRestrictions.Valid)
+	})
+}
+
+func TestEncryptedGuestRepository_ReadsPlainTextAndOldKeys(t *testing.T) {
+	ctx := context.Background()
+	guests, _ := openMemoryBackend(t)
+	createGuest(t, guests, "plain")
+	plain, err := guests.GetByName(ctx, "plain")
+	assert.NoError(t, err)
+	plain.DietaryRestrictions = sql.NullString{String: "Gluten free", Valid: true}
+	assert.NoError(t, guests.Update(ctx, plain))
+
+	oldKey, newKey := newTestKey(t, "old"), newTestKey(t, "new")
+	old := &models.Guest{Name: "old", DietaryRestrictions: sql.NullString{String: "Halal", Valid: true}}
+	assert.NoError(t, NewEncryptedGuestRepository(guests, newTestKeyRing(t, "old", oldKey)).Create(ctx, old))
+
+	encrypted := NewEncryptedGuestRepository(guests, newTestKeyRing(t, "new", oldKey, newKey))
+
+	read, err := encrypted.GetByName(ctx, "plain")
+	assert.NoError(t, err)
+	assert.Equal(t, "Gluten free", read.DietaryRestrictions.String)
+
+	read, err = encrypted.GetByName(ctx, "old")
+	assert.NoError(t, err)
+	assert.Equal(t, "Halal", read.DietaryRestrictions.String)
+
+	_, err = NewEncryptedGuestRepository(guests, newTestKeyRing(t, "new", newKey)).GetByName(ctx, "old")
+	assert.ErrorIs(t, err, fieldcrypt.ErrUnknownKey)
+}
+
+func TestEncryptedGuestRepository_NotFound(t *testing.T) {
+	runContract(t, func(t *testing.T, guests GuestRepository, comments CommentRepository) {
+		ctx := context.Background()
+		encrypted := NewEncryptedGuestRepository(guests, newTestKeyRing(t, "k1"))
+
+		guest, err := encrypted.GetByName(ctx, "nobody")
+		assert.NoError(t, err)
+		assert.Nil(t, guest)
+
+		guest, err = encrypted.GetByID(ctx, 404)
+		assert.NoError(t, err)
+		assert.Nil(t, guest)
+	})
+}
+
+func TestEncryptedTxManager(t *testing.T) {
+	runTxContract(t, func(t *testing.T, repos Repositories, tx TxManager) {
+		ctx := context.Background()
+		keys := newTestKeyRing(t, "k1")
+		guest := createGuest(t, repos.Guests, "alice")
+
+		err := NewEncryptedTxManager(tx, keys).WithinTx(ctx, func(ctx context.Context, unit Repositories) error {
+			guest.DietaryRestrictions = sql.NullString{String: "Kosher", Valid: true}
+			return unit.Guests.Update(ctx, guest)
+		})
+		assert.NoError(t, err)
+
+		stored, err := repos.Guests.GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", fieldcrypt.KeyID(stored.DietaryRestrictions.String))
+
+		read, err := NewEncryptedGuestRepository(repos.Guests, keys).GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Kosher", read.DietaryRestrictions.String)
+	})
+}
@@ -1,55 +1,64 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"wedding-invitation-backend/models"
 )
 
 // GuestRepository defines the interface for guest data access
 type GuestRepository interface {
-	GetByName(name string) (*models.Guest, error)
-	GetAll() ([]models.Guest, error)
-	Create(guest *models.Guest) error
-	Update(guest *models.Guest) error
-	BulkCreate(guests []models.Guest) error
-	BulkUpdate(guests []models.Guest) error
-	MarkInvitationOpened(name string) error
+	GetByName(ctx context.Context, name string) (*models.Guest, error)
+	GetByID(ctx context.Context, id int64) (*models.Guest, error)
+	GetAll(ctx context.Context) ([]models.Guest, error)
+	Create(ctx context.Context, guest *models.Guest) error
+	Update(ctx context.Context, guest *models.Guest) error
+	BulkCreate(ctx context.Context, guests []models.Guest) error
+	BulkUpdate(ctx context.Context, guests []models.Guest) error
+	MarkInvitationOpened(ctx context.Context, guestID int64) error
 }
 
 // SQLGuestRepository implements GuestRepository using SQL database
 type SQLGuestRepository struct {
-	db *sql.DB
+	db     models.DBTX
+	reader models.DBTX
 }
 
-// NewSQLGuestRepository creates a new SQL-based guest repository
-func NewSQLGuestRepository(db *sql.DB) GuestRepository {
-	return &SQLGuestRepository{db: db}
+// NewSQLGuestRepository creates a new SQL-based guest repository. Writes
+// go to db and plain reads to reader, which may be db itself. Calls made
+// inside a unit of work on db run in its transaction.
+func NewSQLGuestRepository(db, reader *sql.DB) GuestRepository {
+	return &SQLGuestRepository{db: db, reader: reader}
 }
 
-func (r *SQLGuestRepository) GetByName(name string) (*models.Guest, error) {
-	return models.GetGuestByName(r.db, name)
+func (r *SQLGuestRepository) GetByName(ctx context.Context, name string) (*models.Guest, error) {
+	return models.GetGuestByName(ctx, txConn(ctx, r.db, r.reader), name)
 }
 
-func (r *SQLGuestRepository) GetAll() ([]models.Guest, error) {
-	return models.GetAllGuests(r.db)
+func (r *SQLGuestRepository) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	return models.GetGuestByID(ctx, txConn(ctx, r.db, r.reader), id)
 }
 
-func (r *SQLGuestRepository) Create(guest *models.Guest) error {
-	return guest.Create(r.db)
+func (r *SQLGuestRepository) GetAll(ctx context.Context) ([]models.Guest, error) {
+	return models.GetAllGuests(ctx, txConn(ctx, r.db, r.reader))
 }
 
-func (r *SQLGuestRepository) Update(guest *models.Guest) error {
-	return guest.Update(r.db)
+func (r *SQLGuestRepository) Create(ctx context.Context, guest *models.Guest) error {
+	return guest.Create(ctx, txConn(ctx, r.db, r.db))
 }
 
-func (r *SQLGuestRepository) BulkCreate(guests []models.Guest) error {
-	return models.BulkCreate(r.db, guests)
+func (r *SQLGuestRepository) Update(ctx context.Context, guest *models.Guest) error {
+	return guest.Update(ctx, txConn(ctx, r.db, r.db))
 }
 
-func (r *SQLGuestRepository) BulkUpdate(guests []models.Guest) error {
-	return models.BulkUpdate(r.db, guests)
+func (r *SQLGuestRepository) BulkCreate(ctx context.Context, guests []models.Guest) error {
+	return models.BulkCreate(ctx, txConn(ctx, r.db, r.db), guests)
 }
 
-func (r *SQLGuestRepository) MarkInvitationOpened(name string) error {
-	return models.MarkInvitationOpened(r.db, name)
+func (r *SQLGuestRepository) BulkUpdate(ctx context.Context, guests []models.Guest) error {
+	return models.BulkUpdate(ctx, txConn(ctx, r.db, r.db), guests)
+}
+
+func (r *SQLGuestRepository) MarkInvitationOpened(ctx context.Context, guestID int64) error {
+	return models.MarkInvitationOpened(ctx, txConn(ctx, r.db, r.db), guestID)
 }
\ No newline at end of file
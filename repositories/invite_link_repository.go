@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"wedding-invitation-backend/models"
+)
+
+// InviteLinkRepository defines the interface for invitation link data access
+type InviteLinkRepository interface {
+	Create(links []models.InviteLink) error
+	FindByID(id string) (*models.InviteLink, error)
+	FindByGuestID(guestID int64) ([]models.InviteLink, error)
+	FindActive(now time.Time) ([]models.InviteLink, error)
+	Revoke(id string) error
+	MarkUsed(id string, at time.Time) error
+}
+
+// SQLInviteLinkRepository implements InviteLinkRepository using SQL database
+type SQLInviteLinkRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLInviteLinkRepository creates a new SQL-based invite link repository;
+// reads go to reader
+func NewSQLInviteLinkRepository(db, reader *sql.DB) InviteLinkRepository {
+	return &SQLInviteLinkRepository{db: db, reader: reader}
+}
+
+func (r *SQLInviteLinkRepository) Create(links []models.InviteLink) error {
+	return models.CreateInviteLinks(r.db, links)
+}
+
+func (r *SQLInviteLinkRepository) FindByID(id string) (*models.InviteLink, error) {
+	return models.GetInviteLinkByID(r.reader, id)
+}
+
+func (r *SQLInviteLinkRepository) FindByGuestID(guestID int64) ([]models.InviteLink, error) {
+	return models.GetInviteLinksByGuestID(r.reader, guestID)
+}
+
+func (r *SQLInviteLinkRepository) FindActive(now time.Time) ([]models.InviteLink, error) {
+	return models.GetActiveInviteLinks(r.reader, now)
+}
+
+func (r *SQLInviteLinkRepository) Revoke(id string) error {
+	return models.RevokeInviteLink(r.db, id)
+}
+
+func (r *SQLInviteLinkRepository) MarkUsed(id string, at time.Time) error {
+	return models.MarkInviteLinkUsed(r.db, id, at)
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"wedding-invitation-backend/models"
+)
+
+// LoginGuardRepository defines the interface for failed-login data access
+type LoginGuardRepository interface {
+	GetLockouts(keys ...string) ([]models.LoginLockout, error)
+	IncrementFailures(key string, now, resetBefore time.Time) (int, error)
+	Lock(key string, until time.Time) error
+	ClearLockout(key string) (bool, error)
+	RecordEvent(event *models.LoginEvent) error
+	GetSuspicious(since, now time.Time, minFailures int) (*models.SuspiciousLoginReport, error)
+	DeleteEventsBefore(before time.Time) (int64, error)
+}
+
+// SQLLoginGuardRepository implements LoginGuardRepository using SQL database
+type SQLLoginGuardRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLLoginGuardRepository creates a new SQL-based login guard repository;
+// reads go to reader
+func NewSQLLoginGuardRepository(db, reader *sql.DB) LoginGuardRepository {
+	return &SQLLoginGuardRepository{db: db, reader: reader}
+}
+
+func (r *SQLLoginGuardRepository) GetLockouts(keys ...string) ([]models.LoginLockout, error) {
+	return models.GetLoginLockouts(r.reader, keys...)
+}
+
+func (r *SQLLoginGuardRepository) IncrementFailures(key string, now, resetBefore time.Time) (int, error) {
+	return models.IncrementLoginFailures(r.db, key, now, resetBefore)
+}
+
+func (r *SQLLoginGuardRepository) Lock(key string, until time.Time) error {
+	return models.SetLoginLockedUntil(r.db, key, until)
+}
+
+func (r *SQLLoginGuardRepository) ClearLockout(key string) (bool, error) {
+	return models.DeleteLoginLockout(r.db, key)
+}
+
+func (r *SQLLoginGuardRepository) RecordEvent(event *models.LoginEvent) error {
+	return models.CreateLoginEvent(r.db, event)
+}
+
+func (r *SQLLoginGuardRepository) GetSuspicious(since, now time.Time, minFailures int) (*models.SuspiciousLoginReport, error) {
+	return models.GetSuspiciousLogins(r.reader, since, now, minFailures)
+}
+
+func (r *SQLLoginGuardRepository) DeleteEventsBefore(before time.Time) (int64, error) {
+	return models.DeleteLoginEventsBefore(r.db, before)
+}
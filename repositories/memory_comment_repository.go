@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+)
+
+// MemoryCommentRepository implements CommentRepository in memory, for tests
+// and demo mode. Guest names are looked up in the given guest repository.
+type MemoryCommentRepository struct {
+	mu       sync.RWMutex
+	comments []models.Comment
+	nextID   int64
+	guests   GuestRepository
+	now      func() time.Time
+}
+
+// NewMemoryCommentRepository creates an empty in-memory comment repository
+func NewMemoryCommentRepository(guests GuestRepository) CommentRepository {
+	return &MemoryCommentRepository{
+		nextID: 1,
+		guests: guests,
+		now:    time.Now,
+	}
+}
+
+// Create stores a comment unless the guest has reached the comment limit;
+// rejected comments do not count
+func (r *MemoryCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if comment.Status == "" {
+		comment.Status = models.CommentStatusApproved
+	}
+
+	count := 0
+	for _, c := range r.comments {
+		if c.GuestID == comment.GuestID && c.Status != models.CommentStatusRejected {
+			count++
+		}
+	}
+	if count >= config.MaxCommentsPerGuest {
+		return models.ErrCommentLimitReached
+	}
+
+	comment.ID = r.nextID
+	r.nextID++
+	comment.CreatedAt = r.now().UTC()
+	comment.SetPhotoURLs()
+	r.comments = append(r.comments, *comment)
+	return nil
+}
+
+func (r *MemoryCommentRepository) GetByGuestID(ctx context.Context, guestID int64) ([]models.Comment, error) {
+	return r.filter(func(c models.Comment) bool { return c.GuestID == guestID }), nil
+}
+
+func (r *MemoryCommentRepository) GetAll(ctx context.Context) ([]models.Comment, error) {
+	return r.filter(func(models.Comment) bool { return true }), nil
+}
+
+// GetAllWithGuests returns a page of approved comments, newest first. As on
+// the SQL repositories, the cursor is the time of the first comment of the
+// next page.
+func (r *MemoryCommentRepository) GetAllWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error) {
+	approved := r.filter(func(c models.Comment) bool { return c.Status == models.CommentStatusApproved })
+
+	page, nextCursor, err := paginate(approved, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := r.withGuests(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PaginatedComments{
+		Comments:   comments,
+		TotalCount: len(approved),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// Search matches comments whose words start with every query term, newest
+// first
+func (r *MemoryCommentRepository) Search(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+	terms := models.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, models.ErrEmptySearchQuery
+	}
+
+	matches := r.filter(func(c models.Comment) bool { return models.MatchesSearch(c.Content, terms) })
+	page, nextCursor, err := paginate(matches, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := r.withGuests(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]models.CommentSearchResult, len(comments))
+	for i, comment := range comments {
+		results[i] = models.CommentSearchResult{
+			CommentWithGuest: comment,
+			Snippet:          models.HighlightMatches(comment.Content, terms),
+		}
+	}
+	return &models.PaginatedSearchResults{
+		Results:    results,
+		TotalCount: len(matches),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (r *MemoryCommentRepository) GetByID(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	found := r.filter(func(c models.Comment) bool { return c.ID == id })
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	comments, err := r.withGuests(ctx, found)
+	if err != nil || len(comments) == 0 {
+		return nil, err
+	}
+	return &comments[0], nil
+}
+
+// GetByStatus returns the comments with a status, oldest first
+func (r *MemoryCommentRepository) GetByStatus(ctx context.Context, status string) ([]models.CommentWithGuest, error) {
+	found := r.filter(func(c models.Comment) bool { return c.Status == status })
+	for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+		found[i], found[j] = found[j], found[i]
+	}
+	return r.withGuests(ctx, found)
+}
+
+func (r *MemoryCommentRepository) UpdateStatus(ctx context.Context, id int64, status, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.comments {
+		if r.comments[i].ID == id {
+			r.comments[i].Status = status
+			r.comments[i].ModerationReason = reason
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// HasDuplicate reports whether the guest already posted the same content,
+// ignoring case and surrounding whitespace
+func (r *MemoryCommentRepository) HasDuplicate(ctx context.Context, guestID int64, content string) (bool, error) {
+	normalized := strings.ToLower(strings.TrimSpace(content))
+	found := r.filter(func(c models.Comment) bool {
+		return c.GuestID == guestID && c.Status != models.CommentStatusRejected &&
+			strings.ToLower(strings.TrimSpace(c.Content)) == normalized
+	})
+	return len(found) > 0, nil
+}
+
+// GetPhotoStatus returns the status of the comment with the photo, or "" if
+// no comment has it
+func (r *MemoryCommentRepository) GetPhotoStatus(ctx context.Context, key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, comment := range r.comments {
+		if comment.PhotoKey == key {
+			return comment.Status, nil
+		}
+	}
+	return "", nil
+}
+
+// ClearPhotos detaches the photos from the guest's comments and returns
+// their keys
+func (r *MemoryCommentRepository) ClearPhotos(ctx context.Context, guestID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := []string{}
+	for i := range r.comments {
+		if r.comments[i].GuestID == guestID && r.comments[i].PhotoKey != "" {
+			keys = append(keys, r.comments[i].PhotoKey)
+			r.comments[i].PhotoKey = ""
+		}
+	}
+	return keys, nil
+}
+
+// filter returns copies of the matching comments, newest first
+func (r *MemoryCommentRepository) filter(match func(models.Comment) bool) []models.Comment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var comments []models.Comment
+	for _, comment := range r.comments {
+		if match(comment) {
+			comments = append(comments, comment)
+		}
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.After(comments[j].CreatedAt)
+		}
+		return comments[i].ID > comments[j].ID
+	})
+	return comments
+}
+
+// withGuests adds guest names, dropping comments of unknown guests as the
+// SQL join does
+func (r *MemoryCommentRepository) withGuests(ctx context.Context, comments []models.Comment) ([]models.CommentWithGuest, error) {
+	var result []models.CommentWithGuest
+	for _, comment := range comments {
+		guest, err := r.guests.GetByID(ctx, comment.GuestID)
+		if err != nil {
+			return nil, err
+		}
+		if guest == nil {
+			continue
+		}
+		result = append(result, models.CommentWithGuest{Comment: comment, GuestName: guest.Name})
+	}
+	return result, nil
+}
+
+// paginate returns the page of newest-first comments starting at cursor and
+// the cursor of the next page
+func paginate(comments []models.Comment, limit int, cursor string) ([]models.Comment, string, error) {
+	if cursor != "" {
+		cursorTime, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := sort.Search(len(comments), func(i int) bool { return !comments[i].CreatedAt.After(cursorTime) })
+		comments = comments[start:]
+	}
+
+	if len(comments) <= limit {
+		return comments, "", nil
+	}
+	return comments[:limit], comments[limit].CreatedAt.Format(time.RFC3339Nano), nil
+}
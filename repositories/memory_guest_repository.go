@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"wedding-invitation-backend/models"
+)
+
+// MemoryGuestRepository implements GuestRepository in memory, for tests and
+// demo mode. It behaves like the SQL repositories but nothing is persisted.
+type MemoryGuestRepository struct {
+	mu     sync.RWMutex
+	guests map[int64]models.Guest
+	nextID int64
+	now    func() time.Time
+}
+
+// NewMemoryGuestRepository creates an empty in-memory guest repository
+func NewMemoryGuestRepository() GuestRepository {
+	return &MemoryGuestRepository{
+		guests: make(map[int64]models.Guest),
+		nextID: 1,
+		now:    time.Now,
+	}
+}
+
+func (r *MemoryGuestRepository) GetByName(ctx context.Context, name string) (*models.Guest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Like the SQL lookup, the first guest with the name wins
+	var found *models.Guest
+	for _, guest := range r.guests {
+		if guest.Name == name && (found == nil || guest.ID < found.ID) {
+			guest := guest
+			found = &guest
+		}
+	}
+	return found, nil
+}
+
+func (r *MemoryGuestRepository) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	guest, ok := r.guests[id]
+	if !ok {
+		return nil, nil
+	}
+	return &guest, nil
+}
+
+func (r *MemoryGuestRepository) GetAll(ctx context.Context) ([]models.Guest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var guests []models.Guest
+	for _, guest := range r.guests {
+		guests = append(guests, guest)
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].ID < guests[j].ID })
+	return guests, nil
+}
+
+func (r *MemoryGuestRepository) Create(ctx context.Context, guest *models.Guest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insert(guest)
+	return nil
+}
+
+func (r *MemoryGuestRepository) Update(ctx context.Context, guest *models.Guest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.update(guest)
+}
+
+func (r *MemoryGuestRepository) BulkCreate(ctx context.Context, guests []models.Guest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range guests {
+		r.insert(&guests[i])
+	}
+	return nil
+}
+
+// BulkUpdate updates every guest or, if one does not exist, none of them
+func (r *MemoryGuestRepository) BulkUpdate(ctx context.Context, guests []models.Guest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, guest := range guests {
+		if _, ok := r.guests[guest.ID]; !ok {
+			return sql.ErrNoRows
+		}
+	}
+	for i := range guests {
+		if err := r.update(&guests[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkInvitationOpened records the first time a guest opened the
+// invitation; later calls keep the original time
+func (r *MemoryGuestRepository) MarkInvitationOpened(ctx context.Context, guestID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	guest, ok := r.guests[guestID]
+	if !ok || guest.FirstOpenedAt.Valid {
+		return nil
+	}
+	guest.FirstOpenedAt = sql.NullTime{Time: r.now().UTC(), Valid: true}
+	r.guests[guestID] = guest
+	return nil
+}
+
+func (r *MemoryGuestRepository) insert(guest *models.Guest) {
+	now := r.now().UTC()
+	guest.ID = r.nextID
+	r.nextID++
+	guest.CreatedAt = now
+	guest.UpdatedAt = now
+	r.guests[guest.ID] = *guest
+}
+
+// update saves the fields the SQL repositories update
+func (r *MemoryGuestRepository) update(guest *models.Guest) error {
+	stored, ok := r.guests[guest.ID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	stored.Name = guest.Name
+	stored.Attending = guest.Attending
+	stored.PlusOnes = guest.PlusOnes
+	stored.DietaryRestrictions = guest.DietaryRestrictions
+	stored.UpdatedAt = r.now().UTC()
+	r.guests[guest.ID] = stored
+	return nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"wedding-invitation-backend/models"
+)
+
+// BenchmarkMixedLoad serves guestbook pages while one request in ten
+// updates a guest, as during an RSVP rush. With a single connection the
+// reads queue behind the writes; with a reader pool they run alongside.
+//
+//	go test ./repositories -run '^$' -bench MixedLoad
+func BenchmarkMixedLoad(b *testing.B) {
+	for _, readers := range []int{0, 4} {
+		name := "single-connection"
+		if readers > 0 {
+			name = fmt.Sprintf("reader-pool-%d", readers)
+		}
+		b.Run(name, func(b *testing.B) {
+			db, reader := openSQLiteFile(b, readers)
+			guests, comments := NewSQLGuestRepository(db, reader), NewSQLCommentRepository(db, reader)
+			seeded := seedBenchmarkGuestbook(b, guests, comments, 200)
+
+			var ops int64
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				ctx := context.Background()
+				for pb.Next() {
+					n := atomic.AddInt64(&ops, 1)
+					var err error
+					if n%10 == 0 {
+						guest := seeded[int(n/10)%len(seeded)]
+						guest.PlusOnes = int(n % 3)
+						err = guests.Update(ctx, &guest)
+					} else {
+						_, err = comments.GetAllWithGuests(ctx, 20, "")
+					}
+					if err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// seedBenchmarkGuestbook creates guests who have replied and each left a
+// comment
+func seedBenchmarkGuestbook(b *testing.B, guests GuestRepository, comments CommentRepository, count int) []models.Guest {
+	b.Helper()
+	ctx := context.Background()
+	seeded := make([]models.Guest, count)
+	for i := range seeded {
+		seeded[i] = models.Guest{
+			Name:      fmt.Sprintf("guest-%03d", i),
+			Attending: sql.NullBool{Bool: true, Valid: true},
+		}
+		if err := guests.Create(ctx, &seeded[i]); err != nil {
+			b.Fatal(err)
+		}
+		comment := &models.Comment{GuestID: seeded[i].ID, Content: fmt.Sprintf("Congratulations from guest %d!", i)}
+		if err := comments.Create(ctx, comment); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return seeded
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"wedding-invitation-backend/models"
+)
+
+// PostgresCommentRepository implements CommentRepository on PostgreSQL
+type PostgresCommentRepository struct {
+	db models.DBTX
+}
+
+// NewPostgresCommentRepository creates a new Postgres-based comment repository
+func NewPostgresCommentRepository(db *sql.DB) CommentRepository {
+	return &PostgresCommentRepository{db: db}
+}
+
+func (r *PostgresCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return models.PostgresCreateComment(ctx, txConn(ctx, r.db, r.db), comment)
+}
+
+func (r *PostgresCommentRepository) GetByGuestID(ctx context.Context, guestID int64) ([]models.Comment, error) {
+	return models.PostgresGetCommentsByGuestID(ctx, txConn(ctx, r.db, r.db), guestID)
+}
+
+func (r *PostgresCommentRepository) GetAll(ctx context.Context) ([]models.Comment, error) {
+	return models.PostgresGetAllComments(ctx, txConn(ctx, r.db, r.db))
+}
+
+func (r *PostgresCommentRepository) GetAllWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error) {
+	return models.PostgresGetAllCommentsWithGuests(ctx, txConn(ctx, r.db, r.db), limit, cursor)
+}
+
+func (r *PostgresCommentRepository) Search(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+	return models.PostgresSearchComments(ctx, txConn(ctx, r.db, r.db), query, limit, cursor)
+}
+
+func (r *PostgresCommentRepository) GetByID(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	return models.PostgresGetCommentWithGuestByID(ctx, txConn(ctx, r.db, r.db), id)
+}
+
+func (r *PostgresCommentRepository) GetByStatus(ctx context.Context, status string) ([]models.CommentWithGuest, error) {
+	return models.PostgresGetCommentsWithGuestsByStatus(ctx, txConn(ctx, r.db, r.db), status)
+}
+
+func (r *PostgresCommentRepository) UpdateStatus(ctx context.Context, id int64, status, reason string) error {
+	return models.PostgresUpdateCommentStatus(ctx, txConn(ctx, r.db, r.db), id, status, reason)
+}
+
+func (r *PostgresCommentRepository) HasDuplicate(ctx context.Context, guestID int64, content string) (bool, error) {
+	return models.PostgresHasDuplicateComment(ctx, txConn(ctx, r.db, r.db), guestID, content)
+}
+
+func (r *PostgresCommentRepository) GetPhotoStatus(ctx context.Context, key string) (string, error) {
+	return models.PostgresGetCommentPhotoStatus(ctx, txConn(ctx, r.db, r.db), key)
+}
+
+func (r *PostgresCommentRepository) ClearPhotos(ctx context.Context, guestID int64) ([]string, error) {
+	return models.PostgresClearCommentPhotos(ctx, txConn(ctx, r.db, r.db), guestID)
+}
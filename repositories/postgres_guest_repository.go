@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"wedding-invitation-backend/models"
+)
+
+// PostgresGuestRepository implements GuestRepository on PostgreSQL
+type PostgresGuestRepository struct {
+	db models.DBTX
+}
+
+// NewPostgresGuestRepository creates a new Postgres-based guest repository
+func NewPostgresGuestRepository(db *sql.DB) GuestRepository {
+	return &PostgresGuestRepository{db: db}
+}
+
+func (r *PostgresGuestRepository) GetByName(ctx context.Context, name string) (*models.Guest, error) {
+	return models.PostgresGetGuestByName(ctx, txConn(ctx, r.db, r.db), name)
+}
+
+func (r *PostgresGuestRepository) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	return models.PostgresGetGuestByID(ctx, txConn(ctx, r.db, r.db), id)
+}
+
+func (r *PostgresGuestRepository) GetAll(ctx context.Context) ([]models.Guest, error) {
+	return models.PostgresGetAllGuests(ctx, txConn(ctx, r.db, r.db))
+}
+
+func (r *PostgresGuestRepository) Create(ctx context.Context, guest *models.Guest) error {
+	return models.PostgresCreateGuest(ctx, txConn(ctx, r.db, r.db), guest)
+}
+
+func (r *PostgresGuestRepository) Update(ctx context.Context, guest *models.Guest) error {
+	return models.PostgresUpdateGuest(ctx, txConn(ctx, r.db, r.db), guest)
+}
+
+func (r *PostgresGuestRepository) BulkCreate(ctx context.Context, guests []models.Guest) error {
+	return models.PostgresBulkCreate(ctx, txConn(ctx, r.db, r.db), guests)
+}
+
+func (r *PostgresGuestRepository) BulkUpdate(ctx context.Context, guests []models.Guest) error {
+	return models.PostgresBulkUpdate(ctx, txConn(ctx, r.db, r.db), guests)
+}
+
+func (r *PostgresGuestRepository) MarkInvitationOpened(ctx context.Context, guestID int64) error {
+	return models.PostgresMarkInvitationOpened(ctx, txConn(ctx, r.db, r.db), guestID)
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"wedding-invitation-backend/models"
+)
+
+// PrivacyRepository reads and erases the records about a guest kept next
+// to the guests and comments
+type PrivacyRepository interface {
+	FindGuestRecords(ctx context.Context, guestID int64) (*models.GuestRecords, error)
+	EraseGuestRecords(ctx context.Context, guestID int64, name, lockoutKey string, auditFields []string) (*models.GuestErasure, error)
+}
+
+// SQLPrivacyRepository implements PrivacyRepository using SQL database
+type SQLPrivacyRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLPrivacyRepository creates a new SQL-based privacy repository; reads
+// go to reader. An erasure inside a unit of work on db runs in its
+// transaction.
+func NewSQLPrivacyRepository(db, reader *sql.DB) PrivacyRepository {
+	return &SQLPrivacyRepository{db: db, reader: reader}
+}
+
+func (r *SQLPrivacyRepository) FindGuestRecords(ctx context.Context, guestID int64) (*models.GuestRecords, error) {
+	return models.GetGuestRecords(r.reader, guestID)
+}
+
+func (r *SQLPrivacyRepository) EraseGuestRecords(ctx context.Context, guestID int64, name, lockoutKey string, auditFields []string) (*models.GuestErasure, error) {
+	return models.EraseGuestRecords(ctx, txConn(ctx, r.db, r.db), guestID, name, lockoutKey, auditFields)
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+	"wedding-invitation-backend/models"
+)
+
+// SessionRepository defines the interface for guest session data access
+type SessionRepository interface {
+	Create(session *models.Session, refreshToken *models.RefreshToken) error
+	FindByID(id string) (*models.Session, error)
+	FindByGuestID(guestID int64) ([]models.Session, error)
+	Revoke(id string) error
+	RevokeByGuestID(guestID int64) ([]string, error)
+	DeleteExpired(before time.Time) (int64, error)
+	FindRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(used, next *models.RefreshToken) error
+}
+
+// SQLSessionRepository implements SessionRepository using SQL database
+type SQLSessionRepository struct {
+	db     *sql.DB
+	reader *sql.DB
+}
+
+// NewSQLSessionRepository creates a new SQL-based session repository;
+// reads go to reader
+func NewSQLSessionRepository(db, reader *sql.DB) SessionRepository {
+	return &SQLSessionRepository{db: db, reader: reader}
+}
+
+func (r *SQLSessionRepository) Create(session *models.Session, refreshToken *models.RefreshToken) error {
+	return session.Create(r.db, refreshToken)
+}
+
+func (r *SQLSessionRepository) FindByID(id string) (*models.Session, error) {
+	return models.GetSessionByID(r.reader, id)
+}
+
+func (r *SQLSessionRepository) FindByGuestID(guestID int64) ([]models.Session, error) {
+	return models.GetSessionsByGuestID(r.reader, guestID)
+}
+
+func (r *SQLSessionRepository) Revoke(id string) error {
+	return models.RevokeSession(r.db, id)
+}
+
+func (r *SQLSessionRepository) RevokeByGuestID(guestID int64) ([]string, error) {
+	return models.RevokeSessionsByGuestID(r.db, guestID)
+}
+
+func (r *SQLSessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	return models.DeleteExpiredSessions(r.db, before)
+}
+
+func (r *SQLSessionRepository) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	return models.GetRefreshTokenByHash(r.reader, tokenHash)
+}
+
+func (r *SQLSessionRepository) RotateRefreshToken(used, next *models.RefreshToken) error {
+	return models.RotateRefreshToken(r.db, used, next)
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"wedding-invitation-backend/models"
+)
+
+// Repositories are the repository instances a unit of work runs on
+type Repositories struct {
+	Guests   GuestRepository
+	Comments CommentRepository
+}
+
+// TxManager runs units of work that span several repository calls. fn gets
+// repositories bound to one transaction, which is committed when fn returns
+// nil and rolled back when it returns an error or panics.
+//
+// A WithinTx call with a ctx that is already inside one of the manager's
+// units joins it instead of starting another, so services can call each
+// other freely. Repository calls made with that ctx outside of the given
+// repositories, for example through a cache, run in the transaction too.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
+
+type unitKey struct{}
+
+// unitOfWork is a running WithinTx call. Units of different managers nest
+// through outer.
+type unitOfWork struct {
+	manager TxManager
+	db      *sql.DB
+	tx      *sql.Tx
+	repos   Repositories
+	outer   *unitOfWork
+}
+
+// currentUnit returns the unit of work of manager ctx runs in, if any
+func currentUnit(ctx context.Context, manager TxManager) *unitOfWork {
+	unit, _ := ctx.Value(unitKey{}).(*unitOfWork)
+	for ; unit != nil; unit = unit.outer {
+		if unit.manager == manager {
+			return unit
+		}
+	}
+	return nil
+}
+
+// withUnit returns ctx inside unit
+func withUnit(ctx context.Context, unit *unitOfWork) context.Context {
+	unit.outer, _ = ctx.Value(unitKey{}).(*unitOfWork)
+	return context.WithValue(ctx, unitKey{}, unit)
+}
+
+// InTx reports whether ctx runs inside a unit of work. Caches use it to
+// avoid keeping data that may yet be rolled back.
+func InTx(ctx context.Context) bool {
+	return ctx.Value(unitKey{}) != nil
+}
+
+// txConn returns the transaction ctx runs in on db, or fallback when it
+// runs in none. On a single writer connection a query outside the
+// transaction would otherwise wait for it forever.
+func txConn(ctx context.Context, db, fallback models.DBTX) models.DBTX {
+	unit, _ := ctx.Value(unitKey{}).(*unitOfWork)
+	for ; unit != nil; unit = unit.outer {
+		if unit.db != nil && models.DBTX(unit.db) == db {
+			return unit.tx
+		}
+	}
+	return fallback
+}
+
+// sqlTxManager runs units of work in database transactions
+type sqlTxManager struct {
+	db   *sql.DB
+	bind func(tx *sql.Tx) Repositories
+}
+
+// NewSQLTxManager creates a transaction manager for the SQLite guest and
+// comment repositories; db must be the writer
+func NewSQLTxManager(db *sql.DB) TxManager {
+	return &sqlTxManager{db: db, bind: func(tx *sql.Tx) Repositories {
+		return Repositories{
+			Guests:   &SQLGuestRepository{db: tx, reader: tx},
+			Comments: &SQLCommentRepository{db: tx, reader: tx},
+		}
+	}}
+}
+
+// NewPostgresTxManager creates a transaction manager for the Postgres guest
+// and comment repositories. Units run at the database's default isolation
+// level, READ COMMITTED.
+func NewPostgresTxManager(db *sql.DB) TxManager {
+	return &sqlTxManager{db: db, bind: func(tx *sql.Tx) Repositories {
+		return Repositories{
+			Guests:   &PostgresGuestRepository{db: tx},
+			Comments: &PostgresCommentRepository{db: tx},
+		}
+	}}
+}
+
+func (m *sqlTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	if unit := currentUnit(ctx, m); unit != nil {
+		return fn(ctx, unit.repos)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	unit := &unitOfWork{manager: m, db: m.db, tx: tx, repos: m.bind(tx)}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(withUnit(ctx, unit), unit.repos); err != nil {
+		return err
+	}
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// memoryTxManager runs units of work on the in-memory repositories. Units
+// run one at a time and a failed unit puts back the data as it was when the
+// unit started, so writes made outside of units in the meantime are lost
+// as well; good enough for tests and demo mode.
+type memoryTxManager struct {
+	mu       sync.Mutex
+	guests   *MemoryGuestRepository
+	comments *MemoryCommentRepository
+}
+
+// NewMemoryTxManager creates a transaction manager for repositories made by
+// NewMemoryGuestRepository and NewMemoryCommentRepository
+func NewMemoryTxManager(guests GuestRepository, comments CommentRepository) TxManager {
+	return &memoryTxManager{
+		guests:   guests.(*MemoryGuestRepository),
+		comments: comments.(*MemoryCommentRepository),
+	}
+}
+
+func (m *memoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error {
+	repos := Repositories{Guests: m.guests, Comments: m.comments}
+	if currentUnit(ctx, m) != nil {
+		return fn(ctx, repos)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	restore := m.snapshot()
+
+	committed := false
+	defer func() {
+		if !committed {
+			restore()
+		}
+	}()
+
+	if err := fn(withUnit(ctx, &unitOfWork{manager: m, repos: repos}), repos); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// snapshot copies the repositories' data and returns a func that puts the
+// copy back
+func (m *memoryTxManager) snapshot() func() {
+	m.guests.mu.RLock()
+	guests := make(map[int64]models.Guest, len(m.guests.guests))
+	for id, guest := range m.guests.guests {
+		guests[id] = guest
+	}
+	nextGuestID := m.guests.nextID
+	m.guests.mu.RUnlock()
+
+	m.comments.mu.RLock()
+	comments := append([]models.Comment(nil), m.comments.comments...)
+	nextCommentID := m.comments.nextID
+	m.comments.mu.RUnlock()
+
+	return func() {
+		m.guests.mu.Lock()
+		m.guests.guests, m.guests.nextID = guests, nextGuestID
+		m.guests.mu.Unlock()
+
+		m.comments.mu.Lock()
+		m.comments.comments, m.comments.nextID = comments, nextCommentID
+		m.comments.mu.Unlock()
+	}
+}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runTxContract runs a transaction case against a fresh store of every
+// backend. The repositories are the plain ones, outside of any unit.
+func runTxContract(t *testing.T, test func(t *testing.T, repos Repositories, tx TxManager)) {
+	open := map[string]func(t *testing.T) (Repositories, TxManager){
+		"sqlite": func(t *testing.T) (Repositories, TxManager) {
+			db, reader := openSQLiteFile(t, 2)
+			repos := Repositories{NewSQLGuestRepository(db, reader), NewSQLCommentRepository(db, reader)}
+			return repos, NewSQLTxManager(db)
+		},
+		"sqlite-single-connection": func(t *testing.T) (Repositories, TxManager) {
+			db, reader := openSQLiteFile(t, 0)
+			repos := Repositories{NewSQLGuestRepository(db, reader), NewSQLCommentRepository(db, reader)}
+			return repos, NewSQLTxManager(db)
+		},
+		"postgres": func(t *testing.T) (Repositories, TxManager) {
+			db := openPostgresDB(t)
+			repos := Repositories{NewPostgresGuestRepository(db), NewPostgresCommentRepository(db)}
+			return repos, NewPostgresTxManager(db)
+		},
+		"memory": func(t *testing.T) (Repositories, TxManager) {
+			guests, comments := openMemoryBackend(t)
+			return Repositories{guests, comments}, NewMemoryTxManager(guests, comments)
+		},
+	}
+	for _, name := range []string{"sqlite", "sqlite-single-connection", "postgres", "memory"} {
+		t.Run(name, func(t *testing.T) {
+			repos, tx := open[name](t)
+			test(t, repos, tx)
+		})
+	}
+}
+
+func TestTxManager_Commit(t *testing.T) {
+	runTxContract(t, func(t *testing.T, repos Repositories, tx TxManager) {
+		ctx := context.Background()
+		guest := createGuest(t, repos.Guests, "alice")
+
+		err := tx.WithinTx(ctx, func(ctx context.Context, unit Repositories) error {
+			guest.Attending = sql.NullBool{Bool: true, Valid: true}
+			if err := unit.Guests.Update(ctx, guest); err != nil {
+				return err
+			}
+			return unit.Comments.Create(ctx, &models.Comment{GuestID: guest.ID, Content: "See you there!"})
+		})
+		assert.NoError(t, err)
+
+		stored, err := repos.Guests.GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.True(t, stored.Attending.Bool)
+		comments, err := repos.Comments.GetByGuestID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 1)
+	})
+}
+
+func TestTxManager_RollbackOnError(t *testing.T) {
+	runTxContract(t, func(t *testing.T, repos Repositories, tx TxManager) {
+		ctx := context.Background()
+		guest := createGuest(t, repos.Guests, "alice")
+		failure := errors.New("history write failed")
+
+		err := tx.WithinTx(ctx, func(ctx context.Context, unit Repositories) error {
+			guest.PlusOnes = 2
+			if err := unit.Guests.Update(ctx, guest); err != nil {
+				return err
+			}
+			if err := unit.Guests.Create(ctx, &models.Guest{Name: "bob"}); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		stored, err := repos.Guests.GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stored.PlusOnes)
+		all, err := repos.Guests.GetAll(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, all, 1)
+	})
+}
+
+func TestTxManager_RollbackOnPanic(t *testing.T) {
+	runTxContract(t, func(t *testing.T, repos Repositories, tx TxManager) {
+		ctx := context.Background()
+		guest := createGuest(t, repos.Guests, "alice")
+
+		assert.PanicsWithValue(t, "boom", func() {
+			tx.WithinTx(ctx, func(ctx context.Context, unit Repositories) error {
+				guest.PlusOnes = 2
+				if err := unit.Guests.Update(ctx, guest); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		})
+
+		stored, err := repos.Guests.GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stored.PlusOnes)
+
+		// The connection was given back and the store still takes writes
+		assert.NoError(t, tx.WithinTx(ctx, func(ctx context.Context, unit Repositories) error {
+			return unit.Guests.Create(ctx, &models.Guest{Name: "bob"})
+		}))
+	})
+}
+
+func TestTxManager_NestedCallsJoin(t *testing.T) {
+	runTxContract(t, func(t *testing.T, repos Repositories, tx TxManager) {
+		ctx := context.Background()
+		guest := createGuest(t, repos.Guests, "alice")
+		failure := errors.New("outer failed")
+
+		err := tx.WithinTx(ctx, func(ctx context.Context, unit Repositories) error {
+			err := tx.WithinTx(ctx, func(ctx context.Context, inner Repositories) error {
+				guest.PlusOnes = 1
+				return inner.Guests.Update(ctx, guest)
+			})
+			if err != nil {
+				return err
+			}
+
+			// Plain repositories called with the unit's context see its
+			// writes instead of waiting for it to finish
+			stored, err := repos.Guests.GetByID(ctx, guest.ID)
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, 1, stored.PlusOnes)
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		// The inner call did not commit on its own
+		stored, err := repos.Guests.GetByID(ctx, guest.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, stored.PlusOnes)
+		assert.False(t, InTx(ctx))
+	})
+}
@@ -0,0 +1,258 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/export"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAdminCommentRoutes(r *gin.RouterGroup, c *container.Container) {
+	commentGroup := r.Group("/comments")
+	{
+		read := adminauth.RequireScope(models.ScopeCommentsModerate, models.AdminRoles...)
+		commentGroup.GET("/search", read, handleSearchComments(c))
+		commentGroup.GET("/pending", read, handleGetPendingComments(c))
+		commentGroup.GET("/export", read, handleExportComments(c))
+
+		moderate := adminauth.RequireScope(models.ScopeCommentsModerate, models.AdminRoleModerator)
+		commentGroup.POST("/:id/approve", moderate, handleApproveComment(c))
+		commentGroup.POST("/:id/reject", moderate, handleRejectComment(c))
+	}
+}
+
+type rejectCommentRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+const (
+	defaultExportTitle = "Our Guestbook"
+	maxExportTitle     = 200
+)
+
+func handleSearchComments(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := strings.TrimSpace(c.Query("q"))
+		if query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Please provide a search term.",
+			})
+			return
+		}
+
+		cursor := c.Query("cursor")
+		if cursor != "" {
+			if _, err := time.Parse(time.RFC3339, cursor); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid pagination cursor.",
+				})
+				return
+			}
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit <= 0 || limit > 100 {
+			limit = 20 // Default limit
+		}
+
+		results, err := container.CommentService.SearchComments(c.Request.Context(), query, limit, cursor)
+		if err != nil {
+			if errors.Is(err, models.ErrEmptySearchQuery) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "The search term must contain letters or numbers.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to search comments right now. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+func handleGetPendingComments(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comments, err := container.CommentService.GetPendingComments(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load comments awaiting review. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"count":    len(comments),
+			"comments": comments,
+		})
+	}
+}
+
+func handleApproveComment(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid comment ID.",
+			})
+			return
+		}
+
+		before, err := container.CommentService.GetCommentByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to approve the comment. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		comment, err := container.CommentService.ApproveComment(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to approve the comment. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if comment == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Comment not found.",
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionCommentApprove, models.AuditTargetComment,
+			services.AuditChange{TargetID: auditTargetID(id), Before: before, After: comment})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Comment approved.",
+			"comment": comment,
+		})
+	}
+}
+
+func handleRejectComment(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid comment ID.",
+			})
+			return
+		}
+
+		// The body is optional; a missing reason is fine
+		var req rejectCommentRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Please provide a valid rejection reason.",
+				})
+				return
+			}
+		}
+
+		before, err := container.CommentService.GetCommentByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to reject the comment. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		comment, err := container.CommentService.RejectComment(c.Request.Context(), id, strings.TrimSpace(req.Reason))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to reject the comment. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if comment == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Comment not found.",
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionCommentReject, models.AuditTargetComment,
+			services.AuditChange{TargetID: auditTargetID(id), Before: before, After: comment})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Comment rejected.",
+			"comment": comment,
+		})
+	}
+}
+
+func handleExportComments(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		formatName := c.Query("format")
+		if formatName == "" {
+			formatName = string(export.FormatJSON)
+		}
+		format, err := export.ParseFormat(formatName)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unsupported export format. Use json, csv, html or pdf.",
+			})
+			return
+		}
+
+		title := strings.TrimSpace(c.Query("title"))
+		if title == "" {
+			title = defaultExportTitle
+		}
+		if titleRunes := []rune(title); len(titleRunes) > maxExportTitle {
+			title = string(titleRunes[:maxExportTitle])
+		}
+
+		comments, err := container.CommentService.GetGuestbookForExport(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load the guestbook. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		book := &export.Guestbook{
+			Title:       title,
+			GeneratedAt: time.Now(),
+			Comments:    comments,
+		}
+
+		// Render fully before responding so a failure can still return an error
+		var buf bytes.Buffer
+		if err := export.Write(&buf, format, book); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to create the guestbook export. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		filename := fmt.Sprintf("guestbook-%s.%s", book.GeneratedAt.Format("20060102"), format)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Data(http.StatusOK, format.ContentType(), buf.Bytes())
+	}
+}
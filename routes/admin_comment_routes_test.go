@@ -0,0 +1,224 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+)
+
+func TestSearchComments_Success(t *testing.T) {
+	mockComment := &mockCommentService{
+		SearchCommentsFunc: func(query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+			assert.Equal(t, "bali trip", query)
+			assert.Equal(t, 5, limit)
+			return &models.PaginatedSearchResults{
+				Results: []models.CommentSearchResult{{
+					CommentWithGuest: *createTestComment(1, "alice", "See you in Bali"),
+					Snippet:          "See you in <mark>Bali</mark>",
+				}},
+				TotalCount: 1,
+			}, nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/search?q=bali+trip&limit=5", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total_count":1`)
+	assert.Contains(t, w.Body.String(), `\u003cmark\u003eBali\u003c/mark\u003e`)
+}
+
+func TestSearchComments_MissingQuery(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/search", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchComments_EmptyTerms(t *testing.T) {
+	mockComment := &mockCommentService{
+		SearchCommentsFunc: func(query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+			return nil, models.ErrEmptySearchQuery
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/search?q=%22%2A", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "letters or numbers")
+}
+
+func TestGetPendingComments(t *testing.T) {
+	mockComment := &mockCommentService{
+		GetPendingCommentsFunc: func() ([]models.CommentWithGuest, error) {
+			return []models.CommentWithGuest{*createTestComment(3, "bob", "www.spam.com")}, nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/pending", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+}
+
+func TestApproveComment_Success(t *testing.T) {
+	mockComment := &mockCommentService{
+		ApproveCommentFunc: func(id int64) (*models.CommentWithGuest, error) {
+			assert.Equal(t, int64(3), id)
+			comment := createTestComment(id, "bob", "Hello")
+			comment.Status = models.CommentStatusApproved
+			return comment, nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/comments/3/approve", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Comment approved")
+}
+
+func TestApproveComment_NotFound(t *testing.T) {
+	mockComment := &mockCommentService{}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/comments/99/approve", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRejectComment_WithReason(t *testing.T) {
+	mockComment := &mockCommentService{
+		RejectCommentFunc: func(id int64, reason string) (*models.CommentWithGuest, error) {
+			assert.Equal(t, "spam", reason)
+			return createTestComment(id, "bob", "Hello"), nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/comments/3/reject", strings.NewReader(`{"reason":" spam "}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRejectComment_InvalidID(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/comments/abc/reject", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportComments_Formats(t *testing.T) {
+	mockComment := &mockCommentService{
+		GetGuestbookForExportFunc: func() ([]models.CommentWithGuest, error) {
+			return []models.CommentWithGuest{*createTestComment(1, "alice", "Happy wedding day!")}, nil
+		},
+	}
+
+	tests := []struct {
+		format      string
+		contentType string
+		extension   string
+		body        string
+	}{
+		{"", "application/json; charset=utf-8", ".json", `"count": 1`},
+		{"csv", "text/csv; charset=utf-8", ".csv", "Happy wedding day!"},
+		{"html", "text/html; charset=utf-8", ".html", "<h1>Our Guestbook</h1>"},
+		{"pdf", "application/pdf", ".pdf", "%PDF-1.4"},
+	}
+
+	for _, tt := range tests {
+		router, w := setupTestRouter(nil, mockComment, nil)
+		c := setupTestContainer(nil, mockComment, nil)
+		SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+		req := httptest.NewRequest("GET", "/admin/comments/export?format="+tt.format, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.format)
+		assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"), tt.format)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), tt.extension+`"`, tt.format)
+		assert.Contains(t, w.Body.String(), tt.body, tt.format)
+	}
+}
+
+func TestExportComments_CustomTitle(t *testing.T) {
+	router, w := setupTestRouter(nil, &mockCommentService{}, nil)
+	c := setupTestContainer(nil, &mockCommentService{}, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/export?format=html&title=Rina+%26+Budi", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<h1>Rina &amp; Budi</h1>")
+}
+
+func TestExportComments_InvalidFormat(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/export?format=docx", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportComments_ServiceError(t *testing.T) {
+	mockComment := &mockCommentService{
+		GetGuestbookForExportFunc: func() ([]models.CommentWithGuest, error) {
+			return nil, errors.New("database error")
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/comments/export?format=pdf", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
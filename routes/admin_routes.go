@@ -0,0 +1,290 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	ratelimitmw "wedding-invitation-backend/middleware/ratelimit"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type createAdminRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+type updateAdminRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// SetupAdminAuthRoutes registers the admin login, which sits outside the
+// authenticated /admin group
+func SetupAdminAuthRoutes(r *gin.Engine, c *container.Container) {
+	r.POST("/admin/login",
+		ratelimitmw.Middleware(c.AuthLimiter),
+		handleAdminLogin(c),
+	)
+}
+
+func SetupAdminAccountRoutes(r *gin.RouterGroup, c *container.Container) {
+	r.GET("/me", adminauth.AnyRole(), handleGetCurrentAdmin())
+	r.PUT("/me/password", adminauth.AnyRole(), handleChangeOwnPassword(c))
+
+	// Only owners manage the committee's accounts
+	adminGroup := r.Group("/admins", adminauth.RequireRole(models.AdminRoleOwner))
+	{
+		adminGroup.GET("", handleListAdmins(c))
+		adminGroup.POST("", handleCreateAdmin(c))
+		adminGroup.PUT("/:id/role", handleUpdateAdminRole(c))
+		adminGroup.DELETE("/:id", handleDeleteAdmin(c))
+	}
+}
+
+func handleAdminLogin(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req adminLoginRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Please enter your username and password.",
+			})
+			return
+		}
+
+		admin, err := container.AdminService.Authenticate(req.Username, req.Password)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidCredentials) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Incorrect username or password.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "We're having trouble signing you in. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		token, expiresAt, err := adminauth.GenerateToken(admin)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "We're experiencing technical difficulties. Please try logging in again.",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"token":      token,
+			"expires_at": expiresAt,
+			"admin":      admin,
+		})
+	}
+}
+
+func handleGetCurrentAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"admin": adminauth.CurrentAdmin(c)})
+	}
+}
+
+func handleChangeOwnPassword(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req changePasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Please enter your current and new password.",
+			})
+			return
+		}
+
+		current := adminauth.CurrentAdmin(c)
+
+		// Re-check the current password so a stolen token cannot take over the account
+		if _, err := container.AdminService.Authenticate(current.Username, req.CurrentPassword); err != nil {
+			if errors.Is(err, services.ErrInvalidCredentials) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Your current password is incorrect.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to change your password. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if _, err := container.AdminService.ChangePassword(current.ID, req.NewPassword); err != nil {
+			respondAdminError(c, err, "Unable to change your password. Please try again.")
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionAdminPasswordChange, models.AuditTargetAdmin,
+			services.AuditChange{TargetID: auditTargetID(current.ID)})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password changed."})
+	}
+}
+
+func handleListAdmins(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admins, err := container.AdminService.GetAllAdmins()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load admin accounts. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"count":  len(admins),
+			"admins": admins,
+		})
+	}
+}
+
+func handleCreateAdmin(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createAdminRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Please provide a username, password and role.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		admin, err := container.AdminService.CreateAdmin(req.Username, req.Password, req.Role)
+		if err != nil {
+			respondAdminError(c, err, "Unable to create the admin account. Please try again.")
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionAdminCreate, models.AuditTargetAdmin,
+			services.AuditChange{TargetID: auditTargetID(admin.ID), After: admin})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Admin account created.",
+			"admin":   admin,
+		})
+	}
+}
+
+func handleUpdateAdminRole(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid admin ID.",
+			})
+			return
+		}
+
+		var req updateAdminRoleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Please provide a role.",
+			})
+			return
+		}
+
+		before, err := container.AdminService.GetAdminByID(id)
+		if err != nil {
+			respondAdminError(c, err, "Unable to change the role. Please try again.")
+			return
+		}
+
+		admin, err := container.AdminService.UpdateAdminRole(id, req.Role)
+		if err != nil {
+			respondAdminError(c, err, "Unable to change the role. Please try again.")
+			return
+		}
+
+		if admin == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Admin not found.",
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionAdminRoleChange, models.AuditTargetAdmin,
+			services.AuditChange{TargetID: auditTargetID(id), Before: before, After: admin})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Role updated.",
+			"admin":   admin,
+		})
+	}
+}
+
+func handleDeleteAdmin(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid admin ID.",
+			})
+			return
+		}
+
+		admin, err := container.AdminService.DeleteAdmin(id)
+		if err != nil {
+			respondAdminError(c, err, "Unable to delete the admin account. Please try again.")
+			return
+		}
+
+		if admin == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Admin not found.",
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionAdminDelete, models.AuditTargetAdmin,
+			services.AuditChange{TargetID: auditTargetID(id), Before: admin})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Admin account deleted.",
+			"admin":   admin,
+		})
+	}
+}
+
+// respondAdminError maps admin service validation errors to client errors
+func respondAdminError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAdminUsername):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Usernames must be 3-64 characters without spaces."})
+	case errors.Is(err, services.ErrInvalidAdminRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be owner, planner, moderator or viewer."})
+	case errors.Is(err, services.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passwords must be at least 10 characters."})
+	case errors.Is(err, models.ErrAdminExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "That username is already taken."})
+	case errors.Is(err, services.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "At least one owner account is required."})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockAdminService implements services.AdminServiceInterface for testing
+type mockAdminService struct {
+	AuthenticateFunc       func(username, password string) (*models.Admin, error)
+	CreateAdminFunc        func(username, password, role string) (*models.Admin, error)
+	BootstrapOwnerFunc     func(username, password string) (*models.Admin, bool, error)
+	GetAdminByIDFunc       func(id int64) (*models.Admin, error)
+	GetAdminByUsernameFunc func(username string) (*models.Admin, error)
+	GetAllAdminsFunc       func() ([]models.Admin, error)
+	UpdateAdminRoleFunc    func(id int64, role string) (*models.Admin, error)
+	ChangePasswordFunc     func(id int64, password string) (bool, error)
+	DeleteAdminFunc        func(id int64) (*models.Admin, error)
+}
+
+func (m *mockAdminService) Authenticate(username, password string) (*models.Admin, error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(username, password)
+	}
+	return nil, services.ErrInvalidCredentials
+}
+
+func (m *mockAdminService) CreateAdmin(username, password, role string) (*models.Admin, error) {
+	if m.CreateAdminFunc != nil {
+		return m.CreateAdminFunc(username, password, role)
+	}
+	return nil, nil
+}
+
+func (m *mockAdminService) BootstrapOwner(username, password string) (*models.Admin, bool, error) {
+	if m.BootstrapOwnerFunc != nil {
+		return m.BootstrapOwnerFunc(username, password)
+	}
+	return nil, false, nil
+}
+
+func (m *mockAdminService) GetAdminByID(id int64) (*models.Admin, error) {
+	if m.GetAdminByIDFunc != nil {
+		return m.GetAdminByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockAdminService) GetAdminByUsername(username string) (*models.Admin, error) {
+	if m.GetAdminByUsernameFunc != nil {
+		return m.GetAdminByUsernameFunc(username)
+	}
+	return nil, nil
+}
+
+func (m *mockAdminService) GetAllAdmins() ([]models.Admin, error) {
+	if m.GetAllAdminsFunc != nil {
+		return m.GetAllAdminsFunc()
+	}
+	return []models.Admin{}, nil
+}
+
+func (m *mockAdminService) UpdateAdminRole(id int64, role string) (*models.Admin, error) {
+	if m.UpdateAdminRoleFunc != nil {
+		return m.UpdateAdminRoleFunc(id, role)
+	}
+	return nil, nil
+}
+
+func (m *mockAdminService) ChangePassword(id int64, password string) (bool, error) {
+	if m.ChangePasswordFunc != nil {
+		return m.ChangePasswordFunc(id, password)
+	}
+	return false, nil
+}
+
+func (m *mockAdminService) DeleteAdmin(id int64) (*models.Admin, error) {
+	if m.DeleteAdminFunc != nil {
+		return m.DeleteAdminFunc(id)
+	}
+	return nil, nil
+}
+
+// setupRoleTestRouter creates a router whose requests act as an admin with the given role
+func setupRoleTestRouter(role string) (*gin.Engine, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(actAsAdmin(role))
+	return router, w
+}
+
+func TestAdminLogin_Success(t *testing.T) {
+	mockAdmin := &mockAdminService{
+		AuthenticateFunc: func(username, password string) (*models.Admin, error) {
+			assert.Equal(t, "alice", username)
+			assert.Equal(t, "correct horse battery", password)
+			return &models.Admin{ID: 1, Username: "alice", PasswordHash: "secret-hash", Role: models.AdminRoleOwner}, nil
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = mockAdmin
+	SetupAdminAuthRoutes(router, c)
+
+	body := `{"username":"alice","password":"correct horse battery"}`
+	req := httptest.NewRequest("POST", "/admin/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response["token"])
+	assert.NotEmpty(t, response["expires_at"])
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestAdminLogin_InvalidCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = &mockAdminService{}
+	SetupAdminAuthRoutes(router, c)
+
+	body := `{"username":"alice","password":"wrong"}`
+	req := httptest.NewRequest("POST", "/admin/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Incorrect username or password")
+}
+
+func TestGetCurrentAdmin(t *testing.T) {
+	router, w := setupRoleTestRouter(models.AdminRoleViewer)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/me", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Role":"viewer"`)
+}
+
+func TestChangeOwnPassword(t *testing.T) {
+	changed := false
+	mockAdmin := &mockAdminService{
+		AuthenticateFunc: func(username, password string) (*models.Admin, error) {
+			if password != "old password 123" {
+				return nil, services.ErrInvalidCredentials
+			}
+			return &models.Admin{ID: 1, Username: username}, nil
+		},
+		ChangePasswordFunc: func(id int64, password string) (bool, error) {
+			assert.Equal(t, int64(1), id)
+			changed = true
+			return true, nil
+		},
+	}
+
+	router, _ := setupRoleTestRouter(models.AdminRolePlanner)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = mockAdmin
+	SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+	body := `{"current_password":"wrong","new_password":"new password 456"}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/admin/me/password", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, changed)
+
+	body = `{"current_password":"old password 123","new_password":"new password 456"}`
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("PUT", "/admin/me/password", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, changed)
+}
+
+func TestCreateAdmin_Success(t *testing.T) {
+	mockAdmin := &mockAdminService{
+		CreateAdminFunc: func(username, password, role string) (*models.Admin, error) {
+			return &models.Admin{ID: 2, Username: username, Role: role}, nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = mockAdmin
+	SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+	body := `{"username":"bob","password":"long enough password","role":"moderator"}`
+	req := httptest.NewRequest("POST", "/admin/admins", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"Role":"moderator"`)
+}
+
+func TestCreateAdmin_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"duplicate", models.ErrAdminExists, http.StatusConflict},
+		{"weak password", services.ErrWeakPassword, http.StatusBadRequest},
+		{"bad role", services.ErrInvalidAdminRole, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAdmin := &mockAdminService{
+				CreateAdminFunc: func(username, password, role string) (*models.Admin, error) {
+					return nil, tt.err
+				},
+			}
+
+			router, w := setupTestRouter(nil, nil, nil)
+			c := setupTestContainer(nil, nil, nil)
+			c.AdminService = mockAdmin
+			SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+			body := `{"username":"bob","password":"pw","role":"owner"}`
+			req := httptest.NewRequest("POST", "/admin/admins", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestUpdateAdminRole_LastOwner(t *testing.T) {
+	mockAdmin := &mockAdminService{
+		UpdateAdminRoleFunc: func(id int64, role string) (*models.Admin, error) {
+			return nil, services.ErrLastOwner
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = mockAdmin
+	SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("PUT", "/admin/admins/1/role", strings.NewReader(`{"role":"viewer"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestDeleteAdmin_NotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = &mockAdminService{}
+	SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/admins/99", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminRoles_Enforced(t *testing.T) {
+	mockComment := &mockCommentService{
+		ApproveCommentFunc: func(id int64) (*models.CommentWithGuest, error) {
+			return createTestComment(id, "alice", "Congratulations!"), nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		role     string
+		method   string
+		path     string
+		expected int
+	}{
+		{"viewer cannot approve", models.AdminRoleViewer, "POST", "/admin/comments/1/approve", http.StatusForbidden},
+		{"planner cannot approve", models.AdminRolePlanner, "POST", "/admin/comments/1/approve", http.StatusForbidden},
+		{"moderator can approve", models.AdminRoleModerator, "POST", "/admin/comments/1/approve", http.StatusOK},
+		{"viewer can read pending", models.AdminRoleViewer, "GET", "/admin/comments/pending", http.StatusOK},
+		{"moderator cannot upload guests", models.AdminRoleModerator, "POST", "/admin/guests/bulk", http.StatusForbidden},
+		{"planner reaches guest upload", models.AdminRolePlanner, "POST", "/admin/guests/bulk", http.StatusBadRequest},
+		{"planner cannot list admins", models.AdminRolePlanner, "GET", "/admin/admins", http.StatusForbidden},
+		{"owner can list admins", models.AdminRoleOwner, "GET", "/admin/admins", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, w := setupRoleTestRouter(tt.role)
+			c := setupTestContainer(&mockGuestService{}, mockComment, nil)
+			c.AdminService = &mockAdminService{}
+			admin := router.Group("/admin")
+			SetupAdminAccountRoutes(admin, c)
+			SetupGuestRoutes(admin, c)
+			SetupAdminCommentRoutes(admin, c)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
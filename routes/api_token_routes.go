@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// SetupAPITokenRoutes registers the routes owners use to manage the API
+// tokens of scripts
+func SetupAPITokenRoutes(r *gin.RouterGroup, c *container.Container) {
+	tokenGroup := r.Group("/api-tokens", adminauth.RequireRole(models.AdminRoleOwner))
+	{
+		tokenGroup.GET("", handleListAPITokens(c))
+		tokenGroup.POST("", handleCreateAPIToken(c))
+		tokenGroup.DELETE("/:id", handleRevokeAPIToken(c))
+	}
+}
+
+func handleListAPITokens(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokens, err := container.APITokens.GetAllTokens()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load API tokens. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tokens": tokens,
+			"count":  len(tokens),
+		})
+	}
+}
+
+func handleCreateAPIToken(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createAPITokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Please enter a name (up to 100 characters) and at least one scope.",
+			})
+			return
+		}
+
+		var expiresAt time.Time
+		if req.ExpiresAt != nil {
+			expiresAt = *req.ExpiresAt
+		}
+
+		admin := adminauth.CurrentAdmin(c)
+		token, secret, err := container.APITokens.CreateToken(req.Name, req.Scopes, expiresAt, admin.Username)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidAPITokenScope):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":  "Scopes must be one or more of the listed values.",
+					"scopes": models.APITokenScopes,
+				})
+			case errors.Is(err, services.ErrInvalidAPITokenExpiry):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "The expiry date must be in the future.",
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "Unable to create the API token. Please try again.",
+					"details": err.Error(),
+				})
+			}
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionAPITokenCreate, models.AuditTargetAPIToken, services.AuditChange{
+			TargetID: auditTargetID(token.ID),
+			After:    token,
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":   "Copy this token now. It will not be shown again.",
+			"token":     secret,
+			"api_token": token,
+		})
+	}
+}
+
+func handleRevokeAPIToken(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid API token ID.",
+			})
+			return
+		}
+
+		token, err := container.APITokens.RevokeToken(id)
+		if err != nil {
+			if errors.Is(err, services.ErrAPITokenNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "API token not found.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to revoke the API token. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionAPITokenRevoke, models.AuditTargetAPIToken, services.AuditChange{
+			TargetID: auditTargetID(id),
+			After:    token,
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":   "API token revoked.",
+			"api_token": token,
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockAPITokenService implements services.APITokenServiceInterface for testing
+type mockAPITokenService struct {
+	CreateTokenFunc  func(name string, scopes []string, expiresAt time.Time, createdBy string) (*models.APIToken, string, error)
+	GetAllTokensFunc func() ([]models.APIToken, error)
+	RevokeTokenFunc  func(id int64) (*models.APIToken, error)
+	AuthenticateFunc func(secret string) (*models.APIToken, error)
+}
+
+func (m *mockAPITokenService) CreateToken(name string, scopes []string, expiresAt time.Time, createdBy string) (*models.APIToken, string, error) {
+	if m.CreateTokenFunc != nil {
+		return m.CreateTokenFunc(name, scopes, expiresAt, createdBy)
+	}
+	return nil, "", services.ErrInvalidAPITokenScope
+}
+
+func (m *mockAPITokenService) GetAllTokens() ([]models.APIToken, error) {
+	if m.GetAllTokensFunc != nil {
+		return m.GetAllTokensFunc()
+	}
+	return []models.APIToken{}, nil
+}
+
+func (m *mockAPITokenService) RevokeToken(id int64) (*models.APIToken, error) {
+	if m.RevokeTokenFunc != nil {
+		return m.RevokeTokenFunc(id)
+	}
+	return nil, services.ErrAPITokenNotFound
+}
+
+func (m *mockAPITokenService) Authenticate(secret string) (*models.APIToken, error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(secret)
+	}
+	return nil, services.ErrInvalidAPIToken
+}
+
+var _ services.APITokenServiceInterface = (*mockAPITokenService)(nil)
+
+// setupTokenTestRouter acts as a script holding a token with the given scopes
+func setupTokenTestRouter(scopes ...string) (*gin.Engine, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(w)
+	router.Use(func(c *gin.Context) {
+		adminauth.SetCurrentAPIToken(c, &models.APIToken{ID: 3, Name: "sync", Scopes: scopes})
+		c.Next()
+	})
+	return router, w
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	var action string
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.APITokens = &mockAPITokenService{
+		CreateTokenFunc: func(name string, scopes []string, expiresAt time.Time, createdBy string) (*models.APIToken, string, error) {
+			assert.Equal(t, "spreadsheet sync", name)
+			assert.Equal(t, []string{models.ScopeGuestsRead}, scopes)
+			assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), expiresAt.UTC())
+			assert.Equal(t, "test-owner", createdBy)
+			return &models.APIToken{ID: 3, Name: name, TokenHash: "hash", Scopes: scopes,
+				ExpiresAt: sql.NullTime{Time: expiresAt, Valid: true}}, "wit_secret", nil
+		},
+	}
+	c.AuditService = &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, act, targetType string, changes ...services.AuditChange) error {
+			action = act
+			assert.Equal(t, models.AuditTargetAPIToken, targetType)
+			assert.Equal(t, "3", changes[0].TargetID)
+			return nil
+		},
+	}
+	SetupAPITokenRoutes(router.Group("/admin"), c)
+
+	body := `{"name":"spreadsheet sync","scopes":["guests:read"],"expires_at":"2026-12-31T00:00:00Z"}`
+	req := httptest.NewRequest("POST", "/admin/api-tokens", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"token":"wit_secret"`)
+	assert.NotContains(t, w.Body.String(), "hash")
+	assert.Equal(t, models.AuditActionAPITokenCreate, action)
+}
+
+func TestCreateAPIToken_InvalidScope(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAPITokenRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/api-tokens", strings.NewReader(`{"name":"sync","scopes":["everything"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), models.ScopeStatsRead)
+}
+
+func TestRevokeAPIToken_NotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAPITokenRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/api-tokens/9", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPITokens_CannotManageTokens(t *testing.T) {
+	router, w := setupTokenTestRouter(models.APITokenScopes...)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAPITokenRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/api-tokens", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPIToken_ScopeEnforcedPerRoute(t *testing.T) {
+	mockGuest := &mockGuestService{
+		GetAllGuestsFunc: func() ([]models.Guest, error) {
+			return []models.Guest{}, nil
+		},
+	}
+	tests := []struct {
+		name     string
+		scope    string
+		method   string
+		path     string
+		expected int
+	}{
+		{"read guests", models.ScopeGuestsRead, "GET", "/admin/rsvps", http.StatusOK},
+		{"read guests without scope", models.ScopeStatsRead, "GET", "/admin/rsvps", http.StatusForbidden},
+		{"stats", models.ScopeStatsRead, "GET", "/admin/stats", http.StatusOK},
+		{"bulk upload with read scope", models.ScopeGuestsRead, "POST", "/admin/guests/bulk", http.StatusForbidden},
+		{"bulk upload with write scope", models.ScopeGuestsWrite, "POST", "/admin/guests/bulk", http.StatusBadRequest},
+		{"moderation", models.ScopeCommentsModerate, "GET", "/admin/comments/pending", http.StatusOK},
+		{"moderation without scope", models.ScopeGuestsWrite, "POST", "/admin/comments/1/approve", http.StatusForbidden},
+		{"audit log is for people", models.ScopeGuestsRead, "GET", "/admin/audit", http.StatusForbidden},
+		{"sessions are for people", models.ScopeGuestsWrite, "GET", "/admin/guests/1/sessions", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, w := setupTokenTestRouter(tt.scope)
+			c := setupTestContainer(mockGuest, &mockCommentService{}, nil)
+			admin := router.Group("/admin")
+			SetupGuestRoutes(admin, c)
+			SetupAdminCommentRoutes(admin, c)
+			SetupAuditRoutes(admin, c)
+			SetupSessionRoutes(admin, c)
+			SetupStatsRoutes(admin, c)
+			admin.GET("/rsvps", adminauth.RequireScope(models.ScopeGuestsRead, models.AdminRoles...), handleGetAllRSVPs(c))
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	mockGuest := &mockGuestService{
+		GetAllGuestsFunc: func() ([]models.Guest, error) {
+			return []models.Guest{
+				{Name: "a", Attending: sql.NullBool{Bool: true, Valid: true}, PlusOnes: 2, FirstOpenedAt: sql.NullTime{Time: time.Now(), Valid: true}},
+				{Name: "b", Attending: sql.NullBool{Bool: false, Valid: true}},
+				{Name: "c"},
+			}, nil
+		},
+	}
+	c := setupTestContainer(mockGuest, nil, nil)
+	SetupStatsRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/stats", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"guests":3,"attending":1,"declined":1,"awaiting_reply":1,"headcount":3,"invitations_opened":1}`, w.Body.String())
+}
@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+func SetupAuditRoutes(r *gin.RouterGroup, c *container.Container) {
+	// The log includes IP addresses, so only owners can read it
+	r.GET("/audit", adminauth.RequireRole(models.AdminRoleOwner), handleGetAuditLog(c))
+}
+
+func handleGetAuditLog(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := models.AuditFilter{
+			Actor:      c.Query("actor"),
+			Action:     c.Query("action"),
+			TargetType: c.Query("target_type"),
+			TargetID:   c.Query("target_id"),
+		}
+
+		var err error
+		if since := c.Query("since"); since != "" {
+			if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "since must be an RFC 3339 time, e.g. 2026-04-11T00:00:00Z.",
+				})
+				return
+			}
+		}
+		if until := c.Query("until"); until != "" {
+			if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "until must be an RFC 3339 time, e.g. 2026-04-11T00:00:00Z.",
+				})
+				return
+			}
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLimit)))
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			limit = defaultAuditLimit
+		}
+
+		cursor := c.Query("cursor")
+		if cursor != "" {
+			if _, err := strconv.ParseInt(cursor, 10, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid cursor.",
+				})
+				return
+			}
+		}
+
+		result, err := container.AuditService.GetAuditLog(filter, limit, cursor)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load the audit log. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// recordAudit records a completed admin change made by the current admin.
+// The change has already been saved, so a failure here is logged rather
+// than turned into an error response.
+func recordAudit(c *gin.Context, container *container.Container, action, targetType string, changes ...services.AuditChange) {
+	admin := adminauth.CurrentAdmin(c)
+	if admin == nil {
+		log.Printf("Audit %s skipped: no admin on request", action)
+		return
+	}
+
+	actor := services.AuditActor{
+		ID:        admin.ID,
+		Name:      admin.Username,
+		IPAddress: c.ClientIP(),
+	}
+	if err := container.AuditService.Record(actor, action, targetType, changes...); err != nil {
+		log.Printf("Failed to record audit %s by %s: %v", action, admin.Username, err)
+	}
+}
+
+// auditTargetID formats a numeric ID for an audit entry
+func auditTargetID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,219 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockAuditService implements services.AuditServiceInterface for testing
+type mockAuditService struct {
+	RecordFunc      func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error
+	GetAuditLogFunc func(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error)
+}
+
+func (m *mockAuditService) Record(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+	if m.RecordFunc != nil {
+		return m.RecordFunc(actor, action, targetType, changes...)
+	}
+	return nil
+}
+
+func (m *mockAuditService) GetAuditLog(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error) {
+	if m.GetAuditLogFunc != nil {
+		return m.GetAuditLogFunc(filter, limit, cursor)
+	}
+	return &models.PaginatedAuditEntries{Entries: []models.AuditEntry{}}, nil
+}
+
+var _ services.AuditServiceInterface = (*mockAuditService)(nil)
+
+func TestGetAuditLog_Filters(t *testing.T) {
+	mockAudit := &mockAuditService{
+		GetAuditLogFunc: func(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error) {
+			assert.Equal(t, "alice", filter.Actor)
+			assert.Equal(t, models.AuditActionGuestUpdate, filter.Action)
+			assert.Equal(t, models.AuditTargetGuest, filter.TargetType)
+			assert.Equal(t, "12", filter.TargetID)
+			assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), filter.Since)
+			assert.True(t, filter.Until.IsZero())
+			assert.Equal(t, 20, limit)
+			assert.Equal(t, "40", cursor)
+			return &models.PaginatedAuditEntries{
+				Entries: []models.AuditEntry{{
+					ID:         39,
+					ActorName:  "alice",
+					Action:     models.AuditActionGuestUpdate,
+					TargetType: models.AuditTargetGuest,
+					TargetID:   "12",
+					Changes:    []byte(`{"PlusOnes":{"before":1,"after":2}}`),
+				}},
+				TotalCount: 1,
+			}, nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.AuditService = mockAudit
+	SetupAuditRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/audit?actor=alice&action=guest.update&target_type=guest&target_id=12&since=2026-04-01T00:00:00Z&limit=20&cursor=40", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"PlusOnes":{"before":1,"after":2}`)
+}
+
+func TestGetAuditLog_InvalidParams(t *testing.T) {
+	for _, query := range []string{"since=yesterday", "until=2026-04-01", "cursor=abc"} {
+		router, w := setupTestRouter(nil, nil, nil)
+		c := setupTestContainer(nil, nil, nil)
+		SetupAuditRoutes(router.Group("/admin"), c)
+
+		req := httptest.NewRequest("GET", "/admin/audit?"+query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetAuditLog_OwnerOnly(t *testing.T) {
+	router, w := setupRoleTestRouter(models.AdminRolePlanner)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAuditRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/audit", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestApproveComment_RecordsAudit(t *testing.T) {
+	mockComment := &mockCommentService{
+		GetCommentByIDFunc: func(id int64) (*models.CommentWithGuest, error) {
+			comment := createTestComment(id, "bob", "Hello")
+			comment.Status = models.CommentStatusPending
+			return comment, nil
+		},
+		ApproveCommentFunc: func(id int64) (*models.CommentWithGuest, error) {
+			comment := createTestComment(id, "bob", "Hello")
+			comment.Status = models.CommentStatusApproved
+			return comment, nil
+		},
+	}
+	var recorded []services.AuditChange
+	mockAudit := &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+			assert.Equal(t, "test-owner", actor.Name)
+			assert.NotEmpty(t, actor.IPAddress)
+			assert.Equal(t, models.AuditActionCommentApprove, action)
+			assert.Equal(t, models.AuditTargetComment, targetType)
+			recorded = changes
+			return nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, mockComment, nil)
+	c := setupTestContainer(nil, mockComment, nil)
+	c.AuditService = mockAudit
+	SetupAdminCommentRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/comments/3/approve", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, "3", recorded[0].TargetID)
+	assert.Equal(t, models.CommentStatusPending, recorded[0].Before.(*models.CommentWithGuest).Status)
+	assert.Equal(t, models.CommentStatusApproved, recorded[0].After.(*models.CommentWithGuest).Status)
+}
+
+func TestBulkGuestUpdate_RecordsAudit(t *testing.T) {
+	mockGuest := &mockGuestService{
+		GetAllGuestsFunc: func() ([]models.Guest, error) {
+			return []models.Guest{{
+				ID:        12,
+				Name:      "alice",
+				PlusOnes:  1,
+				CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			}}, nil
+		},
+	}
+	var recorded []services.AuditChange
+	mockAudit := &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+			assert.Equal(t, models.AuditActionGuestUpdate, action)
+			recorded = changes
+			return nil
+		},
+	}
+
+	router, w := setupTestRouter(mockGuest, nil, nil)
+	c := setupTestContainer(mockGuest, nil, nil)
+	c.AuditService = mockAudit
+	SetupGuestRoutes(router.Group("/admin"), c)
+
+	body := `[{"ID": 12, "Name": "alice", "PlusOnes": 2}]`
+	req := httptest.NewRequest("PUT", "/admin/guests/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, recorded, 1)
+	before := recorded[0].Before.(models.Guest)
+	after := recorded[0].After.(models.Guest)
+	assert.Equal(t, 1, before.PlusOnes)
+	assert.Equal(t, 2, after.PlusOnes)
+	// Columns the update does not write keep their stored values
+	assert.Equal(t, before.CreatedAt, after.CreatedAt)
+}
+
+func TestGuestUpdateChanges_UnknownGuest(t *testing.T) {
+	update := models.Guest{ID: 99, Name: "carol", Attending: sql.NullBool{Bool: true, Valid: true}}
+
+	changes := guestUpdateChanges(nil, []models.Guest{update})
+
+	assert.Len(t, changes, 1)
+	assert.Nil(t, changes[0].Before)
+	assert.Equal(t, update, changes[0].After)
+}
+
+func TestDeleteAdmin_RecordsAudit(t *testing.T) {
+	mockAdmin := &mockAdminService{
+		DeleteAdminFunc: func(id int64) (*models.Admin, error) {
+			return &models.Admin{ID: id, Username: "bob", Role: models.AdminRoleViewer}, nil
+		},
+	}
+	var action string
+	var recorded []services.AuditChange
+	mockAudit := &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, a, targetType string, changes ...services.AuditChange) error {
+			action = a
+			recorded = changes
+			return nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.AdminService = mockAdmin
+	c.AuditService = mockAudit
+	SetupAdminAccountRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/admins/2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.AuditActionAdminDelete, action)
+	assert.Len(t, recorded, 1)
+	assert.NotNil(t, recorded[0].Before)
+	assert.Nil(t, recorded[0].After)
+}
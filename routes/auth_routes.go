@@ -1,10 +1,17 @@
 package routes
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
+	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/container"
 	"wedding-invitation-backend/middleware/auth"
 	ratelimitmw "wedding-invitation-backend/middleware/ratelimit"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,11 +22,38 @@ func SetupAuthRoutes(r *gin.Engine, c *container.Container) {
 		ctx.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
 
+	// Public keys for services that verify guest tokens themselves
+	r.GET("/.well-known/jwks.json", handleJWKS(c))
+
 	// Login route with rate limiting
 	r.GET("/login/:name",
 		ratelimitmw.Middleware(c.AuthLimiter),
 		handleLogin(c),
 	)
+
+	// Invitation links log the guest in directly
+	r.GET("/invite/:token",
+		ratelimitmw.Middleware(c.AuthLimiter),
+		handleInviteLink(c),
+	)
+
+	// Exchange a refresh token for new tokens
+	r.POST(auth.RefreshTokenPath,
+		ratelimitmw.Middleware(c.AuthLimiter),
+		handleRefresh(c),
+	)
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func handleJWKS(c *container.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Short cache so rotated keys are picked up soon after a restart
+		ctx.Header("Cache-Control", "public, max-age=300")
+		ctx.JSON(http.StatusOK, c.TokenKeys.JWKS())
+	}
 }
 
 func handleLogin(c *container.Container) gin.HandlerFunc {
@@ -31,8 +65,24 @@ func handleLogin(c *container.Container) gin.HandlerFunc {
 			return
 		}
 
+		// Known and unknown names answer after the same minimum time, so
+		// response timing does not reveal who is on the guest list
+		defer waitUntil(time.Now().Add(config.LoginMinResponseTime))
+
+		ip := ctx.ClientIP()
+		wait, err := c.LoginGuard.CheckLogin(ip, name)
+		if err != nil {
+			// A broken lockout store should not keep guests out
+			log.Printf("Login lockout check failed for %s: %v", ip, err)
+		}
+		if wait > 0 {
+			ctx.Header("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+			refuseLogin(ctx)
+			return
+		}
+
 		// Check if user is on guest list using service
-		guest, err := c.GuestService.GetGuestByName(name)
+		guest, err := c.GuestService.GetGuestByName(ctx.Request.Context(), name)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"error": "We're having trouble accessing the guest list right now. Please try again in a moment.",
@@ -41,22 +91,150 @@ func handleLogin(c *container.Container) gin.HandlerFunc {
 		}
 
 		if guest == nil {
-			ctx.JSON(http.StatusForbidden, gin.H{
-				"error": "We couldn't find your name on our guest list. Please check the spelling or contact us if you believe this is an error.",
+			if err := c.LoginGuard.RecordFailure(ip, name); err != nil {
+				log.Printf("Failed to record login failure for %s: %v", ip, err)
+			}
+			refuseLogin(ctx)
+			return
+		}
+
+		if err := c.LoginGuard.RecordSuccess(ip, name); err != nil {
+			log.Printf("Failed to record login for %s: %v", ip, err)
+		}
+
+		// Each login is its own session so one device can be signed out
+		session, refreshToken, err := c.SessionService.StartSession(guest, ctx.Request.UserAgent(), ip)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "We're experiencing technical difficulties. Please try logging in again.",
+			})
+			return
+		}
+
+		respondWithTokens(ctx, c, session, refreshToken, useCookies(ctx), "Welcome! You're successfully logged in.")
+	}
+}
+
+func handleInviteLink(c *container.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		guest, err := c.InviteLinks.Redeem(ctx.Request.Context(), ctx.Param("token"))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidInviteLink) {
+				ctx.JSON(http.StatusUnauthorized, gin.H{
+					"error": "This invitation link is no longer valid. Please log in with your name or ask us for a new link.",
+				})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "We're having trouble accessing the guest list right now. Please try again in a moment.",
 			})
 			return
 		}
 
-		token, err := auth.GenerateToken(guest.Name)
+		session, refreshToken, err := c.SessionService.StartSession(guest, ctx.Request.UserAgent(), ctx.ClientIP())
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"error": "We're experiencing technical difficulties. Please try logging in again.",
 			})
 			return
 		}
+
+		respondWithTokens(ctx, c, session, refreshToken, useCookies(ctx), "Welcome! You're successfully logged in.")
+	}
+}
+
+func handleRefresh(c *container.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Browsers in cookie mode send the refresh cookie; other clients
+		// send the token in the body
+		var req refreshRequest
+		_ = ctx.ShouldBindJSON(&req)
+		refreshToken := req.RefreshToken
+		cookieMode := config.AuthMode == config.AuthModeCookie
+		if refreshToken == "" {
+			refreshToken, _ = ctx.Cookie(auth.RefreshTokenCookie)
+			cookieMode = cookieMode || refreshToken != ""
+		}
+		if refreshToken == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token required"})
+			return
+		}
+
+		session, nextRefreshToken, err := c.SessionService.RefreshSession(refreshToken)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+				if cookieMode {
+					auth.ClearTokenCookies(ctx)
+				}
+				ctx.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Your session has ended. Please log in again.",
+				})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "We're experiencing technical difficulties. Please try again.",
+			})
+			return
+		}
+
+		respondWithTokens(ctx, c, session, nextRefreshToken, cookieMode, "Your session has been renewed.")
+	}
+}
+
+// refuseLogin answers an unknown name and a locked-out attempt alike, so the
+// status and body do not reveal who is on the guest list
+func refuseLogin(ctx *gin.Context) {
+	ctx.JSON(http.StatusForbidden, gin.H{
+		"error": "We couldn't log you in with that name. Please check the spelling, or wait a few minutes if you've tried several times.",
+	})
+}
+
+// waitUntil sleeps until deadline. The response is buffered until the
+// handler returns, so deferring it delays the whole response.
+func waitUntil(deadline time.Time) {
+	time.Sleep(time.Until(deadline))
+}
+
+// useCookies reports whether a login responds with cookies: always in
+// cookie mode, and on request with ?mode=cookie in header mode
+func useCookies(ctx *gin.Context) bool {
+	return config.AuthMode == config.AuthModeCookie || ctx.Query("mode") == "cookie"
+}
+
+// respondWithTokens issues an access token for session and sends it with the
+// refresh token, either as HttpOnly cookies or in the response body. Cookie
+// responses also carry a new CSRF token, in a cookie and in the body for
+// clients on another origin that cannot read the cookie.
+func respondWithTokens(ctx *gin.Context, c *container.Container, session *models.Session, refreshToken string, cookieMode bool, message string) {
+	token, err := auth.GenerateToken(c.TokenKeys, session)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "We're experiencing technical difficulties. Please try logging in again.",
+		})
+		return
+	}
+
+	if cookieMode {
+		csrfToken, err := auth.NewCSRFToken()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error": "We're experiencing technical difficulties. Please try logging in again.",
+			})
+			return
+		}
+		auth.SetTokenCookies(ctx, token, refreshToken, csrfToken, session)
 		ctx.JSON(http.StatusOK, gin.H{
-			"token":   token,
-			"message": "Welcome! You're successfully logged in.",
+			"csrf_token": csrfToken,
+			"expires_in": config.JWTExpiry,
+			"message":    message,
 		})
+		return
 	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    config.JWTExpiry,
+		"message":       message,
+	})
 }
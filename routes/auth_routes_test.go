@@ -5,10 +5,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/middleware/auth"
 	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
 )
 
 func init() {
@@ -70,7 +74,7 @@ func TestLoginEndpoint_GuestNotFound(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusForbidden, w.Code)
-	assert.Contains(t, w.Body.String(), "couldn't find your name")
+	assert.Contains(t, w.Body.String(), "couldn't log you in")
 }
 
 func TestLoginEndpoint_ServiceError(t *testing.T) {
@@ -94,3 +98,169 @@ func TestLoginEndpoint_ServiceError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "trouble accessing the guest list")
 }
+
+func TestJWKSEndpoint(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+	// The test ring holds only an HMAC secret, which must never be published
+	assert.JSONEq(t, `{"keys": []}`, w.Body.String())
+}
+
+func TestLogin_CookieMode(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			return createTestGuest(name), nil
+		},
+	}
+
+	router, w := setupTestRouter(mockGuest, nil, nil)
+	c := setupTestContainer(mockGuest, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("GET", "/login/alice?mode=cookie", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "refresh_token")
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	assert.True(t, cookies[auth.AccessTokenCookie].HttpOnly)
+	assert.Equal(t, "test-refresh-token", cookies[auth.RefreshTokenCookie].Value)
+	assert.Equal(t, auth.RefreshTokenPath, cookies[auth.RefreshTokenCookie].Path)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[auth.RefreshTokenCookie].SameSite)
+
+	// The CSRF token is readable by the page and also sent in the body
+	csrf := cookies[auth.CSRFTokenCookie]
+	assert.False(t, csrf.HttpOnly)
+	assert.NotEmpty(t, csrf.Value)
+	assert.Contains(t, w.Body.String(), `"csrf_token":"`+csrf.Value+`"`)
+}
+
+func TestLogin_CookieAuthModeConfig(t *testing.T) {
+	setupTestConfig()
+	config.AuthMode = config.AuthModeCookie
+	defer func() { config.AuthMode = config.AuthModeHeader }()
+
+	mockGuest := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			return createTestGuest(name), nil
+		},
+	}
+
+	router, w := setupTestRouter(mockGuest, nil, nil)
+	c := setupTestContainer(mockGuest, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	// No ?mode=cookie needed
+	req := httptest.NewRequest("GET", "/login/alice", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"token"`)
+	assert.Contains(t, w.Body.String(), `"csrf_token"`)
+	assert.Len(t, w.Result().Cookies(), 3)
+}
+
+func TestCookieSession_RequiresCSRFToken(t *testing.T) {
+	setupTestConfig()
+	opened := 0
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("alice"), nil
+		},
+		MarkInvitationOpenedFunc: func(guestID int64) error {
+			opened++
+			return nil
+		},
+	}
+	c := setupTestContainer(mockGuest, nil, nil)
+
+	send := func(csrfHeader string) int {
+		router, w := setupTestRouter(mockGuest, nil, nil)
+		SetupRoutes(router, c)
+		req := httptest.NewRequest("POST", "/mark-opened", nil)
+		req.AddCookie(&http.Cookie{Name: auth.AccessTokenCookie, Value: generateTestToken("alice")})
+		req.AddCookie(&http.Cookie{Name: auth.CSRFTokenCookie, Value: "csrf-1"})
+		if csrfHeader != "" {
+			req.Header.Set(auth.CSRFTokenHeader, csrfHeader)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, send(""))
+	assert.Equal(t, http.StatusForbidden, send("forged"))
+	assert.Equal(t, 0, opened)
+	assert.Equal(t, http.StatusOK, send("csrf-1"))
+	assert.Equal(t, 1, opened)
+}
+
+func TestRefresh_Body(t *testing.T) {
+	setupTestConfig()
+
+	c := setupTestContainer(nil, nil, nil)
+	c.SessionService = &mockSessionService{
+		RefreshSessionFunc: func(refreshToken string) (*models.Session, string, error) {
+			assert.Equal(t, "old-refresh-token", refreshToken)
+			now := time.Now()
+			return &models.Session{ID: "abc", GuestName: "alice", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}, "new-refresh-token", nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refresh_token":"old-refresh-token"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"refresh_token":"new-refresh-token"`)
+	assert.Contains(t, w.Body.String(), `"token":`)
+}
+
+func TestRefresh_CookieReuseClearsCookies(t *testing.T) {
+	c := setupTestContainer(nil, nil, nil)
+	c.SessionService = &mockSessionService{
+		RefreshSessionFunc: func(refreshToken string) (*models.Session, string, error) {
+			assert.Equal(t, "replayed-token", refreshToken)
+			return nil, "", services.ErrRefreshTokenReused
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("POST", "/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: auth.RefreshTokenCookie, Value: "replayed-token"})
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	for _, cookie := range w.Result().Cookies() {
+		assert.Equal(t, -1, cookie.MaxAge, cookie.Name)
+	}
+	assert.Len(t, w.Result().Cookies(), 3)
+}
+
+func TestRefresh_MissingToken(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("POST", "/auth/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
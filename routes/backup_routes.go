@@ -0,0 +1,110 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Backup routes, relative to /admin. They copy the whole database, which
+// takes longer than the per-request database deadline allows.
+const (
+	backupPath        = "/backup"
+	backupRestorePath = "/backup/restore"
+)
+
+func SetupBackupRoutes(r *gin.RouterGroup, c *container.Container) {
+	// A backup holds every guest's data and a restore replaces all of it,
+	// so only owners can use them
+	owner := adminauth.RequireRole(models.AdminRoleOwner)
+	r.GET(backupPath, owner, handleDownloadBackup(c))
+	r.POST(backupRestorePath, owner, handleRestoreBackup(c))
+}
+
+func handleDownloadBackup(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		snapshot, err := container.Backups.OpenSnapshot(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to create a backup. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+		defer snapshot.Close()
+
+		filename := fmt.Sprintf("guests-%s.db", snapshot.CreatedAt.Format("20060102T150405Z"))
+		recordAudit(c, container, models.AuditActionBackupDownload, models.AuditTargetBackup, services.AuditChange{
+			TargetID: filename,
+			After:    gin.H{"size": snapshot.Size},
+		})
+
+		c.DataFromReader(http.StatusOK, snapshot.Size, "application/vnd.sqlite3", snapshot, map[string]string{
+			"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, filename),
+		})
+	}
+}
+
+func handleRestoreBackup(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.BackupMaxRestoreBytes+multipartOverhead)
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			if isRequestTooLarge(err) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error": fmt.Sprintf("Backups can be at most %d MB.", config.BackupMaxRestoreBytes>>20),
+				})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Please upload the backup file in the \"file\" field.",
+			})
+			return
+		}
+		upload, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unable to read the uploaded backup.",
+			})
+			return
+		}
+		defer upload.Close()
+
+		previous, err := container.Backups.Restore(c.Request.Context(), upload)
+		if errors.Is(err, services.ErrInvalidBackup) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "The uploaded file is not a backup that can be restored.",
+				"details": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to restore the backup. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// Recorded after the restore, which replaced the audit log as well
+		recordAudit(c, container, models.AuditActionBackupRestore, models.AuditTargetBackup, services.AuditChange{
+			TargetID: file.Filename,
+			Before:   gin.H{"backup": previous.Name},
+			After:    gin.H{"size": file.Size},
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":         "Backup restored.",
+			"previous_backup": previous,
+		})
+	}
+}
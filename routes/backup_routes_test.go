@@ -0,0 +1,165 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockBackupService implements services.BackupServiceInterface for testing
+type mockBackupService struct {
+	OpenSnapshotFunc func() (*services.Snapshot, error)
+	RestoreFunc      func(r io.Reader) (*services.Backup, error)
+}
+
+func (m *mockBackupService) CreateBackup(ctx context.Context) (*services.Backup, error) {
+	return nil, nil
+}
+
+func (m *mockBackupService) ListBackups() ([]services.Backup, error) {
+	return nil, nil
+}
+
+func (m *mockBackupService) OpenSnapshot(ctx context.Context) (*services.Snapshot, error) {
+	if m.OpenSnapshotFunc != nil {
+		return m.OpenSnapshotFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockBackupService) Restore(ctx context.Context, r io.Reader) (*services.Backup, error) {
+	if m.RestoreFunc != nil {
+		return m.RestoreFunc(r)
+	}
+	return nil, nil
+}
+
+func (m *mockBackupService) Start(interval time.Duration) {}
+
+func (m *mockBackupService) Stop() {}
+
+var _ services.BackupServiceInterface = (*mockBackupService)(nil)
+
+// testSnapshot writes content to a temporary snapshot file
+func testSnapshot(t *testing.T, content string) *services.Snapshot {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	return &services.Snapshot{
+		File:      file,
+		Size:      int64(len(content)),
+		CreatedAt: time.Date(2026, 6, 20, 12, 30, 0, 0, time.UTC),
+	}
+}
+
+// backupUpload builds a multipart request carrying content in the file field
+func backupUpload(content string) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "guests.db")
+	part.Write([]byte(content))
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/admin/backup/restore", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestDownloadBackup(t *testing.T) {
+	snapshot := testSnapshot(t, "SQLite format 3")
+	var recorded string
+	router, w := setupRoleTestRouter(models.AdminRoleOwner)
+	c := setupTestContainer(nil, nil, nil)
+	c.Backups = &mockBackupService{
+		OpenSnapshotFunc: func() (*services.Snapshot, error) { return snapshot, nil },
+	}
+	c.AuditService = &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+			recorded = action
+			return nil
+		},
+	}
+	SetupBackupRoutes(router.Group("/admin"), c)
+
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/backup", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "SQLite format 3", w.Body.String())
+	assert.Equal(t, `attachment; filename="guests-20260620T123000Z.db"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, models.AuditActionBackupDownload, recorded)
+}
+
+func TestBackupRoutes_OwnerOnly(t *testing.T) {
+	for _, req := range []*http.Request{httptest.NewRequest("GET", "/admin/backup", nil), backupUpload("x")} {
+		router, w := setupRoleTestRouter(models.AdminRolePlanner)
+		c := setupTestContainer(nil, nil, nil)
+		c.Backups = &mockBackupService{}
+		SetupBackupRoutes(router.Group("/admin"), c)
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, req.URL.Path)
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	var uploaded string
+	router, w := setupRoleTestRouter(models.AdminRoleOwner)
+	c := setupTestContainer(nil, nil, nil)
+	c.Backups = &mockBackupService{
+		RestoreFunc: func(r io.Reader) (*services.Backup, error) {
+			data, _ := io.ReadAll(r)
+			uploaded = string(data)
+			return &services.Backup{Name: "guests-20260620T120000.000Z-pre-restore.db"}, nil
+		},
+	}
+	SetupBackupRoutes(router.Group("/admin"), c)
+
+	router.ServeHTTP(w, backupUpload("SQLite format 3"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "SQLite format 3", uploaded)
+	assert.Contains(t, w.Body.String(), "guests-20260620T120000.000Z-pre-restore.db")
+}
+
+func TestRestoreBackup_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *http.Request
+		err      error
+		wantCode int
+	}{
+		{"missing file", httptest.NewRequest("POST", "/admin/backup/restore", nil), nil, http.StatusBadRequest},
+		{"invalid backup", backupUpload("nope"), fmt.Errorf("%w: integrity check failed", services.ErrInvalidBackup), http.StatusUnprocessableEntity},
+		{"restore failure", backupUpload("SQLite format 3"), fmt.Errorf("disk full"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, w := setupRoleTestRouter(models.AdminRoleOwner)
+			c := setupTestContainer(nil, nil, nil)
+			c.Backups = &mockBackupService{
+				RestoreFunc: func(r io.Reader) (*services.Backup, error) { return nil, tt.err },
+			}
+			SetupBackupRoutes(router.Group("/admin"), c)
+
+			router.ServeHTTP(w, tt.req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
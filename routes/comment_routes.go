@@ -2,30 +2,98 @@ package routes
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/contentpolicy"
+	"wedding-invitation-backend/media"
 	"wedding-invitation-backend/middleware/auth"
 	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// CreateCommentRequest is accepted as JSON or as multipart/form-data; the
+// multipart form may also carry an image in the "photo" field
 type CreateCommentRequest struct {
-	Content string `json:"content" binding:"required,min=1,max=1000"`
+	Content string `json:"content" form:"content" binding:"required,min=1,max=1000"`
+}
+
+// multipartOverhead leaves room for the text fields and part headers on top
+// of the photo itself
+const multipartOverhead = 64 << 10
+
+// errPhotoTooLarge is returned when the uploaded photo exceeds MEDIA_MAX_UPLOAD_BYTES
+var errPhotoTooLarge = errors.New("photo exceeds the maximum upload size")
+
+// readCommentPhoto returns the uploaded photo of a multipart request, or nil
+// if the request has none
+func readCommentPhoto(ctx *gin.Context) ([]byte, error) {
+	if ctx.ContentType() != "multipart/form-data" {
+		return nil, nil
+	}
+
+	file, err := ctx.FormFile("photo")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if file.Size > config.MediaMaxUploadBytes {
+		return nil, errPhotoTooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, config.MediaMaxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > config.MediaMaxUploadBytes {
+		return nil, errPhotoTooLarge
+	}
+	return data, nil
+}
+
+// isRequestTooLarge reports whether err came from exceeding the body size limit
+func isRequestTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr) || errors.Is(err, errPhotoTooLarge)
 }
 
 func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 	// Protected routes that require authentication
 	authenticated := r.Group("")
-	authenticated.Use(auth.JWTMiddlewareWithService(c.GuestService))
+	authenticated.Use(auth.JWTMiddlewareWithService(c.GuestService, c.TokenKeys, c.SessionService))
 
 	// POST /comments - Create a new comment
 	authenticated.POST("/comments", func(ctx *gin.Context) {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, config.MediaMaxUploadBytes+multipartOverhead)
+
 		var req CreateCommentRequest
-		if err := ctx.ShouldBindJSON(&req); err != nil {
+		var err error
+		if ctx.ContentType() == "multipart/form-data" {
+			err = ctx.ShouldBind(&req)
+		} else {
+			err = ctx.ShouldBindJSON(&req)
+		}
+		if err != nil {
+			if isRequestTooLarge(err) {
+				ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error":   "Photo is too large",
+					"details": err.Error(),
+				})
+				return
+			}
 			ctx.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Invalid request data",
 				"details": err.Error(),
@@ -33,6 +101,22 @@ func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 			return
 		}
 
+		photo, err := readCommentPhoto(ctx)
+		if err != nil {
+			if isRequestTooLarge(err) {
+				ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error":   "Photo is too large",
+					"details": err.Error(),
+				})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid photo upload",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		// Sanitize content
 		content := strings.TrimSpace(req.Content)
 		if len(content) == 0 {
@@ -42,14 +126,14 @@ func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 			return
 		}
 
-		username, exists := ctx.Get("username")
+		guestID, exists := ctx.Get("guest_id")
 		if !exists {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			return
 		}
 
 		// Create comment using the service
-		comment, err := c.CommentService.CreateComment(username.(string), content)
+		comment, err := c.CommentService.CreateComment(ctx.Request.Context(), guestID.(int64), content, photo)
 		if err != nil {
 			// Check for specific maximum comment limit error
 			if errors.Is(err, models.ErrCommentLimitReached) {
@@ -59,6 +143,35 @@ func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 				})
 				return
 			}
+			var rejected *contentpolicy.RejectedError
+			if errors.As(err, &rejected) {
+				ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":   "Your message couldn't be posted. Please revise it and try again.",
+					"details": rejected.Reason,
+				})
+				return
+			}
+			if errors.Is(err, media.ErrUnsupportedImage) {
+				ctx.JSON(http.StatusUnsupportedMediaType, gin.H{
+					"error":   "Photo must be a JPEG, PNG or GIF image",
+					"details": err.Error(),
+				})
+				return
+			}
+			if errors.Is(err, media.ErrImageTooLarge) {
+				ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error":   "Photo is too large",
+					"details": err.Error(),
+				})
+				return
+			}
+			if errors.Is(err, services.ErrPhotoUploadsDisabled) {
+				ctx.JSON(http.StatusServiceUnavailable, gin.H{
+					"error":   "Photo uploads are currently unavailable",
+					"details": err.Error(),
+				})
+				return
+			}
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Error creating comment",
 				"details": err.Error(),
@@ -73,6 +186,14 @@ func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 			return
 		}
 
+		if comment.Status == models.CommentStatusPending {
+			ctx.JSON(http.StatusAccepted, gin.H{
+				"message": "Thank you! Your message will appear once it has been reviewed.",
+				"comment": comment,
+			})
+			return
+		}
+
 		ctx.JSON(http.StatusCreated, gin.H{
 			"message": "Comment created successfully",
 			"comment": comment,
@@ -81,14 +202,14 @@ func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 
 	// GET /comments/me - Get comments for authenticated user
 	authenticated.GET("/comments/me", func(ctx *gin.Context) {
-		username, exists := ctx.Get("username")
+		guestID, exists := ctx.Get("guest_id")
 		if !exists {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 			return
 		}
 
 		// Get comments for this guest using the service
-		comments, err := c.CommentService.GetCommentsByGuest(username.(string))
+		comments, err := c.CommentService.GetCommentsByGuest(ctx.Request.Context(), guestID.(int64))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Error retrieving comments",
@@ -121,7 +242,7 @@ func SetupCommentRoutes(r *gin.RouterGroup, c *container.Container) {
 		}
 
 		// Get all comments with guest names using the service
-		result, err := c.CommentService.GetAllCommentsWithGuests(limit, cursor)
+		result, err := c.CommentService.GetAllCommentsWithGuests(ctx.Request.Context(), limit, cursor)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Error retrieving comments",
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/contentpolicy"
 	"wedding-invitation-backend/models"
 )
 
@@ -22,14 +23,14 @@ func TestCreateComment_Success(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 	}
 
 	mockComment := &mockCommentService{
-		CreateCommentFunc: func(guestName, content string) (*models.CommentWithGuest, error) {
-			assert.Equal(t, "testuser", guestName)
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			assert.Equal(t, int64(1), guestID)
 			assert.Equal(t, "This is a great wedding!", content)
 			return createTestComment(1, "testuser", content), nil
 		},
@@ -58,7 +59,7 @@ func TestCreateComment_EmptyContent(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 	}
@@ -86,13 +87,13 @@ func TestCreateComment_CommentLimitReached(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 	}
 
 	mockComment := &mockCommentService{
-		CreateCommentFunc: func(guestName, content string) (*models.CommentWithGuest, error) {
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
 			return nil, models.ErrCommentLimitReached
 		},
 	}
@@ -116,18 +117,88 @@ func TestCreateComment_CommentLimitReached(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Maximum comment limit reached")
 }
 
+func TestCreateComment_RejectedByPolicy(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+	}
+
+	mockComment := &mockCommentService{
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			return nil, &contentpolicy.RejectedError{Rule: "duplicate", Reason: "duplicates an earlier comment"}
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, mockComment, nil)
+	c := setupTestContainer(mockGuest, mockComment, nil)
+	SetupCommentRoutes(router.Group("/"), c)
+
+	token := generateTestToken("testuser")
+	body := map[string]string{"content": "Congrats!"}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest("POST", "/comments", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "duplicates an earlier comment")
+}
+
+func TestCreateComment_PendingModeration(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+	}
+
+	mockComment := &mockCommentService{
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			comment := createTestComment(1, "testuser", content)
+			comment.Status = models.CommentStatusPending
+			return comment, nil
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, mockComment, nil)
+	c := setupTestContainer(mockGuest, mockComment, nil)
+	SetupCommentRoutes(router.Group("/"), c)
+
+	token := generateTestToken("testuser")
+	body := map[string]string{"content": "Photos at www.example.com"}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest("POST", "/comments", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "once it has been reviewed")
+}
+
 func TestGetCommentsByGuest_Success(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 	}
 
 	mockComment := &mockCommentService{
-		GetCommentsByGuestFunc: func(guestName string) ([]models.Comment, error) {
-			assert.Equal(t, "testuser", guestName)
+		GetCommentsByGuestFunc: func(guestID int64) ([]models.Comment, error) {
+			assert.Equal(t, int64(1), guestID)
 			return []models.Comment{
 				{ID: 1, GuestID: 1, Content: "First comment", CreatedAt: time.Now()},
 				{ID: 2, GuestID: 1, Content: "Second comment", CreatedAt: time.Now()},
@@ -215,7 +286,7 @@ func TestGetGuestByName_Success(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/pubsub"
+
+	"github.com/gin-gonic/gin"
+)
+
+// commentStreamPath is the live guestbook feed, which has no request deadline
+const commentStreamPath = "/comments/stream"
+
+func SetupCommentStreamRoutes(r *gin.RouterGroup, c *container.Container) {
+	// GET /comments/stream - Live guestbook feed via Server-Sent Events
+	r.GET(commentStreamPath, handleCommentStream(c))
+}
+
+func handleCommentStream(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if container.Broker == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "Live updates are not available right now.",
+			})
+			return
+		}
+
+		lastEventID := parseLastEventID(c)
+		sub, replay := container.Broker.Subscribe(lastEventID)
+		defer container.Broker.Unsubscribe(sub)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		// Disable response buffering in nginx so events are sent immediately
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		// Ask the browser to wait a few seconds before reconnecting
+		fmt.Fprint(c.Writer, "retry: 3000\n\n")
+		for _, event := range replay {
+			if err := writeSSEvent(c.Writer, event); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		heartbeat := time.NewTicker(config.StreamHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case event, ok := <-sub.Events():
+				if !ok {
+					// Dropped for being too slow or server shutting down;
+					// the client reconnects with Last-Event-ID
+					return
+				}
+				if err := writeSSEvent(c.Writer, event); err != nil {
+					log.Printf("Failed to write stream event: %v", err)
+					return
+				}
+				c.Writer.Flush()
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header,
+// falling back to a query parameter for clients that cannot set headers
+func parseLastEventID(c *gin.Context) uint64 {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+func writeSSEvent(w io.Writer, event pubsub.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/pubsub"
+)
+
+// streamRequest runs the stream handler until done is closed and returns the recorded response
+func streamRequest(t *testing.T, router *gin.Engine, req *http.Request, done <-chan struct{}) *httptest.ResponseRecorder {
+	t.Helper()
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	w := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		router.ServeHTTP(w, req)
+		close(finished)
+	}()
+
+	<-done
+	cancel()
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("stream handler did not return after client disconnect")
+	}
+	return w
+}
+
+func TestCommentStream_DeliversPublishedEvents(t *testing.T) {
+	broker := pubsub.NewBroker(10, 4)
+	t.Cleanup(broker.Stop)
+
+	router, _ := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.Broker = broker
+	SetupCommentStreamRoutes(router.Group("/"), c)
+
+	done := make(chan struct{})
+	go func() {
+		// Wait for the handler to subscribe before publishing
+		for broker.SubscriberCount() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		broker.Publish("comment.created", createTestComment(1, "testuser", "Congratulations!"))
+		time.Sleep(20 * time.Millisecond)
+		close(done)
+	}()
+
+	req := httptest.NewRequest("GET", "/comments/stream", nil)
+	w := streamRequest(t, router, req, done)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "id: 1\nevent: comment.created\n")
+	assert.Contains(t, w.Body.String(), "Congratulations!")
+	assert.Equal(t, 0, broker.SubscriberCount())
+}
+
+func TestCommentStream_ResumesFromLastEventID(t *testing.T) {
+	broker := pubsub.NewBroker(10, 4)
+	t.Cleanup(broker.Stop)
+	broker.Publish("comment.created", createTestComment(1, "alice", "First wish"))
+	broker.Publish("comment.created", createTestComment(2, "bob", "Second wish"))
+
+	router, _ := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.Broker = broker
+	SetupCommentStreamRoutes(router.Group("/"), c)
+
+	done := make(chan struct{})
+	go func() {
+		for broker.SubscriberCount() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+	}()
+
+	req := httptest.NewRequest("GET", "/comments/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := streamRequest(t, router, req, done)
+
+	assert.NotContains(t, w.Body.String(), "First wish")
+	assert.Contains(t, w.Body.String(), "id: 2\n")
+	assert.Contains(t, w.Body.String(), "Second wish")
+}
+
+func TestCommentStream_NoBroker(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupCommentStreamRoutes(router.Group("/"), c)
+
+	req := httptest.NewRequest("GET", "/comments/stream", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -21,7 +21,7 @@ func handleGetGuestByName(container *container.Container) gin.HandlerFunc {
 			return
 		}
 
-		guest, err := container.GuestService.GetGuestByName(name)
+		guest, err := container.GuestService.GetGuestByName(c.Request.Context(), name)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "We're having trouble accessing guest information right now. Please try again.",
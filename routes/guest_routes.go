@@ -10,14 +10,16 @@ import (
 	"strings"
 
 	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
 	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupGuestRoutes(r *gin.RouterGroup, c *container.Container) {
 	// Bulk guest operations
-	guestGroup := r.Group("/guests")
+	guestGroup := r.Group("/guests", adminauth.RequireScope(models.ScopeGuestsWrite, models.AdminRolePlanner))
 	{
 		guestGroup.POST("/bulk", handleBulkGuestUpload(c))
 		guestGroup.PUT("/bulk", handleBulkGuestUpdate(c))
@@ -67,7 +69,7 @@ func handleBulkGuestUpload(container *container.Container) gin.HandlerFunc {
 			return
 		}
 
-		if err := container.GuestService.BulkCreateGuests(guests); err != nil {
+		if err := container.GuestService.BulkCreateGuests(c.Request.Context(), guests); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Unable to save the guest list to the database. Please try again.",
 				"details": err.Error(),
@@ -75,6 +77,12 @@ func handleBulkGuestUpload(container *container.Container) gin.HandlerFunc {
 			return
 		}
 
+		created := make([]services.AuditChange, len(guests))
+		for i := range guests {
+			created[i] = services.AuditChange{TargetID: auditTargetID(guests[i].ID), After: guests[i]}
+		}
+		recordAudit(c, container, models.AuditActionGuestCreate, models.AuditTargetGuest, created...)
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": fmt.Sprintf("Successfully uploaded %d guests to the wedding list!", len(guests)),
 			"count":   len(guests),
@@ -149,7 +157,9 @@ func handleBulkGuestUpdate(container *container.Container) gin.HandlerFunc {
 			return
 		}
 
-		if err := container.GuestService.BulkUpdateGuests(guests); err != nil {
+		// Snapshot the current records so the audit log can show what changed
+		existing, err := container.GuestService.GetAllGuests(c.Request.Context())
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Unable to update the guest information. Please try again.",
 				"details": err.Error(),
@@ -157,9 +167,45 @@ func handleBulkGuestUpdate(container *container.Container) gin.HandlerFunc {
 			return
 		}
 
+		if err := container.GuestService.BulkUpdateGuests(c.Request.Context(), guests); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to update the guest information. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionGuestUpdate, models.AuditTargetGuest, guestUpdateChanges(existing, guests)...)
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": fmt.Sprintf("Successfully updated %d guest records!", len(guests)),
 			"count":   len(guests),
 		})
 	}
 }
+
+// guestUpdateChanges pairs each bulk update with the record it replaced.
+// Only the columns a bulk update writes are applied to the snapshot, so
+// timestamps do not show up as changes.
+func guestUpdateChanges(existing, updates []models.Guest) []services.AuditChange {
+	byID := make(map[int64]models.Guest, len(existing))
+	for _, guest := range existing {
+		byID[guest.ID] = guest
+	}
+
+	changes := make([]services.AuditChange, 0, len(updates))
+	for _, update := range updates {
+		change := services.AuditChange{TargetID: auditTargetID(update.ID), After: update}
+		if before, ok := byID[update.ID]; ok {
+			after := before
+			after.Name = update.Name
+			after.Attending = update.Attending
+			after.PlusOnes = update.PlusOnes
+			after.DietaryRestrictions = update.DietaryRestrictions
+			change.Before = before
+			change.After = after
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +20,6 @@ func init() {
 
 func TestBulkGuestUpload_Success(t *testing.T) {
 	setupTestConfig()
-	config.AdminAPIKey = "test-api-key"
 
 	mockGuest := &mockGuestService{
 		BulkCreateGuestsFunc: func(guests []models.Guest) error {
@@ -43,7 +41,6 @@ func TestBulkGuestUpload_Success(t *testing.T) {
 
 	req := httptest.NewRequest("POST", "/admin/guests/bulk", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("X-API-Key", "test-api-key")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -54,7 +51,6 @@ func TestBulkGuestUpload_Success(t *testing.T) {
 
 func TestBulkGuestUpload_MissingFile(t *testing.T) {
 	setupTestConfig()
-	config.AdminAPIKey = "test-api-key"
 
 	router, _ := setupTestRouter(nil, nil, nil)
 	c := setupTestContainer(nil, nil, nil)
@@ -62,7 +58,6 @@ func TestBulkGuestUpload_MissingFile(t *testing.T) {
 
 	req := httptest.NewRequest("POST", "/admin/guests/bulk", nil)
 	req.Header.Set("Content-Type", "multipart/form-data")
-	req.Header.Set("X-API-Key", "test-api-key")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -73,7 +68,6 @@ func TestBulkGuestUpload_MissingFile(t *testing.T) {
 
 func TestBulkGuestUpload_EmptyCSV(t *testing.T) {
 	setupTestConfig()
-	config.AdminAPIKey = "test-api-key"
 
 	mockGuest := &mockGuestService{}
 
@@ -88,7 +82,6 @@ func TestBulkGuestUpload_EmptyCSV(t *testing.T) {
 
 	req := httptest.NewRequest("POST", "/admin/guests/bulk", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("X-API-Key", "test-api-key")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -99,7 +92,6 @@ func TestBulkGuestUpload_EmptyCSV(t *testing.T) {
 
 func TestBulkGuestUpdate_Success(t *testing.T) {
 	setupTestConfig()
-	config.AdminAPIKey = "test-api-key"
 
 	mockGuest := &mockGuestService{
 		BulkUpdateGuestsFunc: func(guests []models.Guest) error {
@@ -120,7 +112,6 @@ func TestBulkGuestUpdate_Success(t *testing.T) {
 
 	req := httptest.NewRequest("PUT", "/admin/guests/bulk", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", "test-api-key")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -131,7 +122,6 @@ func TestBulkGuestUpdate_Success(t *testing.T) {
 
 func TestBulkGuestUpdate_EmptyData(t *testing.T) {
 	setupTestConfig()
-	config.AdminAPIKey = "test-api-key"
 
 	router, _ := setupTestRouter(nil, nil, nil)
 	c := setupTestContainer(nil, nil, nil)
@@ -141,7 +131,6 @@ func TestBulkGuestUpdate_EmptyData(t *testing.T) {
 
 	req := httptest.NewRequest("PUT", "/admin/guests/bulk", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", "test-api-key")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -152,7 +141,6 @@ func TestBulkGuestUpdate_EmptyData(t *testing.T) {
 
 func TestGetAllRSVPs_Success(t *testing.T) {
 	setupTestConfig()
-	config.AdminAPIKey = "test-api-key"
 
 	mockGuest := &mockGuestService{
 		GetAllGuestsFunc: func() ([]models.Guest, error) {
@@ -169,7 +157,6 @@ func TestGetAllRSVPs_Success(t *testing.T) {
 	router.GET("/admin/rsvps", handleGetAllRSVPs(c))
 
 	req := httptest.NewRequest("GET", "/admin/rsvps", nil)
-	req.Header.Set("X-API-Key", "test-api-key")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -14,9 +14,10 @@ func SetupInvitationRoutes(r *gin.RouterGroup, c *container.Container) {
 
 func handleMarkOpened(container *container.Container) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		username := c.MustGet("username").(string)
+		guestID := c.MustGet("guest_id").(int64)
+		username := c.GetString("username")
 
-		if err := container.GuestService.MarkInvitationOpened(username); err != nil {
+		if err := container.GuestService.MarkInvitationOpened(c.Request.Context(), guestID); err != nil {
 			log.Printf("Error marking invitation opened: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "We're having trouble tracking your invitation. This won't affect your access.",
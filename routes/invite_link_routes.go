@@ -0,0 +1,168 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupInviteLinkRoutes registers the admin routes that issue and revoke
+// invitation links
+func SetupInviteLinkRoutes(r *gin.RouterGroup, c *container.Container) {
+	planner := adminauth.RequireScope(models.ScopeGuestsWrite, models.AdminRolePlanner)
+	r.POST("/guests/:id/invite-links", planner, handleCreateInviteLink(c))
+	r.GET("/guests/:id/invite-links", planner, handleGetGuestInviteLinks(c))
+	r.DELETE("/invite-links/:id", planner, handleRevokeInviteLink(c))
+	r.POST("/invite-links/bulk", planner, handleBulkInviteLinks(c))
+}
+
+func handleCreateInviteLink(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid guest ID.",
+			})
+			return
+		}
+
+		link, err := container.InviteLinks.CreateLink(c.Request.Context(), guestID)
+		if err != nil {
+			if errors.Is(err, services.ErrGuestNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Guest not found.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to create the invitation link. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionInviteLinkCreate, models.AuditTargetInviteLink, services.AuditChange{
+			TargetID: link.ID,
+			After:    link.InviteLink,
+		})
+
+		c.JSON(http.StatusCreated, gin.H{
+			"link": link,
+		})
+	}
+}
+
+func handleGetGuestInviteLinks(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid guest ID.",
+			})
+			return
+		}
+
+		links, err := container.InviteLinks.GetGuestLinks(c.Request.Context(), guestID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load invitation links. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"links": links,
+			"count": len(links),
+		})
+	}
+}
+
+func handleRevokeInviteLink(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		link, err := container.InviteLinks.RevokeLink(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, services.ErrInviteLinkNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Invitation link not found.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to revoke the invitation link. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionInviteLinkRevoke, models.AuditTargetInviteLink, services.AuditChange{
+			TargetID: id,
+			After:    link,
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Invitation link revoked.",
+			"link":    link,
+		})
+	}
+}
+
+// handleBulkInviteLinks returns a CSV with a link for every guest, ready to
+// import into a broadcast tool
+func handleBulkInviteLinks(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		links, created, err := container.InviteLinks.LinksForAllGuests(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to create invitation links. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if len(created) > 0 {
+			changes := make([]services.AuditChange, len(created))
+			for i := range created {
+				changes[i] = services.AuditChange{TargetID: created[i].ID, After: created[i]}
+			}
+			recordAudit(c, container, models.AuditActionInviteLinkCreate, models.AuditTargetInviteLink, changes...)
+		}
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"guest_id", "name", "link", "expires_at"})
+		for _, link := range links {
+			w.Write([]string{
+				strconv.FormatInt(link.GuestID, 10),
+				link.GuestName,
+				link.URL,
+				link.ExpiresAt.UTC().Format(time.RFC3339),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to create the invitation link export. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		filename := fmt.Sprintf("invite-links-%s.csv", time.Now().Format("20060102"))
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	}
+}
@@ -0,0 +1,190 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockInviteLinkService implements services.InviteLinkServiceInterface for testing
+type mockInviteLinkService struct {
+	CreateLinkFunc        func(guestID int64) (*services.IssuedInviteLink, error)
+	GetGuestLinksFunc     func(guestID int64) ([]services.IssuedInviteLink, error)
+	LinksForAllGuestsFunc func() ([]services.IssuedInviteLink, []models.InviteLink, error)
+	RedeemFunc            func(token string) (*models.Guest, error)
+	RevokeLinkFunc        func(id string) (*models.InviteLink, error)
+}
+
+func (m *mockInviteLinkService) CreateLink(ctx context.Context, guestID int64) (*services.IssuedInviteLink, error) {
+	if m.CreateLinkFunc != nil {
+		return m.CreateLinkFunc(guestID)
+	}
+	return nil, services.ErrGuestNotFound
+}
+
+func (m *mockInviteLinkService) GetGuestLinks(ctx context.Context, guestID int64) ([]services.IssuedInviteLink, error) {
+	if m.GetGuestLinksFunc != nil {
+		return m.GetGuestLinksFunc(guestID)
+	}
+	return []services.IssuedInviteLink{}, nil
+}
+
+func (m *mockInviteLinkService) LinksForAllGuests(ctx context.Context) ([]services.IssuedInviteLink, []models.InviteLink, error) {
+	if m.LinksForAllGuestsFunc != nil {
+		return m.LinksForAllGuestsFunc()
+	}
+	return nil, nil, nil
+}
+
+func (m *mockInviteLinkService) Redeem(ctx context.Context, token string) (*models.Guest, error) {
+	if m.RedeemFunc != nil {
+		return m.RedeemFunc(token)
+	}
+	return nil, services.ErrInvalidInviteLink
+}
+
+func (m *mockInviteLinkService) RevokeLink(ctx context.Context, id string) (*models.InviteLink, error) {
+	if m.RevokeLinkFunc != nil {
+		return m.RevokeLinkFunc(id)
+	}
+	return nil, services.ErrInviteLinkNotFound
+}
+
+var _ services.InviteLinkServiceInterface = (*mockInviteLinkService)(nil)
+
+func TestInviteLink_LogsIn(t *testing.T) {
+	setupTestConfig()
+	c := setupTestContainer(nil, nil, nil)
+	c.InviteLinks = &mockInviteLinkService{
+		RedeemFunc: func(token string) (*models.Guest, error) {
+			assert.Equal(t, "1.1775000000.abc.mac", token)
+			return createTestGuest("alice"), nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("GET", "/invite/1.1775000000.abc.mac", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"token"`)
+	assert.Contains(t, w.Body.String(), `"refresh_token":"test-refresh-token"`)
+}
+
+func TestInviteLink_Invalid(t *testing.T) {
+	setupTestConfig()
+	c := setupTestContainer(nil, nil, nil)
+	c.SessionService = &mockSessionService{
+		StartSessionFunc: func(guest *models.Guest, userAgent, ipAddress string) (*models.Session, string, error) {
+			t.Error("no session may start for an invalid link")
+			return nil, "", nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("GET", "/invite/forged", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCreateInviteLink_RecordsAudit(t *testing.T) {
+	var action string
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.InviteLinks = &mockInviteLinkService{
+		CreateLinkFunc: func(guestID int64) (*services.IssuedInviteLink, error) {
+			assert.Equal(t, int64(7), guestID)
+			return &services.IssuedInviteLink{
+				InviteLink: models.InviteLink{ID: "abc", GuestID: guestID, GuestName: "alice"},
+				URL:        "https://wedding.example.com/invite/7.1775000000.abc.mac",
+			}, nil
+		},
+	}
+	c.AuditService = &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, act, targetType string, changes ...services.AuditChange) error {
+			action = act
+			assert.Equal(t, models.AuditTargetInviteLink, targetType)
+			return nil
+		},
+	}
+	SetupInviteLinkRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/guests/7/invite-links", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"URL":"https://wedding.example.com/invite/7.1775000000.abc.mac"`)
+	assert.Equal(t, models.AuditActionInviteLinkCreate, action)
+}
+
+func TestCreateInviteLink_GuestNotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupInviteLinkRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/guests/99/invite-links", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRevokeInviteLink_NotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupInviteLinkRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/invite-links/missing", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBulkInviteLinks_CSV(t *testing.T) {
+	expires := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.InviteLinks = &mockInviteLinkService{
+		LinksForAllGuestsFunc: func() ([]services.IssuedInviteLink, []models.InviteLink, error) {
+			return []services.IssuedInviteLink{
+				{InviteLink: models.InviteLink{ID: "a", GuestID: 1, GuestName: "Alice", ExpiresAt: expires}, URL: "https://w.example/invite/a"},
+				{InviteLink: models.InviteLink{ID: "b", GuestID: 2, GuestName: "Bob, Jr.", ExpiresAt: expires}, URL: "https://w.example/invite/b"},
+			}, nil, nil
+		},
+	}
+	SetupInviteLinkRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/invite-links/bulk", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equal(t, []string{
+		"guest_id,name,link,expires_at",
+		"1,Alice,https://w.example/invite/a,2026-07-01T12:00:00Z",
+		`2,"Bob, Jr.",https://w.example/invite/b,2026-07-01T12:00:00Z`,
+	}, lines)
+}
+
+func TestInviteLinks_RequirePlanner(t *testing.T) {
+	router, w := setupRoleTestRouter(models.AdminRoleViewer)
+	c := setupTestContainer(nil, nil, nil)
+	SetupInviteLinkRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/invite-links/bulk", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultLoginActivityWindow is how far back the security view looks by default
+const defaultLoginActivityWindow = 24 * time.Hour
+
+// SetupLoginSecurityRoutes registers the admin view of failed guest logins
+func SetupLoginSecurityRoutes(r *gin.RouterGroup, c *container.Container) {
+	// Like the audit log this shows IP addresses, so it is for owners only
+	securityGroup := r.Group("/security", adminauth.RequireRole(models.AdminRoleOwner))
+	{
+		securityGroup.GET("/logins", handleGetSuspiciousLogins(c))
+		securityGroup.DELETE("/lockouts/:key", handleUnlockLogin(c))
+	}
+}
+
+func handleGetSuspiciousLogins(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since := time.Now().Add(-defaultLoginActivityWindow)
+		if value := c.Query("since"); value != "" {
+			var err error
+			if since, err = time.Parse(time.RFC3339, value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "since must be an RFC 3339 time, e.g. 2026-04-11T00:00:00Z.",
+				})
+				return
+			}
+		}
+
+		report, err := container.LoginGuard.GetSuspiciousLogins(since)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load login activity. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func handleUnlockLogin(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Param("key")
+
+		lockout, err := container.LoginGuard.Unlock(key)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to clear this lockout. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+		if lockout == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "No failed logins recorded for this key.",
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionLoginUnlock, models.AuditTargetLoginLockout, services.AuditChange{
+			TargetID: key,
+			Before:   lockout,
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Lockout cleared.",
+		})
+	}
+}
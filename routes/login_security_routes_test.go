@@ -0,0 +1,261 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockLoginGuardService implements services.LoginGuardServiceInterface for testing
+type mockLoginGuardService struct {
+	CheckLoginFunc          func(ipAddress, name string) (time.Duration, error)
+	RecordFailureFunc       func(ipAddress, name string) error
+	RecordSuccessFunc       func(ipAddress, name string) error
+	GetSuspiciousLoginsFunc func(since time.Time) (*models.SuspiciousLoginReport, error)
+	UnlockFunc              func(key string) (*models.LoginLockout, error)
+}
+
+func (m *mockLoginGuardService) CheckLogin(ipAddress, name string) (time.Duration, error) {
+	if m.CheckLoginFunc != nil {
+		return m.CheckLoginFunc(ipAddress, name)
+	}
+	return 0, nil
+}
+
+func (m *mockLoginGuardService) RecordFailure(ipAddress, name string) error {
+	if m.RecordFailureFunc != nil {
+		return m.RecordFailureFunc(ipAddress, name)
+	}
+	return nil
+}
+
+func (m *mockLoginGuardService) RecordSuccess(ipAddress, name string) error {
+	if m.RecordSuccessFunc != nil {
+		return m.RecordSuccessFunc(ipAddress, name)
+	}
+	return nil
+}
+
+func (m *mockLoginGuardService) GetSuspiciousLogins(since time.Time) (*models.SuspiciousLoginReport, error) {
+	if m.GetSuspiciousLoginsFunc != nil {
+		return m.GetSuspiciousLoginsFunc(since)
+	}
+	return &models.SuspiciousLoginReport{}, nil
+}
+
+func (m *mockLoginGuardService) Unlock(key string) (*models.LoginLockout, error) {
+	if m.UnlockFunc != nil {
+		return m.UnlockFunc(key)
+	}
+	return nil, nil
+}
+
+func (m *mockLoginGuardService) PruneLoginEvents() (int64, error) {
+	return 0, nil
+}
+
+var _ services.LoginGuardServiceInterface = (*mockLoginGuardService)(nil)
+
+func TestLogin_LockedOut(t *testing.T) {
+	setupTestConfig()
+	mockGuest := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			t.Error("guest list must not be checked while locked out")
+			return nil, nil
+		},
+	}
+
+	router, w := setupTestRouter(mockGuest, nil, nil)
+	c := setupTestContainer(mockGuest, nil, nil)
+	c.LoginGuard = &mockLoginGuardService{
+		CheckLoginFunc: func(ipAddress, name string) (time.Duration, error) {
+			assert.Equal(t, "alice", name)
+			return 90 * time.Second, nil
+		},
+	}
+	SetupAuthRoutes(router, c)
+
+	req := httptest.NewRequest("GET", "/login/alice", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+}
+
+func TestLogin_MissLooksLikeLockout(t *testing.T) {
+	setupTestConfig()
+	mockGuest := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			if name == "alice" {
+				return createTestGuest(name), nil
+			}
+			return nil, nil
+		},
+	}
+	c := setupTestContainer(mockGuest, nil, nil)
+
+	login := func(name string, locked bool) *httptest.ResponseRecorder {
+		c.LoginGuard = &mockLoginGuardService{
+			CheckLoginFunc: func(ipAddress, name string) (time.Duration, error) {
+				if locked {
+					return time.Minute, nil
+				}
+				return 0, nil
+			},
+		}
+		router, w := setupTestRouter(mockGuest, nil, nil)
+		SetupAuthRoutes(router, c)
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/login/"+name, nil))
+		return w
+	}
+
+	miss := login("mallory", false)
+	lockedHit := login("alice", true)
+	lockedMiss := login("mallory", true)
+
+	// A guest on the list and a stranger are refused identically
+	assert.Equal(t, http.StatusForbidden, miss.Code)
+	for _, w := range []*httptest.ResponseRecorder{lockedHit, lockedMiss} {
+		assert.Equal(t, miss.Code, w.Code)
+		assert.Equal(t, miss.Body.String(), w.Body.String())
+	}
+	assert.Equal(t, lockedMiss.Header().Get("Retry-After"), lockedHit.Header().Get("Retry-After"))
+}
+
+func TestLogin_RecordsOutcome(t *testing.T) {
+	setupTestConfig()
+	mockGuest := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			if name == "alice" {
+				return createTestGuest(name), nil
+			}
+			return nil, nil
+		},
+	}
+	var failures, successes []string
+	c := setupTestContainer(mockGuest, nil, nil)
+	c.LoginGuard = &mockLoginGuardService{
+		RecordFailureFunc: func(ipAddress, name string) error {
+			failures = append(failures, name)
+			return nil
+		},
+		RecordSuccessFunc: func(ipAddress, name string) error {
+			successes = append(successes, name)
+			return nil
+		},
+	}
+
+	for _, name := range []string{"alice", "mallory"} {
+		router, w := setupTestRouter(mockGuest, nil, nil)
+		SetupAuthRoutes(router, c)
+		req := httptest.NewRequest("GET", "/login/"+name, nil)
+		router.ServeHTTP(w, req)
+	}
+
+	assert.Equal(t, []string{"mallory"}, failures)
+	assert.Equal(t, []string{"alice"}, successes)
+}
+
+func TestLogin_UniformMinimumTime(t *testing.T) {
+	setupTestConfig()
+	config.LoginMinResponseTime = 50 * time.Millisecond
+	defer func() { config.LoginMinResponseTime = 0 }()
+
+	mockGuest := &mockGuestService{
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			if name == "alice" {
+				return createTestGuest(name), nil
+			}
+			return nil, nil
+		},
+	}
+	c := setupTestContainer(mockGuest, nil, nil)
+
+	for _, name := range []string{"alice", "mallory"} {
+		router, w := setupTestRouter(mockGuest, nil, nil)
+		SetupAuthRoutes(router, c)
+		req := httptest.NewRequest("GET", "/login/"+name, nil)
+
+		start := time.Now()
+		router.ServeHTTP(w, req)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, name)
+	}
+}
+
+func TestGetSuspiciousLogins(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.LoginGuard = &mockLoginGuardService{
+		GetSuspiciousLoginsFunc: func(since time.Time) (*models.SuspiciousLoginReport, error) {
+			assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), since)
+			return &models.SuspiciousLoginReport{
+				Lockouts: []models.LoginLockout{{Key: "ip:203.0.113.7", Failures: 12}},
+				IPs:      []models.LoginFailureCount{{Key: "203.0.113.7", Failures: 12, Distinct: 12}},
+				Names:    []models.LoginFailureCount{},
+			}, nil
+		},
+	}
+	SetupLoginSecurityRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/security/logins?since=2026-04-01T00:00:00Z", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Key":"ip:203.0.113.7"`)
+	assert.Contains(t, w.Body.String(), `"Distinct":12`)
+}
+
+func TestLoginSecurity_OwnerOnly(t *testing.T) {
+	router, w := setupRoleTestRouter(models.AdminRolePlanner)
+	c := setupTestContainer(nil, nil, nil)
+	SetupLoginSecurityRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/security/logins", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestUnlockLogin_RecordsAudit(t *testing.T) {
+	var recorded []services.AuditChange
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.LoginGuard = &mockLoginGuardService{
+		UnlockFunc: func(key string) (*models.LoginLockout, error) {
+			assert.Equal(t, "name:john doe", key)
+			return &models.LoginLockout{Key: key, Failures: 6}, nil
+		},
+	}
+	c.AuditService = &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+			assert.Equal(t, models.AuditActionLoginUnlock, action)
+			recorded = changes
+			return nil
+		},
+	}
+	SetupLoginSecurityRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/security/lockouts/name:john%20doe", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, "name:john doe", recorded[0].TargetID)
+}
+
+func TestUnlockLogin_NotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupLoginSecurityRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/security/lockouts/ip:10.0.0.1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
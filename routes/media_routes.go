@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupMediaRoutes(r *gin.RouterGroup, c *container.Container) {
+	// GET /media/:file - Public photos and thumbnails of approved comments
+	r.GET("/media/:file", handleGetMedia(c))
+	r.HEAD("/media/:file", handleGetMedia(c))
+}
+
+func handleGetMedia(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("file")
+		if container.MediaStore == nil || !media.IsPhotoFileName(name) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+			return
+		}
+
+		// Photos of pending and rejected comments stay private, like their text
+		published, err := container.CommentService.IsPhotoPublished(c.Request.Context(), media.PhotoKey(name))
+		if err != nil {
+			log.Printf("Failed to check photo %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving photo"})
+			return
+		}
+		if !published {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+			return
+		}
+
+		f, modTime, err := container.MediaStore.Open(name)
+		if errors.Is(err, os.ErrNotExist) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+			return
+		} else if err != nil {
+			log.Printf("Failed to open photo %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving photo"})
+			return
+		}
+		defer f.Close()
+
+		// File names are random and never reused, so the name is a strong
+		// validator. Caches must still revalidate so a rejected comment's
+		// photo stops being served.
+		c.Header("Content-Type", "image/jpeg")
+		c.Header("Cache-Control", "public, no-cache")
+		c.Header("ETag", `"`+name+`"`)
+		c.Header("X-Content-Type-Options", "nosniff")
+		http.ServeContent(c.Writer, c.Request, name, modTime, f)
+	}
+}
@@ -0,0 +1,243 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/media"
+	"wedding-invitation-backend/models"
+)
+
+const testPhotoName = "0123456789abcdef0123456789abcdef.jpg"
+
+// multipartCommentRequest builds a POST /comments request with an optional photo part
+func multipartCommentRequest(t *testing.T, content string, photo []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	assert.NoError(t, mw.WriteField("content", content))
+	if photo != nil {
+		part, err := mw.CreateFormFile("photo", "selfie.jpg")
+		assert.NoError(t, err)
+		_, err = part.Write(photo)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "/comments", &body)
+	req.Header.Set("Authorization", "Bearer "+generateTestToken("testuser"))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// setupMediaTestRouter serves media for the comment service mock, which by
+// default reports every photo as unpublished
+func setupMediaTestRouter(t *testing.T, comments *mockCommentService) (*gin.Engine, media.Store) {
+	t.Helper()
+	store, err := media.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+
+	c := setupTestContainer(&mockGuestService{}, comments, nil)
+	c.MediaStore = store
+	router := gin.New()
+	SetupMediaRoutes(router.Group("/"), c)
+	return router, store
+}
+
+func TestGetMedia_ServesPhotoWithCacheHeaders(t *testing.T) {
+	router, store := setupMediaTestRouter(t, &mockCommentService{
+		IsPhotoPublishedFunc: func(key string) (bool, error) {
+			assert.Equal(t, media.PhotoKey(testPhotoName), key)
+			return true, nil
+		},
+	})
+	assert.NoError(t, store.Put(testPhotoName, []byte("jpeg bytes")))
+
+	req := httptest.NewRequest("GET", "/media/"+testPhotoName, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "jpeg bytes", w.Body.String())
+	assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	// Revalidation with the ETag is answered without a body
+	req = httptest.NewRequest("GET", "/media/"+testPhotoName, nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestGetMedia_NotFound(t *testing.T) {
+	router, store := setupMediaTestRouter(t, &mockCommentService{
+		IsPhotoPublishedFunc: func(key string) (bool, error) { return true, nil },
+	})
+	assert.NoError(t, store.Put("notes.txt", []byte("not a photo")))
+
+	for _, path := range []string{
+		"/media/" + testPhotoName,
+		"/media/notes.txt",
+		"/media/..%2Fsecret.jpg",
+	} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
+
+func TestGetMedia_UnpublishedPhoto(t *testing.T) {
+	const thumbName = "0123456789abcdef0123456789abcdef_thumb.jpg"
+	var checked []string
+	router, store := setupMediaTestRouter(t, &mockCommentService{
+		IsPhotoPublishedFunc: func(key string) (bool, error) {
+			// Pending, rejected and unknown photos are all unpublished
+			checked = append(checked, key)
+			return false, nil
+		},
+	})
+	assert.NoError(t, store.Put(testPhotoName, []byte("jpeg bytes")))
+	assert.NoError(t, store.Put(thumbName, []byte("thumb bytes")))
+
+	for _, name := range []string{testPhotoName, thumbName} {
+		req := httptest.NewRequest("GET", "/media/"+name, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, name)
+		assert.NotContains(t, w.Body.String(), "bytes", name)
+	}
+	assert.Equal(t, []string{"0123456789abcdef0123456789abcdef", "0123456789abcdef0123456789abcdef"}, checked)
+}
+
+func TestGetMedia_StatusCheckFails(t *testing.T) {
+	router, store := setupMediaTestRouter(t, &mockCommentService{
+		IsPhotoPublishedFunc: func(key string) (bool, error) { return false, errors.New("db down") },
+	})
+	assert.NoError(t, store.Put(testPhotoName, []byte("jpeg bytes")))
+
+	req := httptest.NewRequest("GET", "/media/"+testPhotoName, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestCreateComment_MultipartWithPhoto(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+	}
+	mockComment := &mockCommentService{
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			assert.Equal(t, "Selamat!", content)
+			assert.Equal(t, []byte("image data"), photo)
+			comment := createTestComment(1, "testuser", content)
+			comment.PhotoURL = "/media/" + testPhotoName
+			return comment, nil
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, mockComment, nil)
+	c := setupTestContainer(mockGuest, mockComment, nil)
+	SetupCommentRoutes(router.Group("/"), c)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, multipartCommentRequest(t, "Selamat!", []byte("image data")))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "/media/"+testPhotoName)
+}
+
+func TestCreateComment_MultipartWithoutPhoto(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+	}
+	mockComment := &mockCommentService{
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			assert.Nil(t, photo)
+			return createTestComment(1, "testuser", content), nil
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, mockComment, nil)
+	c := setupTestContainer(mockGuest, mockComment, nil)
+	SetupCommentRoutes(router.Group("/"), c)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, multipartCommentRequest(t, "Selamat!", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestCreateComment_PhotoTooLarge(t *testing.T) {
+	setupTestConfig()
+	original := config.MediaMaxUploadBytes
+	config.MediaMaxUploadBytes = 16
+	t.Cleanup(func() { config.MediaMaxUploadBytes = original })
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+	}
+	mockComment := &mockCommentService{
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			t.Error("oversized upload must not reach the service")
+			return nil, nil
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, mockComment, nil)
+	c := setupTestContainer(mockGuest, mockComment, nil)
+	SetupCommentRoutes(router.Group("/"), c)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, multipartCommentRequest(t, "Selamat!", bytes.Repeat([]byte("x"), 64)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestCreateComment_UnsupportedPhoto(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+	}
+	mockComment := &mockCommentService{
+		CreateCommentFunc: func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
+			return nil, media.ErrUnsupportedImage
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, mockComment, nil)
+	c := setupTestContainer(mockGuest, mockComment, nil)
+	SetupCommentRoutes(router.Group("/"), c)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, multipartCommentRequest(t, "Selamat!", []byte("%PDF-1.4")))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
@@ -0,0 +1,92 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPrivacyRoutes registers the route guests download their data from
+func SetupPrivacyRoutes(r *gin.RouterGroup, c *container.Container) {
+	r.GET("/me/data", handleExportMyData(c))
+}
+
+// SetupAdminPrivacyRoutes registers the admin route that erases a guest's
+// personal data. It cannot be undone, so only owners can use it.
+func SetupAdminPrivacyRoutes(r *gin.RouterGroup, c *container.Container) {
+	r.POST("/guests/:id/forget", adminauth.RequireRole(models.AdminRoleOwner), handleForgetGuest(c))
+}
+
+func handleExportMyData(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guestID := c.MustGet("guest_id").(int64)
+
+		export, err := container.Privacy.ExportGuestData(c.Request.Context(), guestID)
+		if err != nil {
+			if errors.Is(err, services.ErrGuestNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "We couldn't find your guest information. Please contact support.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Unable to collect your data right now. Please try again.",
+			})
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, export)
+	}
+}
+
+func handleForgetGuest(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid guest ID.",
+			})
+			return
+		}
+
+		forgotten, err := container.Privacy.ForgetGuest(c.Request.Context(), guestID)
+		if err != nil {
+			if errors.Is(err, services.ErrGuestNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Guest not found.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to erase this guest's data. Nothing is lost by trying again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// Recorded after the erasure, which removes names from the audit
+		// log, and without the name
+		recordAudit(c, container, models.AuditActionGuestForget, models.AuditTargetGuest, services.AuditChange{
+			TargetID: auditTargetID(guestID),
+			After: gin.H{
+				"CommentsAnonymized": forgotten.CommentsAnonymized,
+				"PhotosDeleted":      forgotten.PhotosDeleted,
+				"SessionsDeleted":    forgotten.Sessions,
+				"InviteLinksDeleted": forgotten.InviteLinks,
+			},
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":   "The guest's personal data has been erased.",
+			"forgotten": forgotten,
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPrivacyService implements services.PrivacyServiceInterface for testing
+type mockPrivacyService struct {
+	ExportGuestDataFunc func(guestID int64) (*services.GuestDataExport, error)
+	ForgetGuestFunc     func(guestID int64) (*services.ForgottenGuest, error)
+}
+
+func (m *mockPrivacyService) ExportGuestData(ctx context.Context, guestID int64) (*services.GuestDataExport, error) {
+	if m.ExportGuestDataFunc != nil {
+		return m.ExportGuestDataFunc(guestID)
+	}
+	return nil, services.ErrGuestNotFound
+}
+
+func (m *mockPrivacyService) ForgetGuest(ctx context.Context, guestID int64) (*services.ForgottenGuest, error) {
+	if m.ForgetGuestFunc != nil {
+		return m.ForgetGuestFunc(guestID)
+	}
+	return nil, services.ErrGuestNotFound
+}
+
+var _ services.PrivacyServiceInterface = (*mockPrivacyService)(nil)
+
+// actAsGuest stands in for the guest JWT middleware
+func actAsGuest(guestID int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("guest_id", guestID)
+		c.Next()
+	}
+}
+
+func TestExportMyData(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	router.Use(actAsGuest(7))
+	c := setupTestContainer(nil, nil, nil)
+	c.Privacy = &mockPrivacyService{
+		ExportGuestDataFunc: func(guestID int64) (*services.GuestDataExport, error) {
+			assert.Equal(t, int64(7), guestID, "guests export only their own data")
+			return &services.GuestDataExport{
+				ExportedAt:  time.Now(),
+				Guest:       &models.Guest{ID: 7, Name: "alice"},
+				RSVPHistory: []services.RSVPChange{{Action: models.AuditActionGuestUpdate, Changes: []byte(`{}`)}},
+				Comments:    []models.Comment{{ID: 1, GuestID: 7, Content: "Congratulations!"}},
+				Sessions:    []models.Session{{ID: "abc", GuestID: 7}},
+				InviteLinks: []models.InviteLink{},
+			}, nil
+		},
+	}
+	SetupPrivacyRoutes(router.Group("/"), c)
+
+	req := httptest.NewRequest("GET", "/me/data", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	for _, part := range []string{`"guest":{"ID":7,"Name":"alice"`, `"rsvp_history":[{"action":"guest.update"`, `"Content":"Congratulations!"`, `"sessions":[{"ID":"abc"`, `"invite_links":[]`} {
+		assert.Contains(t, w.Body.String(), part)
+	}
+}
+
+func TestExportMyData_GuestGone(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	router.Use(actAsGuest(7))
+	c := setupTestContainer(nil, nil, nil)
+	c.Privacy = &mockPrivacyService{}
+	SetupPrivacyRoutes(router.Group("/"), c)
+
+	req := httptest.NewRequest("GET", "/me/data", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestForgetGuest_RecordsAudit(t *testing.T) {
+	var recorded []services.AuditChange
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.AuditService = &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+			assert.Equal(t, models.AuditActionGuestForget, action)
+			assert.Equal(t, models.AuditTargetGuest, targetType)
+			recorded = changes
+			return nil
+		},
+	}
+	c.Privacy = &mockPrivacyService{
+		ForgetGuestFunc: func(guestID int64) (*services.ForgottenGuest, error) {
+			assert.Equal(t, int64(12), guestID)
+			return &services.ForgottenGuest{
+				Guest:              &models.Guest{ID: 12},
+				CommentsAnonymized: 3,
+				PhotosDeleted:      1,
+				GuestErasure:       models.GuestErasure{Sessions: 2},
+			}, nil
+		},
+	}
+	SetupAdminPrivacyRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/guests/12/forget", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"comments_anonymized":3`)
+	assert.Contains(t, w.Body.String(), `"sessions_deleted":2`)
+	if assert.Len(t, recorded, 1) {
+		assert.Equal(t, "12", recorded[0].TargetID)
+		assert.Nil(t, recorded[0].Before)
+	}
+}
+
+func TestForgetGuest_NotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.Privacy = &mockPrivacyService{}
+	SetupAdminPrivacyRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/guests/12/forget", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestForgetGuest_OwnerOnly(t *testing.T) {
+	router, w := setupRoleTestRouter(models.AdminRolePlanner)
+	c := setupTestContainer(nil, nil, nil)
+	c.Privacy = &mockPrivacyService{
+		ForgetGuestFunc: func(guestID int64) (*services.ForgottenGuest, error) {
+			t.Fatal("a planner must not forget guests")
+			return nil, nil
+		},
+	}
+	SetupAdminPrivacyRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("POST", "/admin/guests/12/forget", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -2,12 +2,15 @@ package routes
 
 import (
 	"net/http"
+	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/container"
 	"wedding-invitation-backend/errors"
-	"wedding-invitation-backend/middleware/apikey"
+	"wedding-invitation-backend/middleware/adminauth"
 	"wedding-invitation-backend/middleware/auth"
+	"wedding-invitation-backend/middleware/dbdeadline"
 	"wedding-invitation-backend/middleware/errorhandler"
 	ratelimitmw "wedding-invitation-backend/middleware/ratelimit"
+	"wedding-invitation-backend/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,12 +19,20 @@ func SetupRoutes(r *gin.Engine, c *container.Container) {
 	// Global error handler (must be first)
 	r.Use(errorhandler.ErrorHandler())
 
+	// Bound the database work of each request; the live stream stays open
+	// and backups copy the whole database
+	r.Use(dbdeadline.Middleware(config.DBRequestTimeout, commentStreamPath, "/admin"+backupPath, "/admin"+backupRestorePath))
+
 	// Setup auth routes with rate limiting
 	SetupAuthRoutes(r, c)
 
+	// Public guestbook photos
+	SetupMediaRoutes(r.Group("/"), c)
+
 	// Setup RSVP routes with rate limiting
 	rsvpGroup := r.Group("/")
-	rsvpGroup.Use(auth.JWTMiddlewareWithService(c.GuestService))
+	rsvpGroup.Use(auth.JWTMiddlewareWithService(c.GuestService, c.TokenKeys, c.SessionService))
+	rsvpGroup.Use(auth.CSRFMiddleware())
 	rsvpGroup.Use(ratelimitmw.MiddlewareWithKeyFunc(
 		c.RSVPLimiter,
 		ratelimitmw.UserKeyFunc(),
@@ -30,7 +41,9 @@ func SetupRoutes(r *gin.Engine, c *container.Container) {
 
 	// Protected routes
 	protected := r.Group("/")
-	protected.Use(auth.JWTMiddlewareWithService(c.GuestService))
+	protected.Use(auth.JWTMiddlewareWithService(c.GuestService, c.TokenKeys, c.SessionService))
+	// Cookie-authenticated browsers must echo the CSRF token on changes
+	protected.Use(auth.CSRFMiddleware())
 	{
 		protected.GET("/protected", func(ctx *gin.Context) {
 			username := ctx.MustGet("username").(string)
@@ -53,18 +66,40 @@ func SetupRoutes(r *gin.Engine, c *container.Container) {
 
 		// Setup invitation routes
 		SetupInvitationRoutes(protected, c)
+
+		// Guests can download what is stored about them
+		SetupPrivacyRoutes(protected, c)
 	}
 
-	// Admin routes with API key authentication
+	// Live comment stream. EventSource cannot send headers, so the token
+	// may also be passed as a query parameter; the request log redacts it.
+	streamGroup := r.Group("/")
+	streamGroup.Use(auth.QueryTokenMiddleware("token"))
+	streamGroup.Use(auth.JWTMiddlewareWithService(c.GuestService, c.TokenKeys, c.SessionService))
+	SetupCommentStreamRoutes(streamGroup, c)
+
+	// Admin routes for named admin accounts and API tokens; each route checks
+	// the role, and the scope if scripts may call it
+	SetupAdminAuthRoutes(r, c)
 	admin := r.Group("/admin")
-	admin.Use(apikey.APIKeyMiddleware())
+	admin.Use(adminauth.Middleware(c.AdminService, c.APITokens))
+	SetupAdminAccountRoutes(admin, c)
 	SetupGuestRoutes(admin, c)
-	admin.GET("/rsvps", handleGetAllRSVPs(c))
+	SetupAdminCommentRoutes(admin, c)
+	SetupAuditRoutes(admin, c)
+	SetupSessionRoutes(admin, c)
+	SetupInviteLinkRoutes(admin, c)
+	SetupLoginSecurityRoutes(admin, c)
+	SetupAPITokenRoutes(admin, c)
+	SetupStatsRoutes(admin, c)
+	SetupBackupRoutes(admin, c)
+	SetupAdminPrivacyRoutes(admin, c)
+	admin.GET("/rsvps", adminauth.RequireScope(models.ScopeGuestsRead, models.AdminRoles...), handleGetAllRSVPs(c))
 }
 
 func handleGetAllRSVPs(container *container.Container) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		guests, err := container.GuestService.GetAllGuests()
+		guests, err := container.GuestService.GetAllGuests(c.Request.Context())
 		if err != nil {
 			c.Error(errors.WrapError(err, "Failed to retrieve RSVPs"))
 			c.Abort()
@@ -1,11 +1,14 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
 	"net/http/httptest"
 	"time"
 	"wedding-invitation-backend/config"
 	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/jwtkeys"
+	"wedding-invitation-backend/middleware/adminauth"
 	"wedding-invitation-backend/middleware/auth"
 	"wedding-invitation-backend/models"
 	"wedding-invitation-backend/ratelimit"
@@ -17,107 +20,189 @@ import (
 // mockGuestService implements services.GuestServiceInterface for testing
 type mockGuestService struct {
 	GetGuestByNameFunc       func(name string) (*models.Guest, error)
+	GetGuestByIDFunc         func(id int64) (*models.Guest, error)
 	GetAllGuestsFunc         func() ([]models.Guest, error)
 	CreateGuestFunc          func(guest *models.Guest) error
 	UpdateGuestFunc          func(guest *models.Guest) error
+	SubmitRSVPFunc           func(name string, attending bool) (*models.Guest, error)
 	BulkCreateGuestsFunc     func(guests []models.Guest) error
 	BulkUpdateGuestsFunc     func(guests []models.Guest) error
-	MarkInvitationOpenedFunc func(name string) error
-	ValidateGuestAccessFunc  func(name string) (*models.Guest, error)
+	MarkInvitationOpenedFunc func(guestID int64) error
+	ValidateGuestAccessFunc  func(guestID int64) (*models.Guest, error)
 }
 
-func (m *mockGuestService) GetGuestByName(name string) (*models.Guest, error) {
+func (m *mockGuestService) GetGuestByName(ctx context.Context, name string) (*models.Guest, error) {
 	if m.GetGuestByNameFunc != nil {
 		return m.GetGuestByNameFunc(name)
 	}
 	return nil, nil
 }
 
-func (m *mockGuestService) GetAllGuests() ([]models.Guest, error) {
+func (m *mockGuestService) GetGuestByID(ctx context.Context, id int64) (*models.Guest, error) {
+	if m.GetGuestByIDFunc != nil {
+		return m.GetGuestByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestService) GetAllGuests(ctx context.Context) ([]models.Guest, error) {
 	if m.GetAllGuestsFunc != nil {
 		return m.GetAllGuestsFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockGuestService) CreateGuest(guest *models.Guest) error {
+func (m *mockGuestService) CreateGuest(ctx context.Context, guest *models.Guest) error {
 	if m.CreateGuestFunc != nil {
 		return m.CreateGuestFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestService) UpdateGuest(guest *models.Guest) error {
+func (m *mockGuestService) UpdateGuest(ctx context.Context, guest *models.Guest) error {
 	if m.UpdateGuestFunc != nil {
 		return m.UpdateGuestFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestService) BulkCreateGuests(guests []models.Guest) error {
+// SubmitRSVP defaults to looking the guest up and updating it through the
+// GetGuestByName and UpdateGuest stubs
+func (m *mockGuestService) SubmitRSVP(ctx context.Context, name string, attending bool) (*models.Guest, error) {
+	if m.SubmitRSVPFunc != nil {
+		return m.SubmitRSVPFunc(name, attending)
+	}
+	guest, err := m.GetGuestByName(ctx, name)
+	if err != nil || guest == nil {
+		return nil, err
+	}
+	guest.Attending = sql.NullBool{Bool: attending, Valid: true}
+	if err := m.UpdateGuest(ctx, guest); err != nil {
+		return nil, err
+	}
+	return guest, nil
+}
+
+func (m *mockGuestService) BulkCreateGuests(ctx context.Context, guests []models.Guest) error {
 	if m.BulkCreateGuestsFunc != nil {
 		return m.BulkCreateGuestsFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestService) BulkUpdateGuests(guests []models.Guest) error {
+func (m *mockGuestService) BulkUpdateGuests(ctx context.Context, guests []models.Guest) error {
 	if m.BulkUpdateGuestsFunc != nil {
 		return m.BulkUpdateGuestsFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestService) MarkInvitationOpened(name string) error {
+func (m *mockGuestService) MarkInvitationOpened(ctx context.Context, guestID int64) error {
 	if m.MarkInvitationOpenedFunc != nil {
-		return m.MarkInvitationOpenedFunc(name)
+		return m.MarkInvitationOpenedFunc(guestID)
 	}
 	return nil
 }
 
-func (m *mockGuestService) ValidateGuestAccess(name string) (*models.Guest, error) {
+func (m *mockGuestService) ValidateGuestAccess(ctx context.Context, guestID int64) (*models.Guest, error) {
 	if m.ValidateGuestAccessFunc != nil {
-		return m.ValidateGuestAccessFunc(name)
+		return m.ValidateGuestAccessFunc(guestID)
 	}
 	return nil, nil
 }
 
 // mockCommentService implements services.CommentServiceInterface for testing
 type mockCommentService struct {
-	CreateCommentFunc            func(guestName, content string) (*models.CommentWithGuest, error)
-	GetCommentsByGuestFunc       func(guestName string) ([]models.Comment, error)
+	CreateCommentFunc            func(guestID int64, content string, photo []byte) (*models.CommentWithGuest, error)
+	GetCommentsByGuestFunc       func(guestID int64) ([]models.Comment, error)
 	GetAllCommentsFunc           func() ([]models.Comment, error)
 	GetAllCommentsWithGuestsFunc func(limit int, cursor string) (*models.PaginatedComments, error)
+	SearchCommentsFunc           func(query string, limit int, cursor string) (*models.PaginatedSearchResults, error)
+	GetCommentByIDFunc           func(id int64) (*models.CommentWithGuest, error)
+	GetPendingCommentsFunc       func() ([]models.CommentWithGuest, error)
+	ApproveCommentFunc           func(id int64) (*models.CommentWithGuest, error)
+	RejectCommentFunc            func(id int64, reason string) (*models.CommentWithGuest, error)
+	GetGuestbookForExportFunc    func() ([]models.CommentWithGuest, error)
+	IsPhotoPublishedFunc         func(key string) (bool, error)
 }
 
-func (m *mockCommentService) CreateComment(guestName, content string) (*models.CommentWithGuest, error) {
+func (m *mockCommentService) CreateComment(ctx context.Context, guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
 	if m.CreateCommentFunc != nil {
-		return m.CreateCommentFunc(guestName, content)
+		return m.CreateCommentFunc(guestID, content, photo)
 	}
 	return nil, nil
 }
 
-func (m *mockCommentService) GetCommentsByGuest(guestName string) ([]models.Comment, error) {
+func (m *mockCommentService) GetCommentsByGuest(ctx context.Context, guestID int64) ([]models.Comment, error) {
 	if m.GetCommentsByGuestFunc != nil {
-		return m.GetCommentsByGuestFunc(guestName)
+		return m.GetCommentsByGuestFunc(guestID)
 	}
 	return nil, nil
 }
 
-func (m *mockCommentService) GetAllComments() ([]models.Comment, error) {
+func (m *mockCommentService) GetAllComments(ctx context.Context) ([]models.Comment, error) {
 	if m.GetAllCommentsFunc != nil {
 		return m.GetAllCommentsFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockCommentService) GetAllCommentsWithGuests(limit int, cursor string) (*models.PaginatedComments, error) {
+func (m *mockCommentService) GetAllCommentsWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error) {
 	if m.GetAllCommentsWithGuestsFunc != nil {
 		return m.GetAllCommentsWithGuestsFunc(limit, cursor)
 	}
 	return nil, nil
 }
 
+func (m *mockCommentService) SearchComments(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+	if m.SearchCommentsFunc != nil {
+		return m.SearchCommentsFunc(query, limit, cursor)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentService) GetCommentByID(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	if m.GetCommentByIDFunc != nil {
+		return m.GetCommentByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentService) GetPendingComments(ctx context.Context) ([]models.CommentWithGuest, error) {
+	if m.GetPendingCommentsFunc != nil {
+		return m.GetPendingCommentsFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockCommentService) ApproveComment(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	if m.ApproveCommentFunc != nil {
+		return m.ApproveCommentFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentService) RejectComment(ctx context.Context, id int64, reason string) (*models.CommentWithGuest, error) {
+	if m.RejectCommentFunc != nil {
+		return m.RejectCommentFunc(id, reason)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentService) GetGuestbookForExport(ctx context.Context) ([]models.CommentWithGuest, error) {
+	if m.GetGuestbookForExportFunc != nil {
+		return m.GetGuestbookForExportFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockCommentService) IsPhotoPublished(ctx context.Context, key string) (bool, error) {
+	if m.IsPhotoPublishedFunc != nil {
+		return m.IsPhotoPublishedFunc(key)
+	}
+	return false, nil
+}
+
 // Compile-time checks to ensure mocks implement interfaces
 var _ services.GuestServiceInterface = (*mockGuestService)(nil)
 var _ services.CommentServiceInterface = (*mockCommentService)(nil)
@@ -127,9 +212,19 @@ func setupTestRouter(mockGuest *mockGuestService, mockComment *mockCommentServic
 	gin.SetMode(gin.TestMode)
 	w := httptest.NewRecorder()
 	_, router := gin.CreateTestContext(w)
+	// Admin routes check roles; tests act as an owner unless they need otherwise
+	router.Use(actAsAdmin(models.AdminRoleOwner))
 	return router, w
 }
 
+// actAsAdmin stands in for adminauth.Middleware with an admin of the given role
+func actAsAdmin(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminauth.SetCurrentAdmin(c, &models.Admin{ID: 1, Username: "test-" + role, Role: role})
+		c.Next()
+	}
+}
+
 // setupTestContainer creates a test container with mocked services
 func setupTestContainer(mockGuest *mockGuestService, mockComment *mockCommentService, limiter *ratelimit.SlidingWindowLimiter) *container.Container {
 	// Create a real rate limiter with generous limits if not provided
@@ -139,21 +234,45 @@ func setupTestContainer(mockGuest *mockGuestService, mockComment *mockCommentSer
 	return &container.Container{
 		GuestService:   mockGuest,
 		CommentService: mockComment,
+		AuditService:   &mockAuditService{},
+		SessionService: &mockSessionService{},
+		LoginGuard:     &mockLoginGuardService{},
+		InviteLinks:    &mockInviteLinkService{},
+		APITokens:      &mockAPITokenService{},
+		TokenKeys:      testTokenKeys,
 		AuthLimiter:    limiter,
 		RSVPLimiter:    limiter,
 		CommentLimiter: limiter,
 	}
 }
 
+// testTokenKeys signs and verifies guest tokens in route tests
+var testTokenKeys = newTestKeyRing()
+
+func newTestKeyRing() *jwtkeys.KeyRing {
+	key, err := jwtkeys.NewHMACKey(jwtkeys.DefaultKeyID, []byte("test-secret-key"))
+	if err != nil {
+		panic("failed to create test key: " + err.Error())
+	}
+	keys, err := jwtkeys.NewKeyRing(jwtkeys.DefaultKeyID, []*jwtkeys.Key{key}, nil, time.Hour)
+	if err != nil {
+		panic("failed to create test key ring: " + err.Error())
+	}
+	return keys
+}
+
 // setupTestConfig sets up config for JWT token generation
 func setupTestConfig() {
 	config.JWTSecret = "test-secret-key"
 	config.JWTExpiry = 3600
+	config.LoginMinResponseTime = 0
 }
 
-// generateTestToken generates a valid JWT token for the given username
+// generateTestToken generates a valid JWT token for guest 1 under the given name
 func generateTestToken(username string) string {
-	token, err := auth.GenerateToken(username)
+	now := time.Now()
+	session := &models.Session{ID: "test-session", GuestID: 1, GuestName: username, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	token, err := auth.GenerateToken(testTokenKeys, session)
 	if err != nil {
 		panic("failed to generate test token: " + err.Error())
 	}
@@ -163,6 +282,7 @@ func generateTestToken(username string) string {
 // createTestGuest creates a Guest model for testing
 func createTestGuest(name string) *models.Guest {
 	return &models.Guest{
+		ID:        1,
 		Name:      name,
 		Attending: sql.NullBool{Bool: true, Valid: true},
 		PlusOnes:  1,
@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
 	"wedding-invitation-backend/container"
@@ -31,12 +30,13 @@ func handleRSVPSubmission(container *container.Container) gin.HandlerFunc {
 
 		log.Printf("Processing RSVP for %s", request.Name)
 
-		// Check if guest exists using service
-		existingGuest, err := container.GuestService.GetGuestByName(request.Name)
+		// Update only the attending status
+		log.Printf("Updating RSVP for %s to %t", request.Name, request.Attending)
+		existingGuest, err := container.GuestService.SubmitRSVP(c.Request.Context(), request.Name, request.Attending)
 		if err != nil {
-			log.Printf("Database error checking guest: %v", err)
+			log.Printf("Failed to update RSVP: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "We're having trouble processing your RSVP. Please try again.",
+				"error": "Unable to save your RSVP. Please try again.",
 			})
 			return
 		}
@@ -48,17 +48,6 @@ func handleRSVPSubmission(container *container.Container) gin.HandlerFunc {
 			})
 			return
 		}
-
-		// Update only the attending status
-		existingGuest.Attending = sql.NullBool{Bool: request.Attending, Valid: true}
-		log.Printf("Updating RSVP for %s to %t", request.Name, request.Attending)
-		if err := container.GuestService.UpdateGuest(existingGuest); err != nil {
-			log.Printf("Failed to update RSVP: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Unable to save your RSVP. Please try again.",
-			})
-			return
-		}
 		log.Printf("Successfully updated RSVP for %s", request.Name)
 
 		var statusMessage string
@@ -2,14 +2,17 @@ package routes
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/middleware/dbdeadline"
 	"wedding-invitation-backend/models"
 )
 
@@ -27,7 +30,7 @@ func TestRSVPSubmission_Attending(t *testing.T) {
 	}
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return testGuest, nil
 		},
 		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
@@ -69,7 +72,7 @@ func TestRSVPSubmission_NotAttending(t *testing.T) {
 	}
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return testGuest, nil
 		},
 		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
@@ -105,7 +108,7 @@ func TestRSVPSubmission_GuestNotFound(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
@@ -132,11 +135,44 @@ func TestRSVPSubmission_GuestNotFound(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "couldn't find your guest information")
 }
 
+func TestRSVPSubmission_DatabaseDeadline(t *testing.T) {
+	setupTestConfig()
+
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("testuser"), nil
+		},
+		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+			// A query stuck behind a database lock until the deadline
+			time.Sleep(50 * time.Millisecond)
+			return nil, context.DeadlineExceeded
+		},
+	}
+
+	router, _ := setupTestRouter(mockGuest, nil, nil)
+	router.Use(dbdeadline.Middleware(10 * time.Millisecond))
+	c := setupTestContainer(mockGuest, nil, nil)
+	SetupRSVPRoutes(router.Group("/"), c)
+
+	token := generateTestToken("testuser")
+	jsonBody, _ := json.Marshal(map[string]interface{}{"name": "testuser", "attending": true})
+
+	req := httptest.NewRequest("POST", "/rsvp", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Service temporarily unavailable")
+}
+
 func TestRSVPSubmission_InvalidRequest(t *testing.T) {
 	setupTestConfig()
 
 	mockGuest := &mockGuestService{
-		ValidateGuestAccessFunc: func(name string) (*models.Guest, error) {
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
 			return createTestGuest("testuser"), nil
 		},
 	}
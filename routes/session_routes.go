@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSessionRoutes registers the admin routes that list and sign out
+// guest sessions, e.g. when an invite link was forwarded around
+func SetupSessionRoutes(r *gin.RouterGroup, c *container.Container) {
+	planner := adminauth.RequireRole(models.AdminRolePlanner)
+	r.GET("/guests/:id/sessions", planner, handleGetGuestSessions(c))
+	r.DELETE("/guests/:id/sessions", planner, handleRevokeGuestSessions(c))
+	r.DELETE("/sessions/:id", planner, handleRevokeSession(c))
+}
+
+func handleGetGuestSessions(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid guest ID.",
+			})
+			return
+		}
+
+		sessions, err := container.SessionService.GetGuestSessions(guestID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to load sessions. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"sessions": sessions,
+			"count":    len(sessions),
+		})
+	}
+}
+
+func handleRevokeGuestSessions(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid guest ID.",
+			})
+			return
+		}
+
+		revoked, err := container.SessionService.RevokeGuestSessions(guestID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to sign out this guest. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if len(revoked) > 0 {
+			recordAudit(c, container, models.AuditActionGuestSessionsRevoke, models.AuditTargetGuest, services.AuditChange{
+				TargetID: auditTargetID(guestID),
+				After:    gin.H{"RevokedSessions": revoked},
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Guest signed out on all devices.",
+			"revoked": len(revoked),
+		})
+	}
+}
+
+func handleRevokeSession(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+
+		before, err := container.SessionService.GetSession(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to sign out this session. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		session, err := container.SessionService.RevokeSession(id)
+		if err != nil {
+			if errors.Is(err, services.ErrSessionNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Session not found.",
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Unable to sign out this session. Please try again.",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		recordAudit(c, container, models.AuditActionSessionRevoke, models.AuditTargetSession, services.AuditChange{
+			TargetID: id,
+			Before:   before,
+			After:    session,
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Session signed out.",
+			"session": session,
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/services"
+)
+
+// mockSessionService implements services.SessionServiceInterface for testing
+type mockSessionService struct {
+	StartSessionFunc        func(guest *models.Guest, userAgent, ipAddress string) (*models.Session, string, error)
+	RefreshSessionFunc      func(refreshToken string) (*models.Session, string, error)
+	IsSessionActiveFunc     func(id string) (bool, error)
+	GetSessionFunc          func(id string) (*models.Session, error)
+	GetGuestSessionsFunc    func(guestID int64) ([]models.Session, error)
+	RevokeSessionFunc       func(id string) (*models.Session, error)
+	RevokeGuestSessionsFunc func(guestID int64) ([]string, error)
+}
+
+func (m *mockSessionService) StartSession(guest *models.Guest, userAgent, ipAddress string) (*models.Session, string, error) {
+	if m.StartSessionFunc != nil {
+		return m.StartSessionFunc(guest, userAgent, ipAddress)
+	}
+	now := time.Now()
+	session := &models.Session{ID: "test-session", GuestID: guest.ID, GuestName: guest.Name, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	return session, "test-refresh-token", nil
+}
+
+func (m *mockSessionService) RefreshSession(refreshToken string) (*models.Session, string, error) {
+	if m.RefreshSessionFunc != nil {
+		return m.RefreshSessionFunc(refreshToken)
+	}
+	return nil, "", services.ErrInvalidRefreshToken
+}
+
+func (m *mockSessionService) IsSessionActive(id string) (bool, error) {
+	if m.IsSessionActiveFunc != nil {
+		return m.IsSessionActiveFunc(id)
+	}
+	return true, nil
+}
+
+func (m *mockSessionService) GetSession(id string) (*models.Session, error) {
+	if m.GetSessionFunc != nil {
+		return m.GetSessionFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockSessionService) GetGuestSessions(guestID int64) ([]models.Session, error) {
+	if m.GetGuestSessionsFunc != nil {
+		return m.GetGuestSessionsFunc(guestID)
+	}
+	return []models.Session{}, nil
+}
+
+func (m *mockSessionService) RevokeSession(id string) (*models.Session, error) {
+	if m.RevokeSessionFunc != nil {
+		return m.RevokeSessionFunc(id)
+	}
+	return nil, services.ErrSessionNotFound
+}
+
+func (m *mockSessionService) RevokeGuestSessions(guestID int64) ([]string, error) {
+	if m.RevokeGuestSessionsFunc != nil {
+		return m.RevokeGuestSessionsFunc(guestID)
+	}
+	return nil, nil
+}
+
+func (m *mockSessionService) PruneExpiredSessions() (int64, error) {
+	return 0, nil
+}
+
+var _ services.SessionServiceInterface = (*mockSessionService)(nil)
+
+func TestGetGuestSessions(t *testing.T) {
+	mockSessions := &mockSessionService{
+		GetGuestSessionsFunc: func(guestID int64) ([]models.Session, error) {
+			assert.Equal(t, int64(12), guestID)
+			return []models.Session{{ID: "abc", GuestID: 12, UserAgent: "Mozilla/5.0"}}, nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.SessionService = mockSessions
+	SetupSessionRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("GET", "/admin/guests/12/sessions", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"UserAgent":"Mozilla/5.0"`)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+}
+
+func TestRevokeSession_RecordsAudit(t *testing.T) {
+	mockSessions := &mockSessionService{
+		GetSessionFunc: func(id string) (*models.Session, error) {
+			return &models.Session{ID: id, GuestID: 12}, nil
+		},
+		RevokeSessionFunc: func(id string) (*models.Session, error) {
+			assert.Equal(t, "abc", id)
+			session := &models.Session{ID: id, GuestID: 12}
+			session.RevokedAt.Time = time.Now()
+			session.RevokedAt.Valid = true
+			return session, nil
+		},
+	}
+	var action string
+	var recorded []services.AuditChange
+	mockAudit := &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, a, targetType string, changes ...services.AuditChange) error {
+			action = a
+			assert.Equal(t, models.AuditTargetSession, targetType)
+			recorded = changes
+			return nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.SessionService = mockSessions
+	c.AuditService = mockAudit
+	SetupSessionRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/sessions/abc", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.AuditActionSessionRevoke, action)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, "abc", recorded[0].TargetID)
+	assert.False(t, recorded[0].Before.(*models.Session).RevokedAt.Valid)
+	assert.True(t, recorded[0].After.(*models.Session).RevokedAt.Valid)
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	SetupSessionRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/sessions/missing", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRevokeGuestSessions(t *testing.T) {
+	mockSessions := &mockSessionService{
+		RevokeGuestSessionsFunc: func(guestID int64) ([]string, error) {
+			assert.Equal(t, int64(12), guestID)
+			return []string{"abc", "def"}, nil
+		},
+	}
+	var recorded []services.AuditChange
+	mockAudit := &mockAuditService{
+		RecordFunc: func(actor services.AuditActor, action, targetType string, changes ...services.AuditChange) error {
+			assert.Equal(t, models.AuditActionGuestSessionsRevoke, action)
+			assert.Equal(t, models.AuditTargetGuest, targetType)
+			recorded = changes
+			return nil
+		},
+	}
+
+	router, w := setupTestRouter(nil, nil, nil)
+	c := setupTestContainer(nil, nil, nil)
+	c.SessionService = mockSessions
+	c.AuditService = mockAudit
+	SetupSessionRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/guests/12/sessions", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"revoked":2`)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, "12", recorded[0].TargetID)
+}
+
+func TestSessionRoutes_PlannerOnly(t *testing.T) {
+	router, w := setupRoleTestRouter(models.AdminRoleModerator)
+	c := setupTestContainer(nil, nil, nil)
+	SetupSessionRoutes(router.Group("/admin"), c)
+
+	req := httptest.NewRequest("DELETE", "/admin/guests/12/sessions", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRevokedSessionIsRejected(t *testing.T) {
+	setupTestConfig()
+	mockGuest := &mockGuestService{
+		ValidateGuestAccessFunc: func(guestID int64) (*models.Guest, error) {
+			return createTestGuest("alice"), nil
+		},
+	}
+	c := setupTestContainer(mockGuest, nil, nil)
+	c.SessionService = &mockSessionService{
+		IsSessionActiveFunc: func(id string) (bool, error) {
+			return false, nil
+		},
+	}
+
+	router, w := setupTestRouter(mockGuest, nil, nil)
+	SetupRoutes(router, c)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestToken("alice"))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"net/http"
+
+	"wedding-invitation-backend/container"
+	"wedding-invitation-backend/errors"
+	"wedding-invitation-backend/middleware/adminauth"
+	"wedding-invitation-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupStatsRoutes registers the RSVP summary, for dashboards and the
+// check-in app
+func SetupStatsRoutes(r *gin.RouterGroup, c *container.Container) {
+	r.GET("/stats", adminauth.RequireScope(models.ScopeStatsRead, models.AdminRoles...), handleGetStats(c))
+}
+
+func handleGetStats(container *container.Container) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		guests, err := container.GuestService.GetAllGuests(c.Request.Context())
+		if err != nil {
+			c.Error(errors.WrapError(err, "Failed to retrieve RSVP stats"))
+			c.Abort()
+			return
+		}
+
+		var attending, declined, awaiting, headcount, opened int
+		for _, guest := range guests {
+			switch {
+			case !guest.Attending.Valid:
+				awaiting++
+			case guest.Attending.Bool:
+				attending++
+				headcount += 1 + guest.PlusOnes
+			default:
+				declined++
+			}
+			if guest.FirstOpenedAt.Valid {
+				opened++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"guests":             len(guests),
+			"attending":          attending,
+			"declined":           declined,
+			"awaiting_reply":     awaiting,
+			"headcount":          headcount,
+			"invitations_opened": opened,
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinAdminPasswordLength is the shortest password accepted for admin accounts
+const MinAdminPasswordLength = 10
+
+var (
+	// ErrInvalidCredentials is returned for an unknown username or wrong password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidAdminRole is returned for roles outside models.AdminRoles
+	ErrInvalidAdminRole = errors.New("invalid admin role")
+	// ErrInvalidAdminUsername is returned for empty or malformed usernames
+	ErrInvalidAdminUsername = errors.New("username must be 3-64 characters without spaces")
+	// ErrWeakPassword is returned for passwords shorter than MinAdminPasswordLength
+	ErrWeakPassword = errors.New("password must be at least 10 characters")
+	// ErrLastOwner is returned when a change would leave no owner account
+	ErrLastOwner = errors.New("at least one owner account is required")
+)
+
+// dummyPasswordHash is compared against when a username does not exist, so
+// failed logins take the same time whether or not the account exists
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+// AdminService handles admin accounts and authentication
+type AdminService struct {
+	adminRepo repositories.AdminRepository
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(adminRepo repositories.AdminRepository) *AdminService {
+	return &AdminService{adminRepo: adminRepo}
+}
+
+// Authenticate checks a username and password and records the login
+func (as *AdminService) Authenticate(username, password string) (*models.Admin, error) {
+	admin, err := as.adminRepo.GetByUsername(strings.TrimSpace(username))
+	if err != nil {
+		return nil, err
+	}
+
+	if admin == nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := as.adminRepo.RecordLogin(admin.ID); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// CreateAdmin creates a new admin account with a hashed password
+func (as *AdminService) CreateAdmin(username, password, role string) (*models.Admin, error) {
+	username = strings.TrimSpace(username)
+	if len(username) < 3 || len(username) > 64 || strings.ContainsAny(username, " \t\r\n") {
+		return nil, ErrInvalidAdminUsername
+	}
+	if !models.IsValidAdminRole(role) {
+		return nil, ErrInvalidAdminRole
+	}
+
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := &models.Admin{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+	}
+	if err := as.adminRepo.Create(admin); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// BootstrapOwner creates the first owner account. It does nothing and
+// returns false if any admin account already exists.
+func (as *AdminService) BootstrapOwner(username, password string) (*models.Admin, bool, error) {
+	count, err := as.adminRepo.Count()
+	if err != nil {
+		return nil, false, err
+	}
+	if count > 0 {
+		return nil, false, nil
+	}
+
+	admin, err := as.CreateAdmin(username, password, models.AdminRoleOwner)
+	if err != nil {
+		return nil, false, err
+	}
+	return admin, true, nil
+}
+
+// GetAdminByID retrieves an admin by ID. Returns nil if not found.
+func (as *AdminService) GetAdminByID(id int64) (*models.Admin, error) {
+	return as.adminRepo.GetByID(id)
+}
+
+// GetAdminByUsername retrieves an admin by username. Returns nil if not found.
+func (as *AdminService) GetAdminByUsername(username string) (*models.Admin, error) {
+	return as.adminRepo.GetByUsername(username)
+}
+
+// GetAllAdmins retrieves all admin accounts
+func (as *AdminService) GetAllAdmins() ([]models.Admin, error) {
+	return as.adminRepo.GetAll()
+}
+
+// UpdateAdminRole changes an admin's role. Returns nil if the admin does not exist.
+func (as *AdminService) UpdateAdminRole(id int64, role string) (*models.Admin, error) {
+	if !models.IsValidAdminRole(role) {
+		return nil, ErrInvalidAdminRole
+	}
+
+	admin, err := as.adminRepo.GetByID(id)
+	if err != nil || admin == nil {
+		return nil, err
+	}
+	if admin.Role == role {
+		return admin, nil
+	}
+	if err := as.ensureOtherOwner(admin); err != nil {
+		return nil, err
+	}
+
+	if err := as.adminRepo.UpdateRole(id, role); err != nil {
+		return nil, err
+	}
+	admin.Role = role
+	return admin, nil
+}
+
+// ChangePassword replaces an admin's password. Returns false if the admin does not exist.
+func (as *AdminService) ChangePassword(id int64, password string) (bool, error) {
+	hash, err := hashAdminPassword(password)
+	if err != nil {
+		return false, err
+	}
+
+	admin, err := as.adminRepo.GetByID(id)
+	if err != nil || admin == nil {
+		return false, err
+	}
+	if err := as.adminRepo.UpdatePassword(id, hash); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteAdmin removes an admin account. Returns nil if the admin does not exist.
+func (as *AdminService) DeleteAdmin(id int64) (*models.Admin, error) {
+	admin, err := as.adminRepo.GetByID(id)
+	if err != nil || admin == nil {
+		return nil, err
+	}
+	if err := as.ensureOtherOwner(admin); err != nil {
+		return nil, err
+	}
+
+	if err := as.adminRepo.Delete(id); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// ensureOtherOwner returns ErrLastOwner if admin is the only owner left
+func (as *AdminService) ensureOtherOwner(admin *models.Admin) error {
+	if admin.Role != models.AdminRoleOwner {
+		return nil
+	}
+	owners, err := as.adminRepo.CountByRole(models.AdminRoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func hashAdminPassword(password string) (string, error) {
+	if len(password) < MinAdminPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mockAdminRepo implements repositories.AdminRepository using function fields
+type mockAdminRepo struct {
+	CreateFunc         func(admin *models.Admin) error
+	GetByUsernameFunc  func(username string) (*models.Admin, error)
+	GetByIDFunc        func(id int64) (*models.Admin, error)
+	GetAllFunc         func() ([]models.Admin, error)
+	CountFunc          func() (int, error)
+	CountByRoleFunc    func(role string) (int, error)
+	UpdateRoleFunc     func(id int64, role string) error
+	UpdatePasswordFunc func(id int64, passwordHash string) error
+	RecordLoginFunc    func(id int64) error
+	DeleteFunc         func(id int64) error
+}
+
+func (m *mockAdminRepo) Create(admin *models.Admin) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(admin)
+	}
+	return nil
+}
+
+func (m *mockAdminRepo) GetByUsername(username string) (*models.Admin, error) {
+	if m.GetByUsernameFunc != nil {
+		return m.GetByUsernameFunc(username)
+	}
+	return nil, nil
+}
+
+func (m *mockAdminRepo) GetByID(id int64) (*models.Admin, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockAdminRepo) GetAll() ([]models.Admin, error) {
+	if m.GetAllFunc != nil {
+		return m.GetAllFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockAdminRepo) Count() (int, error) {
+	if m.CountFunc != nil {
+		return m.CountFunc()
+	}
+	return 0, nil
+}
+
+func (m *mockAdminRepo) CountByRole(role string) (int, error) {
+	if m.CountByRoleFunc != nil {
+		return m.CountByRoleFunc(role)
+	}
+	return 0, nil
+}
+
+func (m *mockAdminRepo) UpdateRole(id int64, role string) error {
+	if m.UpdateRoleFunc != nil {
+		return m.UpdateRoleFunc(id, role)
+	}
+	return nil
+}
+
+func (m *mockAdminRepo) UpdatePassword(id int64, passwordHash string) error {
+	if m.UpdatePasswordFunc != nil {
+		return m.UpdatePasswordFunc(id, passwordHash)
+	}
+	return nil
+}
+
+func (m *mockAdminRepo) RecordLogin(id int64) error {
+	if m.RecordLoginFunc != nil {
+		return m.RecordLoginFunc(id)
+	}
+	return nil
+}
+
+func (m *mockAdminRepo) Delete(id int64) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(id)
+	}
+	return nil
+}
+
+func testAdmin(t *testing.T, id int64, role, password string) *models.Admin {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return &models.Admin{ID: id, Username: "alice", PasswordHash: string(hash), Role: role}
+}
+
+func TestAdminService_Authenticate_Success(t *testing.T) {
+	admin := testAdmin(t, 1, models.AdminRoleOwner, "correct horse battery")
+	recorded := false
+	repo := &mockAdminRepo{
+		GetByUsernameFunc: func(username string) (*models.Admin, error) {
+			assert.Equal(t, "alice", username)
+			return admin, nil
+		},
+		RecordLoginFunc: func(id int64) error {
+			recorded = true
+			return nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	result, err := service.Authenticate(" alice ", "correct horse battery")
+
+	assert.NoError(t, err)
+	assert.Equal(t, admin, result)
+	assert.True(t, recorded)
+}
+
+func TestAdminService_Authenticate_WrongPassword(t *testing.T) {
+	admin := testAdmin(t, 1, models.AdminRoleOwner, "correct horse battery")
+	repo := &mockAdminRepo{
+		GetByUsernameFunc: func(username string) (*models.Admin, error) {
+			return admin, nil
+		},
+		RecordLoginFunc: func(id int64) error {
+			t.Error("failed login must not be recorded as a login")
+			return nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	result, err := service.Authenticate("alice", "wrong password")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Nil(t, result)
+}
+
+func TestAdminService_Authenticate_UnknownUser(t *testing.T) {
+	service := NewAdminService(&mockAdminRepo{})
+
+	result, err := service.Authenticate("nobody", "whatever-password")
+
+	// Unknown users get the same error as wrong passwords
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Nil(t, result)
+}
+
+func TestAdminService_CreateAdmin(t *testing.T) {
+	var stored *models.Admin
+	repo := &mockAdminRepo{
+		CreateFunc: func(admin *models.Admin) error {
+			stored = admin
+			return nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	admin, err := service.CreateAdmin("bob", "long enough password", models.AdminRolePlanner)
+
+	assert.NoError(t, err)
+	assert.Equal(t, stored, admin)
+	assert.Equal(t, models.AdminRolePlanner, admin.Role)
+	assert.NotEqual(t, "long enough password", admin.PasswordHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte("long enough password")))
+}
+
+func TestAdminService_CreateAdmin_Validation(t *testing.T) {
+	repo := &mockAdminRepo{
+		CreateFunc: func(admin *models.Admin) error {
+			t.Error("invalid admin must not be stored")
+			return nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	_, err := service.CreateAdmin("bob", "short", models.AdminRoleViewer)
+	assert.ErrorIs(t, err, ErrWeakPassword)
+
+	_, err = service.CreateAdmin("bob", "long enough password", "superuser")
+	assert.ErrorIs(t, err, ErrInvalidAdminRole)
+
+	_, err = service.CreateAdmin("bo b", "long enough password", models.AdminRoleViewer)
+	assert.ErrorIs(t, err, ErrInvalidAdminUsername)
+}
+
+func TestAdminService_BootstrapOwner(t *testing.T) {
+	count := 0
+	repo := &mockAdminRepo{
+		CountFunc: func() (int, error) {
+			return count, nil
+		},
+		CreateFunc: func(admin *models.Admin) error {
+			count++
+			return nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	admin, created, err := service.BootstrapOwner("alice", "long enough password")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, models.AdminRoleOwner, admin.Role)
+
+	// Later starts leave existing accounts alone
+	admin, created, err = service.BootstrapOwner("alice", "long enough password")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Nil(t, admin)
+}
+
+func TestAdminService_LastOwnerIsProtected(t *testing.T) {
+	owner := &models.Admin{ID: 1, Username: "alice", Role: models.AdminRoleOwner}
+	repo := &mockAdminRepo{
+		GetByIDFunc: func(id int64) (*models.Admin, error) {
+			return owner, nil
+		},
+		CountByRoleFunc: func(role string) (int, error) {
+			return 1, nil
+		},
+		UpdateRoleFunc: func(id int64, role string) error {
+			t.Error("last owner must not be demoted")
+			return nil
+		},
+		DeleteFunc: func(id int64) error {
+			t.Error("last owner must not be deleted")
+			return nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	_, err := service.UpdateAdminRole(1, models.AdminRoleViewer)
+	assert.ErrorIs(t, err, ErrLastOwner)
+
+	_, err = service.DeleteAdmin(1)
+	assert.ErrorIs(t, err, ErrLastOwner)
+}
+
+func TestAdminService_UpdateAdminRole(t *testing.T) {
+	viewer := &models.Admin{ID: 2, Username: "bob", Role: models.AdminRoleViewer}
+	repo := &mockAdminRepo{
+		GetByIDFunc: func(id int64) (*models.Admin, error) {
+			if id == 2 {
+				return viewer, nil
+			}
+			return nil, nil
+		},
+	}
+	service := NewAdminService(repo)
+
+	admin, err := service.UpdateAdminRole(2, models.AdminRoleModerator)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdminRoleModerator, admin.Role)
+
+	admin, err = service.UpdateAdminRole(404, models.AdminRoleModerator)
+	assert.NoError(t, err)
+	assert.Nil(t, admin)
+}
+
+func TestAdminService_ChangePassword_RepoError(t *testing.T) {
+	repo := &mockAdminRepo{
+		GetByIDFunc: func(id int64) (*models.Admin, error) {
+			return &models.Admin{ID: id}, nil
+		},
+		UpdatePasswordFunc: func(id int64, passwordHash string) error {
+			return errors.New("database error")
+		},
+	}
+	service := NewAdminService(repo)
+
+	changed, err := service.ChangePassword(1, "long enough password")
+
+	assert.Error(t, err)
+	assert.False(t, changed)
+}
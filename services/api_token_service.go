@@ -0,0 +1,152 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+// APITokenPrefix starts every admin API token, so leaked tokens are easy to
+// recognise in logs and by secret scanners
+const APITokenPrefix = "wit_"
+
+// apiTokenUseInterval limits how often last-used times are written, so a
+// busy script does not cause a write per request
+const apiTokenUseInterval = time.Minute
+
+var (
+	// ErrInvalidAPIToken is returned for unknown, expired and revoked tokens
+	ErrInvalidAPIToken = errors.New("invalid API token")
+	// ErrAPITokenNotFound is returned when revoking a token that does not exist
+	ErrAPITokenNotFound = errors.New("API token not found")
+	// ErrInvalidAPITokenScope is returned when no scopes, or scopes outside
+	// models.APITokenScopes, are requested
+	ErrInvalidAPITokenScope = errors.New("invalid API token scope")
+	// ErrInvalidAPITokenExpiry is returned for expiry times in the past
+	ErrInvalidAPITokenExpiry = errors.New("API token expiry must be in the future")
+)
+
+// APITokenService issues the API tokens that scripts use to call /admin
+type APITokenService struct {
+	repo repositories.APITokenRepository
+	now  func() time.Time
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(repo repositories.APITokenRepository) *APITokenService {
+	return &APITokenService{repo: repo, now: time.Now}
+}
+
+// CreateToken issues a token with the given scopes. A zero expiresAt gives a
+// token that never expires. The token is returned only here; it is stored
+// hashed.
+func (s *APITokenService) CreateToken(name string, scopes []string, expiresAt time.Time, createdBy string) (*models.APIToken, string, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now().UTC()
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return nil, "", ErrInvalidAPITokenExpiry
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	secret := APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	token := &models.APIToken{
+		Name:      strings.TrimSpace(name),
+		TokenHash: hashAPIToken(secret),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: sql.NullTime{Time: expiresAt.UTC(), Valid: !expiresAt.IsZero()},
+	}
+	if err := s.repo.Create(token); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+// GetAllTokens returns every token, newest first
+func (s *APITokenService) GetAllTokens() ([]models.APIToken, error) {
+	return s.repo.FindAll()
+}
+
+// RevokeToken stops a token from working and returns it
+func (s *APITokenService) RevokeToken(id int64) (*models.APIToken, error) {
+	if err := s.repo.Revoke(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+	return s.repo.FindByID(id)
+}
+
+// Authenticate returns the token matching secret and records its use.
+// Unknown, expired and revoked tokens give ErrInvalidAPIToken.
+func (s *APITokenService) Authenticate(secret string) (*models.APIToken, error) {
+	if !strings.HasPrefix(secret, APITokenPrefix) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	token, err := s.repo.FindByHash(hashAPIToken(secret))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if token == nil || !token.IsActive(now) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	if !token.LastUsedAt.Valid || now.Sub(token.LastUsedAt.Time) >= apiTokenUseInterval {
+		// A failed write must not lock the script out
+		if err := s.repo.MarkUsed(token.ID, now); err != nil {
+			log.Printf("Failed to record use of API token %d: %v", token.ID, err)
+		} else {
+			token.LastUsedAt = sql.NullTime{Time: now, Valid: true}
+		}
+	}
+	return token, nil
+}
+
+// normalizeScopes checks scopes against models.APITokenScopes and drops
+// duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidAPITokenScope
+	}
+
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !models.IsValidAPITokenScope(scope) {
+			return nil, ErrInvalidAPITokenScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+// hashAPIToken returns the hex SHA-256 of a token. The tokens are random,
+// so a fast unsalted hash is enough.
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
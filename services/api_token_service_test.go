@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAPITokenRepo implements repositories.APITokenRepository in memory
+type mockAPITokenRepo struct {
+	tokens    map[int64]*models.APIToken
+	markCalls int
+}
+
+func newMockAPITokenRepo() *mockAPITokenRepo {
+	return &mockAPITokenRepo{tokens: make(map[int64]*models.APIToken)}
+}
+
+func (m *mockAPITokenRepo) Create(token *models.APIToken) error {
+	token.ID = int64(len(m.tokens) + 1)
+	copied := *token
+	m.tokens[token.ID] = &copied
+	return nil
+}
+
+func (m *mockAPITokenRepo) FindByID(id int64) (*models.APIToken, error) {
+	if token, ok := m.tokens[id]; ok {
+		copied := *token
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAPITokenRepo) FindByHash(tokenHash string) (*models.APIToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAPITokenRepo) FindAll() ([]models.APIToken, error) {
+	tokens := []models.APIToken{}
+	for _, token := range m.tokens {
+		tokens = append(tokens, *token)
+	}
+	return tokens, nil
+}
+
+func (m *mockAPITokenRepo) Revoke(id int64) error {
+	token, ok := m.tokens[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	token.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (m *mockAPITokenRepo) MarkUsed(id int64, at time.Time) error {
+	m.markCalls++
+	m.tokens[id].LastUsedAt = sql.NullTime{Time: at, Valid: true}
+	return nil
+}
+
+func TestAPITokenService_CreateAndAuthenticate(t *testing.T) {
+	repo := newMockAPITokenRepo()
+	svc := NewAPITokenService(repo)
+
+	token, secret, err := svc.CreateToken(" sync ", []string{models.ScopeGuestsRead, models.ScopeGuestsRead, models.ScopeStatsRead}, time.Time{}, "alice")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, APITokenPrefix))
+	assert.Equal(t, "sync", token.Name)
+	assert.Equal(t, []string{models.ScopeGuestsRead, models.ScopeStatsRead}, token.Scopes)
+	assert.False(t, token.ExpiresAt.Valid)
+	assert.NotContains(t, repo.tokens[token.ID].TokenHash, secret, "only the hash is stored")
+
+	found, err := svc.Authenticate(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.True(t, found.LastUsedAt.Valid)
+
+	// Uses within a minute are not written again
+	_, err = svc.Authenticate(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.markCalls)
+
+	_, err = svc.Authenticate(secret + "x")
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	_, err = svc.Authenticate("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+}
+
+func TestAPITokenService_CreateValidation(t *testing.T) {
+	svc := NewAPITokenService(newMockAPITokenRepo())
+
+	_, _, err := svc.CreateToken("sync", nil, time.Time{}, "alice")
+	assert.ErrorIs(t, err, ErrInvalidAPITokenScope)
+	_, _, err = svc.CreateToken("sync", []string{"guests:delete"}, time.Time{}, "alice")
+	assert.ErrorIs(t, err, ErrInvalidAPITokenScope)
+	_, _, err = svc.CreateToken("sync", []string{models.ScopeStatsRead}, time.Now().Add(-time.Minute), "alice")
+	assert.ErrorIs(t, err, ErrInvalidAPITokenExpiry)
+}
+
+func TestAPITokenService_ExpiredAndRevoked(t *testing.T) {
+	svc := NewAPITokenService(newMockAPITokenRepo())
+
+	token, secret, err := svc.CreateToken("check-in", []string{models.ScopeGuestsRead}, time.Now().Add(time.Hour), "alice")
+	assert.NoError(t, err)
+	assert.True(t, token.ExpiresAt.Valid)
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = svc.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	svc.now = time.Now
+
+	revoked, err := svc.RevokeToken(token.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Valid)
+	_, err = svc.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrInvalidAPIToken)
+
+	_, err = svc.RevokeToken(99)
+	assert.ErrorIs(t, err, ErrAPITokenNotFound)
+}
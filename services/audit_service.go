@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+// AuditActor identifies who made an audited change and from where
+type AuditActor struct {
+	ID        int64
+	Name      string
+	IPAddress string
+}
+
+// AuditChange is one changed target. Before is nil for creations and After
+// is nil for deletions.
+type AuditChange struct {
+	TargetID string
+	Before   interface{}
+	After    interface{}
+}
+
+// AuditService records admin changes and serves the audit log
+type AuditService struct {
+	repo repositories.AuditRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record stores one entry per change, all in one transaction. The values
+// of the target type's sensitive fields are stored as models.AuditRedacted.
+func (s *AuditService) Record(actor AuditActor, action, targetType string, changes ...AuditChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	entries := make([]models.AuditEntry, 0, len(changes))
+	for _, change := range changes {
+		diff, err := diffJSON(change.Before, change.After, models.SensitiveAuditFields[targetType])
+		if err != nil {
+			return err
+		}
+		entries = append(entries, models.AuditEntry{
+			ActorID:    actor.ID,
+			ActorName:  actor.Name,
+			Action:     action,
+			TargetType: targetType,
+			TargetID:   change.TargetID,
+			Changes:    diff,
+			IPAddress:  actor.IPAddress,
+		})
+	}
+
+	return s.repo.Create(entries)
+}
+
+// GetAuditLog returns a page of audit entries matching filter, newest first
+func (s *AuditService) GetAuditLog(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error) {
+	return s.repo.Find(filter, limit, cursor)
+}
+
+// auditFieldChange is one field of the changes of an audit entry
+type auditFieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// diffJSON compares the JSON forms of before and after and returns the
+// top-level fields that differ as {"Field": {"before": ..., "after": ...}}.
+// Fields hidden from JSON, such as password hashes, never appear; the
+// values of masked fields that differ are replaced by models.AuditRedacted.
+func diffJSON(before, after interface{}, masked []string) (json.RawMessage, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]auditFieldChange)
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = auditFieldChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, seen := beforeFields[name]; !seen {
+			changes[name] = auditFieldChange{After: value}
+		}
+	}
+
+	for _, name := range masked {
+		change, ok := changes[name]
+		if !ok {
+			continue
+		}
+		if change.Before != nil {
+			change.Before = models.AuditRedacted
+		}
+		if change.After != nil {
+			change.After = models.AuditRedacted
+		}
+		changes[name] = change
+	}
+
+	// encoding/json sorts map keys, so identical changes encode identically
+	return json.Marshal(changes)
+}
+
+// jsonFields decodes v's JSON object form into its top-level fields
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
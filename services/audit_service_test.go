@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAuditRepo implements repositories.AuditRepository using function fields
+type mockAuditRepo struct {
+	CreateFunc     func(entries []models.AuditEntry) error
+	FindFunc       func(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error)
+	MaskFieldsFunc func(targetType string, fields []string) (int64, error)
+}
+
+func (m *mockAuditRepo) Create(entries []models.AuditEntry) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(entries)
+	}
+	return nil
+}
+
+// CreateContext stores through CreateFunc too
+func (m *mockAuditRepo) CreateContext(ctx context.Context, entries []models.AuditEntry) error {
+	return m.Create(entries)
+}
+
+func (m *mockAuditRepo) Find(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error) {
+	if m.FindFunc != nil {
+		return m.FindFunc(filter, limit, cursor)
+	}
+	return nil, nil
+}
+
+func (m *mockAuditRepo) MaskFields(ctx context.Context, targetType string, fields []string) (int64, error) {
+	if m.MaskFieldsFunc != nil {
+		return m.MaskFieldsFunc(targetType, fields)
+	}
+	return 0, nil
+}
+
+func TestAuditService_Record(t *testing.T) {
+	var stored []models.AuditEntry
+	repo := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			stored = entries
+			return nil
+		},
+	}
+	service := NewAuditService(repo)
+
+	before := models.Guest{ID: 1, Name: "alice", PlusOnes: 1}
+	after := before
+	after.PlusOnes = 2
+	after.DietaryRestrictions = sql.NullString{String: "vegan", Valid: true}
+
+	actor := AuditActor{ID: 3, Name: "carol", IPAddress: "10.0.0.1"}
+	err := service.Record(actor, models.AuditActionGuestUpdate, models.AuditTargetGuest,
+		AuditChange{TargetID: "1", Before: before, After: after},
+		AuditChange{TargetID: "2", After: models.Guest{ID: 2, Name: "bob"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, int64(3), stored[0].ActorID)
+	assert.Equal(t, "carol", stored[0].ActorName)
+	assert.Equal(t, "10.0.0.1", stored[0].IPAddress)
+	assert.JSONEq(t, `{
+		"PlusOnes": {"before": 1, "after": 2},
+		"DietaryRestrictions": {"before": "[redacted]", "after": "[redacted]"}
+	}`, string(stored[0].Changes))
+
+	// A creation lists every field with a null before
+	assert.Contains(t, string(stored[1].Changes), `"Name":{"before":null,"after":"bob"}`)
+}
+
+func TestAuditService_RecordHidesSecrets(t *testing.T) {
+	var stored []models.AuditEntry
+	repo := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			stored = entries
+			return nil
+		},
+	}
+	service := NewAuditService(repo)
+
+	admin := &models.Admin{ID: 2, Username: "bob", PasswordHash: "$2a$10$secret", Role: models.AdminRoleViewer}
+	err := service.Record(AuditActor{ID: 1, Name: "alice"}, models.AuditActionAdminDelete, models.AuditTargetAdmin,
+		AuditChange{TargetID: "2", Before: admin})
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(stored[0].Changes), "secret")
+	assert.Contains(t, string(stored[0].Changes), `"Role":{"before":"viewer","after":null}`)
+}
+
+func TestAuditService_RecordNilPointer(t *testing.T) {
+	var stored []models.AuditEntry
+	repo := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			stored = entries
+			return nil
+		},
+	}
+	service := NewAuditService(repo)
+
+	var missing *models.CommentWithGuest
+	err := service.Record(AuditActor{}, models.AuditActionCommentApprove, models.AuditTargetComment,
+		AuditChange{TargetID: "1", Before: missing, After: missing})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(stored[0].Changes))
+}
+
+func TestAuditService_RecordNothing(t *testing.T) {
+	repo := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			t.Error("nothing should be stored")
+			return nil
+		},
+	}
+
+	assert.NoError(t, NewAuditService(repo).Record(AuditActor{}, models.AuditActionGuestCreate, models.AuditTargetGuest))
+}
+
+func TestAuditService_RecordRepoError(t *testing.T) {
+	repo := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			return errors.New("database error")
+		},
+	}
+
+	err := NewAuditService(repo).Record(AuditActor{}, models.AuditActionGuestCreate, models.AuditTargetGuest,
+		AuditChange{TargetID: "1", After: models.Guest{ID: 1}})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"wedding-invitation-backend/database"
+)
+
+// ErrInvalidBackup is returned when an uploaded file cannot be restored
+var ErrInvalidBackup = database.ErrInvalidBackup
+
+const (
+	backupPrefix     = "guests-"
+	backupSuffix     = ".db"
+	backupTimeFormat = "20060102T150405.000Z"
+)
+
+// Backup is a snapshot file in the backup directory
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshot is a temporary snapshot opened for reading; Close deletes it
+type Snapshot struct {
+	*os.File
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Close closes and deletes the snapshot file
+func (s *Snapshot) Close() error {
+	err := s.File.Close()
+	if removeErr := os.Remove(s.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// BackupService writes snapshots of the SQLite database to a directory, on
+// a schedule and before restores, and keeps the newest of them
+type BackupService struct {
+	db        *sql.DB
+	dir       string
+	retention int
+	onRestore func()
+	now       func() time.Time
+
+	// mu serializes snapshots and restores
+	mu       sync.Mutex
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewBackupService creates a backup service writing to dir and keeping the
+// newest retention backups. onRestore, if set, runs after a restore, e.g. to
+// drop caches holding the old data.
+func NewBackupService(db *sql.DB, dir string, retention int, onRestore func()) *BackupService {
+	return &BackupService{
+		db:        db,
+		dir:       dir,
+		retention: retention,
+		onRestore: onRestore,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start writes a backup every interval until Stop is called
+func (s *BackupService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if backup, err := s.CreateBackup(context.Background()); err != nil {
+					log.Printf("Scheduled backup failed: %v", err)
+				} else {
+					log.Printf("Wrote scheduled backup %s", backup.Name)
+				}
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts scheduled backups
+func (s *BackupService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+// CreateBackup writes a snapshot to the backup directory and drops the
+// backups beyond the retention count
+func (s *BackupService) CreateBackup(ctx context.Context) (*Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createBackup(ctx, "")
+}
+
+// createBackup writes a snapshot named after the current time and label.
+// The snapshot is written under a temporary name first so a failed write
+// never looks like a backup. Callers hold mu.
+func (s *BackupService) createBackup(ctx context.Context, label string) (*Backup, error) {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+
+	createdAt := s.now().UTC()
+	name := backupPrefix + createdAt.Format(backupTimeFormat)
+	if label != "" {
+		name += "-" + label
+	}
+	name += backupSuffix
+
+	path := filepath.Join(s.dir, name)
+	tmpPath := path + ".tmp"
+	if err := database.Snapshot(ctx, s.db, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prune(); err != nil {
+		log.Printf("Failed to drop old backups: %v", err)
+	}
+	return &Backup{Name: name, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// ListBackups returns the backups in the backup directory, newest first
+func (s *BackupService) ListBackups() ([]Backup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, Backup{Name: name, Size: info.Size(), CreatedAt: info.ModTime().UTC()})
+	}
+	// Names start with the creation time, so they sort chronologically
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+// prune deletes the backups beyond the retention count; a retention of 0
+// keeps every backup
+func (s *BackupService) prune() error {
+	if s.retention <= 0 {
+		return nil
+	}
+	backups, err := s.ListBackups()
+	if err != nil {
+		return err
+	}
+	for i := s.retention; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(s.dir, backups[i].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenSnapshot writes a snapshot to a temporary file, outside the retention
+// count, and opens it for reading
+func (s *BackupService) OpenSnapshot(ctx context.Context) (*Snapshot, error) {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(s.dir, "download-*.db.tmp")
+	if err != nil {
+		return nil, err
+	}
+	// VACUUM INTO needs a path that does not exist
+	path := tmp.Name()
+	tmp.Close()
+	os.Remove(path)
+
+	createdAt := s.now().UTC()
+	if err := database.Snapshot(ctx, s.db, path); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	snapshot := &Snapshot{File: file, CreatedAt: createdAt}
+	info, err := file.Stat()
+	if err != nil {
+		snapshot.Close()
+		return nil, err
+	}
+	snapshot.Size = info.Size()
+	return snapshot, nil
+}
+
+// Restore replaces the database with the SQLite file read from r. The file
+// is checked and migrated to the current schema first; if it is not usable
+// ErrInvalidBackup is returned and nothing changes. The current data is
+// kept as a backup, which is returned, before it is replaced.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader) (*Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+	upload, err := os.CreateTemp(s.dir, "restore-*.db.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(upload.Name())
+
+	_, err = io.Copy(upload, r)
+	if closeErr := upload.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("saving upload: %w", err)
+	}
+
+	if err := database.ValidateBackup(ctx, upload.Name()); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.createBackup(ctx, "pre-restore")
+	if err != nil {
+		return nil, fmt.Errorf("backing up current data: %w", err)
+	}
+	if err := database.Restore(ctx, s.db, upload.Name()); err != nil {
+		return nil, fmt.Errorf("restoring backup: %w", err)
+	}
+
+	if s.onRestore != nil {
+		s.onRestore()
+	}
+	return previous, nil
+}
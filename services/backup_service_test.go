@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/database"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+// newTestBackupService returns a backup service for a migrated database
+// file, with a clock that advances a second per backup
+func newTestBackupService(t *testing.T, retention int, onRestore func()) (*BackupService, *sql.DB) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "guests.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewBackupService(db, filepath.Join(dir, "backups"), retention, onRestore)
+	clock := time.Date(2026, 6, 20, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return service, db
+}
+
+func TestBackupService_CreateBackupKeepsNewest(t *testing.T) {
+	service, _ := newTestBackupService(t, 2, nil)
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		backup, err := service.CreateBackup(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, backup.Size > 0)
+		names = append(names, backup.Name)
+	}
+	assert.Equal(t, "guests-20260620T120001.000Z.db", names[0])
+
+	backups, err := service.ListBackups()
+	assert.NoError(t, err)
+	if assert.Len(t, backups, 2) {
+		assert.Equal(t, names[2], backups[0].Name)
+		assert.Equal(t, names[1], backups[1].Name)
+	}
+}
+
+func TestBackupService_OpenSnapshotIsDeletedOnClose(t *testing.T) {
+	service, _ := newTestBackupService(t, 2, nil)
+
+	snapshot, err := service.OpenSnapshot(context.Background())
+	assert.NoError(t, err)
+	data, err := io.ReadAll(snapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot.Size, int64(len(data)))
+	assert.True(t, strings.HasPrefix(string(data), "SQLite format 3"))
+
+	assert.NoError(t, snapshot.Close())
+	_, err = os.Stat(snapshot.Name())
+	assert.True(t, os.IsNotExist(err))
+
+	// Downloads do not count as backups
+	backups, err := service.ListBackups()
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestBackupService_Restore(t *testing.T) {
+	restored := false
+	service, db := newTestBackupService(t, 5, func() { restored = true })
+	ctx := context.Background()
+
+	_, err := db.Exec("INSERT INTO guests (name) VALUES ('alice')")
+	assert.NoError(t, err)
+	snapshot, err := service.OpenSnapshot(ctx)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(snapshot)
+	assert.NoError(t, err)
+	snapshot.Close()
+
+	_, err = db.Exec("INSERT INTO guests (name) VALUES ('bob')")
+	assert.NoError(t, err)
+
+	previous, err := service.Restore(ctx, bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.True(t, restored)
+	assert.Contains(t, previous.Name, "-pre-restore")
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM guests").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	// The replaced data was kept, and no scratch files are left behind
+	entries, err := os.ReadDir(service.dir)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, previous.Name, entries[0].Name())
+	}
+}
+
+func TestBackupService_RestoreRejectsInvalidFile(t *testing.T) {
+	restored := false
+	service, db := newTestBackupService(t, 5, func() { restored = true })
+	_, err := db.Exec("INSERT INTO guests (name) VALUES ('alice')")
+	assert.NoError(t, err)
+
+	_, err = service.Restore(context.Background(), strings.NewReader("not a database"))
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+	assert.False(t, restored)
+
+	var count int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM guests").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	backups, err := service.ListBackups()
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+}
@@ -1,32 +1,63 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+
 	"wedding-invitation-backend/cache"
 	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/contentpolicy"
+	"wedding-invitation-backend/media"
 	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/pubsub"
 	"wedding-invitation-backend/repositories"
 )
 
+// Event types published by the comment service
+const (
+	EventCommentCreated  = "comment.created"
+	EventCommentApproved = "comment.approved"
+	EventCommentRemoved  = "comment.removed"
+)
+
+// ErrPhotoUploadsDisabled is returned when a photo is attached but no media store is configured
+var ErrPhotoUploadsDisabled = errors.New("photo uploads are not available")
+
 // CommentService handles comment business logic
 type CommentService struct {
 	commentRepo repositories.CommentRepository
 	guestService GuestServiceInterface
 	commentCache cache.CacheInterface
+	publisher    pubsub.Publisher
+	policy       *contentpolicy.Policy
+	photos       *media.Photos
+	tx           repositories.TxManager
 }
 
-// NewCommentService creates a new comment service
-func NewCommentService(commentRepo repositories.CommentRepository, guestService GuestServiceInterface) *CommentService {
+// NewCommentService creates a new comment service. The publisher, policy and
+// photos are optional; when set, comment changes are broadcast to live
+// subscribers, new comments are checked against the content policy and
+// guests may attach a photo. tx runs moderation changes.
+func NewCommentService(commentRepo repositories.CommentRepository, guestService GuestServiceInterface, publisher pubsub.Publisher, policy *contentpolicy.Policy, photos *media.Photos, tx repositories.TxManager) *CommentService {
 	return &CommentService{
 		commentRepo:  commentRepo,
 		guestService: guestService,
 		commentCache: cache.NewMemoryCache(config.CacheCommentTTL),
+		publisher:    publisher,
+		policy:       policy,
+		photos:       photos,
+		tx:           tx,
 	}
 }
 
-// CreateComment creates a new comment
-func (cs *CommentService) CreateComment(guestName, content string) (*models.CommentWithGuest, error) {
+// CreateComment creates a new comment. photo is the raw uploaded image and
+// may be nil; it is stored only after the content policy has accepted the text.
+func (cs *CommentService) CreateComment(ctx context.Context, guestID int64, content string, photo []byte) (*models.CommentWithGuest, error) {
 	// Validate guest exists
-	guest, err := cs.guestService.GetGuestByName(guestName)
+	guest, err := cs.guestService.GetGuestByID(ctx, guestID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,14 +66,49 @@ func (cs *CommentService) CreateComment(guestName, content string) (*models.Comm
 		return nil, nil // Guest not found
 	}
 	
+	// Apply content policy; rejections are returned as *contentpolicy.RejectedError
+	status := models.CommentStatusApproved
+	reason := ""
+	if cs.policy != nil {
+		verdict, err := cs.policy.Evaluate(ctx, contentpolicy.Input{GuestID: guest.ID, Content: content})
+		if err != nil {
+			return nil, err
+		}
+		content = verdict.Content
+		if verdict.Action == contentpolicy.ActionModerate {
+			status = models.CommentStatusPending
+			reason = verdict.Reason()
+		}
+	}
+	
+	// Process and store the photo before the comment references it
+	photoKey := ""
+	if len(photo) > 0 {
+		if cs.photos == nil {
+			return nil, ErrPhotoUploadsDisabled
+		}
+		photoKey, err = cs.photos.Save(photo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	
 	// Create comment
 	comment := &models.Comment{
-		GuestID: guest.ID,
-		Content: content,
+		GuestID:          guest.ID,
+		Content:          content,
+		Status:           status,
+		ModerationReason: reason,
+		PhotoKey:         photoKey,
 	}
 	
-	err = cs.commentRepo.Create(comment)
+	err = cs.commentRepo.Create(ctx, comment)
 	if err != nil {
+		if photoKey != "" {
+			if delErr := cs.photos.Delete(photoKey); delErr != nil {
+				log.Printf("Failed to delete orphaned photo %s: %v", photoKey, delErr)
+			}
+		}
 		return nil, err
 	}
 	
@@ -55,13 +121,18 @@ func (cs *CommentService) CreateComment(guestName, content string) (*models.Comm
 		GuestName: guest.Name,
 	}
 	
+	// Comments awaiting moderation are announced once approved
+	if comment.Status == models.CommentStatusApproved {
+		cs.publish(EventCommentCreated, commentWithGuest)
+	}
+	
 	return commentWithGuest, nil
 }
 
 // GetCommentsByGuest retrieves comments for a specific guest (cached)
-func (cs *CommentService) GetCommentsByGuest(guestName string) ([]models.Comment, error) {
+func (cs *CommentService) GetCommentsByGuest(ctx context.Context, guestID int64) ([]models.Comment, error) {
 	// Get guest to validate access
-	guest, err := cs.guestService.GetGuestByName(guestName)
+	guest, err := cs.guestService.GetGuestByID(ctx, guestID)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +142,7 @@ func (cs *CommentService) GetCommentsByGuest(guestName string) ([]models.Comment
 	}
 	
 	// Try cache first
-	cacheKey := "comments_guest_" + guestName
+	cacheKey := "comments_guest_" + strconv.FormatInt(guest.ID, 10)
 	if cached, found := cs.commentCache.Get(cacheKey); found {
 		if comments, ok := cached.([]models.Comment); ok {
 			return comments, nil
@@ -79,7 +150,7 @@ func (cs *CommentService) GetCommentsByGuest(guestName string) ([]models.Comment
 	}
 	
 	// Cache miss, get from repository
-	comments, err := cs.commentRepo.GetByGuestID(guest.ID)
+	comments, err := cs.commentRepo.GetByGuestID(ctx, guest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +162,7 @@ func (cs *CommentService) GetCommentsByGuest(guestName string) ([]models.Comment
 }
 
 // GetAllComments retrieves all comments (cached)
-func (cs *CommentService) GetAllComments() ([]models.Comment, error) {
+func (cs *CommentService) GetAllComments(ctx context.Context) ([]models.Comment, error) {
 	// Try cache first
 	if cached, found := cs.commentCache.Get("all_comments"); found {
 		if comments, ok := cached.([]models.Comment); ok {
@@ -100,7 +171,7 @@ func (cs *CommentService) GetAllComments() ([]models.Comment, error) {
 	}
 	
 	// Cache miss, get from repository
-	comments, err := cs.commentRepo.GetAll()
+	comments, err := cs.commentRepo.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +183,7 @@ func (cs *CommentService) GetAllComments() ([]models.Comment, error) {
 }
 
 // GetAllCommentsWithGuests retrieves all comments with guest names (cached)
-func (cs *CommentService) GetAllCommentsWithGuests(limit int, cursor string) (*models.PaginatedComments, error) {
+func (cs *CommentService) GetAllCommentsWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error) {
 	// Try cache first for first page (no cursor)
 	cacheKey := "all_comments_with_guests"
 	if cursor == "" {
@@ -124,7 +195,7 @@ func (cs *CommentService) GetAllCommentsWithGuests(limit int, cursor string) (*m
 	}
 	
 	// Cache miss or paginated request, get from repository
-	comments, err := cs.commentRepo.GetAllWithGuests(limit, cursor)
+	comments, err := cs.commentRepo.GetAllWithGuests(ctx, limit, cursor)
 	if err != nil {
 		return nil, err
 	}
@@ -135,4 +206,136 @@ func (cs *CommentService) GetAllCommentsWithGuests(limit int, cursor string) (*m
 	}
 	
 	return comments, nil
-}
\ No newline at end of file
+}
+
+// SearchComments runs a full-text search over comments (not cached)
+func (cs *CommentService) SearchComments(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+	return cs.commentRepo.Search(ctx, query, limit, cursor)
+}
+
+// GetCommentByID retrieves a comment in any status. Returns nil if it does not exist.
+func (cs *CommentService) GetCommentByID(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	return cs.commentRepo.GetByID(ctx, id)
+}
+
+// GetPendingComments retrieves comments awaiting moderation, oldest first
+func (cs *CommentService) GetPendingComments(ctx context.Context) ([]models.CommentWithGuest, error) {
+	return cs.commentRepo.GetByStatus(ctx, models.CommentStatusPending)
+}
+
+// ApproveComment makes a comment publicly visible. Returns nil if the comment does not exist.
+func (cs *CommentService) ApproveComment(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	comment, previousStatus, err := cs.setCommentStatus(ctx, id, models.CommentStatusApproved, "")
+	if err != nil || comment == nil {
+		return nil, err
+	}
+	
+	if previousStatus != models.CommentStatusApproved {
+		cs.publish(EventCommentApproved, comment)
+	}
+	
+	return comment, nil
+}
+
+// RejectComment hides a comment from the guestbook. Returns nil if the comment does not exist.
+func (cs *CommentService) RejectComment(ctx context.Context, id int64, reason string) (*models.CommentWithGuest, error) {
+	comment, previousStatus, err := cs.setCommentStatus(ctx, id, models.CommentStatusRejected, reason)
+	if err != nil || comment == nil {
+		return nil, err
+	}
+	
+	// Only comments that were on screen need to be taken down
+	if previousStatus == models.CommentStatusApproved {
+		cs.publish(EventCommentRemoved, comment)
+	}
+	
+	return comment, nil
+}
+
+// IsPhotoPublished reports whether key names the photo of an approved comment.
+// Photos of pending and rejected comments are not served.
+func (cs *CommentService) IsPhotoPublished(ctx context.Context, key string) (bool, error) {
+	status, err := cs.commentRepo.GetPhotoStatus(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return status == models.CommentStatusApproved, nil
+}
+
+// GetGuestbookForExport returns every approved comment with its guest name,
+// oldest first, for the printable keepsake
+func (cs *CommentService) GetGuestbookForExport(ctx context.Context) ([]models.CommentWithGuest, error) {
+	comments, err := cs.GetAllComments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	guests, err := cs.guestService.GetAllGuests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(guests))
+	for _, guest := range guests {
+		names[guest.ID] = guest.Name
+	}
+	
+	guestbook := make([]models.CommentWithGuest, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Status != models.CommentStatusApproved {
+			continue
+		}
+		guestbook = append(guestbook, models.CommentWithGuest{
+			Comment:   comment,
+			GuestName: names[comment.GuestID],
+		})
+	}
+	
+	// Comments are stored newest first; a book reads oldest first
+	sort.SliceStable(guestbook, func(i, j int) bool {
+		if guestbook[i].CreatedAt.Equal(guestbook[j].CreatedAt) {
+			return guestbook[i].ID < guestbook[j].ID
+		}
+		return guestbook[i].CreatedAt.Before(guestbook[j].CreatedAt)
+	})
+	
+	return guestbook, nil
+}
+
+// setCommentStatus updates a comment's status and returns the updated
+// comment along with its previous status. Both happen in one transaction,
+// so two moderators acting at once each see the status the other left.
+func (cs *CommentService) setCommentStatus(ctx context.Context, id int64, status, reason string) (*models.CommentWithGuest, string, error) {
+	var comment *models.CommentWithGuest
+	err := cs.tx.WithinTx(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		var err error
+		comment, err = repos.Comments.GetByID(ctx, id)
+		if err != nil || comment == nil {
+			return err
+		}
+		return repos.Comments.UpdateStatus(ctx, id, status, reason)
+	})
+	if err != nil || comment == nil {
+		return nil, "", err
+	}
+	
+	// Invalidate comment caches
+	cs.commentCache.Clear()
+	
+	previousStatus := comment.Status
+	comment.Status = status
+	comment.ModerationReason = reason
+	return comment, previousStatus, nil
+}
+
+// ClearCache drops every cached comment list, e.g. after a backup was
+// restored
+func (cs *CommentService) ClearCache() {
+	cs.commentCache.Clear()
+}
+
+// publish broadcasts an event if a publisher is configured
+func (cs *CommentService) publish(eventType string, data interface{}) {
+	if cs.publisher != nil {
+		cs.publisher.Publish(eventType, data)
+	}
+}
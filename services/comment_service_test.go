@@ -1,9 +1,20 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"errors"
+	"image"
+	"image/png"
+	"os"
 	"testing"
+	"time"
+	"wedding-invitation-backend/contentpolicy"
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/media"
 	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/pubsub"
 	"wedding-invitation-backend/repositories"
 
 	"github.com/stretchr/testify/assert"
@@ -15,103 +26,175 @@ type mockCommentRepo struct {
 	GetByGuestIDFunc     func(guestID int64) ([]models.Comment, error)
 	GetAllFunc           func() ([]models.Comment, error)
 	GetAllWithGuestsFunc func(limit int, cursor string) (*models.PaginatedComments, error)
+	SearchFunc           func(query string, limit int, cursor string) (*models.PaginatedSearchResults, error)
+	GetByIDFunc          func(id int64) (*models.CommentWithGuest, error)
+	GetByStatusFunc      func(status string) ([]models.CommentWithGuest, error)
+	UpdateStatusFunc     func(id int64, status, reason string) error
+	HasDuplicateFunc     func(guestID int64, content string) (bool, error)
+	ClearPhotosFunc      func(guestID int64) ([]string, error)
+	GetPhotoStatusFunc   func(key string) (string, error)
 }
 
-func (m *mockCommentRepo) Create(comment *models.Comment) error {
+func (m *mockCommentRepo) Create(ctx context.Context, comment *models.Comment) error {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(comment)
 	}
 	return nil
 }
 
-func (m *mockCommentRepo) GetByGuestID(guestID int64) ([]models.Comment, error) {
+func (m *mockCommentRepo) GetByGuestID(ctx context.Context, guestID int64) ([]models.Comment, error) {
 	if m.GetByGuestIDFunc != nil {
 		return m.GetByGuestIDFunc(guestID)
 	}
 	return nil, nil
 }
 
-func (m *mockCommentRepo) GetAll() ([]models.Comment, error) {
+func (m *mockCommentRepo) GetAll(ctx context.Context) ([]models.Comment, error) {
 	if m.GetAllFunc != nil {
 		return m.GetAllFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockCommentRepo) GetAllWithGuests(limit int, cursor string) (*models.PaginatedComments, error) {
+func (m *mockCommentRepo) GetAllWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error) {
 	if m.GetAllWithGuestsFunc != nil {
 		return m.GetAllWithGuestsFunc(limit, cursor)
 	}
 	return nil, nil
 }
 
+func (m *mockCommentRepo) Search(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+	if m.SearchFunc != nil {
+		return m.SearchFunc(query, limit, cursor)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentRepo) GetByID(ctx context.Context, id int64) (*models.CommentWithGuest, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentRepo) GetByStatus(ctx context.Context, status string) ([]models.CommentWithGuest, error) {
+	if m.GetByStatusFunc != nil {
+		return m.GetByStatusFunc(status)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentRepo) UpdateStatus(ctx context.Context, id int64, status, reason string) error {
+	if m.UpdateStatusFunc != nil {
+		return m.UpdateStatusFunc(id, status, reason)
+	}
+	return nil
+}
+
+func (m *mockCommentRepo) HasDuplicate(ctx context.Context, guestID int64, content string) (bool, error) {
+	if m.HasDuplicateFunc != nil {
+		return m.HasDuplicateFunc(guestID, content)
+	}
+	return false, nil
+}
+
+func (m *mockCommentRepo) ClearPhotos(ctx context.Context, guestID int64) ([]string, error) {
+	if m.ClearPhotosFunc != nil {
+		return m.ClearPhotosFunc(guestID)
+	}
+	return nil, nil
+}
+
+func (m *mockCommentRepo) GetPhotoStatus(ctx context.Context, key string) (string, error) {
+	if m.GetPhotoStatusFunc != nil {
+		return m.GetPhotoStatusFunc(key)
+	}
+	return "", nil
+}
+
 // Compile-time check
 var _ repositories.CommentRepository = (*mockCommentRepo)(nil)
 
 // mockGuestService implements GuestServiceInterface using function fields
 type mockGuestService struct {
 	GetGuestByNameFunc       func(name string) (*models.Guest, error)
+	GetGuestByIDFunc         func(id int64) (*models.Guest, error)
 	GetAllGuestsFunc         func() ([]models.Guest, error)
 	CreateGuestFunc          func(guest *models.Guest) error
 	UpdateGuestFunc          func(guest *models.Guest) error
+	SubmitRSVPFunc           func(name string, attending bool) (*models.Guest, error)
 	BulkCreateGuestsFunc     func(guests []models.Guest) error
 	BulkUpdateGuestsFunc     func(guests []models.Guest) error
-	MarkInvitationOpenedFunc func(name string) error
-	ValidateGuestAccessFunc  func(name string) (*models.Guest, error)
+	MarkInvitationOpenedFunc func(guestID int64) error
+	ValidateGuestAccessFunc  func(guestID int64) (*models.Guest, error)
 }
 
-func (m *mockGuestService) GetGuestByName(name string) (*models.Guest, error) {
+func (m *mockGuestService) GetGuestByName(ctx context.Context, name string) (*models.Guest, error) {
 	if m.GetGuestByNameFunc != nil {
 		return m.GetGuestByNameFunc(name)
 	}
 	return nil, nil
 }
 
-func (m *mockGuestService) GetAllGuests() ([]models.Guest, error) {
+func (m *mockGuestService) GetGuestByID(ctx context.Context, id int64) (*models.Guest, error) {
+	if m.GetGuestByIDFunc != nil {
+		return m.GetGuestByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestService) GetAllGuests(ctx context.Context) ([]models.Guest, error) {
 	if m.GetAllGuestsFunc != nil {
 		return m.GetAllGuestsFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockGuestService) CreateGuest(guest *models.Guest) error {
+func (m *mockGuestService) CreateGuest(ctx context.Context, guest *models.Guest) error {
 	if m.CreateGuestFunc != nil {
 		return m.CreateGuestFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestService) UpdateGuest(guest *models.Guest) error {
+func (m *mockGuestService) UpdateGuest(ctx context.Context, guest *models.Guest) error {
 	if m.UpdateGuestFunc != nil {
 		return m.UpdateGuestFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestService) BulkCreateGuests(guests []models.Guest) error {
+func (m *mockGuestService) SubmitRSVP(ctx context.Context, name string, attending bool) (*models.Guest, error) {
+	if m.SubmitRSVPFunc != nil {
+		return m.SubmitRSVPFunc(name, attending)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestService) BulkCreateGuests(ctx context.Context, guests []models.Guest) error {
 	if m.BulkCreateGuestsFunc != nil {
 		return m.BulkCreateGuestsFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestService) BulkUpdateGuests(guests []models.Guest) error {
+func (m *mockGuestService) BulkUpdateGuests(ctx context.Context, guests []models.Guest) error {
 	if m.BulkUpdateGuestsFunc != nil {
 		return m.BulkUpdateGuestsFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestService) MarkInvitationOpened(name string) error {
+func (m *mockGuestService) MarkInvitationOpened(ctx context.Context, guestID int64) error {
 	if m.MarkInvitationOpenedFunc != nil {
-		return m.MarkInvitationOpenedFunc(name)
+		return m.MarkInvitationOpenedFunc(guestID)
 	}
 	return nil
 }
 
-func (m *mockGuestService) ValidateGuestAccess(name string) (*models.Guest, error) {
+func (m *mockGuestService) ValidateGuestAccess(ctx context.Context, guestID int64) (*models.Guest, error) {
 	if m.ValidateGuestAccessFunc != nil {
-		return m.ValidateGuestAccessFunc(name)
+		return m.ValidateGuestAccessFunc(guestID)
 	}
 	return nil, nil
 }
@@ -119,14 +202,23 @@ func (m *mockGuestService) ValidateGuestAccess(name string) (*models.Guest, erro
 // Compile-time check
 var _ GuestServiceInterface = (*mockGuestService)(nil)
 
+// mockTx runs units of work straight on the given mock repository
+type mockTx struct {
+	comments repositories.CommentRepository
+}
+
+func (m mockTx) WithinTx(ctx context.Context, fn func(ctx context.Context, repos repositories.Repositories) error) error {
+	return fn(ctx, repositories.Repositories{Comments: m.comments})
+}
+
 func TestCommentService_CreateComment_Success(t *testing.T) {
 	guest := &models.Guest{
 		ID:   1,
 		Name: "john-doe",
 	}
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
-			assert.Equal(t, "john-doe", name)
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			assert.Equal(t, int64(1), id)
 			return guest, nil
 		},
 	}
@@ -138,12 +230,12 @@ func TestCommentService_CreateComment_Success(t *testing.T) {
 			return nil
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	result, err := service.CreateComment("john-doe", "Hello world")
+	result, err := service.CreateComment(context.Background(), 1, "Hello world", nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -155,17 +247,17 @@ func TestCommentService_CreateComment_Success(t *testing.T) {
 
 func TestCommentService_CreateComment_GuestNotFound(t *testing.T) {
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
 			return nil, nil // Guest not found
 		},
 	}
 	mockRepo := &mockCommentRepo{}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	result, err := service.CreateComment("unknown", "Hello world")
+	result, err := service.CreateComment(context.Background(), 99, "Hello world", nil)
 
 	assert.NoError(t, err)
 	assert.Nil(t, result)
@@ -174,17 +266,17 @@ func TestCommentService_CreateComment_GuestNotFound(t *testing.T) {
 func TestCommentService_CreateComment_GuestServiceError(t *testing.T) {
 	expectedErr := errors.New("database error")
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
 			return nil, expectedErr
 		},
 	}
 	mockRepo := &mockCommentRepo{}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	result, err := service.CreateComment("john-doe", "Hello world")
+	result, err := service.CreateComment(context.Background(), 1, "Hello world", nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
@@ -198,7 +290,7 @@ func TestCommentService_CreateComment_RepoError(t *testing.T) {
 	}
 	expectedErr := errors.New("insert failed")
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
 			return guest, nil
 		},
 	}
@@ -207,12 +299,12 @@ func TestCommentService_CreateComment_RepoError(t *testing.T) {
 			return expectedErr
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	result, err := service.CreateComment("john-doe", "Hello world")
+	result, err := service.CreateComment(context.Background(), 1, "Hello world", nil)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
@@ -230,7 +322,7 @@ func TestCommentService_GetCommentsByGuest_CacheMiss(t *testing.T) {
 	}
 	repoCallCount := 0
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
 			return guest, nil
 		},
 	}
@@ -241,12 +333,12 @@ func TestCommentService_GetCommentsByGuest_CacheMiss(t *testing.T) {
 			return expectedComments, nil
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	comments, err := service.GetCommentsByGuest("john-doe")
+	comments, err := service.GetCommentsByGuest(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedComments, comments)
@@ -263,7 +355,7 @@ func TestCommentService_GetCommentsByGuest_CacheHit(t *testing.T) {
 	}
 	repoCallCount := 0
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
 			return guest, nil
 		},
 	}
@@ -273,18 +365,18 @@ func TestCommentService_GetCommentsByGuest_CacheHit(t *testing.T) {
 			return expectedComments, nil
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
 	// First call - cache miss
-	comments1, err := service.GetCommentsByGuest("john-doe")
+	comments1, err := service.GetCommentsByGuest(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedComments, comments1)
 
 	// Second call - cache hit
-	comments2, err := service.GetCommentsByGuest("john-doe")
+	comments2, err := service.GetCommentsByGuest(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedComments, comments2)
 
@@ -294,17 +386,17 @@ func TestCommentService_GetCommentsByGuest_CacheHit(t *testing.T) {
 
 func TestCommentService_GetCommentsByGuest_GuestNotFound(t *testing.T) {
 	mockGuestService := &mockGuestService{
-		GetGuestByNameFunc: func(name string) (*models.Guest, error) {
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
 			return nil, nil
 		},
 	}
 	mockRepo := &mockCommentRepo{}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	comments, err := service.GetCommentsByGuest("unknown")
+	comments, err := service.GetCommentsByGuest(context.Background(), 99)
 
 	assert.NoError(t, err)
 	assert.Nil(t, comments)
@@ -325,12 +417,12 @@ func TestCommentService_GetAllCommentsWithGuests(t *testing.T) {
 			return expectedResult, nil
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	result, err := service.GetAllCommentsWithGuests(10, "")
+	result, err := service.GetAllCommentsWithGuests(context.Background(), 10, "")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResult, result)
@@ -352,12 +444,12 @@ func TestCommentService_GetAllCommentsWithGuests_WithCursor(t *testing.T) {
 			return expectedResult, nil
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	result, err := service.GetAllCommentsWithGuests(5, cursor)
+	result, err := service.GetAllCommentsWithGuests(context.Background(), 5, cursor)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResult, result)
@@ -374,13 +466,454 @@ func TestCommentService_GetAllComments(t *testing.T) {
 			return expectedComments, nil
 		},
 	}
-	service := NewCommentService(mockRepo, mockGuestService)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
 	t.Cleanup(func() {
 		service.commentCache.Stop()
 	})
 
-	comments, err := service.GetAllComments()
+	comments, err := service.GetAllComments(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedComments, comments)
 }
+
+func TestCommentService_CreateComment_PublishesEvent(t *testing.T) {
+	guest := &models.Guest{
+		ID:   1,
+		Name: "john-doe",
+	}
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return guest, nil
+		},
+	}
+	mockRepo := &mockCommentRepo{
+		CreateFunc: func(comment *models.Comment) error {
+			comment.ID = 7
+			return nil
+		},
+	}
+	broker := pubsub.NewBroker(10, 4)
+	sub, _ := broker.Subscribe(0)
+	service := NewCommentService(mockRepo, mockGuestService, broker, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+		broker.Stop()
+	})
+
+	result, err := service.CreateComment(context.Background(), 1, "Hello world", nil)
+	assert.NoError(t, err)
+
+	event := <-sub.Events()
+	assert.Equal(t, EventCommentCreated, event.Type)
+	assert.Equal(t, result, event.Data)
+}
+
+func TestCommentService_CreateComment_EventCarriesCreatedAt(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	guests := repositories.NewSQLGuestRepository(db, db)
+	comments := repositories.NewSQLCommentRepository(db, db)
+	tx := repositories.NewSQLTxManager(db)
+	guest := &models.Guest{Name: "john-doe"}
+	assert.NoError(t, guests.Create(context.Background(), guest))
+
+	broker := pubsub.NewBroker(10, 4)
+	sub, _ := broker.Subscribe(0)
+	service := NewCommentService(comments, NewGuestService(guests, &mockAuditRepo{}, tx), broker, nil, nil, tx)
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+		broker.Stop()
+	})
+
+	_, err = service.CreateComment(context.Background(), guest.ID, "Hello world", nil)
+	assert.NoError(t, err)
+
+	// Stream clients show the time of the new comment
+	event := <-sub.Events()
+	assert.Equal(t, EventCommentCreated, event.Type)
+	created := event.Data.(*models.CommentWithGuest)
+	assert.False(t, created.CreatedAt.IsZero())
+	stored, err := comments.GetByID(context.Background(), created.ID)
+	assert.NoError(t, err)
+	assert.True(t, created.CreatedAt.Equal(stored.CreatedAt))
+}
+
+func TestCommentService_SearchComments(t *testing.T) {
+	expectedResult := &models.PaginatedSearchResults{
+		Results:    []models.CommentSearchResult{{Snippet: "<mark>Bali</mark>"}},
+		TotalCount: 1,
+	}
+	mockRepo := &mockCommentRepo{
+		SearchFunc: func(query string, limit int, cursor string) (*models.PaginatedSearchResults, error) {
+			assert.Equal(t, "bali", query)
+			assert.Equal(t, 20, limit)
+			assert.Equal(t, "", cursor)
+			return expectedResult, nil
+		},
+	}
+	service := NewCommentService(mockRepo, &mockGuestService{}, nil, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	result, err := service.SearchComments(context.Background(), "bali", 20, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResult, result)
+}
+
+func TestCommentService_CreateComment_PolicyModerates(t *testing.T) {
+	guest := &models.Guest{ID: 1, Name: "john-doe"}
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return guest, nil
+		},
+	}
+	mockRepo := &mockCommentRepo{
+		CreateFunc: func(comment *models.Comment) error {
+			assert.Equal(t, models.CommentStatusPending, comment.Status)
+			assert.Equal(t, "contains links", comment.ModerationReason)
+			return nil
+		},
+	}
+	broker := pubsub.NewBroker(10, 4)
+	policy := contentpolicy.NewPolicy(contentpolicy.NewLinkRule(contentpolicy.ActionModerate))
+	service := NewCommentService(mockRepo, mockGuestService, broker, policy, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+		broker.Stop()
+	})
+
+	result, err := service.CreateComment(context.Background(), 1, "Photos at www.example.com", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.CommentStatusPending, result.Status)
+	// Pending comments are not broadcast
+	_, replay := broker.Subscribe(0)
+	assert.Empty(t, replay)
+}
+
+func TestCommentService_CreateComment_PolicyMasks(t *testing.T) {
+	guest := &models.Guest{ID: 1, Name: "john-doe"}
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return guest, nil
+		},
+	}
+	mockRepo := &mockCommentRepo{}
+	policy := contentpolicy.NewPolicy(contentpolicy.NewWordListRule([]string{"bangsat"}, contentpolicy.ActionMask))
+	service := NewCommentService(mockRepo, mockGuestService, nil, policy, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	result, err := service.CreateComment(context.Background(), 1, "bangsat keren", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "******* keren", result.Content)
+	assert.Equal(t, models.CommentStatusApproved, result.Status)
+}
+
+func TestCommentService_CreateComment_PolicyRejects(t *testing.T) {
+	guest := &models.Guest{ID: 1, Name: "john-doe"}
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return guest, nil
+		},
+	}
+	mockRepo := &mockCommentRepo{
+		HasDuplicateFunc: func(guestID int64, content string) (bool, error) {
+			return true, nil
+		},
+		CreateFunc: func(comment *models.Comment) error {
+			t.Error("rejected comment must not be stored")
+			return nil
+		},
+	}
+	policy := contentpolicy.NewPolicy(contentpolicy.NewDuplicateRule(mockRepo.HasDuplicate, contentpolicy.ActionReject))
+	service := NewCommentService(mockRepo, mockGuestService, nil, policy, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	result, err := service.CreateComment(context.Background(), 1, "Congrats!", nil)
+
+	var rejected *contentpolicy.RejectedError
+	assert.True(t, errors.As(err, &rejected))
+	assert.Nil(t, result)
+}
+
+func TestCommentService_ApproveComment(t *testing.T) {
+	mockRepo := &mockCommentRepo{
+		GetByIDFunc: func(id int64) (*models.CommentWithGuest, error) {
+			return &models.CommentWithGuest{
+				Comment:   models.Comment{ID: id, Status: models.CommentStatusPending},
+				GuestName: "john-doe",
+			}, nil
+		},
+		UpdateStatusFunc: func(id int64, status, reason string) error {
+			assert.Equal(t, int64(5), id)
+			assert.Equal(t, models.CommentStatusApproved, status)
+			return nil
+		},
+	}
+	broker := pubsub.NewBroker(10, 4)
+	sub, _ := broker.Subscribe(0)
+	service := NewCommentService(mockRepo, &mockGuestService{}, broker, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+		broker.Stop()
+	})
+
+	comment, err := service.ApproveComment(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.CommentStatusApproved, comment.Status)
+	event := <-sub.Events()
+	assert.Equal(t, EventCommentApproved, event.Type)
+}
+
+func TestCommentService_RejectComment_RemovesApproved(t *testing.T) {
+	mockRepo := &mockCommentRepo{
+		GetByIDFunc: func(id int64) (*models.CommentWithGuest, error) {
+			return &models.CommentWithGuest{
+				Comment: models.Comment{ID: id, Status: models.CommentStatusApproved},
+			}, nil
+		},
+		UpdateStatusFunc: func(id int64, status, reason string) error {
+			assert.Equal(t, models.CommentStatusRejected, status)
+			assert.Equal(t, "off-topic", reason)
+			return nil
+		},
+	}
+	broker := pubsub.NewBroker(10, 4)
+	sub, _ := broker.Subscribe(0)
+	service := NewCommentService(mockRepo, &mockGuestService{}, broker, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+		broker.Stop()
+	})
+
+	comment, err := service.RejectComment(context.Background(), 5, "off-topic")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.CommentStatusRejected, comment.Status)
+	event := <-sub.Events()
+	assert.Equal(t, EventCommentRemoved, event.Type)
+}
+
+func TestCommentService_ApproveComment_NotFound(t *testing.T) {
+	mockRepo := &mockCommentRepo{
+		UpdateStatusFunc: func(id int64, status, reason string) error {
+			t.Error("status must not be updated for a missing comment")
+			return nil
+		},
+	}
+	service := NewCommentService(mockRepo, &mockGuestService{}, nil, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	comment, err := service.ApproveComment(context.Background(), 404)
+
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+}
+
+func TestCommentService_IsPhotoPublished(t *testing.T) {
+	statuses := map[string]string{
+		"approved": models.CommentStatusApproved,
+		"pending":  models.CommentStatusPending,
+		"rejected": models.CommentStatusRejected,
+	}
+	mockRepo := &mockCommentRepo{
+		GetPhotoStatusFunc: func(key string) (string, error) {
+			return statuses[key], nil
+		},
+	}
+	service := NewCommentService(mockRepo, &mockGuestService{}, nil, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	for key, want := range map[string]bool{"approved": true, "pending": false, "rejected": false, "unknown": false} {
+		published, err := service.IsPhotoPublished(context.Background(), key)
+		assert.NoError(t, err)
+		assert.Equal(t, want, published, key)
+	}
+}
+
+func testPhoto(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 8, 8))))
+	return buf.Bytes()
+}
+
+func TestCommentService_CreateComment_WithPhoto(t *testing.T) {
+	guest := &models.Guest{ID: 1, Name: "john-doe"}
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return guest, nil
+		},
+	}
+	mockRepo := &mockCommentRepo{}
+	store, err := media.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, media.NewPhotos(store, 100, 50), mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	result, err := service.CreateComment(context.Background(), 1, "Selfie!", testPhoto(t))
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.PhotoKey)
+	f, _, err := store.Open(media.ThumbnailFileName(result.PhotoKey))
+	assert.NoError(t, err)
+	f.Close()
+}
+
+func TestCommentService_CreateComment_PhotoRemovedOnRepoError(t *testing.T) {
+	guest := &models.Guest{ID: 1, Name: "john-doe"}
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return guest, nil
+		},
+	}
+	var photoKey string
+	mockRepo := &mockCommentRepo{
+		CreateFunc: func(comment *models.Comment) error {
+			photoKey = comment.PhotoKey
+			return models.ErrCommentLimitReached
+		},
+	}
+	store, err := media.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, media.NewPhotos(store, 100, 50), mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	_, err = service.CreateComment(context.Background(), 1, "Selfie!", testPhoto(t))
+
+	assert.ErrorIs(t, err, models.ErrCommentLimitReached)
+	assert.NotEmpty(t, photoKey)
+	_, _, err = store.Open(media.PhotoFileName(photoKey))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCommentService_CreateComment_PhotoWithoutStore(t *testing.T) {
+	mockGuestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			return &models.Guest{ID: 1, Name: "john-doe"}, nil
+		},
+	}
+	mockRepo := &mockCommentRepo{
+		CreateFunc: func(comment *models.Comment) error {
+			t.Error("comment must not be stored without its photo")
+			return nil
+		},
+	}
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	_, err := service.CreateComment(context.Background(), 1, "Selfie!", testPhoto(t))
+
+	assert.ErrorIs(t, err, ErrPhotoUploadsDisabled)
+}
+
+func TestCommentService_GetGuestbookForExport(t *testing.T) {
+	base := time.Date(2026, 6, 20, 18, 0, 0, 0, time.UTC)
+	mockRepo := &mockCommentRepo{
+		GetAllFunc: func() ([]models.Comment, error) {
+			// Repository order is newest first
+			return []models.Comment{
+				{ID: 3, GuestID: 2, Content: "Latest", Status: models.CommentStatusApproved, CreatedAt: base.Add(2 * time.Hour)},
+				{ID: 2, GuestID: 1, Content: "Held", Status: models.CommentStatusPending, CreatedAt: base.Add(time.Hour)},
+				{ID: 1, GuestID: 1, Content: "First", Status: models.CommentStatusApproved, CreatedAt: base},
+				{ID: 4, GuestID: 9, Content: "Orphan", Status: models.CommentStatusApproved, CreatedAt: base.Add(3 * time.Hour)},
+			}, nil
+		},
+	}
+	mockGuestService := &mockGuestService{
+		GetAllGuestsFunc: func() ([]models.Guest, error) {
+			return []models.Guest{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, nil
+		},
+	}
+	service := NewCommentService(mockRepo, mockGuestService, nil, nil, nil, mockTx{mockRepo})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	guestbook, err := service.GetGuestbookForExport(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, guestbook, 3)
+	assert.Equal(t, "First", guestbook[0].Content)
+	assert.Equal(t, "alice", guestbook[0].GuestName)
+	assert.Equal(t, "Latest", guestbook[1].Content)
+	assert.Equal(t, "bob", guestbook[1].GuestName)
+	assert.Equal(t, "", guestbook[2].GuestName)
+}
+
+func TestCommentService_GetGuestbookForExport_GuestServiceError(t *testing.T) {
+	mockGuestService := &mockGuestService{
+		GetAllGuestsFunc: func() ([]models.Guest, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	service := NewCommentService(&mockCommentRepo{}, mockGuestService, nil, nil, nil, mockTx{})
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	guestbook, err := service.GetGuestbookForExport(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, guestbook)
+}
+
+func TestCommentService_WithMemoryRepositories(t *testing.T) {
+	guests := repositories.NewMemoryGuestRepository()
+	comments := repositories.NewMemoryCommentRepository(guests)
+	tx := repositories.NewMemoryTxManager(guests, comments)
+	guestService := NewGuestService(guests, &mockAuditRepo{}, tx)
+	service := NewCommentService(comments, guestService, nil, nil, nil, tx)
+	t.Cleanup(func() {
+		service.commentCache.Stop()
+	})
+
+	guest := &models.Guest{Name: "alice"}
+	assert.NoError(t, guests.Create(context.Background(), guest))
+
+	first, err := service.CreateComment(context.Background(), guest.ID, "Congratulations!", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", first.GuestName)
+	_, err = service.CreateComment(context.Background(), guest.ID, "See you there", nil)
+	assert.NoError(t, err)
+
+	_, err = service.CreateComment(context.Background(), guest.ID, "One more", nil)
+	assert.ErrorIs(t, err, models.ErrCommentLimitReached)
+
+	page, err := service.GetAllCommentsWithGuests(context.Background(), 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.TotalCount)
+
+	rejected, err := service.RejectComment(context.Background(), first.ID, "duplicate")
+	assert.NoError(t, err)
+	assert.Equal(t, models.CommentStatusRejected, rejected.Status)
+	page, err = service.GetAllCommentsWithGuests(context.Background(), 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, page.TotalCount)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"wedding-invitation-backend/fieldcrypt"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+// ErrFieldKeyMissing is returned when stored guest fields are encrypted but
+// no field encryption key is configured
+var ErrFieldKeyMissing = errors.New("guest fields are encrypted but FIELD_ENCRYPTION_KEY and FIELD_ENCRYPTION_KEYS_DIR are not set")
+
+// CheckUnencryptedStorage returns ErrFieldKeyMissing if any stored dietary
+// restriction is encrypted. Run without keys, the server would show the
+// encrypted values and store plain text next to them.
+func CheckUnencryptedStorage(ctx context.Context, guests repositories.GuestRepository) error {
+	all, err := guests.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	encrypted := 0
+	for _, guest := range all {
+		if guest.DietaryRestrictions.Valid && fieldcrypt.KeyID(guest.DietaryRestrictions.String) != "" {
+			encrypted++
+		}
+	}
+	if encrypted > 0 {
+		return fmt.Errorf("%w (%d values)", ErrFieldKeyMissing, encrypted)
+	}
+	return nil
+}
+
+// EncryptionService reports and rotates the keys sensitive guest fields are
+// encrypted with. It works on the stored values, so tx must not encrypt.
+type EncryptionService struct {
+	tx    repositories.TxManager
+	audit repositories.AuditRepository
+	keys  *fieldcrypt.KeyRing
+}
+
+// NewEncryptionService creates a new encryption service
+func NewEncryptionService(tx repositories.TxManager, audit repositories.AuditRepository, keys *fieldcrypt.KeyRing) *EncryptionService {
+	return &EncryptionService{tx: tx, audit: audit, keys: keys}
+}
+
+// CurrentKeyID returns the ID of the key new values are encrypted with
+func (s *EncryptionService) CurrentKeyID() string {
+	return s.keys.CurrentKeyID()
+}
+
+// Status counts the stored dietary restrictions by the ID of the key they
+// are encrypted with; plain text values count under ""
+func (s *EncryptionService) Status(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		guests, err := repos.Guests.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, guest := range guests {
+			if guest.DietaryRestrictions.Valid {
+				counts[fieldcrypt.KeyID(guest.DietaryRestrictions.String)]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// Reencrypt rewrites every stored value that is plain text or encrypted
+// with an old key with the current key, in one transaction, and returns how
+// many guests it rewrote. Their updated_at changes too. Once it reports
+// nothing left to do, keys other than the current one can be removed.
+func (s *EncryptionService) Reencrypt(ctx context.Context) (int, error) {
+	var rewritten int
+	err := s.tx.WithinTx(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		guests, err := repos.Guests.GetAll(ctx)
+		if err != nil {
+			return err
+		}
+
+		var stale []models.Guest
+		for _, guest := range guests {
+			value := guest.DietaryRestrictions
+			if !value.Valid || !s.keys.NeedsReencryption(value.String) {
+				continue
+			}
+			plaintext, err := s.keys.Decrypt(repositories.DietaryRestrictionsField, value.String)
+			if err != nil {
+				return fmt.Errorf("guest %d: %w", guest.ID, err)
+			}
+			guest.DietaryRestrictions.String = plaintext
+			stale = append(stale, guest)
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+
+		if err := repositories.NewEncryptedGuestRepository(repos.Guests, s.keys).BulkUpdate(ctx, stale); err != nil {
+			return err
+		}
+		rewritten = len(stale)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rewritten, nil
+}
+
+// MaskAuditLog replaces the sensitive guest values in audit entries
+// recorded before they were masked and returns how many entries it
+// rewrote
+func (s *EncryptionService) MaskAuditLog(ctx context.Context) (int64, error) {
+	return s.audit.MaskFields(ctx, models.AuditTargetGuest, models.SensitiveAuditFields[models.AuditTargetGuest])
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/fieldcrypt"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFieldKey(t *testing.T, id string) *fieldcrypt.Key {
+	t.Helper()
+	secret := make([]byte, fieldcrypt.KeySize)
+	_, err := rand.Read(secret)
+	assert.NoError(t, err)
+	key, err := fieldcrypt.NewKey(id, secret)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestEncryptionService_Reencrypt(t *testing.T) {
+	ctx := context.Background()
+	guests := repositories.NewMemoryGuestRepository()
+	tx := repositories.NewMemoryTxManager(guests, repositories.NewMemoryCommentRepository(guests))
+
+	oldKey, newKey := newFieldKey(t, "old"), newFieldKey(t, "new")
+	oldRing, err := fieldcrypt.NewKeyRing("old", []*fieldcrypt.Key{oldKey})
+	assert.NoError(t, err)
+	ring, err := fieldcrypt.NewKeyRing("new", []*fieldcrypt.Key{oldKey, newKey})
+	assert.NoError(t, err)
+
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "plain", DietaryRestrictions: sql.NullString{String: "Vegan", Valid: true}}))
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "none"}))
+	assert.NoError(t, repositories.NewEncryptedGuestRepository(guests, oldRing).Create(ctx,
+		&models.Guest{Name: "old", DietaryRestrictions: sql.NullString{String: "No shellfish", Valid: true}}))
+	assert.NoError(t, repositories.NewEncryptedGuestRepository(guests, ring).Create(ctx,
+		&models.Guest{Name: "new", DietaryRestrictions: sql.NullString{String: "Halal", Valid: true}}))
+
+	service := NewEncryptionService(tx, &mockAuditRepo{}, ring)
+	assert.Equal(t, "new", service.CurrentKeyID())
+
+	status, err := service.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"": 1, "old": 1, "new": 1}, status)
+
+	rewritten, err := service.Reencrypt(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rewritten)
+
+	status, err = service.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"new": 3}, status)
+
+	rewritten, err = service.Reencrypt(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rewritten)
+
+	newOnly, err := fieldcrypt.NewKeyRing("new", []*fieldcrypt.Key{newKey})
+	assert.NoError(t, err)
+	all, err := repositories.NewEncryptedGuestRepository(guests, newOnly).GetAll(ctx)
+	assert.NoError(t, err)
+	byName := make(map[string]string)
+	for _, guest := range all {
+		byName[guest.Name] = guest.DietaryRestrictions.String
+	}
+	assert.Equal(t, map[string]string{"plain": "Vegan", "none": "", "old": "No shellfish", "new": "Halal"}, byName)
+}
+
+func TestEncryptionService_ReencryptUnknownKey(t *testing.T) {
+	ctx := context.Background()
+	guests := repositories.NewMemoryGuestRepository()
+	tx := repositories.NewMemoryTxManager(guests, repositories.NewMemoryCommentRepository(guests))
+
+	lost, err := fieldcrypt.NewKeyRing("lost", []*fieldcrypt.Key{newFieldKey(t, "lost")})
+	assert.NoError(t, err)
+	assert.NoError(t, repositories.NewEncryptedGuestRepository(guests, lost).Create(ctx,
+		&models.Guest{Name: "alice", DietaryRestrictions: sql.NullString{String: "Vegan", Valid: true}}))
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "bob", DietaryRestrictions: sql.NullString{String: "None", Valid: true}}))
+
+	ring, err := fieldcrypt.NewKeyRing("new", []*fieldcrypt.Key{newFieldKey(t, "new")})
+	assert.NoError(t, err)
+	_, err = NewEncryptionService(tx, &mockAuditRepo{}, ring).Reencrypt(ctx)
+	assert.ErrorIs(t, err, fieldcrypt.ErrUnknownKey)
+
+	bob, err := guests.GetByName(ctx, "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, "None", bob.DietaryRestrictions.String, "nothing is rewritten when a value cannot be read")
+}
+
+func TestEncryptionService_MaskAuditLog(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	audit := repositories.NewSQLAuditRepository(db, db)
+
+	// An entry from before values were masked
+	assert.NoError(t, models.CreateAuditEntries(db, []models.AuditEntry{{
+		ActorName: "owner", Action: models.AuditActionGuestCreate, TargetType: models.AuditTargetGuest, TargetID: "1",
+		Changes: json.RawMessage(`{"Name":{"before":null,"after":"alice"},"DietaryRestrictions":{"before":null,"after":{"String":"Peanut allergy","Valid":true}}}`),
+	}}))
+
+	// New entries never hold the value
+	before := models.Guest{ID: 2, Name: "bob"}
+	after := before
+	after.DietaryRestrictions = sql.NullString{String: "Coeliac", Valid: true}
+	assert.NoError(t, NewAuditService(audit).Record(AuditActor{Name: "owner"}, models.AuditActionGuestUpdate, models.AuditTargetGuest,
+		AuditChange{TargetID: "2", Before: before, After: after},
+		AuditChange{TargetID: "3", After: models.Guest{ID: 3, Name: "carol", DietaryRestrictions: sql.NullString{String: "Kosher", Valid: true}}},
+	))
+
+	service := NewEncryptionService(nil, audit, nil)
+	masked, err := service.MaskAuditLog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), masked, "only the old entry needed masking")
+
+	rows, err := db.Query(`SELECT changes FROM audit_log`)
+	assert.NoError(t, err)
+	defer rows.Close()
+	var stored []string
+	for rows.Next() {
+		var changes string
+		assert.NoError(t, rows.Scan(&changes))
+		stored = append(stored, changes)
+		for _, secret := range []string{"Peanut", "Coeliac", "Kosher"} {
+			assert.NotContains(t, changes, secret)
+		}
+	}
+	assert.Len(t, stored, 3)
+	assert.Contains(t, stored[0], `"DietaryRestrictions":{"after":"[redacted]","before":null}`)
+	assert.Contains(t, stored[0], `"alice"`, "other fields are kept")
+
+	masked, err = service.MaskAuditLog(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), masked)
+}
+
+func TestCheckUnencryptedStorage(t *testing.T) {
+	ctx := context.Background()
+	guests := repositories.NewMemoryGuestRepository()
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "plain", DietaryRestrictions: sql.NullString{String: "Vegan", Valid: true}}))
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "none"}))
+	assert.NoError(t, CheckUnencryptedStorage(ctx, guests))
+
+	ring, err := fieldcrypt.NewKeyRing("k1", []*fieldcrypt.Key{newFieldKey(t, "k1")})
+	assert.NoError(t, err)
+	assert.NoError(t, repositories.NewEncryptedGuestRepository(guests, ring).Create(ctx,
+		&models.Guest{Name: "secret", DietaryRestrictions: sql.NullString{String: "Halal", Valid: true}}))
+
+	err = CheckUnencryptedStorage(ctx, guests)
+	assert.ErrorIs(t, err, ErrFieldKeyMissing)
+	assert.Contains(t, err.Error(), "1 values")
+}
@@ -1,6 +1,11 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
 	"wedding-invitation-backend/cache"
 	"wedding-invitation-backend/models"
 	"wedding-invitation-backend/repositories"
@@ -9,53 +14,116 @@ import (
 // GuestService handles guest business logic
 type GuestService struct {
 	guestCache cache.GuestCacheInterface
+	audit      repositories.AuditRepository
+	tx         repositories.TxManager
 }
 
-// NewGuestService creates a new guest service
-func NewGuestService(guestRepo repositories.GuestRepository) *GuestService {
+// NewGuestService creates a new guest service. tx runs the changes that
+// read and write the guest in one go; submitted RSVPs are recorded in
+// audit.
+func NewGuestService(guestRepo repositories.GuestRepository, audit repositories.AuditRepository, tx repositories.TxManager) *GuestService {
 	return &GuestService{
 		guestCache: cache.NewGuestCache(guestRepo),
+		audit:      audit,
+		tx:         tx,
 	}
 }
 
 // GetGuestByName retrieves a guest by name (cached)
-func (gs *GuestService) GetGuestByName(name string) (*models.Guest, error) {
-	return gs.guestCache.GetByName(name)
+func (gs *GuestService) GetGuestByName(ctx context.Context, name string) (*models.Guest, error) {
+	return gs.guestCache.GetByName(ctx, name)
+}
+
+// GetGuestByID retrieves a guest by ID (cached)
+func (gs *GuestService) GetGuestByID(ctx context.Context, id int64) (*models.Guest, error) {
+	return gs.guestCache.GetByID(ctx, id)
 }
 
 // GetAllGuests retrieves all guests (cached)
-func (gs *GuestService) GetAllGuests() ([]models.Guest, error) {
-	return gs.guestCache.GetAll()
+func (gs *GuestService) GetAllGuests(ctx context.Context) ([]models.Guest, error) {
+	return gs.guestCache.GetAll(ctx)
 }
 
 // CreateGuest creates a new guest
-func (gs *GuestService) CreateGuest(guest *models.Guest) error {
-	return gs.guestCache.Create(guest)
+func (gs *GuestService) CreateGuest(ctx context.Context, guest *models.Guest) error {
+	return gs.guestCache.Create(ctx, guest)
 }
 
 // UpdateGuest updates an existing guest
-func (gs *GuestService) UpdateGuest(guest *models.Guest) error {
-	return gs.guestCache.Update(guest)
+func (gs *GuestService) UpdateGuest(ctx context.Context, guest *models.Guest) error {
+	return gs.guestCache.Update(ctx, guest)
 }
 
 // BulkCreateGuests creates multiple guests
-func (gs *GuestService) BulkCreateGuests(guests []models.Guest) error {
-	return gs.guestCache.BulkCreate(guests)
+func (gs *GuestService) BulkCreateGuests(ctx context.Context, guests []models.Guest) error {
+	return gs.guestCache.BulkCreate(ctx, guests)
 }
 
 // BulkUpdateGuests updates multiple guests
-func (gs *GuestService) BulkUpdateGuests(guests []models.Guest) error {
-	return gs.guestCache.BulkUpdate(guests)
+func (gs *GuestService) BulkUpdateGuests(ctx context.Context, guests []models.Guest) error {
+	return gs.guestCache.BulkUpdate(ctx, guests)
+}
+
+// SubmitRSVP records whether a guest is attending. The guest is read from
+// the database rather than the cache, in the same transaction as the
+// update, so a stale cached copy cannot overwrite the guest's other
+// details. Every submission is recorded in the audit log in the same unit
+// of work, so the guest's RSVP history is complete; with guests in SQLite
+// that is one transaction. Returns nil if the guest does not exist.
+func (gs *GuestService) SubmitRSVP(ctx context.Context, name string, attending bool) (*models.Guest, error) {
+	var guest *models.Guest
+	err := gs.tx.WithinTx(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		var err error
+		guest, err = repos.Guests.GetByName(ctx, name)
+		if err != nil || guest == nil {
+			return err
+		}
+		before := guest.Attending
+		guest.Attending = sql.NullBool{Bool: attending, Valid: true}
+		if err := repos.Guests.Update(ctx, guest); err != nil {
+			return err
+		}
+
+		// Recorded even when the answer is unchanged: it is still a submission
+		changes, err := json.Marshal(map[string]auditFieldChange{
+			"Attending": {Before: before, After: guest.Attending},
+		})
+		if err != nil {
+			return err
+		}
+		return gs.audit.CreateContext(ctx, []models.AuditEntry{{
+			ActorName:  models.AuditActorGuest,
+			Action:     models.AuditActionGuestRSVP,
+			TargetType: models.AuditTargetGuest,
+			TargetID:   strconv.FormatInt(guest.ID, 10),
+			Changes:    changes,
+		}})
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	if guest != nil {
+		gs.guestCache.Invalidate(guest)
+	}
+	return guest, nil
 }
 
 // MarkInvitationOpened marks an invitation as opened
-func (gs *GuestService) MarkInvitationOpened(name string) error {
-	return gs.guestCache.MarkInvitationOpened(name)
+func (gs *GuestService) MarkInvitationOpened(ctx context.Context, guestID int64) error {
+	return gs.guestCache.MarkInvitationOpened(ctx, guestID)
+}
+
+// ClearCache drops every cached guest so the next reads go to the
+// repository, e.g. after a backup was restored
+func (gs *GuestService) ClearCache() {
+	gs.guestCache.Clear()
 }
 
-// ValidateGuestAccess checks if a guest exists and has access
-func (gs *GuestService) ValidateGuestAccess(name string) (*models.Guest, error) {
-	guest, err := gs.GetGuestByName(name)
+// ValidateGuestAccess checks if a guest exists and has access. Guests are
+// identified by ID so renaming a guest keeps them logged in.
+func (gs *GuestService) ValidateGuestAccess(ctx context.Context, guestID int64) (*models.Guest, error) {
+	guest, err := gs.GetGuestByID(ctx, guestID)
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,14 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"strconv"
 	"testing"
+	"wedding-invitation-backend/database"
 	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,64 +16,86 @@ import (
 // mockGuestCache implements cache.GuestCacheInterface using function fields
 type mockGuestCache struct {
 	GetByNameFunc            func(name string) (*models.Guest, error)
+	GetByIDFunc              func(id int64) (*models.Guest, error)
 	GetAllFunc               func() ([]models.Guest, error)
 	CreateFunc               func(guest *models.Guest) error
 	UpdateFunc               func(guest *models.Guest) error
 	BulkCreateFunc           func(guests []models.Guest) error
 	BulkUpdateFunc           func(guests []models.Guest) error
-	MarkInvitationOpenedFunc func(name string) error
+	MarkInvitationOpenedFunc func(guestID int64) error
+	InvalidateFunc           func(guest *models.Guest)
+	ClearFunc                func()
 	StopFunc                 func()
 }
 
-func (m *mockGuestCache) GetByName(name string) (*models.Guest, error) {
+func (m *mockGuestCache) GetByName(ctx context.Context, name string) (*models.Guest, error) {
 	if m.GetByNameFunc != nil {
 		return m.GetByNameFunc(name)
 	}
 	return nil, nil
 }
 
-func (m *mockGuestCache) GetAll() ([]models.Guest, error) {
+func (m *mockGuestCache) GetByID(ctx context.Context, id int64) (*models.Guest, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *mockGuestCache) GetAll(ctx context.Context) ([]models.Guest, error) {
 	if m.GetAllFunc != nil {
 		return m.GetAllFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockGuestCache) Create(guest *models.Guest) error {
+func (m *mockGuestCache) Create(ctx context.Context, guest *models.Guest) error {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestCache) Update(guest *models.Guest) error {
+func (m *mockGuestCache) Update(ctx context.Context, guest *models.Guest) error {
 	if m.UpdateFunc != nil {
 		return m.UpdateFunc(guest)
 	}
 	return nil
 }
 
-func (m *mockGuestCache) BulkCreate(guests []models.Guest) error {
+func (m *mockGuestCache) BulkCreate(ctx context.Context, guests []models.Guest) error {
 	if m.BulkCreateFunc != nil {
 		return m.BulkCreateFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestCache) BulkUpdate(guests []models.Guest) error {
+func (m *mockGuestCache) BulkUpdate(ctx context.Context, guests []models.Guest) error {
 	if m.BulkUpdateFunc != nil {
 		return m.BulkUpdateFunc(guests)
 	}
 	return nil
 }
 
-func (m *mockGuestCache) MarkInvitationOpened(name string) error {
+func (m *mockGuestCache) MarkInvitationOpened(ctx context.Context, guestID int64) error {
 	if m.MarkInvitationOpenedFunc != nil {
-		return m.MarkInvitationOpenedFunc(name)
+		return m.MarkInvitationOpenedFunc(guestID)
 	}
 	return nil
 }
 
+func (m *mockGuestCache) Invalidate(guest *models.Guest) {
+	if m.InvalidateFunc != nil {
+		m.InvalidateFunc(guest)
+	}
+}
+
+func (m *mockGuestCache) Clear() {
+	if m.ClearFunc != nil {
+		m.ClearFunc()
+	}
+}
+
 func (m *mockGuestCache) Stop() {
 	if m.StopFunc != nil {
 		m.StopFunc()
@@ -93,7 +120,7 @@ func TestGuestService_GetGuestByName_Found(t *testing.T) {
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guest, err := service.GetGuestByName("john-doe")
+	guest, err := service.GetGuestByName(context.Background(), "john-doe")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuest, guest)
@@ -107,7 +134,7 @@ func TestGuestService_GetGuestByName_NotFound(t *testing.T) {
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guest, err := service.GetGuestByName("unknown")
+	guest, err := service.GetGuestByName(context.Background(), "unknown")
 
 	assert.NoError(t, err)
 	assert.Nil(t, guest)
@@ -122,7 +149,7 @@ func TestGuestService_GetGuestByName_Error(t *testing.T) {
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guest, err := service.GetGuestByName("john-doe")
+	guest, err := service.GetGuestByName(context.Background(), "john-doe")
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
@@ -141,7 +168,7 @@ func TestGuestService_GetAllGuests(t *testing.T) {
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guests, err := service.GetAllGuests()
+	guests, err := service.GetAllGuests(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuests, guests)
@@ -153,14 +180,14 @@ func TestGuestService_ValidateGuestAccess_Found(t *testing.T) {
 		Name: "john-doe",
 	}
 	mockCache := &mockGuestCache{
-		GetByNameFunc: func(name string) (*models.Guest, error) {
-			assert.Equal(t, "john-doe", name)
+		GetByIDFunc: func(id int64) (*models.Guest, error) {
+			assert.Equal(t, int64(1), id)
 			return expectedGuest, nil
 		},
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guest, err := service.ValidateGuestAccess("john-doe")
+	guest, err := service.ValidateGuestAccess(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGuest, guest)
@@ -168,13 +195,13 @@ func TestGuestService_ValidateGuestAccess_Found(t *testing.T) {
 
 func TestGuestService_ValidateGuestAccess_NotFound(t *testing.T) {
 	mockCache := &mockGuestCache{
-		GetByNameFunc: func(name string) (*models.Guest, error) {
+		GetByIDFunc: func(id int64) (*models.Guest, error) {
 			return nil, nil
 		},
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guest, err := service.ValidateGuestAccess("unknown")
+	guest, err := service.ValidateGuestAccess(context.Background(), 99)
 
 	assert.NoError(t, err)
 	assert.Nil(t, guest)
@@ -183,15 +210,124 @@ func TestGuestService_ValidateGuestAccess_NotFound(t *testing.T) {
 func TestGuestService_ValidateGuestAccess_Error(t *testing.T) {
 	expectedErr := errors.New("database error")
 	mockCache := &mockGuestCache{
-		GetByNameFunc: func(name string) (*models.Guest, error) {
+		GetByIDFunc: func(id int64) (*models.Guest, error) {
 			return nil, expectedErr
 		},
 	}
 	service := newGuestServiceWithCache(mockCache)
 
-	guest, err := service.ValidateGuestAccess("john-doe")
+	guest, err := service.ValidateGuestAccess(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
 	assert.Nil(t, guest)
 }
+
+func TestGuestService_SubmitRSVP(t *testing.T) {
+	guests := repositories.NewMemoryGuestRepository()
+	tx := repositories.NewMemoryTxManager(guests, repositories.NewMemoryCommentRepository(guests))
+	var recorded []models.AuditEntry
+	audit := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			recorded = append(recorded, entries...)
+			return nil
+		},
+	}
+	service := NewGuestService(guests, audit, tx)
+	ctx := context.Background()
+
+	guest := &models.Guest{Name: "alice"}
+	assert.NoError(t, guests.Create(ctx, guest))
+
+	// Cache the guest, then change it behind the cache's back
+	_, err := service.GetGuestByName(ctx, "alice")
+	assert.NoError(t, err)
+	guest.PlusOnes = 2
+	assert.NoError(t, guests.Update(ctx, guest))
+
+	updated, err := service.SubmitRSVP(ctx, "alice", true)
+	assert.NoError(t, err)
+	assert.True(t, updated.Attending.Bool)
+	assert.Equal(t, 2, updated.PlusOnes)
+
+	// The stale cached copy was dropped
+	cached, err := service.GetGuestByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.True(t, cached.Attending.Valid)
+	assert.Equal(t, 2, cached.PlusOnes)
+
+	// Each submission is recorded, an unchanged answer too
+	_, err = service.SubmitRSVP(ctx, "alice", true)
+	assert.NoError(t, err)
+	if assert.Len(t, recorded, 2) {
+		assert.Equal(t, models.AuditActionGuestRSVP, recorded[0].Action)
+		assert.Equal(t, models.AuditActorGuest, recorded[0].ActorName)
+		assert.Equal(t, models.AuditTargetGuest, recorded[0].TargetType)
+		assert.Equal(t, strconv.FormatInt(guest.ID, 10), recorded[0].TargetID)
+		assert.JSONEq(t, `{"Attending":{"before":{"Bool":false,"Valid":false},"after":{"Bool":true,"Valid":true}}}`, string(recorded[0].Changes))
+		assert.JSONEq(t, `{"Attending":{"before":{"Bool":true,"Valid":true},"after":{"Bool":true,"Valid":true}}}`, string(recorded[1].Changes))
+	}
+}
+
+func TestGuestService_SubmitRSVP_AuditFailureRollsBack(t *testing.T) {
+	guests := repositories.NewMemoryGuestRepository()
+	tx := repositories.NewMemoryTxManager(guests, repositories.NewMemoryCommentRepository(guests))
+	audit := &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			return errors.New("disk full")
+		},
+	}
+	service := NewGuestService(guests, audit, tx)
+	ctx := context.Background()
+	assert.NoError(t, guests.Create(ctx, &models.Guest{Name: "alice"}))
+
+	_, err := service.SubmitRSVP(ctx, "alice", true)
+
+	assert.Error(t, err)
+	stored, err := guests.GetByName(ctx, "alice")
+	assert.NoError(t, err)
+	assert.False(t, stored.Attending.Valid, "an RSVP that was not recorded is not kept")
+}
+
+func TestGuestService_SubmitRSVP_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection: the audit entry must join the RSVP's transaction
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	guests := repositories.NewSQLGuestRepository(db, db)
+	service := NewGuestService(guests, repositories.NewSQLAuditRepository(db, db), repositories.NewSQLTxManager(db))
+	ctx := context.Background()
+	guest := &models.Guest{Name: "alice"}
+	assert.NoError(t, guests.Create(ctx, guest))
+
+	_, err = service.SubmitRSVP(ctx, "alice", false)
+
+	assert.NoError(t, err)
+	var action, actor string
+	assert.NoError(t, db.QueryRow(`SELECT action, actor_name FROM audit_log WHERE target_type = ? AND target_id = ?`,
+		models.AuditTargetGuest, strconv.FormatInt(guest.ID, 10)).Scan(&action, &actor))
+	assert.Equal(t, models.AuditActionGuestRSVP, action)
+	assert.Equal(t, models.AuditActorGuest, actor)
+}
+
+func TestGuestService_SubmitRSVP_NotFound(t *testing.T) {
+	guests := repositories.NewMemoryGuestRepository()
+	tx := repositories.NewMemoryTxManager(guests, repositories.NewMemoryCommentRepository(guests))
+	service := NewGuestService(guests, &mockAuditRepo{
+		CreateFunc: func(entries []models.AuditEntry) error {
+			t.Error("nothing is recorded for an unknown guest")
+			return nil
+		},
+	}, tx)
+
+	guest, err := service.SubmitRSVP(context.Background(), "nobody", true)
+
+	assert.NoError(t, err)
+	assert.Nil(t, guest)
+}
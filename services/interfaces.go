@@ -1,29 +1,134 @@
 package services
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"wedding-invitation-backend/models"
 )
 
 // GuestServiceInterface defines the interface for guest business logic
 type GuestServiceInterface interface {
-	GetGuestByName(name string) (*models.Guest, error)
-	GetAllGuests() ([]models.Guest, error)
-	CreateGuest(guest *models.Guest) error
-	UpdateGuest(guest *models.Guest) error
-	BulkCreateGuests(guests []models.Guest) error
-	BulkUpdateGuests(guests []models.Guest) error
-	MarkInvitationOpened(name string) error
-	ValidateGuestAccess(name string) (*models.Guest, error)
+	GetGuestByName(ctx context.Context, name string) (*models.Guest, error)
+	GetGuestByID(ctx context.Context, id int64) (*models.Guest, error)
+	GetAllGuests(ctx context.Context) ([]models.Guest, error)
+	CreateGuest(ctx context.Context, guest *models.Guest) error
+	UpdateGuest(ctx context.Context, guest *models.Guest) error
+	SubmitRSVP(ctx context.Context, name string, attending bool) (*models.Guest, error)
+	BulkCreateGuests(ctx context.Context, guests []models.Guest) error
+	BulkUpdateGuests(ctx context.Context, guests []models.Guest) error
+	MarkInvitationOpened(ctx context.Context, guestID int64) error
+	ValidateGuestAccess(ctx context.Context, guestID int64) (*models.Guest, error)
 }
 
 // CommentServiceInterface defines the interface for comment business logic
 type CommentServiceInterface interface {
-	CreateComment(guestName, content string) (*models.CommentWithGuest, error)
-	GetCommentsByGuest(guestName string) ([]models.Comment, error)
-	GetAllComments() ([]models.Comment, error)
-	GetAllCommentsWithGuests(limit int, cursor string) (*models.PaginatedComments, error)
+	CreateComment(ctx context.Context, guestID int64, content string, photo []byte) (*models.CommentWithGuest, error)
+	GetCommentsByGuest(ctx context.Context, guestID int64) ([]models.Comment, error)
+	GetAllComments(ctx context.Context) ([]models.Comment, error)
+	GetAllCommentsWithGuests(ctx context.Context, limit int, cursor string) (*models.PaginatedComments, error)
+	SearchComments(ctx context.Context, query string, limit int, cursor string) (*models.PaginatedSearchResults, error)
+	GetCommentByID(ctx context.Context, id int64) (*models.CommentWithGuest, error)
+	GetPendingComments(ctx context.Context) ([]models.CommentWithGuest, error)
+	ApproveComment(ctx context.Context, id int64) (*models.CommentWithGuest, error)
+	RejectComment(ctx context.Context, id int64, reason string) (*models.CommentWithGuest, error)
+	GetGuestbookForExport(ctx context.Context) ([]models.CommentWithGuest, error)
+	IsPhotoPublished(ctx context.Context, key string) (bool, error)
+}
+
+// AdminServiceInterface defines the interface for admin accounts and authentication
+type AdminServiceInterface interface {
+	Authenticate(username, password string) (*models.Admin, error)
+	CreateAdmin(username, password, role string) (*models.Admin, error)
+	BootstrapOwner(username, password string) (*models.Admin, bool, error)
+	GetAdminByID(id int64) (*models.Admin, error)
+	GetAdminByUsername(username string) (*models.Admin, error)
+	GetAllAdmins() ([]models.Admin, error)
+	UpdateAdminRole(id int64, role string) (*models.Admin, error)
+	ChangePassword(id int64, password string) (bool, error)
+	DeleteAdmin(id int64) (*models.Admin, error)
+}
+
+// AuditServiceInterface defines the interface for the admin audit log
+type AuditServiceInterface interface {
+	Record(actor AuditActor, action, targetType string, changes ...AuditChange) error
+	GetAuditLog(filter models.AuditFilter, limit int, cursor string) (*models.PaginatedAuditEntries, error)
+}
+
+// SessionServiceInterface defines the interface for guest login sessions
+type SessionServiceInterface interface {
+	StartSession(guest *models.Guest, userAgent, ipAddress string) (*models.Session, string, error)
+	RefreshSession(refreshToken string) (*models.Session, string, error)
+	IsSessionActive(id string) (bool, error)
+	GetSession(id string) (*models.Session, error)
+	GetGuestSessions(guestID int64) ([]models.Session, error)
+	RevokeSession(id string) (*models.Session, error)
+	RevokeGuestSessions(guestID int64) ([]string, error)
+	PruneExpiredSessions() (int64, error)
+}
+
+// LoginGuardServiceInterface defines the interface for failed guest login
+// tracking and lockouts
+type LoginGuardServiceInterface interface {
+	CheckLogin(ipAddress, name string) (time.Duration, error)
+	RecordFailure(ipAddress, name string) error
+	RecordSuccess(ipAddress, name string) error
+	GetSuspiciousLogins(since time.Time) (*models.SuspiciousLoginReport, error)
+	Unlock(key string) (*models.LoginLockout, error)
+	PruneLoginEvents() (int64, error)
+}
+
+// InviteLinkServiceInterface defines the interface for invitation links
+type InviteLinkServiceInterface interface {
+	CreateLink(ctx context.Context, guestID int64) (*IssuedInviteLink, error)
+	GetGuestLinks(ctx context.Context, guestID int64) ([]IssuedInviteLink, error)
+	LinksForAllGuests(ctx context.Context) ([]IssuedInviteLink, []models.InviteLink, error)
+	Redeem(ctx context.Context, token string) (*models.Guest, error)
+	RevokeLink(ctx context.Context, id string) (*models.InviteLink, error)
+}
+
+// APITokenServiceInterface defines the interface for admin API tokens
+type APITokenServiceInterface interface {
+	CreateToken(name string, scopes []string, expiresAt time.Time, createdBy string) (*models.APIToken, string, error)
+	GetAllTokens() ([]models.APIToken, error)
+	RevokeToken(id int64) (*models.APIToken, error)
+	Authenticate(secret string) (*models.APIToken, error)
+}
+
+// BackupServiceInterface defines the interface for SQLite database backups
+type BackupServiceInterface interface {
+	CreateBackup(ctx context.Context) (*Backup, error)
+	ListBackups() ([]Backup, error)
+	OpenSnapshot(ctx context.Context) (*Snapshot, error)
+	Restore(ctx context.Context, r io.Reader) (*Backup, error)
+	Start(interval time.Duration)
+	Stop()
+}
+
+// EncryptionServiceInterface defines the interface for field encryption keys
+type EncryptionServiceInterface interface {
+	CurrentKeyID() string
+	Status(ctx context.Context) (map[string]int, error)
+	Reencrypt(ctx context.Context) (int, error)
+	MaskAuditLog(ctx context.Context) (int64, error)
+}
+
+// PrivacyServiceInterface defines the interface for exporting and erasing guest data
+type PrivacyServiceInterface interface {
+	ExportGuestData(ctx context.Context, guestID int64) (*GuestDataExport, error)
+	ForgetGuest(ctx context.Context, guestID int64) (*ForgottenGuest, error)
 }
 
 // Compile-time checks to ensure implementations satisfy interfaces
 var _ GuestServiceInterface = (*GuestService)(nil)
 var _ CommentServiceInterface = (*CommentService)(nil)
+var _ AdminServiceInterface = (*AdminService)(nil)
+var _ AuditServiceInterface = (*AuditService)(nil)
+var _ SessionServiceInterface = (*SessionService)(nil)
+var _ LoginGuardServiceInterface = (*LoginGuardService)(nil)
+var _ InviteLinkServiceInterface = (*InviteLinkService)(nil)
+var _ APITokenServiceInterface = (*APITokenService)(nil)
+var _ BackupServiceInterface = (*BackupService)(nil)
+var _ EncryptionServiceInterface = (*EncryptionService)(nil)
+var _ PrivacyServiceInterface = (*PrivacyService)(nil)
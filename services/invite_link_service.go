@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/invitelink"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+var (
+	// ErrGuestNotFound is returned when an operation names a guest that does not exist
+	ErrGuestNotFound = errors.New("guest not found")
+	// ErrInviteLinkNotFound is returned when revoking a link that does not exist
+	ErrInviteLinkNotFound = errors.New("invite link not found")
+	// ErrInvalidInviteLink is returned for forged, expired and revoked links
+	ErrInvalidInviteLink = errors.New("invalid invite link")
+)
+
+// IssuedInviteLink is an invite link together with its URL
+type IssuedInviteLink struct {
+	models.InviteLink
+	URL string
+}
+
+// InviteLinkService issues invitation links that log a guest in directly,
+// for sending invitations over chat apps
+type InviteLinkService struct {
+	repo   repositories.InviteLinkRepository
+	guests GuestServiceInterface
+	signer *invitelink.Signer
+	now    func() time.Time
+}
+
+// NewInviteLinkService creates a new invite link service
+func NewInviteLinkService(repo repositories.InviteLinkRepository, guests GuestServiceInterface, signer *invitelink.Signer) *InviteLinkService {
+	return &InviteLinkService{repo: repo, guests: guests, signer: signer, now: time.Now}
+}
+
+// CreateLink issues a new link for a guest. Returns ErrGuestNotFound if the
+// guest does not exist.
+func (s *InviteLinkService) CreateLink(ctx context.Context, guestID int64) (*IssuedInviteLink, error) {
+	guest, err := s.guests.GetGuestByID(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return nil, ErrGuestNotFound
+	}
+
+	link, err := s.newLink(guestID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create([]models.InviteLink{*link}); err != nil {
+		return nil, err
+	}
+	link.GuestName = guest.Name
+	return s.issued(*link), nil
+}
+
+// GetGuestLinks returns a guest's links with their URLs, newest first
+func (s *InviteLinkService) GetGuestLinks(ctx context.Context, guestID int64) ([]IssuedInviteLink, error) {
+	links, err := s.repo.FindByGuestID(guestID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.guestName(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := make([]IssuedInviteLink, len(links))
+	for i, link := range links {
+		link.GuestName = name
+		issued[i] = *s.issued(link)
+	}
+	return issued, nil
+}
+
+// LinksForAllGuests returns a link for every guest, for sending out the
+// invitations in bulk. A guest's newest link is reused while it has more
+// than half of its lifetime left, so exporting twice sends the same links.
+// The links created by this call are returned separately.
+func (s *InviteLinkService) LinksForAllGuests(ctx context.Context) ([]IssuedInviteLink, []models.InviteLink, error) {
+	guests, err := s.guests.GetAllGuests(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	active, err := s.repo.FindActive(s.now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reuseAfter := s.now().Add(config.InviteLinkExpiry / 2)
+	current := make(map[int64]models.InviteLink)
+	for _, link := range active {
+		if _, seen := current[link.GuestID]; !seen && link.ExpiresAt.After(reuseAfter) {
+			current[link.GuestID] = link
+		}
+	}
+
+	var created []models.InviteLink
+	for _, guest := range guests {
+		if _, ok := current[guest.ID]; ok {
+			continue
+		}
+		link, err := s.newLink(guest.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		link.GuestName = guest.Name
+		created = append(created, *link)
+		current[guest.ID] = *link
+	}
+	if len(created) > 0 {
+		if err := s.repo.Create(created); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	issued := make([]IssuedInviteLink, len(guests))
+	for i, guest := range guests {
+		link := current[guest.ID]
+		link.GuestName = guest.Name
+		issued[i] = *s.issued(link)
+	}
+	return issued, created, nil
+}
+
+// Redeem verifies a link token and returns the guest it logs in. Forged,
+// expired and revoked links, and links of deleted guests, give
+// ErrInvalidInviteLink.
+func (s *InviteLinkService) Redeem(ctx context.Context, token string) (*models.Guest, error) {
+	now := s.now()
+	claims, err := s.signer.Verify(token, now)
+	if err != nil {
+		return nil, ErrInvalidInviteLink
+	}
+
+	link, err := s.repo.FindByID(claims.LinkID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil || link.GuestID != claims.GuestID || !link.IsActive(now) {
+		return nil, ErrInvalidInviteLink
+	}
+
+	guest, err := s.guests.GetGuestByID(ctx, link.GuestID)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return nil, ErrInvalidInviteLink
+	}
+
+	if err := s.repo.MarkUsed(link.ID, now); err != nil {
+		return nil, err
+	}
+	return guest, nil
+}
+
+// RevokeLink stops a single link from working. Sessions already started
+// with it are not signed out. The revoked link is returned.
+func (s *InviteLinkService) RevokeLink(ctx context.Context, id string) (*models.InviteLink, error) {
+	if err := s.repo.Revoke(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInviteLinkNotFound
+		}
+		return nil, err
+	}
+	link, err := s.repo.FindByID(id)
+	if err != nil || link == nil {
+		return link, err
+	}
+	if link.GuestName, err = s.guestName(ctx, link.GuestID); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// guestName returns the name shown with a guest's links; links of deleted
+// guests have none
+func (s *InviteLinkService) guestName(ctx context.Context, guestID int64) (string, error) {
+	guest, err := s.guests.GetGuestByID(ctx, guestID)
+	if err != nil || guest == nil {
+		return "", err
+	}
+	return guest.Name, nil
+}
+
+// newLink returns a link for a guest with a random ID, valid for the
+// configured lifetime
+func (s *InviteLinkService) newLink(guestID int64) (*models.InviteLink, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	// Tokens carry whole seconds
+	now := s.now().UTC().Truncate(time.Second)
+	return &models.InviteLink{
+		ID:        base64.RawURLEncoding.EncodeToString(b),
+		GuestID:   guestID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(config.InviteLinkExpiry),
+	}, nil
+}
+
+// issued adds the URL to a link. Tokens are deterministic, so the URL of a
+// stored link can be rebuilt at any time.
+func (s *InviteLinkService) issued(link models.InviteLink) *IssuedInviteLink {
+	token := s.signer.Sign(invitelink.Claims{
+		LinkID:    link.ID,
+		GuestID:   link.GuestID,
+		ExpiresAt: link.ExpiresAt,
+	})
+	return &IssuedInviteLink{
+		InviteLink: link,
+		URL:        config.InviteLinkBaseURL + "/invite/" + token,
+	}
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/invitelink"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockInviteLinkRepo implements repositories.InviteLinkRepository in
+// memory. Like the SQL repository, it knows nothing about guests.
+type mockInviteLinkRepo struct {
+	links map[string]*models.InviteLink
+}
+
+func (m *mockInviteLinkRepo) Create(links []models.InviteLink) error {
+	for _, link := range links {
+		link := link
+		link.GuestName = ""
+		m.links[link.ID] = &link
+	}
+	return nil
+}
+
+func (m *mockInviteLinkRepo) FindByID(id string) (*models.InviteLink, error) {
+	if link, ok := m.links[id]; ok {
+		copied := *link
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockInviteLinkRepo) find(match func(models.InviteLink) bool) []models.InviteLink {
+	links := []models.InviteLink{}
+	for _, link := range m.links {
+		if match(*link) {
+			links = append(links, *link)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].CreatedAt.After(links[j].CreatedAt) })
+	return links
+}
+
+func (m *mockInviteLinkRepo) FindByGuestID(guestID int64) ([]models.InviteLink, error) {
+	return m.find(func(link models.InviteLink) bool { return link.GuestID == guestID }), nil
+}
+
+func (m *mockInviteLinkRepo) FindActive(now time.Time) ([]models.InviteLink, error) {
+	return m.find(func(link models.InviteLink) bool { return link.IsActive(now) }), nil
+}
+
+func (m *mockInviteLinkRepo) Revoke(id string) error {
+	link, ok := m.links[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	link.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (m *mockInviteLinkRepo) MarkUsed(id string, at time.Time) error {
+	m.links[id].LastUsedAt = sql.NullTime{Time: at, Valid: true}
+	return nil
+}
+
+func newTestInviteLinkService(t *testing.T, guests ...models.Guest) (*InviteLinkService, *mockInviteLinkRepo) {
+	t.Helper()
+	baseURL, expiry := config.InviteLinkBaseURL, config.InviteLinkExpiry
+	t.Cleanup(func() { config.InviteLinkBaseURL, config.InviteLinkExpiry = baseURL, expiry })
+	config.InviteLinkBaseURL = "https://wedding.example.com"
+	config.InviteLinkExpiry = 48 * time.Hour
+
+	repo := &mockInviteLinkRepo{links: make(map[string]*models.InviteLink)}
+	guestService := &mockGuestService{
+		GetGuestByIDFunc: func(id int64) (*models.Guest, error) {
+			for _, guest := range guests {
+				if guest.ID == id {
+					guest := guest
+					return &guest, nil
+				}
+			}
+			return nil, nil
+		},
+		GetAllGuestsFunc: func() ([]models.Guest, error) {
+			return guests, nil
+		},
+	}
+	return NewInviteLinkService(repo, guestService, invitelink.NewSigner([]byte("secret"))), repo
+}
+
+func inviteToken(link *IssuedInviteLink) string {
+	return strings.TrimPrefix(link.URL, "https://wedding.example.com/invite/")
+}
+
+func TestInviteLinkService_CreateAndRedeem(t *testing.T) {
+	svc, repo := newTestInviteLinkService(t, models.Guest{ID: 1, Name: "alice"})
+
+	link, err := svc.CreateLink(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", link.GuestName)
+	assert.True(t, strings.HasPrefix(link.URL, "https://wedding.example.com/invite/1."))
+
+	guest, err := svc.Redeem(context.Background(), inviteToken(link))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), guest.ID)
+	assert.True(t, repo.links[link.ID].LastUsedAt.Valid)
+
+	_, err = svc.CreateLink(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrGuestNotFound)
+}
+
+func TestInviteLinkService_RedeemRejectsInvalidLinks(t *testing.T) {
+	svc, _ := newTestInviteLinkService(t, models.Guest{ID: 1, Name: "alice"})
+	link, err := svc.CreateLink(context.Background(), 1)
+	assert.NoError(t, err)
+
+	// Forged
+	_, err = svc.Redeem(context.Background(), inviteToken(link)+"x")
+	assert.ErrorIs(t, err, ErrInvalidInviteLink)
+
+	// Expired
+	svc.now = func() time.Time { return time.Now().Add(49 * time.Hour) }
+	_, err = svc.Redeem(context.Background(), inviteToken(link))
+	assert.ErrorIs(t, err, ErrInvalidInviteLink)
+	svc.now = time.Now
+
+	// Revoked
+	revoked, err := svc.RevokeLink(context.Background(), link.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Valid)
+	assert.Equal(t, "alice", revoked.GuestName)
+	_, err = svc.Redeem(context.Background(), inviteToken(link))
+	assert.ErrorIs(t, err, ErrInvalidInviteLink)
+
+	_, err = svc.RevokeLink(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrInviteLinkNotFound)
+}
+
+func TestInviteLinkService_LinksForAllGuests(t *testing.T) {
+	svc, repo := newTestInviteLinkService(t,
+		models.Guest{ID: 1, Name: "alice"},
+		models.Guest{ID: 2, Name: "bob"},
+	)
+	existing, err := svc.CreateLink(context.Background(), 1)
+	assert.NoError(t, err)
+
+	links, created, err := svc.LinksForAllGuests(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, existing.URL, links[0].URL, "fresh links are reused")
+	assert.Equal(t, "alice", links[0].GuestName)
+	assert.Equal(t, "bob", links[1].GuestName)
+	assert.Len(t, created, 1)
+	assert.Len(t, repo.links, 2)
+
+	// Exporting again gives the same links
+	again, created, err := svc.LinksForAllGuests(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, links, again)
+	assert.Empty(t, created)
+
+	// Links past half their lifetime are replaced
+	svc.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	renewed, created, err := svc.LinksForAllGuests(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, created, 2)
+	assert.NotEqual(t, links[0].URL, renewed[0].URL)
+}
+
+// With DB_DRIVER=postgres the SQLite guests table stays empty
+func TestInviteLinkService_GuestsOutsideSQLite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+
+	mock, _ := newTestInviteLinkService(t, models.Guest{ID: 7, Name: "alice"})
+	svc := NewInviteLinkService(repositories.NewSQLInviteLinkRepository(db, db), mock.guests, mock.signer)
+
+	link, err := svc.CreateLink(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", link.GuestName)
+
+	links, err := svc.GetGuestLinks(ctx, 7)
+	assert.NoError(t, err)
+	if assert.Len(t, links, 1) {
+		assert.Equal(t, link.URL, links[0].URL)
+		assert.Equal(t, "alice", links[0].GuestName)
+	}
+
+	all, created, err := svc.LinksForAllGuests(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, created)
+	assert.Equal(t, "alice", all[0].GuestName)
+
+	guest, err := svc.Redeem(ctx, inviteToken(link))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), guest.ID)
+
+	_, err = svc.CreateLink(ctx, 8)
+	assert.ErrorIs(t, err, ErrGuestNotFound)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+// maxLoginNameLength caps attempted names stored in login events
+const maxLoginNameLength = 100
+
+// LoginIPKey returns the lockout key for failed logins from an IP address
+func LoginIPKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+// LoginNameKey returns the lockout key for failed logins with a name,
+// ignoring case and surrounding spaces
+func LoginNameKey(name string) string {
+	return "name:" + strings.ToLower(strings.TrimSpace(name))
+}
+
+// LoginGuardService slows down guessing of guest names. Failed logins are
+// counted per IP address and per attempted name; past a threshold each
+// further failure locks the key for twice as long.
+type LoginGuardService struct {
+	repo repositories.LoginGuardRepository
+	now  func() time.Time
+}
+
+// NewLoginGuardService creates a new login guard service
+func NewLoginGuardService(repo repositories.LoginGuardRepository) *LoginGuardService {
+	return &LoginGuardService{repo: repo, now: time.Now}
+}
+
+// CheckLogin returns how long the caller must wait before trying to log in
+// from ipAddress or as name, or zero if neither is locked
+func (s *LoginGuardService) CheckLogin(ipAddress, name string) (time.Duration, error) {
+	lockouts, err := s.repo.GetLockouts(LoginIPKey(ipAddress), LoginNameKey(name))
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	var wait time.Duration
+	for _, lockout := range lockouts {
+		if lockout.LockedUntil.Valid && lockout.LockedUntil.Time.After(now) {
+			if remaining := lockout.LockedUntil.Time.Sub(now); remaining > wait {
+				wait = remaining
+			}
+		}
+	}
+
+	if wait > 0 {
+		if err := s.recordEvent(ipAddress, name, models.LoginOutcomeLocked); err != nil {
+			return 0, err
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure counts a login with an unknown name against both the IP
+// address and the name, locking either once it has failed too often
+func (s *LoginGuardService) RecordFailure(ipAddress, name string) error {
+	if err := s.recordEvent(ipAddress, name, models.LoginOutcomeUnknownName); err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	for _, key := range []string{LoginIPKey(ipAddress), LoginNameKey(name)} {
+		failures, err := s.repo.IncrementFailures(key, now, now.Add(-config.LoginFailureWindow))
+		if err != nil {
+			return err
+		}
+		if lockFor := loginBackoff(failures); lockFor > 0 {
+			if err := s.repo.Lock(key, now.Add(lockFor)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RecordSuccess records a successful login and forgets the name's failures.
+// The IP's failures are kept: one right guess after many wrong ones should
+// not reset the backoff of someone trying names.
+func (s *LoginGuardService) RecordSuccess(ipAddress, name string) error {
+	if err := s.recordEvent(ipAddress, name, models.LoginOutcomeSuccess); err != nil {
+		return err
+	}
+	_, err := s.repo.ClearLockout(LoginNameKey(name))
+	return err
+}
+
+// GetSuspiciousLogins reports current lockouts and the IPs and names with at
+// least the backoff threshold of failures since the given time
+func (s *LoginGuardService) GetSuspiciousLogins(since time.Time) (*models.SuspiciousLoginReport, error) {
+	return s.repo.GetSuspicious(since, s.now(), config.LoginBackoffThreshold)
+}
+
+// Unlock clears the failures and any lockout of key. It returns the cleared
+// record, or nil if the key had none.
+func (s *LoginGuardService) Unlock(key string) (*models.LoginLockout, error) {
+	lockouts, err := s.repo.GetLockouts(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(lockouts) == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.repo.ClearLockout(key); err != nil {
+		return nil, err
+	}
+	return &lockouts[0], nil
+}
+
+// PruneLoginEvents deletes login events older than the retention period
+func (s *LoginGuardService) PruneLoginEvents() (int64, error) {
+	return s.repo.DeleteEventsBefore(s.now().Add(-config.LoginEventRetention))
+}
+
+func (s *LoginGuardService) recordEvent(ipAddress, name, outcome string) error {
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > maxLoginNameLength {
+		name = string(runes[:maxLoginNameLength])
+	}
+	return s.repo.RecordEvent(&models.LoginEvent{
+		IPAddress: ipAddress,
+		Name:      name,
+		Outcome:   outcome,
+		CreatedAt: s.now().UTC(),
+	})
+}
+
+// loginBackoff returns how long to lock a key after its nth failure: nothing
+// below the threshold, then the base duration doubling per failure, capped
+// at the maximum
+func loginBackoff(failures int) time.Duration {
+	if failures < config.LoginBackoffThreshold {
+		return 0
+	}
+
+	lockFor := config.LoginBackoffBase
+	for i := config.LoginBackoffThreshold; i < failures && lockFor < config.LoginBackoffMax; i++ {
+		lockFor *= 2
+	}
+	if lockFor > config.LoginBackoffMax {
+		return config.LoginBackoffMax
+	}
+	return lockFor
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLoginGuardRepo implements repositories.LoginGuardRepository in memory
+type mockLoginGuardRepo struct {
+	lockouts map[string]*models.LoginLockout
+	events   []models.LoginEvent
+}
+
+func newMockLoginGuardRepo() *mockLoginGuardRepo {
+	return &mockLoginGuardRepo{lockouts: make(map[string]*models.LoginLockout)}
+}
+
+func (m *mockLoginGuardRepo) GetLockouts(keys ...string) ([]models.LoginLockout, error) {
+	var lockouts []models.LoginLockout
+	for _, key := range keys {
+		if lockout, ok := m.lockouts[key]; ok {
+			lockouts = append(lockouts, *lockout)
+		}
+	}
+	return lockouts, nil
+}
+
+func (m *mockLoginGuardRepo) IncrementFailures(key string, now, resetBefore time.Time) (int, error) {
+	lockout, ok := m.lockouts[key]
+	if !ok || lockout.LastFailureAt.Before(resetBefore) {
+		lockout = &models.LoginLockout{Key: key}
+		m.lockouts[key] = lockout
+	}
+	lockout.Failures++
+	lockout.LastFailureAt = now
+	return lockout.Failures, nil
+}
+
+func (m *mockLoginGuardRepo) Lock(key string, until time.Time) error {
+	m.lockouts[key].LockedUntil = sql.NullTime{Time: until, Valid: true}
+	return nil
+}
+
+func (m *mockLoginGuardRepo) ClearLockout(key string) (bool, error) {
+	_, ok := m.lockouts[key]
+	delete(m.lockouts, key)
+	return ok, nil
+}
+
+func (m *mockLoginGuardRepo) RecordEvent(event *models.LoginEvent) error {
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *mockLoginGuardRepo) GetSuspicious(since, now time.Time, minFailures int) (*models.SuspiciousLoginReport, error) {
+	return &models.SuspiciousLoginReport{}, nil
+}
+
+func (m *mockLoginGuardRepo) DeleteEventsBefore(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// setLoginBackoffConfig sets the backoff settings for a test
+func setLoginBackoffConfig(t *testing.T, threshold int, base, max time.Duration) {
+	oldThreshold, oldBase, oldMax, oldWindow := config.LoginBackoffThreshold, config.LoginBackoffBase, config.LoginBackoffMax, config.LoginFailureWindow
+	t.Cleanup(func() {
+		config.LoginBackoffThreshold, config.LoginBackoffBase, config.LoginBackoffMax, config.LoginFailureWindow = oldThreshold, oldBase, oldMax, oldWindow
+	})
+	config.LoginBackoffThreshold, config.LoginBackoffBase, config.LoginBackoffMax = threshold, base, max
+	config.LoginFailureWindow = time.Hour
+}
+
+func TestLoginBackoff(t *testing.T) {
+	setLoginBackoffConfig(t, 3, 30*time.Second, 5*time.Minute)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, 30 * time.Second},
+		{4, time.Minute},
+		{5, 2 * time.Minute},
+		{6, 4 * time.Minute},
+		{7, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, loginBackoff(tt.failures), "failures %d", tt.failures)
+	}
+}
+
+func TestLoginGuardService_LocksAfterRepeatedFailures(t *testing.T) {
+	setLoginBackoffConfig(t, 3, 30*time.Second, time.Hour)
+	repo := newMockLoginGuardRepo()
+	service := NewLoginGuardService(repo)
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	// Different names from one IP: only the IP reaches the threshold
+	for _, name := range []string{"aaron", "abby", "adam"} {
+		wait, err := service.CheckLogin("10.0.0.1", name)
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+		assert.NoError(t, service.RecordFailure("10.0.0.1", name))
+	}
+
+	wait, err := service.CheckLogin("10.0.0.1", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, wait)
+	assert.Equal(t, models.LoginOutcomeLocked, repo.events[len(repo.events)-1].Outcome)
+
+	// Other IPs are unaffected
+	wait, err = service.CheckLogin("10.0.0.2", "alice")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// The lockout expires
+	now = now.Add(31 * time.Second)
+	wait, err = service.CheckLogin("10.0.0.1", "alice")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+}
+
+func TestLoginGuardService_LocksNameAcrossIPs(t *testing.T) {
+	setLoginBackoffConfig(t, 2, time.Minute, time.Hour)
+	repo := newMockLoginGuardRepo()
+	service := NewLoginGuardService(repo)
+
+	assert.NoError(t, service.RecordFailure("10.0.0.1", "Jon Doe"))
+	assert.NoError(t, service.RecordFailure("10.0.0.2", " jon doe "))
+
+	wait, err := service.CheckLogin("10.0.0.3", "JON DOE")
+	assert.NoError(t, err)
+	assert.True(t, wait > 0)
+}
+
+func TestLoginGuardService_SuccessClearsNameOnly(t *testing.T) {
+	setLoginBackoffConfig(t, 5, time.Minute, time.Hour)
+	repo := newMockLoginGuardRepo()
+	service := NewLoginGuardService(repo)
+
+	assert.NoError(t, service.RecordFailure("10.0.0.1", "alise"))
+	assert.NoError(t, service.RecordFailure("10.0.0.1", "alice"))
+	assert.NoError(t, service.RecordSuccess("10.0.0.1", "Alice"))
+
+	assert.NotContains(t, repo.lockouts, LoginNameKey("alice"))
+	assert.Contains(t, repo.lockouts, LoginNameKey("alise"))
+	assert.Equal(t, 2, repo.lockouts[LoginIPKey("10.0.0.1")].Failures)
+	assert.Equal(t, models.LoginOutcomeSuccess, repo.events[len(repo.events)-1].Outcome)
+}
+
+func TestLoginGuardService_Unlock(t *testing.T) {
+	setLoginBackoffConfig(t, 1, time.Minute, time.Hour)
+	repo := newMockLoginGuardRepo()
+	service := NewLoginGuardService(repo)
+	assert.NoError(t, service.RecordFailure("10.0.0.1", "nobody"))
+
+	lockout, err := service.Unlock(LoginIPKey("10.0.0.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lockout.Failures)
+	wait, err := service.CheckLogin("10.0.0.1", "somebody")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	lockout, err = service.Unlock(LoginIPKey("10.0.0.1"))
+	assert.NoError(t, err)
+	assert.Nil(t, lockout)
+}
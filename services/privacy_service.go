@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"wedding-invitation-backend/media"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+// personalAuditFields are the guest fields that identify a guest, as named
+// in the changes of audit entries
+var personalAuditFields = []string{"Name", "DietaryRestrictions"}
+
+// rsvpAuditActions are the audit actions that make up a guest's RSVP history
+var rsvpAuditActions = map[string]bool{
+	models.AuditActionGuestCreate: true,
+	models.AuditActionGuestUpdate: true,
+	models.AuditActionGuestRSVP:   true,
+}
+
+// RSVPChange is an RSVP the guest submitted or an admin's change to the
+// guest's record, from the audit log
+type RSVPChange struct {
+	Action    string          `json:"action"`
+	Changes   json.RawMessage `json:"changes"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+// GuestDataExport is everything stored about a guest
+type GuestDataExport struct {
+	ExportedAt  time.Time           `json:"exported_at"`
+	Guest       *models.Guest       `json:"guest"`
+	RSVPHistory []RSVPChange        `json:"rsvp_history"`
+	Comments    []models.Comment    `json:"comments"`
+	Sessions    []models.Session    `json:"sessions"`
+	InviteLinks []models.InviteLink `json:"invite_links"`
+}
+
+// ForgottenGuest reports what ForgetGuest erased
+type ForgottenGuest struct {
+	Guest              *models.Guest `json:"guest"`
+	CommentsAnonymized int           `json:"comments_anonymized"`
+	PhotosDeleted      int           `json:"photos_deleted"`
+	models.GuestErasure
+}
+
+// PrivacyService exports and erases the personal data of a guest
+type PrivacyService struct {
+	guests   GuestServiceInterface
+	comments CommentServiceInterface
+	records  repositories.PrivacyRepository
+	tx       repositories.TxManager
+	photos   *media.Photos
+	onForget func()
+	now      func() time.Time
+}
+
+// NewPrivacyService creates a new privacy service. photos may be nil.
+// onForget, if set, runs after a guest was forgotten, e.g. to drop cached
+// copies of the guest's data.
+func NewPrivacyService(guests GuestServiceInterface, comments CommentServiceInterface, records repositories.PrivacyRepository, tx repositories.TxManager, photos *media.Photos, onForget func()) *PrivacyService {
+	return &PrivacyService{
+		guests:   guests,
+		comments: comments,
+		records:  records,
+		tx:       tx,
+		photos:   photos,
+		onForget: onForget,
+		now:      time.Now,
+	}
+}
+
+// ExportGuestData collects everything stored about a guest. The RSVP
+// history holds every RSVP the guest submitted and every change admins made
+// to the guest. Returns ErrGuestNotFound if the guest does not exist.
+func (s *PrivacyService) ExportGuestData(ctx context.Context, guestID int64) (*GuestDataExport, error) {
+	guest, err := s.guests.GetGuestByID(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if guest == nil {
+		return nil, ErrGuestNotFound
+	}
+
+	comments, err := s.comments.GetCommentsByGuest(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.records.FindGuestRecords(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &GuestDataExport{
+		ExportedAt:  s.now().UTC(),
+		Guest:       guest,
+		RSVPHistory: []RSVPChange{},
+		Comments:    comments,
+		Sessions:    records.Sessions,
+		InviteLinks: records.InviteLinks,
+	}
+	if export.Comments == nil {
+		export.Comments = []models.Comment{}
+	}
+	for _, entry := range records.AuditEntries {
+		if !rsvpAuditActions[entry.Action] {
+			continue
+		}
+		export.RSVPHistory = append(export.RSVPHistory, RSVPChange{
+			Action:    entry.Action,
+			Changes:   entry.Changes,
+			ChangedAt: entry.CreatedAt,
+		})
+	}
+	return export, nil
+}
+
+// ForgetGuest erases a guest's personal data while keeping the guest in the
+// RSVP counts and their comments in the guestbook:
+//   - the name and dietary restrictions are cleared; an empty name cannot
+//     log in, so the record can no longer be used
+//   - comments stay but no longer name their author, and their photos are
+//     deleted
+//   - sessions, invitation links, login events and lockouts of the name are
+//     deleted
+//   - the name and dietary restrictions are removed from audit entries about
+//     the guest, and the name from those about the guest's links, sessions,
+//     comments and lockout
+//
+// Forgetting a guest again is harmless, so a failed call can be retried.
+// Returns ErrGuestNotFound if the guest does not exist.
+func (s *PrivacyService) ForgetGuest(ctx context.Context, guestID int64) (*ForgottenGuest, error) {
+	result := &ForgottenGuest{}
+	var photoKeys []string
+	err := s.tx.WithinTx(ctx, func(ctx context.Context, repos repositories.Repositories) error {
+		guest, err := repos.Guests.GetByID(ctx, guestID)
+		if err != nil {
+			return err
+		}
+		if guest == nil {
+			return ErrGuestNotFound
+		}
+
+		comments, err := repos.Comments.GetByGuestID(ctx, guestID)
+		if err != nil {
+			return err
+		}
+		if photoKeys, err = repos.Comments.ClearPhotos(ctx, guestID); err != nil {
+			return err
+		}
+
+		// The other records go first, while the name still finds the login
+		// events; when guests live in Postgres this is a separate
+		// transaction, and a retry must see the name
+		erasure, err := s.records.EraseGuestRecords(ctx, guestID, guest.Name, LoginNameKey(guest.Name), personalAuditFields)
+		if err != nil {
+			return err
+		}
+
+		guest.Name = ""
+		guest.DietaryRestrictions = sql.NullString{}
+		if err := repos.Guests.Update(ctx, guest); err != nil {
+			return err
+		}
+
+		result.Guest = guest
+		result.CommentsAnonymized = len(comments)
+		result.GuestErasure = *erasure
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files go only once the database no longer refers to them
+	for _, key := range photoKeys {
+		if s.photos == nil {
+			log.Printf("Warning: cannot delete photo %s of forgotten guest %d: photo store unavailable", key, guestID)
+			continue
+		}
+		if err := s.photos.Delete(key); err != nil {
+			log.Printf("Warning: failed to delete photo %s of forgotten guest %d: %v", key, guestID, err)
+			continue
+		}
+		result.PhotosDeleted++
+	}
+
+	if s.onForget != nil {
+		s.onForget()
+	}
+	return result, nil
+}
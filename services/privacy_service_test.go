@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/database"
+	"wedding-invitation-backend/media"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+type privacyTestEnv struct {
+	service  *PrivacyService
+	rsvps    *GuestService
+	guests   repositories.GuestRepository
+	comments repositories.CommentRepository
+	db       *sql.DB
+	store    media.Store
+	photos   *media.Photos
+	forgets  int
+}
+
+// newPrivacyTestEnv keeps guests and comments in memory and the other
+// tables in SQLite, as in demo mode
+func newPrivacyTestEnv(t *testing.T) *privacyTestEnv {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := database.CreateSchema(db); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := media.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	photos := media.NewPhotos(store, 100, 50)
+
+	guests := repositories.NewMemoryGuestRepository()
+	comments := repositories.NewMemoryCommentRepository(guests)
+	tx := repositories.NewMemoryTxManager(guests, comments)
+	guestService := NewGuestService(guests, repositories.NewSQLAuditRepository(db, db), tx)
+	commentService := NewCommentService(comments, guestService, nil, nil, photos, tx)
+	t.Cleanup(func() { commentService.commentCache.Stop() })
+
+	env := &privacyTestEnv{rsvps: guestService, guests: guests, comments: comments, db: db, store: store, photos: photos}
+	env.service = NewPrivacyService(guestService, commentService, repositories.NewSQLPrivacyRepository(db, db), tx, photos, func() {
+		env.forgets++
+		guestService.ClearCache()
+		commentService.ClearCache()
+	})
+	return env
+}
+
+// seed stores a guest with a reply, a photo comment, a session and an
+// audit trail
+func (env *privacyTestEnv) seed(t *testing.T, name string) (*models.Guest, string) {
+	t.Helper()
+	ctx := context.Background()
+	guest := &models.Guest{
+		Name:                name,
+		Attending:           sql.NullBool{Bool: true, Valid: true},
+		PlusOnes:            1,
+		DietaryRestrictions: sql.NullString{String: "Vegetarian", Valid: true},
+	}
+	assert.NoError(t, env.guests.Create(ctx, guest))
+
+	photoKey, err := env.photos.Save(testPhoto(t))
+	assert.NoError(t, err)
+	assert.NoError(t, env.comments.Create(ctx, &models.Comment{GuestID: guest.ID, Content: "Congratulations from " + name, PhotoKey: photoKey}))
+
+	now := time.Now().UTC()
+	session := &models.Session{ID: name + "-session", GuestID: guest.ID, UserAgent: "Phone", IPAddress: "10.0.0.1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, session.Create(env.db, nil))
+	assert.NoError(t, models.CreateLoginEvent(env.db, &models.LoginEvent{IPAddress: "10.0.0.1", Name: name, Outcome: models.LoginOutcomeSuccess, CreatedAt: now}))
+
+	changes := `{"Name":{"before":null,"after":"` + name + `"},"Attending":{"before":null,"after":{"Bool":true,"Valid":true}}}`
+	assert.NoError(t, models.CreateAuditEntries(env.db, []models.AuditEntry{
+		{ActorName: "owner", Action: models.AuditActionGuestUpdate, TargetType: models.AuditTargetGuest, TargetID: strconv.FormatInt(guest.ID, 10), Changes: json.RawMessage(changes)},
+		{ActorName: "owner", Action: models.AuditActionGuestSessionsRevoke, TargetType: models.AuditTargetGuest, TargetID: strconv.FormatInt(guest.ID, 10), Changes: json.RawMessage(`{}`)},
+		{ActorName: "owner", Action: models.AuditActionInviteLinkCreate, TargetType: models.AuditTargetInviteLink, TargetID: "link-" + strconv.FormatInt(guest.ID, 10),
+			Changes: json.RawMessage(`{"GuestID":{"before":null,"after":` + strconv.FormatInt(guest.ID, 10) + `},"GuestName":{"before":null,"after":"` + name + `"}}`)},
+		{ActorName: "owner", Action: models.AuditActionLoginUnlock, TargetType: models.AuditTargetLoginLockout, TargetID: LoginNameKey(name),
+			Changes: json.RawMessage(`{"Key":{"before":"` + LoginNameKey(name) + `","after":null}}`)},
+	}))
+	return guest, photoKey
+}
+
+func TestPrivacyService_ExportGuestData(t *testing.T) {
+	env := newPrivacyTestEnv(t)
+	guest, _ := env.seed(t, "alice")
+	env.seed(t, "bob")
+	// The guest changes their mind
+	_, err := env.rsvps.SubmitRSVP(context.Background(), "alice", false)
+	assert.NoError(t, err)
+
+	export, err := env.service.ExportGuestData(context.Background(), guest.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", export.Guest.Name)
+	assert.Equal(t, "Vegetarian", export.Guest.DietaryRestrictions.String)
+	if assert.Len(t, export.Comments, 1) {
+		assert.Equal(t, "Congratulations from alice", export.Comments[0].Content)
+	}
+	if assert.Len(t, export.Sessions, 1) {
+		assert.Equal(t, "alice-session", export.Sessions[0].ID)
+	}
+	if assert.Len(t, export.RSVPHistory, 2, "only RSVP changes") {
+		actions := []string{export.RSVPHistory[0].Action, export.RSVPHistory[1].Action}
+		assert.ElementsMatch(t, []string{models.AuditActionGuestUpdate, models.AuditActionGuestRSVP}, actions)
+		for _, change := range export.RSVPHistory {
+			if change.Action == models.AuditActionGuestRSVP {
+				assert.JSONEq(t, `{"Attending":{"before":{"Bool":true,"Valid":true},"after":{"Bool":false,"Valid":true}}}`, string(change.Changes))
+			}
+		}
+	}
+	assert.NotNil(t, export.InviteLinks)
+
+	_, err = env.service.ExportGuestData(context.Background(), 999)
+	assert.ErrorIs(t, err, ErrGuestNotFound)
+}
+
+func TestPrivacyService_ForgetGuest(t *testing.T) {
+	ctx := context.Background()
+	env := newPrivacyTestEnv(t)
+	guest, photoKey := env.seed(t, "alice")
+	other, otherPhoto := env.seed(t, "bob")
+
+	forgotten, err := env.service.ForgetGuest(ctx, guest.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, env.forgets, "caches are cleared")
+	assert.Equal(t, 1, forgotten.CommentsAnonymized)
+	assert.Equal(t, 1, forgotten.PhotosDeleted)
+	assert.Equal(t, int64(1), forgotten.Sessions)
+	assert.Equal(t, int64(1), forgotten.LoginEvents)
+	assert.Equal(t, int64(3), forgotten.AuditEntries)
+
+	// The record stays for the counts, without anything identifying
+	stored, err := env.guests.GetByID(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, stored.Name)
+	assert.False(t, stored.DietaryRestrictions.Valid)
+	assert.True(t, stored.Attending.Bool)
+	assert.Equal(t, 1, stored.PlusOnes)
+
+	comments, err := env.comments.GetByGuestID(ctx, guest.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Empty(t, comments[0].PhotoKey)
+	}
+	_, _, err = env.store.Open(media.PhotoFileName(photoKey))
+	assert.Error(t, err, "the photo file is deleted")
+
+	export, err := env.service.ExportGuestData(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, export.Sessions)
+	if assert.Len(t, export.RSVPHistory, 1) {
+		assert.NotContains(t, string(export.RSVPHistory[0].Changes), "alice")
+		assert.Contains(t, string(export.RSVPHistory[0].Changes), "Attending")
+	}
+
+	var mentions int
+	assert.NoError(t, env.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE target_id || changes LIKE '%alice%'`).Scan(&mentions))
+	assert.Equal(t, 0, mentions, "the name is gone from the audit log")
+
+	// Other guests are untouched
+	kept, err := env.guests.GetByID(ctx, other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", kept.Name)
+	assert.NoError(t, env.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE target_id || changes LIKE '%bob%'`).Scan(&mentions))
+	assert.Equal(t, 3, mentions)
+	f, _, err := env.store.Open(media.PhotoFileName(otherPhoto))
+	assert.NoError(t, err)
+	f.Close()
+
+	// Forgetting again is harmless
+	forgotten, err = env.service.ForgetGuest(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, forgotten.PhotosDeleted)
+	assert.Equal(t, int64(0), forgotten.Sessions)
+
+	_, err = env.service.ForgetGuest(ctx, 999)
+	assert.ErrorIs(t, err, ErrGuestNotFound)
+}
+
+func TestPrivacyService_ForgetGuest_RollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	env := newPrivacyTestEnv(t)
+	guest, photoKey := env.seed(t, "alice")
+	env.db.Close()
+
+	_, err := env.service.ForgetGuest(ctx, guest.ID)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, env.forgets)
+	stored, err := env.guests.GetByID(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", stored.Name, "the name is kept so a retry finds the records")
+	comments, err := env.comments.GetByGuestID(ctx, guest.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, photoKey, comments[0].PhotoKey)
+	f, _, err := env.store.Open(media.PhotoFileName(photoKey))
+	assert.NoError(t, err)
+	f.Close()
+}
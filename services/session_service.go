@@ -0,0 +1,222 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"wedding-invitation-backend/cache"
+	"wedding-invitation-backend/config"
+	"wedding-invitation-backend/models"
+	"wedding-invitation-backend/repositories"
+)
+
+var (
+	// ErrSessionNotFound is returned when revoking a session that does not exist
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrInvalidRefreshToken is returned for unknown or expired refresh tokens
+	// and for tokens of revoked sessions
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token is replayed; the
+	// session it belongs to has been revoked
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// SessionService tracks guest logins so single devices can be signed out
+type SessionService struct {
+	sessionRepo  repositories.SessionRepository
+	sessionCache cache.CacheInterface
+}
+
+// NewSessionService creates a new session service. The cache holds the
+// result of recent revocation checks so most requests skip the database.
+func NewSessionService(sessionRepo repositories.SessionRepository, sessionCache cache.CacheInterface) *SessionService {
+	return &SessionService{
+		sessionRepo:  sessionRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+// StartSession records a new login for guest. It returns the session, whose
+// ID goes into access tokens as their jti claim, and the session's first
+// refresh token.
+func (ss *SessionService) StartSession(guest *models.Guest, userAgent, ipAddress string) (*models.Session, string, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	session := &models.Session{
+		ID:        id,
+		GuestID:   guest.ID,
+		GuestName: guest.Name,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+		CreatedAt: now,
+		ExpiresAt: now.Add(config.RefreshTokenExpiry),
+	}
+	refreshToken, stored, err := newRefreshToken(session.ID, now)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := ss.sessionRepo.Create(session, stored); err != nil {
+		return nil, "", err
+	}
+	return session, refreshToken, nil
+}
+
+// RefreshSession exchanges a refresh token for a new one and extends the
+// session. Each refresh token works once; presenting a used one again means
+// it was copied, so the whole session is revoked.
+func (ss *SessionService) RefreshSession(refreshToken string) (*models.Session, string, error) {
+	used, err := ss.sessionRepo.FindRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, "", err
+	}
+	if used == nil {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if used.UsedAt.Valid {
+		return nil, "", ss.revokeReusedSession(used.SessionID)
+	}
+
+	now := time.Now().UTC()
+	session, err := ss.sessionRepo.FindByID(used.SessionID)
+	if err != nil {
+		return nil, "", err
+	}
+	if session == nil || !session.IsActive(now) || !now.Before(used.ExpiresAt) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	next, stored, err := newRefreshToken(session.ID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := ss.sessionRepo.RotateRefreshToken(used, stored); err != nil {
+		if errors.Is(err, models.ErrRefreshTokenUsed) {
+			return nil, "", ss.revokeReusedSession(used.SessionID)
+		}
+		return nil, "", err
+	}
+
+	session.ExpiresAt = stored.ExpiresAt
+	return session, next, nil
+}
+
+// revokeReusedSession revokes the session of a replayed refresh token and
+// returns the error to report to the caller
+func (ss *SessionService) revokeReusedSession(id string) error {
+	log.Printf("Refresh token reused for session %s; revoking the session", id)
+	if err := ss.sessionRepo.Revoke(id); err != nil {
+		return err
+	}
+	ss.sessionCache.Delete("session_" + id)
+	return ErrRefreshTokenReused
+}
+
+// IsSessionActive reports whether the session exists and is neither revoked
+// nor expired (cached)
+func (ss *SessionService) IsSessionActive(id string) (bool, error) {
+	cacheKey := "session_" + id
+	if cached, found := ss.sessionCache.Get(cacheKey); found {
+		if active, ok := cached.(bool); ok {
+			return active, nil
+		}
+	}
+
+	session, err := ss.sessionRepo.FindByID(id)
+	if err != nil {
+		return false, err
+	}
+
+	active := session != nil && session.IsActive(time.Now())
+	ss.sessionCache.Set(cacheKey, active)
+	return active, nil
+}
+
+// GetSession returns a session by ID, or nil if it does not exist
+func (ss *SessionService) GetSession(id string) (*models.Session, error) {
+	return ss.sessionRepo.FindByID(id)
+}
+
+// GetGuestSessions returns a guest's sessions, newest first
+func (ss *SessionService) GetGuestSessions(guestID int64) ([]models.Session, error) {
+	return ss.sessionRepo.FindByGuestID(guestID)
+}
+
+// RevokeSession signs out one session. The revoked session is returned so
+// callers can tell which guest it belonged to.
+func (ss *SessionService) RevokeSession(id string) (*models.Session, error) {
+	session, err := ss.sessionRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	if err := ss.sessionRepo.Revoke(id); err != nil {
+		return nil, err
+	}
+	ss.sessionCache.Delete("session_" + id)
+
+	return ss.sessionRepo.FindByID(id)
+}
+
+// RevokeGuestSessions signs out every active session of a guest and returns
+// the IDs of the sessions it revoked
+func (ss *SessionService) RevokeGuestSessions(guestID int64) ([]string, error) {
+	ids, err := ss.sessionRepo.RevokeByGuestID(guestID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		ss.sessionCache.Delete("session_" + id)
+	}
+	return ids, nil
+}
+
+// PruneExpiredSessions deletes sessions whose tokens have expired
+func (ss *SessionService) PruneExpiredSessions() (int64, error) {
+	return ss.sessionRepo.DeleteExpired(time.Now())
+}
+
+// newRefreshToken returns a random refresh token for a session and the
+// record to store for it, which holds only the token's hash
+func newRefreshToken(sessionID string, now time.Time) (string, *models.RefreshToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, &models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: hashRefreshToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(config.RefreshTokenExpiry),
+	}, nil
+}
+
+// hashRefreshToken returns the hex SHA-256 of a refresh token. The tokens
+// are random, so a fast unsalted hash is enough.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newSessionID returns a random 128-bit hex session ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
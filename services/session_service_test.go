@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"wedding-invitation-backend/cache"
+	"wedding-invitation-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSessionRepo implements repositories.SessionRepository with an
+// in-memory map and counts lookups so tests can check caching
+type mockSessionRepo struct {
+	sessions      map[string]*models.Session
+	refreshTokens map[string]*models.RefreshToken
+	lookups       int
+}
+
+func newMockSessionRepo() *mockSessionRepo {
+	return &mockSessionRepo{
+		sessions:      make(map[string]*models.Session),
+		refreshTokens: make(map[string]*models.RefreshToken),
+	}
+}
+
+func (m *mockSessionRepo) Create(session *models.Session, refreshToken *models.RefreshToken) error {
+	copied := *session
+	m.sessions[session.ID] = &copied
+	if refreshToken != nil {
+		m.refreshTokens[refreshToken.TokenHash] = refreshToken
+	}
+	return nil
+}
+
+func (m *mockSessionRepo) FindByID(id string) (*models.Session, error) {
+	m.lookups++
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (m *mockSessionRepo) FindByGuestID(guestID int64) ([]models.Session, error) {
+	var sessions []models.Session
+	for _, session := range m.sessions {
+		if session.GuestID == guestID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepo) Revoke(id string) error {
+	m.sessions[id].RevokedAt.Time = time.Now()
+	m.sessions[id].RevokedAt.Valid = true
+	return nil
+}
+
+func (m *mockSessionRepo) RevokeByGuestID(guestID int64) ([]string, error) {
+	var ids []string
+	for id, session := range m.sessions {
+		if session.GuestID == guestID && !session.RevokedAt.Valid {
+			m.Revoke(id)
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockSessionRepo) DeleteExpired(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockSessionRepo) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	token, ok := m.refreshTokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (m *mockSessionRepo) RotateRefreshToken(used, next *models.RefreshToken) error {
+	stored := m.refreshTokens[used.TokenHash]
+	if stored.UsedAt.Valid {
+		return models.ErrRefreshTokenUsed
+	}
+	stored.UsedAt.Time = next.CreatedAt
+	stored.UsedAt.Valid = true
+	m.refreshTokens[next.TokenHash] = next
+	m.sessions[next.SessionID].ExpiresAt = next.ExpiresAt
+	return nil
+}
+
+func newTestSessionService(repo *mockSessionRepo) *SessionService {
+	return NewSessionService(repo, cache.NewMemoryCache(time.Minute))
+}
+
+func TestSessionService_StartSession(t *testing.T) {
+	repo := newMockSessionRepo()
+	service := newTestSessionService(repo)
+
+	first, refreshToken, err := service.StartSession(&models.Guest{ID: 7, Name: "alice"}, "Mozilla/5.0", "10.0.0.1")
+	assert.NoError(t, err)
+	second, _, err := service.StartSession(&models.Guest{ID: 7}, "curl/8.0", "10.0.0.2")
+	assert.NoError(t, err)
+
+	assert.Len(t, first.ID, 32)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, int64(7), first.GuestID)
+	assert.True(t, first.ExpiresAt.After(first.CreatedAt))
+	assert.Equal(t, "alice", first.GuestName)
+	assert.Len(t, repo.sessions, 2)
+
+	// Only the hash of the refresh token is stored
+	assert.NotEmpty(t, refreshToken)
+	assert.Contains(t, repo.refreshTokens, hashRefreshToken(refreshToken))
+	assert.NotContains(t, repo.refreshTokens, refreshToken)
+}
+
+func TestSessionService_IsSessionActiveIsCached(t *testing.T) {
+	repo := newMockSessionRepo()
+	service := newTestSessionService(repo)
+	session, _, err := service.StartSession(&models.Guest{ID: 7}, "", "")
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		active, err := service.IsSessionActive(session.ID)
+		assert.NoError(t, err)
+		assert.True(t, active)
+	}
+	assert.Equal(t, 1, repo.lookups)
+
+	active, err := service.IsSessionActive("unknown")
+	assert.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestSessionService_RevokeInvalidatesCache(t *testing.T) {
+	repo := newMockSessionRepo()
+	service := newTestSessionService(repo)
+	first, _, _ := service.StartSession(&models.Guest{ID: 7}, "", "")
+	second, _, _ := service.StartSession(&models.Guest{ID: 7}, "", "")
+
+	// Warm the cache
+	service.IsSessionActive(first.ID)
+	service.IsSessionActive(second.ID)
+
+	revoked, err := service.RevokeSession(first.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Valid)
+	active, _ := service.IsSessionActive(first.ID)
+	assert.False(t, active)
+
+	ids, err := service.RevokeGuestSessions(7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{second.ID}, ids)
+	active, _ = service.IsSessionActive(second.ID)
+	assert.False(t, active)
+}
+
+func TestSessionService_RevokeUnknownSession(t *testing.T) {
+	service := newTestSessionService(newMockSessionRepo())
+
+	_, err := service.RevokeSession("missing")
+
+	assert.True(t, errors.Is(err, ErrSessionNotFound))
+}
+
+func TestSessionService_RefreshSessionRotates(t *testing.T) {
+	repo := newMockSessionRepo()
+	service := newTestSessionService(repo)
+	session, first, err := service.StartSession(&models.Guest{ID: 7, Name: "alice"}, "", "")
+	assert.NoError(t, err)
+
+	refreshed, second, err := service.RefreshSession(first)
+	assert.NoError(t, err)
+	assert.Equal(t, session.ID, refreshed.ID)
+	assert.Equal(t, "alice", refreshed.GuestName)
+	assert.NotEqual(t, first, second)
+	assert.False(t, refreshed.ExpiresAt.Before(session.ExpiresAt))
+
+	// The new token works in turn
+	_, _, err = service.RefreshSession(second)
+	assert.NoError(t, err)
+}
+
+func TestSessionService_RefreshTokenReuseRevokesSession(t *testing.T) {
+	repo := newMockSessionRepo()
+	service := newTestSessionService(repo)
+	session, first, _ := service.StartSession(&models.Guest{ID: 7}, "", "")
+	_, second, err := service.RefreshSession(first)
+	assert.NoError(t, err)
+
+	// Replaying the first token signs the whole session out
+	_, _, err = service.RefreshSession(first)
+	assert.True(t, errors.Is(err, ErrRefreshTokenReused))
+	active, _ := service.IsSessionActive(session.ID)
+	assert.False(t, active)
+
+	// Including the token the legitimate holder got
+	_, _, err = service.RefreshSession(second)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+}
+
+func TestSessionService_RefreshInvalidToken(t *testing.T) {
+	repo := newMockSessionRepo()
+	service := newTestSessionService(repo)
+
+	_, _, err := service.RefreshSession("not-a-token")
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+
+	session, token, _ := service.StartSession(&models.Guest{ID: 7}, "", "")
+	repo.sessions[session.ID].ExpiresAt = time.Now().Add(-time.Minute)
+	_, _, err = service.RefreshSession(token)
+	assert.True(t, errors.Is(err, ErrInvalidRefreshToken))
+}